@@ -9,12 +9,57 @@ import (
 
 func main() {
 	repo := setup.Repository()
-	email, invoice := setup.Services()
-	producer, consumer := setup.Kafka(repo)
+	invoices := setup.InvoiceRepository()
+	clients := setup.Clients()
+	calendar := setup.BusinessCalendar()
+	optOuts := setup.OptOutRepository()
+	optOutUseCase := setup.OptOutUseCase(optOuts)
+	email, invoice := setup.Services(clients, calendar, optOutUseCase)
+	deliveries := setup.EmailDeliveryRepository()
+	invalidEmails := setup.InvalidEmailRepository()
+	contacts := setup.ContactPreferenceRepository()
+	webhookSubscriptions := setup.WebhookSubscriptionRepository()
+	webhookDeliveries := setup.WebhookDeliveryRepository()
+	webhooks := setup.WebhookPublisher(webhookSubscriptions, webhookDeliveries)
+	issueUseCase := setup.IssueInvoiceUseCase(invoice, invoices, invalidEmails, contacts, webhooks)
+	producer, consumer := setup.Kafka(repo, issueUseCase)
 	defer setup.CloseKafka(producer, consumer)
 
-	useCase := setup.UseCase(repo, email, invoice, producer)
-	router := setup.Routes(useCase)
+	stopDispatcher := setup.OutboxDispatcher(repo, deliveries, invalidEmails, optOuts, setup.Notifiers(email), webhooks)
+	defer stopDispatcher()
+
+	stopWebhooks := setup.WebhookDispatcher(webhookSubscriptions, webhookDeliveries)
+	defer stopWebhooks()
+
+	stopDunning := setup.DunningScheduler(repo, invoices, contacts, invalidEmails, clients, setup.LockRepository())
+	defer stopDunning()
+
+	emailFeedbackUseCase := setup.EmailFeedbackUseCase(repo, deliveries, invalidEmails)
+	stopDSNPoller := setup.DSNPoller(emailFeedbackUseCase)
+	defer stopDSNPoller()
+
+	paymentProducer := setup.PaymentProducer()
+	defer paymentProducer.Close()
+
+	jobs := setup.IngestionJobRepository()
+	useCase := setup.UseCase(repo, email, invoice, producer, webhooks, jobs)
+	fileIntakeUseCase := setup.FileIntakeUseCase(useCase, clients)
+	stopDropFolder := setup.DropFolder(fileIntakeUseCase, jobs)
+	defer stopDropFolder()
+
+	stopSFTP := setup.SFTPSources(fileIntakeUseCase, jobs)
+	defer stopSFTP()
+
+	uploadUseCase := setup.ResumableUploadUseCase(setup.UploadStore())
+	stopUploadCleanup := setup.UploadCleanup(uploadUseCase)
+	defer stopUploadCleanup()
+
+	reconcileUseCase := setup.ReconcileUseCase(invoices, clients, calendar, webhooks)
+	paymentUseCase := setup.PaymentUseCase(invoices, setup.PaymentEventRepository(), paymentProducer, clients, calendar, webhooks)
+	installmentUseCase := setup.InstallmentPlanUseCase(invoices, setup.InstallmentPlanRepository(), invoice)
+	router := setup.Routes(fileIntakeUseCase, reconcileUseCase, paymentUseCase, installmentUseCase, emailFeedbackUseCase, setup.ContactPreferencesUseCase(contacts), optOutUseCase,
+		setup.WebhookSubscriptionUseCase(webhookSubscriptions, webhookDeliveries), setup.IngestDebtsUseCase(useCase, jobs),
+		uploadUseCase)
 
 	if err := router.Run(fmt.Sprintf(":%v", config.GetEnv("HTTP_PORT", "8084"))); err != nil {
 		log.Fatalf("Erro ao iniciar o servidor: %v", err)
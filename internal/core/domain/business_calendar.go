@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"time"
+)
+
+// Holiday representa um feriado em data fixa de um ano específico (YYYY-MM-DD) ou
+// recorrente todos os anos (MM-DD).
+type Holiday struct {
+	Date string
+	Name string
+}
+
+// BusinessCalendar identifica dias úteis bancários considerando os feriados nacionais,
+// incluindo os móveis calculados a partir da Páscoa, e feriados municipais configurados.
+type BusinessCalendar struct {
+	dated     map[string]string
+	recurring map[string]string
+}
+
+func NewBusinessCalendar(holidays ...Holiday) *BusinessCalendar {
+	calendar := &BusinessCalendar{
+		dated:     make(map[string]string),
+		recurring: make(map[string]string),
+	}
+
+	for _, holiday := range holidays {
+		if len(holiday.Date) == len("01-02") {
+			calendar.recurring[holiday.Date] = holiday.Name
+			continue
+		}
+
+		calendar.dated[holiday.Date] = holiday.Name
+	}
+
+	return calendar
+}
+
+func (c *BusinessCalendar) Holiday(day time.Time) (string, bool) {
+	if name, ok := nationalHolidays(day.Year())[day.Format("01-02")]; ok {
+		return name, true
+	}
+
+	if name, ok := c.dated[day.Format(time.DateOnly)]; ok {
+		return name, true
+	}
+
+	name, ok := c.recurring[day.Format("01-02")]
+
+	return name, ok
+}
+
+func (c *BusinessCalendar) IsBusinessDay(day time.Time) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+
+	_, holiday := c.Holiday(day)
+
+	return !holiday
+}
+
+// NextBusinessDay retorna o próprio dia quando ele é útil ou o primeiro dia útil seguinte,
+// que é quando um boleto vencido em fim de semana ou feriado pode ser pago sem encargos.
+func (c *BusinessCalendar) NextBusinessDay(day time.Time) time.Time {
+	for !c.IsBusinessDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return day
+}
+
+func nationalHolidays(year int) map[string]string {
+	easter := EasterSunday(year)
+	holidays := map[string]string{
+		"01-01": "Confraternização Universal",
+		"04-21": "Tiradentes",
+		"05-01": "Dia do Trabalho",
+		"09-07": "Independência do Brasil",
+		"10-12": "Nossa Senhora Aparecida",
+		"11-02": "Finados",
+		"11-15": "Proclamação da República",
+		"12-25": "Natal",
+	}
+
+	if year >= 2024 {
+		holidays["11-20"] = "Dia Nacional de Zumbi e da Consciência Negra"
+	}
+
+	holidays[easter.AddDate(0, 0, -48).Format("01-02")] = "Carnaval"
+	holidays[easter.AddDate(0, 0, -47).Format("01-02")] = "Carnaval"
+	holidays[easter.AddDate(0, 0, -2).Format("01-02")] = "Sexta-feira Santa"
+	holidays[easter.AddDate(0, 0, 60).Format("01-02")] = "Corpus Christi"
+
+	return holidays
+}
+
+// EasterSunday calcula o domingo de Páscoa pelo algoritmo de Meeus/Jones/Butcher.
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
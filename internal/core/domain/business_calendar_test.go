@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(value string) time.Time {
+	parsed, _ := time.Parse(time.DateOnly, value)
+
+	return parsed
+}
+
+func TestEasterSunday(t *testing.T) {
+	assert.Equal(t, date("2024-03-31"), EasterSunday(2024))
+	assert.Equal(t, date("2025-04-20"), EasterSunday(2025))
+	assert.Equal(t, date("2026-04-05"), EasterSunday(2026))
+}
+
+func TestBusinessCalendar_Holiday(t *testing.T) {
+	calendar := NewBusinessCalendar(
+		Holiday{Date: "01-25", Name: "Aniversário de São Paulo"},
+		Holiday{Date: "2025-07-09", Name: "Revolução Constitucionalista"},
+	)
+
+	tests := []struct {
+		day      string
+		expected string
+	}{
+		{"2025-03-03", "Carnaval"},
+		{"2025-03-04", "Carnaval"},
+		{"2025-04-18", "Sexta-feira Santa"},
+		{"2025-06-19", "Corpus Christi"},
+		{"2025-11-20", "Dia Nacional de Zumbi e da Consciência Negra"},
+		{"2025-12-25", "Natal"},
+		{"2026-01-25", "Aniversário de São Paulo"},
+		{"2025-07-09", "Revolução Constitucionalista"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.day, func(t *testing.T) {
+			name, ok := calendar.Holiday(date(tt.day))
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+
+	_, ok := calendar.Holiday(date("2026-07-09"))
+	assert.False(t, ok, "feriado com ano definido não deve se repetir")
+
+	_, ok = calendar.Holiday(date("2023-11-20"))
+	assert.False(t, ok, "Consciência Negra só é feriado nacional a partir de 2024")
+}
+
+func TestBusinessCalendar_NextBusinessDay(t *testing.T) {
+	calendar := NewBusinessCalendar()
+
+	assert.Equal(t, date("2025-01-13"), calendar.NextBusinessDay(date("2025-01-11")), "sábado vai para segunda")
+	assert.Equal(t, date("2025-03-05"), calendar.NextBusinessDay(date("2025-03-03")), "Carnaval vai para quarta de cinzas")
+	assert.Equal(t, date("2025-04-22"), calendar.NextBusinessDay(date("2025-04-18")), "Sexta Santa, fim de semana e Tiradentes")
+	assert.Equal(t, date("2025-01-14"), calendar.NextBusinessDay(date("2025-01-14")), "dia útil permanece")
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// ChargePolicy define os encargos aplicados a um débito pago fora do vencimento.
+type ChargePolicy struct {
+	FinePercent                 float64 `json:"finePercent"`
+	MonthlyInterestPercent      float64 `json:"monthlyInterestPercent"`
+	EarlyPaymentDiscountPercent float64 `json:"earlyPaymentDiscountPercent"`
+	EarlyPaymentDiscountDays    int     `json:"earlyPaymentDiscountDays"`
+}
+
+type Charges struct {
+	Principal float64 `json:"principal"`
+	Fine      float64 `json:"fine"`
+	Interest  float64 `json:"interest"`
+	Discount  float64 `json:"discount"`
+	Total     float64 `json:"total"`
+	DaysLate  int     `json:"days_late"`
+}
+
+// Calculate aplica a multa (percentual fixo), os juros de mora (taxa mensal rateada por
+// dia corrido de atraso) e o desconto por pagamento antecipado sobre o valor principal,
+// considerando a data de pagamento ou de reemissão informada em at. O débito só é
+// considerado em atraso depois do vencimento efetivo (próximo dia útil), mas os dias de
+// atraso são contados a partir do vencimento original.
+func (p ChargePolicy) Calculate(amount float64, dueDate, effectiveDueDate, at time.Time) Charges {
+	charges := Charges{Principal: amount}
+
+	daysLate := daysBetween(dueDate, at)
+	switch {
+	case daysBetween(effectiveDueDate, at) > 0:
+		charges.DaysLate = daysLate
+		charges.Fine = roundCents(amount * p.FinePercent / 100)
+		charges.Interest = roundCents(amount * p.MonthlyInterestPercent / 100 / 30 * float64(daysLate))
+	case p.EarlyPaymentDiscountPercent > 0 && -daysLate >= p.EarlyPaymentDiscountDays:
+		charges.Discount = roundCents(amount * p.EarlyPaymentDiscountPercent / 100)
+	}
+
+	charges.Total = roundCents(amount + charges.Fine + charges.Interest - charges.Discount)
+
+	return charges
+}
+
+func daysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
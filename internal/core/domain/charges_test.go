@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChargePolicy_Calculate(t *testing.T) {
+	policy := ChargePolicy{
+		FinePercent:                 2,
+		MonthlyInterestPercent:      1,
+		EarlyPaymentDiscountPercent: 5,
+		EarlyPaymentDiscountDays:    10,
+	}
+	dueDate := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected Charges
+	}{
+		{
+			name:     "Pagamento no vencimento",
+			at:       dueDate,
+			expected: Charges{Principal: 1000, Total: 1000},
+		},
+		{
+			name:     "Pagamento com 15 dias de atraso",
+			at:       dueDate.AddDate(0, 0, 15),
+			expected: Charges{Principal: 1000, Fine: 20, Interest: 5, Total: 1025, DaysLate: 15},
+		},
+		{
+			name:     "Pagamento antecipado dentro do prazo de desconto",
+			at:       dueDate.AddDate(0, 0, -10),
+			expected: Charges{Principal: 1000, Discount: 50, Total: 950},
+		},
+		{
+			name:     "Pagamento antecipado fora do prazo de desconto",
+			at:       dueDate.AddDate(0, 0, -9),
+			expected: Charges{Principal: 1000, Total: 1000},
+		},
+		{
+			name:     "Horário do pagamento não altera os dias de atraso",
+			at:       time.Date(2025, 1, 11, 23, 59, 0, 0, time.UTC),
+			expected: Charges{Principal: 1000, Fine: 20, Interest: 0.33, Total: 1020.33, DaysLate: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.Calculate(1000, dueDate, dueDate, tt.at))
+		})
+	}
+}
+
+func TestChargePolicy_ZeroPolicy(t *testing.T) {
+	dueDate := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	charges := ChargePolicy{}.Calculate(150.75, dueDate, dueDate, dueDate.AddDate(0, 1, 0))
+
+	assert.Equal(t, 150.75, charges.Total)
+	assert.Equal(t, 31, charges.DaysLate)
+}
+
+func TestChargePolicy_EffectiveDueDate(t *testing.T) {
+	policy := ChargePolicy{FinePercent: 2, MonthlyInterestPercent: 3}
+	dueDate := date("2025-01-11")
+	effectiveDueDate := date("2025-01-13")
+
+	t.Run("Pagamento no próximo dia útil não gera encargos", func(t *testing.T) {
+		charges := policy.Calculate(1000, dueDate, effectiveDueDate, effectiveDueDate)
+		assert.Equal(t, Charges{Principal: 1000, Total: 1000}, charges)
+	})
+
+	t.Run("Atraso após o dia útil conta desde o vencimento original", func(t *testing.T) {
+		charges := policy.Calculate(1000, dueDate, effectiveDueDate, date("2025-01-14"))
+		assert.Equal(t, 3, charges.DaysLate)
+		assert.Equal(t, 3.0, charges.Interest)
+	})
+}
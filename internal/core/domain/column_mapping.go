@@ -0,0 +1,248 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidColumnMapping = errors.New("mapeamento de colunas inválido")
+	ErrMissingColumns       = errors.New("colunas obrigatórias ausentes")
+)
+
+// DebtField identifica um campo do débito lido das colunas de um arquivo.
+type DebtField string
+
+const (
+	DebtFieldName         DebtField = "name"
+	DebtFieldGovernmentID DebtField = "governmentId"
+	DebtFieldEmail        DebtField = "email"
+	DebtFieldDebtAmount   DebtField = "debtAmount"
+	DebtFieldDebtDueDate  DebtField = "debtDueDate"
+	DebtFieldDebtID       DebtField = "debtId"
+	DebtFieldLocale       DebtField = "locale"
+)
+
+// DebtFields são os campos obrigatórios de uma linha de débito, na ordem do cabeçalho
+// padrão dos arquivos.
+var DebtFields = []DebtField{
+	DebtFieldName,
+	DebtFieldGovernmentID,
+	DebtFieldEmail,
+	DebtFieldDebtAmount,
+	DebtFieldDebtDueDate,
+	DebtFieldDebtID,
+}
+
+// OptionalDebtFields são os campos que os arquivos podem não ter. Sem a coluna, o campo
+// fica vazio; um locale vazio usa o idioma do cliente.
+var OptionalDebtFields = []DebtField{
+	DebtFieldLocale,
+}
+
+// ColumnSpec indica onde um campo está no arquivo: pelo nome da coluna, aceitando
+// qualquer um dos Headers, ou pela Position da coluna, a partir de 1. Position tem
+// precedência sobre Headers.
+type ColumnSpec struct {
+	Headers  []string
+	Position int
+}
+
+// UnmarshalJSON aceita o nome de uma coluna ("cpf"), uma lista de nomes
+// (["cpf", "documento"]) ou a posição da coluna (2).
+func (s *ColumnSpec) UnmarshalJSON(data []byte) error {
+	var header string
+	if err := json.Unmarshal(data, &header); err == nil {
+		*s = ColumnSpec{Headers: []string{header}}
+
+		return nil
+	}
+
+	var headers []string
+	if err := json.Unmarshal(data, &headers); err == nil {
+		*s = ColumnSpec{Headers: headers}
+
+		return nil
+	}
+
+	var position int
+	if err := json.Unmarshal(data, &position); err == nil {
+		*s = ColumnSpec{Position: position}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: esperado o nome, uma lista de nomes ou a posição da coluna", ErrInvalidColumnMapping)
+}
+
+// ColumnMapping associa os campos do débito às colunas do arquivo. Os campos ausentes do
+// mapeamento usam os nomes de DefaultColumnMapping.
+type ColumnMapping map[DebtField]ColumnSpec
+
+// DefaultColumnMapping aceita o cabeçalho padrão e seus nomes mais comuns em português.
+var DefaultColumnMapping = ColumnMapping{
+	DebtFieldName:         {Headers: []string{"name", "nome"}},
+	DebtFieldGovernmentID: {Headers: []string{"governmentId", "cpf", "cnpj", "cpf/cnpj", "documento"}},
+	DebtFieldEmail:        {Headers: []string{"email"}},
+	DebtFieldDebtAmount:   {Headers: []string{"debtAmount", "valor"}},
+	DebtFieldDebtDueDate:  {Headers: []string{"debtDueDate", "vencimento", "data de vencimento"}},
+	DebtFieldDebtID:       {Headers: []string{"debtId", "id"}},
+	DebtFieldLocale:       {Headers: []string{"locale", "idioma"}},
+}
+
+func (m ColumnMapping) Validate() error {
+	for field, spec := range m {
+		if !slices.Contains(DebtFields, field) && !slices.Contains(OptionalDebtFields, field) {
+			return fmt.Errorf("%w: campo desconhecido %q", ErrInvalidColumnMapping, field)
+		}
+
+		if spec.Position < 0 || (spec.Position == 0 && len(spec.Headers) == 0) {
+			return fmt.Errorf("%w: campo %s sem coluna", ErrInvalidColumnMapping, field)
+		}
+	}
+
+	return nil
+}
+
+// Merge devolve o mapeamento com os campos de override substituindo os de m.
+func (m ColumnMapping) Merge(override ColumnMapping) ColumnMapping {
+	merged := make(ColumnMapping, len(m)+len(override))
+	for field, spec := range m {
+		merged[field] = spec
+	}
+
+	for field, spec := range override {
+		merged[field] = spec
+	}
+
+	return merged
+}
+
+// Resolve localiza no cabeçalho a coluna de cada campo. Os nomes são comparados sem
+// diferenciar maiúsculas, espaços e pontuação, e colunas que não correspondem a nenhum
+// campo são ignoradas. Todos os campos obrigatórios ausentes são informados no erro.
+func (m ColumnMapping) Resolve(headers []string) (DebtColumns, error) {
+	mapping := DefaultColumnMapping.Merge(m)
+	columns := make(DebtColumns, len(DebtFields))
+
+	var missing []string
+	for _, field := range DebtFields {
+		index, found := mapping[field].find(headers)
+		if !found {
+			missing = append(missing, string(field))
+
+			continue
+		}
+
+		columns[field] = index
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingColumns, strings.Join(missing, ", "))
+	}
+
+	for _, field := range OptionalDebtFields {
+		if index, found := mapping[field].find(headers); found {
+			columns[field] = index
+		}
+	}
+
+	return columns, nil
+}
+
+func (s ColumnSpec) find(headers []string) (int, bool) {
+	if s.Position > 0 {
+		return s.Position - 1, s.Position <= len(headers)
+	}
+
+	for _, name := range s.Headers {
+		for index, header := range headers {
+			if normalizeHeader(header) == normalizeHeader(name) {
+				return index, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// DebtColumns é a posição, a partir de zero, de cada campo nas linhas do arquivo.
+type DebtColumns map[DebtField]int
+
+// Record extrai os campos do débito de uma linha do arquivo.
+func (c DebtColumns) Record(row []string) (DebtRecord, error) {
+	record := make(DebtRecord, len(c))
+	for field, index := range c {
+		if index >= len(row) {
+			return nil, fmt.Errorf("linha com %d colunas, sem a coluna %d (%s)", len(row), index+1, field)
+		}
+
+		record[field] = strings.TrimSpace(row[index])
+	}
+
+	return record, nil
+}
+
+// DebtRecord são os campos de um débito como lidos do arquivo, antes da validação.
+type DebtRecord map[DebtField]string
+
+// NewDebtRecord converte um débito para os campos lidos dos arquivos. Os campos opcionais
+// vazios ficam de fora, como em um arquivo sem a coluna.
+func NewDebtRecord(debt Debt) DebtRecord {
+	record := DebtRecord{
+		DebtFieldName:         debt.Name,
+		DebtFieldGovernmentID: debt.GovernmentID,
+		DebtFieldEmail:        debt.Email,
+		DebtFieldDebtAmount:   strconv.FormatFloat(debt.DebtAmount, 'f', -1, 64),
+		DebtFieldDebtDueDate:  debt.DebtDueDate,
+		DebtFieldDebtID:       debt.DebtID,
+	}
+
+	if debt.Locale != "" {
+		record[DebtFieldLocale] = debt.Locale
+	}
+
+	return record
+}
+
+// Debt monta o débito a partir dos campos já validados.
+func (r DebtRecord) Debt(clientID string) Debt {
+	amount, _ := strconv.ParseFloat(r[DebtFieldDebtAmount], 64)
+
+	return Debt{
+		Name:         r[DebtFieldName],
+		GovernmentID: r[DebtFieldGovernmentID],
+		Email:        r[DebtFieldEmail],
+		DebtAmount:   amount,
+		DebtDueDate:  r[DebtFieldDebtDueDate],
+		DebtID:       r[DebtFieldDebtID],
+		ClientID:     clientID,
+		Locale:       r[DebtFieldLocale],
+	}
+}
+
+// accents remove os acentos do português dos nomes de coluna, para que "Código" e
+// "codigo" sejam a mesma coluna.
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+func normalizeHeader(header string) string {
+	return accents.Replace(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, header))
+}
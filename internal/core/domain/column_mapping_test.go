@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnMapping_Resolve(t *testing.T) {
+	t.Run("Cabeçalho padrão", func(t *testing.T) {
+		columns, err := ColumnMapping(nil).Resolve([]string{"name", "governmentId", "email", "debtAmount", "debtDueDate", "debtId"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, DebtColumns{
+			DebtFieldName: 0, DebtFieldGovernmentID: 1, DebtFieldEmail: 2,
+			DebtFieldDebtAmount: 3, DebtFieldDebtDueDate: 4, DebtFieldDebtID: 5,
+		}, columns)
+	})
+
+	t.Run("Nomes alternativos, outra ordem e colunas extras", func(t *testing.T) {
+		mapping := ColumnMapping{DebtFieldDebtID: {Headers: []string{"Nº do contrato"}}, DebtFieldName: {Position: 2}}
+
+		columns, err := mapping.Resolve([]string{"Nº do Contrato", "Devedor", "CPF", "Observação", "E-mail", "Valor", "Data de Vencimento"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, DebtColumns{
+			DebtFieldName: 1, DebtFieldGovernmentID: 2, DebtFieldEmail: 4,
+			DebtFieldDebtAmount: 5, DebtFieldDebtDueDate: 6, DebtFieldDebtID: 0,
+		}, columns)
+	})
+
+	t.Run("Coluna opcional de idioma", func(t *testing.T) {
+		columns, err := ColumnMapping(nil).Resolve([]string{"nome", "cpf", "email", "valor", "vencimento", "id", "Idioma"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 6, columns[DebtFieldLocale])
+
+		mapping := ColumnMapping{DebtFieldLocale: {Headers: []string{"language"}}}
+		assert.NoError(t, mapping.Validate())
+	})
+
+	t.Run("Colunas ausentes", func(t *testing.T) {
+		_, err := ColumnMapping{DebtFieldDebtID: {Position: 9}}.Resolve([]string{"nome", "cpf", "valor"})
+
+		assert.ErrorIs(t, err, ErrMissingColumns)
+		assert.Contains(t, err.Error(), "email, debtDueDate, debtId")
+	})
+}
+
+func TestDebtColumns_Record(t *testing.T) {
+	columns := DebtColumns{
+		DebtFieldName: 0, DebtFieldGovernmentID: 1, DebtFieldEmail: 2,
+		DebtFieldDebtAmount: 3, DebtFieldDebtDueDate: 4, DebtFieldDebtID: 5,
+	}
+
+	record, err := columns.Record([]string{"John Doe", " 1234 ", "john@example.com", "100.50", "2025-01-01", "d1", "extra"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", record[DebtFieldGovernmentID])
+	assert.Equal(t, Debt{
+		Name: "John Doe", GovernmentID: "1234", Email: "john@example.com", DebtAmount: 100.5,
+		DebtDueDate: "2025-01-01", DebtID: "d1", ClientID: "acme",
+	}, record.Debt("acme"))
+
+	columns[DebtFieldLocale] = 6
+	record, err = columns.Record([]string{"John Doe", "1234", "john@example.com", "100.5", "2025-01-01", "d1", "en-US"})
+	assert.NoError(t, err)
+	assert.Equal(t, "en-US", record.Debt("acme").Locale)
+	assert.Equal(t, record, NewDebtRecord(record.Debt("acme")))
+
+	_, err = columns.Record([]string{"John Doe", "1234"})
+	assert.Error(t, err)
+}
+
+func TestColumnMapping_JSON(t *testing.T) {
+	var mapping ColumnMapping
+	err := json.Unmarshal([]byte(`{"governmentId": "cpf", "email": ["email", "contato"], "debtAmount": 4}`), &mapping)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mapping.Validate())
+	assert.Equal(t, ColumnMapping{
+		DebtFieldGovernmentID: {Headers: []string{"cpf"}},
+		DebtFieldEmail:        {Headers: []string{"email", "contato"}},
+		DebtFieldDebtAmount:   {Position: 4},
+	}, mapping)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"email": true}`), &mapping))
+	assert.ErrorIs(t, ColumnMapping{"cpf": {Headers: []string{"documento"}}}.Validate(), ErrInvalidColumnMapping)
+	assert.ErrorIs(t, ColumnMapping{DebtFieldEmail: {Position: -1}}.Validate(), ErrInvalidColumnMapping)
+}
@@ -7,4 +7,41 @@ type Debt struct {
 	DebtAmount   float64 `json:"DebtAmount"`
 	DebtDueDate  string  `json:"DebtDueDate"`
 	DebtID       string  `json:"DebtID"`
+	ClientID     string  `json:"ClientID,omitempty"`
+	// Locale é o idioma das notificações do devedor (por exemplo, pt-BR ou en-US); vazio
+	// usa o idioma do cliente.
+	Locale string `json:"Locale,omitempty"`
+}
+
+// FileOptions reúne as informações enviadas junto com um arquivo que não fazem parte
+// de suas linhas.
+type FileOptions struct {
+	ClientID string
+	// Sheet é a aba lida das planilhas XLSX; vazio lê a primeira aba.
+	Sheet string
+	// Columns localiza os campos do débito no cabeçalho do arquivo; vazio usa os nomes de
+	// DefaultColumnMapping.
+	Columns ColumnMapping
+	// Format é o formato do texto dos arquivos CSV. Os campos vazios são detectados a
+	// partir do conteúdo do arquivo.
+	Format FileFormat
+	// JobID é o envio em que os totais do arquivo são registrados; vazio não registra.
+	JobID string
+}
+
+// FileFormat descreve o texto de um arquivo CSV: o separador de colunas, a codificação dos
+// caracteres e se o arquivo começa com um BOM.
+type FileFormat struct {
+	Delimiter string `json:"Delimiter"`
+	Encoding  string `json:"Encoding"`
+	BOM       bool   `json:"BOM"`
+}
+
+// Comma devolve o separador de colunas, usando a vírgula quando não informado.
+func (f FileFormat) Comma() rune {
+	for _, r := range f.Delimiter {
+		return r
+	}
+
+	return ','
 }
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var ErrInvalidDunningCadence = errors.New("régua de cobrança inválida")
+
+// DunningCadence é a régua de cobrança: os dias, relativos ao vencimento do boleto, em que
+// o devedor recebe um lembrete. Valores negativos são anteriores ao vencimento.
+type DunningCadence []int
+
+// DefaultDunningCadence envia lembretes 5 dias e 1 dia antes do vencimento, no dia do
+// vencimento e 3 e 15 dias depois dele.
+var DefaultDunningCadence = DunningCadence{-5, -1, 0, 3, 15}
+
+func (c DunningCadence) Validate() error {
+	if !slices.IsSorted(c) {
+		return fmt.Errorf("%w: os dias devem estar em ordem crescente", ErrInvalidDunningCadence)
+	}
+
+	if len(slices.Compact(slices.Clone(c))) != len(c) {
+		return fmt.Errorf("%w: dias repetidos", ErrInvalidDunningCadence)
+	}
+
+	return nil
+}
+
+// Stage devolve a etapa da régua a ser enviada em today para um boleto com vencimento em
+// dueDate e emitido em issuedOn: a etapa mais recente já alcançada. Etapas anteriores à
+// emissão do boleto são ignoradas, e etapas perdidas (por exemplo, com o agendador parado)
+// não são enviadas em sequência, apenas a mais recente.
+func (c DunningCadence) Stage(dueDate, issuedOn, today time.Time) (int, bool) {
+	daysFromDue := daysBetween(dueDate, today)
+	issuedDaysFromDue := daysBetween(dueDate, issuedOn)
+
+	stage, found := 0, false
+	for _, offset := range c {
+		if offset <= daysFromDue && offset > issuedDaysFromDue {
+			stage, found = offset, true
+		}
+	}
+
+	return stage, found
+}
+
+// DunningStageLabel formata a etapa da régua como D-5, D+0 ou D+3.
+func DunningStageLabel(daysFromDue int) string {
+	if daysFromDue < 0 {
+		return fmt.Sprintf("D%d", daysFromDue)
+	}
+
+	return fmt.Sprintf("D+%d", daysFromDue)
+}
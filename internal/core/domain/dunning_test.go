@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDunningCadence_Stage(t *testing.T) {
+	due := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	issued := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return due.AddDate(0, 0, d).Add(14 * time.Hour) }
+
+	tests := []struct {
+		name  string
+		today time.Time
+		stage int
+		found bool
+	}{
+		{name: "Antes da primeira etapa", today: day(-6), found: false},
+		{name: "D-5", today: day(-5), stage: -5, found: true},
+		{name: "Entre etapas mantém a última alcançada", today: day(-3), stage: -5, found: true},
+		{name: "Dia do vencimento", today: day(0), stage: 0, found: true},
+		{name: "Etapas perdidas enviam apenas a mais recente", today: day(20), stage: 15, found: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, found := DefaultDunningCadence.Stage(due, issued, tt.today)
+
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.stage, stage)
+		})
+	}
+
+	t.Run("Etapas anteriores à emissão são ignoradas", func(t *testing.T) {
+		_, found := DefaultDunningCadence.Stage(due, day(-2), day(-2))
+		assert.False(t, found)
+
+		stage, found := DefaultDunningCadence.Stage(due, day(-2), day(-1))
+		assert.True(t, found)
+		assert.Equal(t, -1, stage)
+	})
+}
+
+func TestDunningCadence_Validate(t *testing.T) {
+	assert.NoError(t, DefaultDunningCadence.Validate())
+	assert.NoError(t, DunningCadence{}.Validate())
+	assert.ErrorIs(t, DunningCadence{3, -1}.Validate(), ErrInvalidDunningCadence)
+	assert.ErrorIs(t, DunningCadence{0, 0}.Validate(), ErrInvalidDunningCadence)
+}
+
+func TestDebtReminderKey(t *testing.T) {
+	invoice := Invoice{Debt: Debt{DebtID: "d1"}, NossoNumero: "1"}
+
+	assert.Equal(t, "debt_reminder:d1:1:D-5", DebtReminderKey(invoice, -5))
+	assert.Equal(t, "debt_reminder:d1:1:D+0", DebtReminderKey(invoice, 0))
+	assert.Equal(t, "debt_reminder:d1:1:D+3", NewDebtReminder(invoice, NotificationRoute{}, 3, time.Now()).ID)
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type EmailDeliveryStatus string
+
+const (
+	EmailDeliveryQueued     EmailDeliveryStatus = "queued"
+	EmailDeliverySent       EmailDeliveryStatus = "sent"
+	EmailDeliveryDeferred   EmailDeliveryStatus = "deferred"
+	EmailDeliveryBounced    EmailDeliveryStatus = "bounced"
+	EmailDeliveryComplained EmailDeliveryStatus = "complained"
+)
+
+// EmailDelivery registra uma tentativa de envio de e-mail de cobrança para um débito.
+type EmailDelivery struct {
+	ID                string              `json:"ID"`
+	NotificationID    string              `json:"NotificationID"`
+	DebtID            string              `json:"DebtID"`
+	GovernmentID      string              `json:"GovernmentID"`
+	Email             string              `json:"Email"`
+	Attempt           int                 `json:"Attempt"`
+	ProviderMessageID string              `json:"ProviderMessageID,omitempty"`
+	Status            EmailDeliveryStatus `json:"Status"`
+	Reason            string              `json:"Reason,omitempty"`
+	CreatedAt         time.Time           `json:"CreatedAt"`
+	UpdatedAt         time.Time           `json:"UpdatedAt"`
+}
+
+type EmailFeedbackType string
+
+const (
+	EmailFeedbackBounce    EmailFeedbackType = "bounce"
+	EmailFeedbackComplaint EmailFeedbackType = "complaint"
+	EmailFeedbackDelay     EmailFeedbackType = "delay"
+)
+
+// EmailFeedback é um retorno assíncrono sobre um e-mail enviado, recebido por webhook do
+// provedor ou extraído de uma notificação de status de entrega (DSN).
+type EmailFeedback struct {
+	ProviderMessageID string
+	Email             string
+	Type              EmailFeedbackType
+	Permanent         bool
+	Reason            string
+	OccurredAt        time.Time
+}
+
+// InvalidatesEmail indica se o retorno torna o endereço inutilizável: bounces permanentes
+// e reclamações de spam.
+func (f EmailFeedback) InvalidatesEmail() bool {
+	return f.Type == EmailFeedbackComplaint || (f.Type == EmailFeedbackBounce && f.Permanent)
+}
+
+func (d *EmailDelivery) ApplyFeedback(feedback EmailFeedback) {
+	switch {
+	case feedback.Type == EmailFeedbackComplaint:
+		d.Status = EmailDeliveryComplained
+	case feedback.Type == EmailFeedbackBounce && feedback.Permanent:
+		d.Status = EmailDeliveryBounced
+	case d.Status != EmailDeliveryBounced && d.Status != EmailDeliveryComplained:
+		d.Status = EmailDeliveryDeferred
+	}
+
+	d.Reason = feedback.Reason
+	d.UpdatedAt = feedback.OccurredAt
+}
+
+// InvalidEmail marca o e-mail de um devedor como inválido. Novos débitos do mesmo
+// GovernmentID com esse e-mail são sinalizados para cobrança por outro canal.
+type InvalidEmail struct {
+	GovernmentID string    `json:"GovernmentID"`
+	Email        string    `json:"Email"`
+	Reason       string    `json:"Reason"`
+	MarkedAt     time.Time `json:"MarkedAt"`
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
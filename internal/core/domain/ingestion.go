@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+type IngestionStatus string
+
+// MaxIngestionResults limita os resultados guardados de um envio; os itens seguintes
+// entram apenas nos totais.
+const MaxIngestionResults = 1000
+
+const (
+	IngestionAccepted  IngestionStatus = "accepted"
+	IngestionDuplicate IngestionStatus = "duplicate"
+	IngestionInvalid   IngestionStatus = "invalid"
+	IngestionFailed    IngestionStatus = "failed"
+)
+
+// IngestionResult é o resultado de um débito enviado pela API. Index é a posição do débito
+// no corpo da requisição, a partir de zero.
+type IngestionResult struct {
+	Index  int             `json:"Index"`
+	DebtID string          `json:"DebtID,omitempty"`
+	Status IngestionStatus `json:"Status"`
+	Error  string          `json:"Error,omitempty"`
+}
+
+// IngestionJob reúne os resultados de um envio de débitos, em JSON, NDJSON ou por arquivo.
+// Os envios guardam os totais e, nos envios pela API, apenas os resultados dos itens
+// inválidos ou com falha, para que o job não cresça com o tamanho do envio. Nos envios por
+// arquivo, apenas os totais são registrados.
+type IngestionJob struct {
+	ID       string `json:"ID"`
+	Source   string `json:"Source"`
+	ClientID string `json:"ClientID,omitempty"`
+	FileName string `json:"FileName,omitempty"`
+	// Format é o formato detectado ou informado dos arquivos CSV.
+	Format *FileFormat `json:"Format,omitempty"`
+	// Error é o motivo do descarte do arquivo inteiro, como colunas obrigatórias ausentes.
+	Error      string            `json:"Error,omitempty"`
+	Total      int               `json:"Total"`
+	Accepted   int               `json:"Accepted"`
+	Duplicates int               `json:"Duplicates"`
+	Invalid    int               `json:"Invalid"`
+	Failed     int               `json:"Failed"`
+	Results    []IngestionResult `json:"Results"`
+	// OmittedResults conta os itens inválidos ou com falha além de MaxIngestionResults,
+	// sem resultado guardado.
+	OmittedResults int       `json:"OmittedResults,omitempty"`
+	CreatedAt      time.Time `json:"CreatedAt"`
+	FinishedAt     time.Time `json:"FinishedAt"`
+}
+
+// Record soma o item aos totais e guarda seu resultado quando ele é inválido ou falhou.
+func (j *IngestionJob) Record(result IngestionResult) {
+	j.Count(result.Status)
+
+	if result.Status != IngestionInvalid && result.Status != IngestionFailed {
+		return
+	}
+
+	if len(j.Results) < MaxIngestionResults {
+		j.Results = append(j.Results, result)
+	} else {
+		j.OmittedResults++
+	}
+}
+
+// Count soma um item aos totais do envio sem registrar seu resultado.
+func (j *IngestionJob) Count(status IngestionStatus) {
+	j.Total++
+
+	switch status {
+	case IngestionAccepted:
+		j.Accepted++
+	case IngestionDuplicate:
+		j.Duplicates++
+	case IngestionInvalid:
+		j.Invalid++
+	case IngestionFailed:
+		j.Failed++
+	}
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxInstallments limita o parcelamento ao que cabe nos dois dígitos do sufixo das parcelas.
+const MaxInstallments = 99
+
+var ErrInstallmentPlanExists = errors.New("débito já possui parcelamento")
+
+type InstallmentPlan struct {
+	DebtID                 string        `json:"debt_id"`
+	OriginalAmount         float64       `json:"original_amount"`
+	MonthlyInterestPercent float64       `json:"monthly_interest_percent"`
+	IntervalDays           int           `json:"interval_days"`
+	Total                  float64       `json:"total"`
+	Installments           []Installment `json:"installments"`
+	CreatedAt              time.Time     `json:"created_at"`
+}
+
+type Installment struct {
+	Number      int           `json:"number"`
+	DebtID      string        `json:"debt_id"`
+	Amount      float64       `json:"amount"`
+	DueDate     string        `json:"due_date"`
+	NossoNumero string        `json:"nosso_numero"`
+	Status      InvoiceStatus `json:"status"`
+}
+
+func NewInstallmentPlan(
+	debtID string,
+	amount float64,
+	count int,
+	firstDueDate time.Time,
+	intervalDays int,
+	monthlyInterestPercent float64,
+	at time.Time,
+) InstallmentPlan {
+	plan := InstallmentPlan{
+		DebtID:                 debtID,
+		OriginalAmount:         amount,
+		MonthlyInterestPercent: monthlyInterestPercent,
+		IntervalDays:           intervalDays,
+		Installments:           BuildInstallments(debtID, amount, count, firstDueDate, intervalDays, monthlyInterestPercent),
+		CreatedAt:              at,
+	}
+
+	for _, installment := range plan.Installments {
+		plan.Total += installment.Amount
+	}
+
+	plan.Total = roundCents(plan.Total)
+
+	return plan
+}
+
+// InstallmentDebtID identifica o débito filho gerado para uma parcela.
+func InstallmentDebtID(debtID string, number int) string {
+	return fmt.Sprintf("%s-P%02d", debtID, number)
+}
+
+// BuildInstallments divide o valor em parcelas iguais pela tabela Price, convertendo a taxa
+// mensal para o intervalo entre parcelas. A diferença de arredondamento fica na última parcela.
+func BuildInstallments(debtID string, amount float64, count int, firstDueDate time.Time, intervalDays int, monthlyInterestPercent float64) []Installment {
+	rate := math.Pow(1+monthlyInterestPercent/100, float64(intervalDays)/30) - 1
+
+	payment := amount / float64(count)
+	if rate > 0 {
+		payment = amount * rate / (1 - math.Pow(1+rate, -float64(count)))
+	}
+
+	payment = roundCents(payment)
+	total := roundCents(payment * float64(count))
+	if rate == 0 {
+		total = roundCents(amount)
+	}
+
+	installments := make([]Installment, count)
+	remaining := total
+	for i := range installments {
+		value := payment
+		if i == count-1 {
+			value = roundCents(remaining)
+		}
+
+		remaining -= value
+		installments[i] = Installment{
+			Number:  i + 1,
+			DebtID:  InstallmentDebtID(debtID, i+1),
+			Amount:  value,
+			DueDate: firstDueDate.AddDate(0, 0, i*intervalDays).Format(time.DateOnly),
+		}
+	}
+
+	return installments
+}
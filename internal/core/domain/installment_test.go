@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInstallments_WithoutInterest(t *testing.T) {
+	installments := BuildInstallments("abc", 100, 3, date("2025-01-10"), 30, 0)
+
+	assert.Len(t, installments, 3)
+	assert.Equal(t, Installment{Number: 1, DebtID: "abc-P01", Amount: 33.33, DueDate: "2025-01-10"}, installments[0])
+	assert.Equal(t, "2025-02-09", installments[1].DueDate)
+	assert.Equal(t, "abc-P03", installments[2].DebtID)
+	assert.Equal(t, 33.34, installments[2].Amount, "a última parcela absorve o arredondamento")
+}
+
+func TestBuildInstallments_WithInterest(t *testing.T) {
+	installments := BuildInstallments("abc", 1000, 4, date("2025-01-10"), 30, 2)
+
+	var total float64
+	for _, installment := range installments {
+		assert.InDelta(t, 262.62, installment.Amount, 0.01)
+		total += installment.Amount
+	}
+
+	assert.InDelta(t, 1050.48, total, 0.01)
+}
+
+func TestNewInstallmentPlan(t *testing.T) {
+	plan := NewInstallmentPlan("abc", 100, 3, date("2025-01-10"), 15, 0, date("2025-01-01"))
+
+	assert.Equal(t, 100.0, plan.Total)
+	assert.Equal(t, 100.0, plan.OriginalAmount)
+	assert.Equal(t, "2025-01-25", plan.Installments[1].DueDate)
+}
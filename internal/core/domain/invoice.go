@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceStatusIssued     InvoiceStatus = "issued"
+	InvoiceStatusRegistered InvoiceStatus = "registered"
+	InvoiceStatusPaid       InvoiceStatus = "paid"
+	InvoiceStatusPartial    InvoiceStatus = "partially_paid"
+	InvoiceStatusRejected   InvoiceStatus = "rejected"
+	InvoiceStatusCancelled  InvoiceStatus = "cancelled"
+)
+
+var (
+	ErrInvoiceAlreadyPaid = errors.New("boleto já foi liquidado")
+	ErrInvoiceRejected    = errors.New("boleto foi rejeitado pelo banco")
+	ErrInvoiceCancelled   = errors.New("boleto foi cancelado")
+)
+
+type Invoice struct {
+	Debt             Debt          `json:"Debt"`
+	NossoNumero      string        `json:"NossoNumero"`
+	PixTxID          string        `json:"PixTxID"`
+	Barcode          string        `json:"Barcode,omitempty"`
+	DigitableLine    string        `json:"DigitableLine,omitempty"`
+	PixCopyPaste     string        `json:"PixCopyPaste,omitempty"`
+	Amount           float64       `json:"Amount"`
+	DueDate          string        `json:"DueDate"`
+	OriginalDueDate  string        `json:"OriginalDueDate,omitempty"`
+	EffectiveDueDate string        `json:"EffectiveDueDate,omitempty"`
+	Charges          *Charges      `json:"Charges,omitempty"`
+	Status           InvoiceStatus `json:"Status"`
+	PaidAmount       float64       `json:"PaidAmount,omitempty"`
+	PaidDate         string        `json:"PaidDate,omitempty"`
+	Fees             float64       `json:"Fees,omitempty"`
+	RejectionReason  string        `json:"RejectionReason,omitempty"`
+	AlternateChannel bool          `json:"AlternateChannel,omitempty"`
+	PaymentEvents    []string      `json:"PaymentEvents,omitempty"`
+	IssuedAt         time.Time     `json:"IssuedAt"`
+	UpdatedAt        time.Time     `json:"UpdatedAt"`
+}
+
+func (i *Invoice) Register(at time.Time) error {
+	switch i.Status {
+	case InvoiceStatusPaid:
+		return ErrInvoiceAlreadyPaid
+	case InvoiceStatusRejected:
+		return ErrInvoiceRejected
+	case InvoiceStatusCancelled:
+		return ErrInvoiceCancelled
+	}
+
+	i.Status = InvoiceStatusRegistered
+	i.UpdatedAt = at
+
+	return nil
+}
+
+// MarkPaid registra um pagamento e considera o boleto liquidado quando o total pago
+// cobre amountDue, o valor devido na data do pagamento já com os encargos.
+func (i *Invoice) MarkPaid(amount float64, paidDate string, fees float64, amountDue float64, at time.Time) error {
+	switch i.Status {
+	case InvoiceStatusPaid:
+		return ErrInvoiceAlreadyPaid
+	case InvoiceStatusRejected:
+		return ErrInvoiceRejected
+	case InvoiceStatusCancelled:
+		return ErrInvoiceCancelled
+	}
+
+	i.PaidAmount = roundCents(i.PaidAmount + amount)
+	i.PaidDate = paidDate
+	i.Fees = roundCents(i.Fees + fees)
+	i.UpdatedAt = at
+
+	i.Status = InvoiceStatusPartial
+	if i.PaidAmount >= roundCents(amountDue) {
+		i.Status = InvoiceStatusPaid
+	}
+
+	return nil
+}
+
+// HasPaymentEvent indica se o aviso de pagamento eventKey, no formato provedor:evento, já
+// foi registrado no boleto.
+func (i Invoice) HasPaymentEvent(eventKey string) bool {
+	for _, key := range i.PaymentEvents {
+		if key == eventKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Cancel baixa um boleto em aberto, por exemplo quando o débito é substituído por um
+// parcelamento.
+func (i *Invoice) Cancel(at time.Time) error {
+	switch i.Status {
+	case InvoiceStatusPaid, InvoiceStatusPartial:
+		return ErrInvoiceAlreadyPaid
+	case InvoiceStatusCancelled:
+		return ErrInvoiceCancelled
+	}
+
+	i.Status = InvoiceStatusCancelled
+	i.UpdatedAt = at
+
+	return nil
+}
+
+// IsOpen indica se o boleto ainda pode ser pago.
+func (i Invoice) IsOpen() bool {
+	switch i.Status {
+	case InvoiceStatusIssued, InvoiceStatusRegistered, InvoiceStatusPartial:
+		return true
+	}
+
+	return false
+}
+
+// PrincipalDueDate é o vencimento sobre o qual os encargos são calculados; boletos
+// reemitidos com atraso mantêm o vencimento original do débito.
+func (i Invoice) PrincipalDueDate() string {
+	if i.OriginalDueDate != "" {
+		return i.OriginalDueDate
+	}
+
+	return i.DueDate
+}
+
+func (i *Invoice) Reject(reason string, at time.Time) error {
+	if i.Status == InvoiceStatusPaid {
+		return ErrInvoiceAlreadyPaid
+	}
+
+	i.Status = InvoiceStatusRejected
+	i.RejectionReason = reason
+	i.UpdatedAt = at
+
+	return nil
+}
+
+func NormalizeNossoNumero(nossoNumero string) string {
+	normalized := strings.TrimLeft(strings.TrimSpace(nossoNumero), "0")
+	if normalized == "" && nossoNumero != "" {
+		return "0"
+	}
+
+	return normalized
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelSMS      Channel = "sms"
+	ChannelWhatsApp Channel = "whatsapp"
+)
+
+// DefaultChannelOrder é a ordem de tentativa dos canais quando o devedor não definiu
+// preferências: e-mail primeiro e, se ele for recusado, SMS e WhatsApp.
+var DefaultChannelOrder = []Channel{ChannelEmail, ChannelSMS, ChannelWhatsApp}
+
+// ErrPermanentDeliveryFailure indica que o canal recusou o destinatário de forma definitiva
+// (por exemplo, respostas SMTP 5xx ou número inexistente); o envio não deve ser repetido
+// pelo mesmo canal.
+var ErrPermanentDeliveryFailure = errors.New("destinatário recusado permanentemente pelo canal")
+
+func (c Channel) IsValid() bool {
+	return slices.Contains(DefaultChannelOrder, c)
+}
+
+// ContactPreferences guarda os contatos adicionais do devedor e a ordem em que os canais
+// devem ser tentados.
+type ContactPreferences struct {
+	GovernmentID string    `json:"GovernmentID"`
+	Phone        string    `json:"Phone,omitempty"`
+	WhatsApp     string    `json:"WhatsApp,omitempty"`
+	Channels     []Channel `json:"Channels,omitempty"`
+}
+
+// NotificationRoute é a sequência de canais, com o destinatário de cada um, usada para
+// notificar um débito.
+type NotificationRoute struct {
+	Channels   []Channel
+	Recipients map[Channel]string
+}
+
+func (r NotificationRoute) IsEmpty() bool {
+	return len(r.Channels) == 0
+}
+
+// Route monta a rota de notificação do débito, mantendo apenas os canais com contato
+// conhecido. O WhatsApp usa o telefone de SMS quando não há número específico.
+// emailUsable indica se o e-mail do débito ainda pode receber notificações.
+func (p ContactPreferences) Route(debt Debt, emailUsable bool) NotificationRoute {
+	order := p.Channels
+	if len(order) == 0 {
+		order = DefaultChannelOrder
+	}
+
+	whatsApp := p.WhatsApp
+	if whatsApp == "" {
+		whatsApp = p.Phone
+	}
+
+	contacts := map[Channel]string{ChannelSMS: p.Phone, ChannelWhatsApp: whatsApp}
+	if emailUsable {
+		contacts[ChannelEmail] = debt.Email
+	}
+
+	route := NotificationRoute{Recipients: make(map[Channel]string)}
+	for _, channel := range order {
+		if contacts[channel] == "" || slices.Contains(route.Channels, channel) {
+			continue
+		}
+
+		route.Channels = append(route.Channels, channel)
+		route.Recipients[channel] = contacts[channel]
+	}
+
+	return route
+}
+
+// Notification é o conteúdo entregue ao devedor, em qualquer canal. Key identifica a
+// notificação de forma estável e é usada como chave de idempotência junto aos provedores.
+type Notification struct {
+	Key     string
+	Kind    OutboxKind
+	Invoice Invoice
+	// DaysFromDue é a distância em dias até o vencimento nos lembretes de cobrança:
+	// negativa antes do vencimento e positiva depois dele.
+	DaysFromDue int
+}
+
+func (n Notification) IsReminder() bool {
+	return n.Kind == OutboxKindDebtReminder
+}
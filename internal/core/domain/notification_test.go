@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContactPreferences_Route(t *testing.T) {
+	debt := Debt{GovernmentID: "123", Email: "joao@example.com"}
+
+	t.Run("Sem preferências usa apenas o e-mail", func(t *testing.T) {
+		route := ContactPreferences{}.Route(debt, true)
+
+		assert.Equal(t, []Channel{ChannelEmail}, route.Channels)
+		assert.Equal(t, "joao@example.com", route.Recipients[ChannelEmail])
+	})
+
+	t.Run("WhatsApp usa o telefone de SMS", func(t *testing.T) {
+		route := ContactPreferences{Phone: "5511999998888"}.Route(debt, true)
+
+		assert.Equal(t, []Channel{ChannelEmail, ChannelSMS, ChannelWhatsApp}, route.Channels)
+		assert.Equal(t, "5511999998888", route.Recipients[ChannelWhatsApp])
+	})
+
+	t.Run("Ordem definida pelo devedor", func(t *testing.T) {
+		preferences := ContactPreferences{WhatsApp: "5511988887777", Channels: []Channel{ChannelWhatsApp, ChannelEmail}}
+		route := preferences.Route(debt, true)
+
+		assert.Equal(t, []Channel{ChannelWhatsApp, ChannelEmail}, route.Channels)
+	})
+
+	t.Run("E-mail inválido é removido da rota", func(t *testing.T) {
+		assert.True(t, ContactPreferences{}.Route(debt, false).IsEmpty())
+		assert.Equal(t, []Channel{ChannelSMS, ChannelWhatsApp}, ContactPreferences{Phone: "5511999998888"}.Route(debt, false).Channels)
+	})
+}
+
+func TestOutboxMessage_Fallback(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	route := ContactPreferences{Phone: "5511999998888"}.Route(Debt{Email: "joao@example.com"}, true)
+	message := NewDebtNotification(Invoice{Debt: Debt{DebtID: "d1"}}, route, now)
+	assert.Equal(t, ChannelEmail, message.Channel)
+
+	message.MarkDead("550 usuário inexistente", now)
+	assert.True(t, message.Fallback("550 usuário inexistente", now.Add(time.Minute)))
+	assert.Equal(t, ChannelSMS, message.Channel)
+	assert.Equal(t, "5511999998888", message.Recipient)
+	assert.Equal(t, OutboxStatusPending, message.Status)
+	assert.Zero(t, message.Attempts)
+
+	assert.True(t, message.Fallback("número inexistente", now))
+	assert.Equal(t, ChannelWhatsApp, message.Channel)
+	assert.False(t, message.Fallback("número inexistente", now))
+}
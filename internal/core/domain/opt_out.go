@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type OptOutSource string
+
+const (
+	OptOutSourceLink     OptOutSource = "link"
+	OptOutSourceOneClick OptOutSource = "one_click"
+)
+
+// OptOut registra que o devedor não quer mais receber lembretes de cobrança no e-mail
+// informado. A notificação de emissão do boleto é obrigatória e continua sendo enviada.
+type OptOut struct {
+	GovernmentID string       `json:"GovernmentID"`
+	Email        string       `json:"Email"`
+	Source       OptOutSource `json:"Source"`
+	OptedOutAt   time.Time    `json:"OptedOutAt"`
+}
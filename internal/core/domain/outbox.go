@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type OutboxKind string
+
+const (
+	OutboxKindDebtNotification OutboxKind = "debt_notification"
+	OutboxKindDebtReminder     OutboxKind = "debt_reminder"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusDead      OutboxStatus = "dead"
+	// OutboxStatusSuppressed indica uma mensagem descartada de propósito, como um lembrete
+	// para um devedor descadastrado.
+	OutboxStatusSuppressed OutboxStatus = "suppressed"
+)
+
+// OutboxMessage registra um efeito colateral a ser entregue depois que a mudança de estado
+// do débito foi persistida. O ID é a chave de idempotência: uma mensagem com o mesmo ID
+// nunca é registrada duas vezes.
+type OutboxMessage struct {
+	ID            string             `json:"ID"`
+	Kind          OutboxKind         `json:"Kind"`
+	Channel       Channel            `json:"Channel"`
+	Recipient     string             `json:"Recipient"`
+	Route         []Channel          `json:"Route,omitempty"`
+	Recipients    map[Channel]string `json:"Recipients,omitempty"`
+	Invoice       Invoice            `json:"Invoice"`
+	DaysFromDue   int                `json:"DaysFromDue,omitempty"`
+	Status        OutboxStatus       `json:"Status"`
+	Attempts      int                `json:"Attempts"`
+	NextAttemptAt time.Time          `json:"NextAttemptAt"`
+	LastError     string             `json:"LastError,omitempty"`
+	CreatedAt     time.Time          `json:"CreatedAt"`
+	DeliveredAt   time.Time          `json:"DeliveredAt,omitempty"`
+}
+
+// NewDebtNotification cria a notificação de cobrança de um boleto, garantindo uma única
+// notificação por boleto emitido. A notificação começa pelo primeiro canal da rota e passa
+// para o seguinte quando um canal recusa o destinatário.
+func NewDebtNotification(invoice Invoice, route NotificationRoute, at time.Time) OutboxMessage {
+	var channel Channel
+	if !route.IsEmpty() {
+		channel = route.Channels[0]
+	}
+
+	return OutboxMessage{
+		ID:            DebtNotificationKey(invoice),
+		Kind:          OutboxKindDebtNotification,
+		Channel:       channel,
+		Recipient:     route.Recipients[channel],
+		Route:         route.Channels,
+		Recipients:    route.Recipients,
+		Invoice:       invoice,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: at,
+		CreatedAt:     at,
+	}
+}
+
+func DebtNotificationKey(invoice Invoice) string {
+	return fmt.Sprintf("%s:%s:%s", OutboxKindDebtNotification, invoice.Debt.DebtID, invoice.NossoNumero)
+}
+
+// NewDebtReminder cria o lembrete de uma etapa da régua de cobrança. A chave inclui a
+// etapa, de modo que cada lembrete é registrado uma única vez por boleto.
+func NewDebtReminder(invoice Invoice, route NotificationRoute, daysFromDue int, at time.Time) OutboxMessage {
+	message := NewDebtNotification(invoice, route, at)
+	message.ID = DebtReminderKey(invoice, daysFromDue)
+	message.Kind = OutboxKindDebtReminder
+	message.DaysFromDue = daysFromDue
+
+	return message
+}
+
+func DebtReminderKey(invoice Invoice, daysFromDue int) string {
+	return fmt.Sprintf("%s:%s:%s:%s", OutboxKindDebtReminder, invoice.Debt.DebtID, invoice.NossoNumero, DunningStageLabel(daysFromDue))
+}
+
+func (m OutboxMessage) Notification() Notification {
+	return Notification{Key: m.ID, Kind: m.Kind, Invoice: m.Invoice, DaysFromDue: m.DaysFromDue}
+}
+
+func (m *OutboxMessage) MarkDelivered(at time.Time) {
+	m.Status = OutboxStatusDelivered
+	m.Attempts++
+	m.LastError = ""
+	m.DeliveredAt = at
+}
+
+// MarkFailed registra uma tentativa sem sucesso. Ao atingir maxAttempts a mensagem deixa
+// de ser reenviada e fica marcada como dead para análise manual.
+func (m *OutboxMessage) MarkFailed(reason string, retryAt time.Time, maxAttempts int) {
+	m.Attempts++
+	m.LastError = reason
+	m.NextAttemptAt = retryAt
+
+	if m.Attempts >= maxAttempts {
+		m.Status = OutboxStatusDead
+	}
+}
+
+// Defer adia o envio sem contar como tentativa, usado quando a notificação esbarra em um
+// limite de envio ou no horário de silêncio.
+func (m *OutboxMessage) Defer(reason string, until time.Time) {
+	m.LastError = reason
+	m.NextAttemptAt = until
+}
+
+// MarkDead encerra as tentativas de entrega de uma mensagem que não pode ser entregue.
+func (m *OutboxMessage) MarkDead(reason string, at time.Time) {
+	m.Attempts++
+	m.Status = OutboxStatusDead
+	m.LastError = reason
+	m.NextAttemptAt = at
+}
+
+// MarkSuppressed descarta a mensagem sem tentar entregá-la.
+func (m *OutboxMessage) MarkSuppressed(reason string, at time.Time) {
+	m.Status = OutboxStatusSuppressed
+	m.LastError = reason
+	m.NextAttemptAt = at
+}
+
+// Fallback passa a notificação para o próximo canal da rota, reiniciando as tentativas.
+// Devolve false quando não há outro canal disponível.
+func (m *OutboxMessage) Fallback(reason string, at time.Time) bool {
+	next := -1
+	for i, channel := range m.Route {
+		if channel == m.Channel && i+1 < len(m.Route) {
+			next = i + 1
+		}
+	}
+
+	if next < 0 {
+		return false
+	}
+
+	m.Channel = m.Route[next]
+	m.Recipient = m.Recipients[m.Channel]
+	m.Status = OutboxStatusPending
+	m.Attempts = 0
+	m.LastError = reason
+	m.NextAttemptAt = at
+	m.DeliveredAt = time.Time{}
+
+	return true
+}
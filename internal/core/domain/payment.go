@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+type PaymentMethod string
+
+const (
+	PaymentMethodPix    PaymentMethod = "pix"
+	PaymentMethodBoleto PaymentMethod = "boleto"
+)
+
+// PaymentNotification é o aviso de pagamento enviado por um PSP via webhook.
+type PaymentNotification struct {
+	Provider    string
+	EventID     string
+	Method      PaymentMethod
+	TxID        string
+	NossoNumero string
+	Amount      float64
+	PaidDate    string
+}
+
+// PaymentReceivedEvent é publicado no Kafka quando um pagamento é associado a um débito.
+type PaymentReceivedEvent struct {
+	EventID     string        `json:"event_id"`
+	Provider    string        `json:"provider"`
+	DebtID      string        `json:"debt_id"`
+	NossoNumero string        `json:"nosso_numero"`
+	Method      PaymentMethod `json:"method"`
+	Amount      float64       `json:"amount"`
+	PaidDate    string        `json:"paid_date"`
+	Settled     bool          `json:"settled"`
+	ReceivedAt  time.Time     `json:"received_at"`
+}
@@ -0,0 +1,46 @@
+package domain
+
+type OccurrenceKind string
+
+const (
+	OccurrenceRegistered OccurrenceKind = "registered"
+	OccurrencePaid       OccurrenceKind = "paid"
+	OccurrenceRejected   OccurrenceKind = "rejected"
+	OccurrenceOther      OccurrenceKind = "other"
+)
+
+// ReturnOccurrence é uma linha de detalhe de um arquivo de retorno CNAB já normalizada,
+// independente do layout (240 ou 400) em que foi recebida.
+type ReturnOccurrence struct {
+	Line        int            `json:"line"`
+	NossoNumero string         `json:"nosso_numero"`
+	Code        string         `json:"code"`
+	Kind        OccurrenceKind `json:"kind"`
+	Amount      float64        `json:"amount"`
+	PaidAmount  float64        `json:"paid_amount"`
+	Fees        float64        `json:"fees"`
+	PaidDate    string         `json:"paid_date,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+}
+
+type ReconciliationEntry struct {
+	Line           int     `json:"line"`
+	NossoNumero    string  `json:"nosso_numero"`
+	DebtID         string  `json:"debt_id,omitempty"`
+	Code           string  `json:"code"`
+	ExpectedAmount float64 `json:"expected_amount,omitempty"`
+	PaidAmount     float64 `json:"paid_amount,omitempty"`
+	Reason         string  `json:"reason"`
+}
+
+type ReconciliationReport struct {
+	FileName         string                `json:"file_name"`
+	Layout           string                `json:"layout"`
+	TotalOccurrences int                   `json:"total_occurrences"`
+	Registered       int                   `json:"registered"`
+	Paid             int                   `json:"paid"`
+	Rejected         int                   `json:"rejected"`
+	Unmatched        []ReconciliationEntry `json:"unmatched"`
+	AmountMismatches []ReconciliationEntry `json:"amount_mismatches"`
+	Errors           []ReconciliationEntry `json:"errors"`
+}
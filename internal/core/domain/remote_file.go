@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RemoteFile é um arquivo baixado de um servidor remoto, como um SFTP de banco. Um arquivo
+// republicado com outro tamanho ou outra data de alteração é considerado um arquivo novo.
+type RemoteFile struct {
+	Source     string    `json:"Source"`
+	Path       string    `json:"Path"`
+	Size       int64     `json:"Size"`
+	ModTime    time.Time `json:"ModTime"`
+	JobIDs     []string  `json:"JobIDs,omitempty"`
+	Error      string    `json:"Error,omitempty"`
+	IngestedAt time.Time `json:"IngestedAt"`
+}
+
+// Key identifica a versão do arquivo no servidor.
+func (f RemoteFile) Key() string {
+	return fmt.Sprintf("%s|%s|%d|%d", f.Source, f.Path, f.Size, f.ModTime.UnixNano())
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRateLimit  = errors.New("limite de envio inválido")
+	ErrInvalidQuietHours = errors.New("horário de silêncio inválido")
+)
+
+// RateLimit limita a quantidade de envios em uma janela de tempo, como 100 por segundo ou
+// 3 a cada 24 horas. O valor zero significa sem limite.
+type RateLimit struct {
+	Count int
+	Per   time.Duration
+}
+
+// ParseRateLimit interpreta limites no formato <quantidade>/<janela>, em que a janela é
+// s, m, h, d ou uma duração como 24h.
+func ParseRateLimit(value string) (RateLimit, error) {
+	countPart, windowPart, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("%w: %q", ErrInvalidRateLimit, value)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countPart))
+	if err != nil || count <= 0 {
+		return RateLimit{}, fmt.Errorf("%w: %q", ErrInvalidRateLimit, value)
+	}
+
+	var per time.Duration
+	switch windowPart = strings.TrimSpace(windowPart); windowPart {
+	case "s":
+		per = time.Second
+	case "m":
+		per = time.Minute
+	case "h":
+		per = time.Hour
+	case "d":
+		per = 24 * time.Hour
+	default:
+		per, err = time.ParseDuration(windowPart)
+		if err != nil || per <= 0 {
+			return RateLimit{}, fmt.Errorf("%w: %q", ErrInvalidRateLimit, value)
+		}
+	}
+
+	return RateLimit{Count: count, Per: per}, nil
+}
+
+func (l RateLimit) IsZero() bool {
+	return l.Count == 0
+}
+
+// NextAllowed devolve quando um novo envio passa a caber no limite, dados os envios já
+// feitos em ordem cronológica. Devolve false quando o envio já é permitido em now.
+func (l RateLimit) NextAllowed(sent []time.Time, now time.Time) (time.Time, bool) {
+	if l.IsZero() {
+		return time.Time{}, false
+	}
+
+	var recent []time.Time
+	for _, at := range sent {
+		if now.Sub(at) < l.Per {
+			recent = append(recent, at)
+		}
+	}
+
+	if len(recent) < l.Count {
+		return time.Time{}, false
+	}
+
+	return recent[len(recent)-l.Count].Add(l.Per), true
+}
+
+// TokenBucket aplica um RateLimit permitindo rajadas de até Count envios, com os tokens
+// repostos continuamente ao longo da janela.
+type TokenBucket struct {
+	limit     RateLimit
+	tokens    float64
+	updatedAt time.Time
+}
+
+func NewTokenBucket(limit RateLimit, now time.Time) *TokenBucket {
+	return &TokenBucket{limit: limit, tokens: float64(limit.Count), updatedAt: now}
+}
+
+// Wait devolve quanto tempo falta para haver um token disponível; zero quando já há.
+func (b *TokenBucket) Wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil((1 - b.tokens) / b.rate()))
+}
+
+// Take consome um token. Deve ser chamado depois que Wait indicou um token disponível.
+func (b *TokenBucket) Take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updatedAt); elapsed > 0 {
+		b.tokens = min(float64(b.limit.Count), b.tokens+float64(elapsed)*b.rate())
+		b.updatedAt = now
+	}
+}
+
+// rate é a reposição de tokens por nanossegundo.
+func (b *TokenBucket) rate() float64 {
+	return float64(b.limit.Count) / float64(b.limit.Per)
+}
+
+// QuietHours é o intervalo diário em que as notificações não são enviadas, no fuso de
+// Location. O intervalo pode atravessar a meia-noite, como 21:00-08:00. O valor zero
+// significa sem horário de silêncio.
+type QuietHours struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+}
+
+// ParseQuietHours interpreta o intervalo no formato HH:MM-HH:MM. Um valor vazio desativa o
+// horário de silêncio.
+func ParseQuietHours(value string, location *time.Location) (QuietHours, error) {
+	if strings.TrimSpace(value) == "" {
+		return QuietHours{}, nil
+	}
+
+	startPart, endPart, ok := strings.Cut(value, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("%w: %q", ErrInvalidQuietHours, value)
+	}
+
+	start, startErr := parseClock(startPart)
+	end, endErr := parseClock(endPart)
+	if startErr != nil || endErr != nil || start == end {
+		return QuietHours{}, fmt.Errorf("%w: %q", ErrInvalidQuietHours, value)
+	}
+
+	return QuietHours{Start: start, End: end, Location: location}, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+func (q QuietHours) IsZero() bool {
+	return q.Start == q.End
+}
+
+// Until devolve o fim do horário de silêncio quando at está dentro dele.
+func (q QuietHours) Until(at time.Time) (time.Time, bool) {
+	if q.IsZero() {
+		return time.Time{}, false
+	}
+
+	location := q.Location
+	if location == nil {
+		location = time.UTC
+	}
+
+	local := at.In(location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	sinceMidnight := local.Sub(midnight)
+
+	switch {
+	case q.Start < q.End && sinceMidnight >= q.Start && sinceMidnight < q.End:
+		return midnight.Add(q.End), true
+	case q.Start > q.End && sinceMidnight >= q.Start:
+		return midnight.AddDate(0, 0, 1).Add(q.End), true
+	case q.Start > q.End && sinceMidnight < q.End:
+		return midnight.Add(q.End), true
+	default:
+		return time.Time{}, false
+	}
+}
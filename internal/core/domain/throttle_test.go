@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		limit RateLimit
+	}{
+		{value: "100/s", limit: RateLimit{Count: 100, Per: time.Second}},
+		{value: "600/m", limit: RateLimit{Count: 600, Per: time.Minute}},
+		{value: "3/d", limit: RateLimit{Count: 3, Per: 24 * time.Hour}},
+		{value: " 2 / 12h", limit: RateLimit{Count: 2, Per: 12 * time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			limit, err := ParseRateLimit(tt.value)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.limit, limit)
+		})
+	}
+
+	for _, invalid := range []string{"100", "0/s", "-1/s", "10/semana", "abc/s"} {
+		_, err := ParseRateLimit(invalid)
+		assert.ErrorIs(t, err, ErrInvalidRateLimit, invalid)
+	}
+}
+
+func TestRateLimit_NextAllowed(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	limit := RateLimit{Count: 2, Per: 24 * time.Hour}
+
+	_, capped := limit.NextAllowed([]time.Time{now.Add(-30 * time.Hour), now.Add(-time.Hour)}, now)
+	assert.False(t, capped)
+
+	until, capped := limit.NextAllowed([]time.Time{now.Add(-5 * time.Hour), now.Add(-time.Hour)}, now)
+	assert.True(t, capped)
+	assert.Equal(t, now.Add(19*time.Hour), until)
+
+	_, capped = RateLimit{}.NextAllowed([]time.Time{now}, now)
+	assert.False(t, capped)
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	bucket := NewTokenBucket(RateLimit{Count: 2, Per: time.Second}, now)
+
+	for range 2 {
+		assert.Zero(t, bucket.Wait(now))
+		bucket.Take(now)
+	}
+
+	assert.Equal(t, 500*time.Millisecond, bucket.Wait(now))
+	assert.Equal(t, 250*time.Millisecond, bucket.Wait(now.Add(250*time.Millisecond)))
+	assert.Zero(t, bucket.Wait(now.Add(500*time.Millisecond)))
+
+	t.Run("Reposição limitada à capacidade", func(t *testing.T) {
+		later := now.Add(time.Hour)
+		for range 2 {
+			assert.Zero(t, bucket.Wait(later))
+			bucket.Take(later)
+		}
+
+		assert.Positive(t, bucket.Wait(later))
+	})
+}
+
+func TestQuietHours_Until(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	quiet, err := ParseQuietHours("21:00-08:00", saoPaulo)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		until time.Time
+		quiet bool
+	}{
+		{name: "Antes da meia-noite", at: time.Date(2025, 3, 1, 22, 30, 0, 0, saoPaulo), until: time.Date(2025, 3, 2, 8, 0, 0, 0, saoPaulo), quiet: true},
+		{name: "Depois da meia-noite", at: time.Date(2025, 3, 2, 3, 0, 0, 0, saoPaulo), until: time.Date(2025, 3, 2, 8, 0, 0, 0, saoPaulo), quiet: true},
+		{name: "Horário em UTC", at: time.Date(2025, 3, 2, 10, 59, 0, 0, time.UTC), until: time.Date(2025, 3, 2, 8, 0, 0, 0, saoPaulo), quiet: true},
+		{name: "Fim do silêncio", at: time.Date(2025, 3, 2, 8, 0, 0, 0, saoPaulo)},
+		{name: "Durante o dia", at: time.Date(2025, 3, 2, 14, 0, 0, 0, saoPaulo)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, isQuiet := quiet.Until(tt.at)
+
+			assert.Equal(t, tt.quiet, isQuiet)
+			assert.True(t, tt.until.Equal(until), "esperado %s, obtido %s", tt.until, until)
+		})
+	}
+
+	daytime, err := ParseQuietHours("12:00-14:00", saoPaulo)
+	assert.NoError(t, err)
+	until, isQuiet := daytime.Until(time.Date(2025, 3, 2, 13, 0, 0, 0, saoPaulo))
+	assert.True(t, isQuiet)
+	assert.True(t, until.Equal(time.Date(2025, 3, 2, 14, 0, 0, 0, saoPaulo)))
+
+	disabled, err := ParseQuietHours("", saoPaulo)
+	assert.NoError(t, err)
+	_, isQuiet = disabled.Until(time.Date(2025, 3, 2, 23, 0, 0, 0, saoPaulo))
+	assert.False(t, isQuiet)
+
+	for _, invalid := range []string{"21:00", "25:00-08:00", "08:00-08:00"} {
+		_, err := ParseQuietHours(invalid, saoPaulo)
+		assert.ErrorIs(t, err, ErrInvalidQuietHours, invalid)
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+type UploadStatus string
+
+const (
+	UploadInProgress UploadStatus = "uploading"
+	// UploadCompleting indica que as partes estão sendo montadas e o arquivo entregue ao
+	// processamento; nenhuma parte é aceita. As partes só são removidas na conclusão.
+	UploadCompleting UploadStatus = "completing"
+	UploadCompleted  UploadStatus = "completed"
+)
+
+// UploadPart é uma parte recebida de um upload retomável. Checksum é o SHA-256 da parte,
+// em hexadecimal.
+type UploadPart struct {
+	Number   int    `json:"Number"`
+	Size     int64  `json:"Size"`
+	Checksum string `json:"Checksum"`
+}
+
+// Upload é um arquivo enviado em partes, que podem chegar em qualquer ordem e ser
+// reenviadas até a conclusão. Checksum é o SHA-256 do arquivo inteiro, conferido na
+// conclusão, e Options são os campos do formulário de /process-files aplicados ao arquivo.
+type Upload struct {
+	ID          string            `json:"ID"`
+	FileName    string            `json:"FileName"`
+	Checksum    string            `json:"Checksum"`
+	Options     map[string]string `json:"Options,omitempty"`
+	Status      UploadStatus      `json:"Status"`
+	Size        int64             `json:"Size"`
+	Parts       []UploadPart      `json:"Parts"`
+	JobIDs      []string          `json:"JobIDs,omitempty"`
+	CreatedAt   time.Time         `json:"CreatedAt"`
+	UpdatedAt   time.Time         `json:"UpdatedAt"`
+	CompletedAt time.Time         `json:"CompletedAt"`
+}
+
+// AddPart registra a parte, substituindo uma parte anterior de mesmo número, e mantém as
+// partes em ordem.
+func (u *Upload) AddPart(part UploadPart) {
+	parts := u.Parts[:0]
+	for _, existing := range u.Parts {
+		if existing.Number != part.Number {
+			parts = append(parts, existing)
+		}
+	}
+
+	u.Parts = append(parts, part)
+	sort.Slice(u.Parts, func(i, j int) bool { return u.Parts[i].Number < u.Parts[j].Number })
+
+	u.Size = 0
+	for _, existing := range u.Parts {
+		u.Size += existing.Size
+	}
+}
+
+// PartSize devolve o tamanho da parte number já recebida, ou zero.
+func (u *Upload) PartSize(number int) int64 {
+	for _, part := range u.Parts {
+		if part.Number == number {
+			return part.Size
+		}
+	}
+
+	return 0
+}
+
+// MissingParts devolve os números ausentes entre 1 e a maior parte recebida.
+func (u *Upload) MissingParts() []int {
+	var missing []int
+	next := 1
+	for _, part := range u.Parts {
+		for ; next < part.Number; next++ {
+			missing = append(missing, next)
+		}
+		next = part.Number + 1
+	}
+
+	return missing
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventFileProcessed WebhookEventType = "file.processed"
+	WebhookEventDebtInvoiced  WebhookEventType = "debt.invoiced"
+	WebhookEventDebtNotified  WebhookEventType = "debt.notified"
+	WebhookEventDebtPaid      WebhookEventType = "debt.paid"
+	WebhookEventDebtFailed    WebhookEventType = "debt.failed"
+)
+
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventFileProcessed,
+	WebhookEventDebtInvoiced,
+	WebhookEventDebtNotified,
+	WebhookEventDebtPaid,
+	WebhookEventDebtFailed,
+}
+
+func (t WebhookEventType) IsValid() bool {
+	return slices.Contains(WebhookEventTypes, t)
+}
+
+// WebhookEvent é o evento enviado aos sistemas do cliente. O ID é derivado do fato que
+// originou o evento, de modo que reprocessar o mesmo fato não gera um segundo envio.
+type WebhookEvent struct {
+	ID         string           `json:"id"`
+	Type       WebhookEventType `json:"type"`
+	ClientID   string           `json:"client_id"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       json.RawMessage  `json:"data"`
+}
+
+func NewWebhookEvent(id string, eventType WebhookEventType, clientID string, data any, at time.Time) (WebhookEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return WebhookEvent{}, fmt.Errorf("erro ao serializar evento %s: %w", id, err)
+	}
+
+	return WebhookEvent{ID: id, Type: eventType, ClientID: clientID, OccurredAt: at, Data: payload}, nil
+}
+
+// WebhookDebtData é o conteúdo dos eventos de débito. Os campos opcionais dependem do
+// tipo do evento.
+type WebhookDebtData struct {
+	DebtID      string        `json:"debt_id"`
+	NossoNumero string        `json:"nosso_numero,omitempty"`
+	Amount      float64       `json:"amount,omitempty"`
+	DueDate     string        `json:"due_date,omitempty"`
+	Channel     Channel       `json:"channel,omitempty"`
+	Reminder    bool          `json:"reminder,omitempty"`
+	Method      PaymentMethod `json:"method,omitempty"`
+	PaidAmount  float64       `json:"paid_amount,omitempty"`
+	PaidDate    string        `json:"paid_date,omitempty"`
+	Stage       string        `json:"stage,omitempty"`
+	Reason      string        `json:"reason,omitempty"`
+}
+
+type WebhookFileData struct {
+	FileName   string `json:"file_name"`
+	TotalLines int    `json:"total_lines"`
+}
+
+// nonPublicPrefixes são as faixas fora de IsPrivate, IsLoopback e IsLinkLocalUnicast que
+// também não levam a um endereço da internet.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicWebhookAddress indica se os webhooks podem ser entregues no endereço. Endereços
+// de loopback, de redes privadas e link-local, como o serviço de metadados da nuvem em
+// 169.254.169.254, são recusados para que uma inscrição não alcance a rede interna.
+func IsPublicWebhookAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WebhookSubscription é a inscrição de um cliente para receber eventos em uma URL. As
+// entregas são assinadas com HMAC-SHA256 usando Secret.
+type WebhookSubscription struct {
+	ID        string             `json:"ID"`
+	ClientID  string             `json:"ClientID"`
+	URL       string             `json:"URL"`
+	Secret    string             `json:"-"`
+	Events    []WebhookEventType `json:"Events"`
+	CreatedAt time.Time          `json:"CreatedAt"`
+}
+
+func (s WebhookSubscription) Accepts(eventType WebhookEventType) bool {
+	return slices.Contains(s.Events, eventType)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "dead"
+)
+
+// WebhookAttempt registra uma tentativa de entrega no histórico da entrega.
+type WebhookAttempt struct {
+	At         time.Time `json:"At"`
+	StatusCode int       `json:"StatusCode,omitempty"`
+	Error      string    `json:"Error,omitempty"`
+}
+
+// WebhookDelivery é a entrega de um evento a uma inscrição, com o histórico de
+// tentativas.
+type WebhookDelivery struct {
+	ID             string                `json:"ID"`
+	SubscriptionID string                `json:"SubscriptionID"`
+	ClientID       string                `json:"ClientID"`
+	URL            string                `json:"URL"`
+	Event          WebhookEvent          `json:"Event"`
+	Status         WebhookDeliveryStatus `json:"Status"`
+	Attempts       int                   `json:"Attempts"`
+	NextAttemptAt  time.Time             `json:"NextAttemptAt"`
+	LastError      string                `json:"LastError,omitempty"`
+	History        []WebhookAttempt      `json:"History,omitempty"`
+	CreatedAt      time.Time             `json:"CreatedAt"`
+	DeliveredAt    time.Time             `json:"DeliveredAt,omitempty"`
+}
+
+func NewWebhookDelivery(subscription WebhookSubscription, event WebhookEvent, at time.Time) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             event.ID + "@" + subscription.ID,
+		SubscriptionID: subscription.ID,
+		ClientID:       subscription.ClientID,
+		URL:            subscription.URL,
+		Event:          event,
+		Status:         WebhookDeliveryPending,
+		NextAttemptAt:  at,
+		CreatedAt:      at,
+	}
+}
+
+func (d *WebhookDelivery) MarkDelivered(statusCode int, at time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryDelivered
+	d.LastError = ""
+	d.DeliveredAt = at
+	d.History = append(d.History, WebhookAttempt{At: at, StatusCode: statusCode})
+}
+
+// MarkFailed registra uma tentativa sem sucesso. Ao atingir maxAttempts a entrega fica
+// marcada como dead e só é repetida por um reenvio manual.
+func (d *WebhookDelivery) MarkFailed(statusCode int, reason string, at, retryAt time.Time, maxAttempts int) {
+	d.Attempts++
+	d.LastError = reason
+	d.NextAttemptAt = retryAt
+	d.History = append(d.History, WebhookAttempt{At: at, StatusCode: statusCode, Error: reason})
+
+	if d.Attempts >= maxAttempts {
+		d.Status = WebhookDeliveryDead
+	}
+}
+
+// MarkDead encerra a entrega sem nova tentativa, como quando a inscrição foi removida.
+func (d *WebhookDelivery) MarkDead(reason string, at time.Time) {
+	d.Status = WebhookDeliveryDead
+	d.LastError = reason
+	d.NextAttemptAt = at
+}
+
+// Redeliver agenda um novo envio da entrega, reiniciando as tentativas e mantendo o
+// histórico.
+func (d *WebhookDelivery) Redeliver(at time.Time) {
+	d.Status = WebhookDeliveryPending
+	d.Attempts = 0
+	d.LastError = ""
+	d.NextAttemptAt = at
+	d.DeliveredAt = time.Time{}
+}
@@ -0,0 +1,8 @@
+package service
+
+import "kanastra-api/internal/core/domain"
+
+type ContactPreferenceRepository interface {
+	Save(preferences domain.ContactPreferences) error
+	FindByGovernmentID(governmentID string) (domain.ContactPreferences, bool)
+}
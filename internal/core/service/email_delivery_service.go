@@ -0,0 +1,14 @@
+package service
+
+import "kanastra-api/internal/core/domain"
+
+type EmailDeliveryRepository interface {
+	Save(delivery domain.EmailDelivery) error
+	FindByProviderMessageID(messageID string) (domain.EmailDelivery, bool)
+	FindByDebtID(debtID string) []domain.EmailDelivery
+}
+
+type InvalidEmailRepository interface {
+	MarkInvalid(invalid domain.InvalidEmail) error
+	IsInvalid(governmentID, email string) bool
+}
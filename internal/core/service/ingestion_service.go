@@ -0,0 +1,8 @@
+package service
+
+import "kanastra-api/internal/core/domain"
+
+type IngestionJobRepository interface {
+	Save(job domain.IngestionJob) error
+	FindByID(id string) (domain.IngestionJob, bool)
+}
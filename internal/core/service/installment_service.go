@@ -0,0 +1,12 @@
+package service
+
+import "kanastra-api/internal/core/domain"
+
+type InstallmentPlanRepository interface {
+	// Create grava o parcelamento se o débito ainda não tiver um; caso contrário, devolve
+	// domain.ErrInstallmentPlanExists. A verificação e a gravação são atômicas.
+	Create(plan domain.InstallmentPlan) error
+	Save(plan domain.InstallmentPlan) error
+	Delete(debtID string)
+	FindByDebtID(debtID string) (domain.InstallmentPlan, bool)
+}
@@ -0,0 +1,14 @@
+package service
+
+import "kanastra-api/internal/core/domain"
+
+type InvoiceRepository interface {
+	Save(invoice domain.Invoice) error
+	// Delete remove o boleto do débito e seus índices.
+	Delete(debtID string)
+	FindByNossoNumero(nossoNumero string) (domain.Invoice, bool)
+	FindByDebtID(debtID string) (domain.Invoice, bool)
+	FindByPixTxID(txID string) (domain.Invoice, bool)
+	// FindOpen lista os boletos que ainda aguardam pagamento.
+	FindOpen() []domain.Invoice
+}
@@ -0,0 +1,10 @@
+package service
+
+import "time"
+
+// SchedulerLock garante que uma tarefa agendada rode em uma única réplica por vez. O lock
+// expira depois de ttl, de modo que uma réplica encerrada não o mantém indefinidamente.
+type SchedulerLock interface {
+	TryLock(name, owner string, ttl time.Duration, now time.Time) bool
+	Unlock(name, owner string)
+}
@@ -0,0 +1,10 @@
+package service
+
+import "kanastra-api/internal/core/domain"
+
+type OptOutRepository interface {
+	Save(optOut domain.OptOut) error
+	Delete(governmentID, email string) error
+	IsOptedOut(governmentID, email string) bool
+	FindByGovernmentID(governmentID string) []domain.OptOut
+}
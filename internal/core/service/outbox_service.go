@@ -0,0 +1,20 @@
+package service
+
+import (
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type OutboxRepository interface {
+	// ClaimPending reserva até limit mensagens pendentes com tentativa vencida, adiando a
+	// próxima tentativa por lease para que outro dispatcher não as entregue em paralelo.
+	ClaimPending(now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error)
+	Update(message domain.OutboxMessage) error
+	FindOutboxMessage(id string) (domain.OutboxMessage, bool)
+	// Enqueue registra mensagens ignorando as já existentes e devolve quantas eram novas.
+	Enqueue(messages []domain.OutboxMessage) (int, error)
+	// DeliveredSince devolve, em ordem cronológica, os horários das notificações entregues
+	// ao devedor a partir de since.
+	DeliveredSince(governmentID string, since time.Time) ([]time.Time, error)
+}
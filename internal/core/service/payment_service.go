@@ -0,0 +1,6 @@
+package service
+
+type PaymentEventRepository interface {
+	TryRegister(eventKey string) bool
+	Forget(eventKey string)
+}
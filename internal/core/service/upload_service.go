@@ -0,0 +1,29 @@
+package service
+
+import (
+	"io"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// UploadStore guarda os uploads retomáveis e suas partes até a conclusão.
+type UploadStore interface {
+	Save(upload domain.Upload) error
+	FindByID(id string) (domain.Upload, bool)
+	// FindAll lista os uploads, concluídos ou não.
+	FindAll() []domain.Upload
+	// WritePart grava a parte em um arquivo provisório e devolve sua referência. A parte de
+	// mesmo número já gravada só é substituída em CommitPart; DiscardPart remove o
+	// provisório de uma parte recusada.
+	WritePart(uploadID string, number int, content io.Reader) (domain.UploadPart, string, error)
+	CommitPart(uploadID string, part domain.UploadPart, staged string) error
+	DiscardPart(staged string)
+	// Assemble concatena as partes em ordem em um novo arquivo e devolve seu caminho e o
+	// SHA-256 do conteúdo. O arquivo pertence a quem chamou, que deve removê-lo, ou
+	// descartá-lo com DiscardAssembled quando o conteúdo é recusado.
+	Assemble(upload domain.Upload) (string, string, error)
+	DiscardAssembled(path string)
+	RemoveParts(uploadID string) error
+	// Remove apaga o upload e todas as suas partes.
+	Remove(uploadID string) error
+}
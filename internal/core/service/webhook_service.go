@@ -0,0 +1,25 @@
+package service
+
+import (
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type WebhookSubscriptionRepository interface {
+	Save(subscription domain.WebhookSubscription) error
+	Delete(id string) error
+	FindByID(id string) (domain.WebhookSubscription, bool)
+	FindByClientID(clientID string) []domain.WebhookSubscription
+}
+
+type WebhookDeliveryRepository interface {
+	// Enqueue registra entregas ignorando as já existentes e devolve quantas eram novas.
+	Enqueue(deliveries []domain.WebhookDelivery) (int, error)
+	// ClaimPending reserva até limit entregas pendentes com tentativa vencida, adiando a
+	// próxima tentativa por lease.
+	ClaimPending(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	Update(delivery domain.WebhookDelivery) error
+	FindByID(id string) (domain.WebhookDelivery, bool)
+	FindBySubscriptionID(subscriptionID string) []domain.WebhookDelivery
+}
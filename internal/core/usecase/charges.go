@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type ChargePolicyProvider interface {
+	ChargePolicy(clientID string) domain.ChargePolicy
+}
+
+type BusinessCalendar interface {
+	NextBusinessDay(day time.Time) time.Time
+}
+
+// amountDue calcula quanto o boleto vale na data do pagamento, aplicando a política de
+// encargos do cliente sobre o valor original do débito.
+func amountDue(
+	policies ChargePolicyProvider,
+	calendar BusinessCalendar,
+	invoice domain.Invoice,
+	paidDate string,
+	now time.Time,
+) float64 {
+	dueDate, err := time.Parse(time.DateOnly, invoice.PrincipalDueDate())
+	if err != nil {
+		return invoice.Amount
+	}
+
+	paidAt := now
+	if parsed, err := time.Parse(time.DateOnly, paidDate); err == nil {
+		paidAt = parsed
+	}
+
+	policy := policies.ChargePolicy(invoice.Debt.ClientID)
+
+	return policy.Calculate(invoice.Debt.DebtAmount, dueDate, calendar.NextBusinessDay(dueDate), paidAt).Total
+}
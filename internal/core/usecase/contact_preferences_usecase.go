@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var (
+	ErrInvalidContactPreferences  = errors.New("preferências de contato inválidas")
+	ErrContactPreferencesNotFound = errors.New("preferências de contato não encontradas")
+)
+
+var phonePattern = regexp.MustCompile(`^\d{10,15}$`)
+
+type ContactPreferencesUseCase struct {
+	contacts service.ContactPreferenceRepository
+}
+
+func NewContactPreferencesUseCase(contacts service.ContactPreferenceRepository) *ContactPreferencesUseCase {
+	return &ContactPreferencesUseCase{contacts: contacts}
+}
+
+// Save valida e grava as preferências. Telefones são normalizados para apenas dígitos,
+// com código do país (por exemplo, 5511999998888).
+func (u *ContactPreferencesUseCase) Save(preferences domain.ContactPreferences) (domain.ContactPreferences, error) {
+	var err error
+	if preferences.Phone, err = normalizePhone(preferences.Phone); err != nil {
+		return domain.ContactPreferences{}, err
+	}
+
+	if preferences.WhatsApp, err = normalizePhone(preferences.WhatsApp); err != nil {
+		return domain.ContactPreferences{}, err
+	}
+
+	for _, channel := range preferences.Channels {
+		if !channel.IsValid() {
+			return domain.ContactPreferences{}, fmt.Errorf("%w: canal desconhecido %q", ErrInvalidContactPreferences, channel)
+		}
+	}
+
+	if err := u.contacts.Save(preferences); err != nil {
+		return domain.ContactPreferences{}, err
+	}
+
+	return preferences, nil
+}
+
+func (u *ContactPreferencesUseCase) Get(governmentID string) (domain.ContactPreferences, error) {
+	preferences, exists := u.contacts.FindByGovernmentID(governmentID)
+	if !exists {
+		return domain.ContactPreferences{}, ErrContactPreferencesNotFound
+	}
+
+	return preferences, nil
+}
+
+func normalizePhone(phone string) (string, error) {
+	normalized := strings.NewReplacer("+", "", " ", "", "-", "", "(", "", ")", "").Replace(phone)
+	if normalized != "" && !phonePattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: telefone %q", ErrInvalidContactPreferences, phone)
+	}
+
+	return normalized, nil
+}
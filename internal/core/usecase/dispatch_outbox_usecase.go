@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+type OutboxRetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Lease       time.Duration
+	BatchSize   int
+}
+
+func DefaultOutboxRetryPolicy() OutboxRetryPolicy {
+	return OutboxRetryPolicy{
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    time.Hour,
+		Lease:       2 * time.Minute,
+		BatchSize:   100,
+	}
+}
+
+// backoff dobra o intervalo a cada tentativa sem sucesso, limitado a MaxDelay.
+func (p OutboxRetryPolicy) backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+type OutboxDispatchResult struct {
+	Delivered int
+	Failed    int
+	Deferred  int
+	// Suppressed conta os lembretes descartados porque o devedor se descadastrou.
+	Suppressed int
+}
+
+// DispatchOutboxUseCase entrega as mensagens registradas no outbox. Uma mensagem só é
+// marcada como entregue depois que o envio foi confirmado; falhas temporárias são
+// reagendadas com backoff exponencial até o limite de tentativas. Quando um canal recusa o
+// destinatário ou esgota as tentativas, a notificação passa para o próximo canal da rota.
+// Cada tentativa de envio de e-mail fica registrada com o Message-ID para o acompanhamento
+// de bounces. Antes do envio, o throttle pode adiar a notificação por horário de silêncio
+// ou limite de envio, sem contar como tentativa. Lembretes de devedores descadastrados são
+// descartados; a notificação de emissão é obrigatória e sempre enviada. A entrega e o
+// esgotamento de todos os canais são publicados como eventos de webhook do cliente.
+type DispatchOutboxUseCase struct {
+	outbox        service.OutboxRepository
+	deliveries    service.EmailDeliveryRepository
+	invalidEmails service.InvalidEmailRepository
+	optOuts       service.OptOutRepository
+	notifiers     map[domain.Channel]Notifier
+	throttle      *NotificationThrottle
+	webhooks      WebhookEventPublisher
+	policy        OutboxRetryPolicy
+	now           func() time.Time
+}
+
+func NewDispatchOutboxUseCase(
+	outbox service.OutboxRepository,
+	deliveries service.EmailDeliveryRepository,
+	invalidEmails service.InvalidEmailRepository,
+	optOuts service.OptOutRepository,
+	notifiers []Notifier,
+	throttle *NotificationThrottle,
+	webhooks WebhookEventPublisher,
+	policy OutboxRetryPolicy,
+) *DispatchOutboxUseCase {
+	byChannel := make(map[domain.Channel]Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		byChannel[notifier.Channel()] = notifier
+	}
+
+	return &DispatchOutboxUseCase{
+		outbox:        outbox,
+		deliveries:    deliveries,
+		invalidEmails: invalidEmails,
+		optOuts:       optOuts,
+		notifiers:     byChannel,
+		throttle:      throttle,
+		webhooks:      webhooks,
+		policy:        policy,
+		now:           time.Now,
+	}
+}
+
+func (u *DispatchOutboxUseCase) Dispatch() (OutboxDispatchResult, error) {
+	var result OutboxDispatchResult
+
+	messages, err := u.outbox.ClaimPending(u.now(), u.policy.Lease, u.policy.BatchSize)
+	if err != nil {
+		return result, fmt.Errorf("erro ao buscar mensagens pendentes do outbox: %w", err)
+	}
+
+	for _, message := range messages {
+		switch {
+		case u.optedOut(&message):
+			result.Suppressed++
+		case u.throttled(&message):
+			result.Deferred++
+		case u.deliver(&message):
+			result.Delivered++
+		default:
+			result.Failed++
+		}
+
+		if err := u.outbox.Update(message); err != nil {
+			log.Printf("Erro ao atualizar mensagem %s do outbox: %v", message.ID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Run entrega as mensagens pendentes a cada interval até o contexto ser encerrado.
+func (u *DispatchOutboxUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.Dispatch(); err != nil {
+			log.Printf("Erro ao despachar outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// optedOut descarta o lembrete quando o devedor se descadastrou dos lembretes no e-mail do
+// débito, qualquer que seja o canal do lembrete.
+func (u *DispatchOutboxUseCase) optedOut(message *domain.OutboxMessage) bool {
+	debt := message.Invoice.Debt
+	if message.Kind != domain.OutboxKindDebtReminder || !u.optOuts.IsOptedOut(debt.GovernmentID, debt.Email) {
+		return false
+	}
+
+	log.Printf("Lembrete %s descartado: devedor %s descadastrado dos lembretes", message.ID, debt.GovernmentID)
+	message.MarkSuppressed("devedor descadastrado dos lembretes", u.now())
+
+	return true
+}
+
+// throttled adia a notificação quando o throttle não permite o envio agora.
+func (u *DispatchOutboxUseCase) throttled(message *domain.OutboxMessage) bool {
+	if message.Kind != domain.OutboxKindDebtNotification && message.Kind != domain.OutboxKindDebtReminder {
+		return false
+	}
+
+	if message.Channel == "" {
+		message.Channel = domain.ChannelEmail
+	}
+
+	notifier, exists := u.notifiers[message.Channel]
+	if !exists {
+		return false
+	}
+
+	until, reason, allowed := u.throttle.Acquire(*message, notifier.Provider(), u.now())
+	if allowed {
+		return false
+	}
+
+	message.Defer(reason, until)
+
+	return true
+}
+
+func (u *DispatchOutboxUseCase) deliver(message *domain.OutboxMessage) bool {
+	switch message.Kind {
+	case domain.OutboxKindDebtNotification, domain.OutboxKindDebtReminder:
+		return u.sendNotification(message)
+	default:
+		log.Printf("Tipo de mensagem do outbox desconhecido: %s", message.Kind)
+		message.MarkDead(fmt.Sprintf("tipo de mensagem desconhecido: %s", message.Kind), u.now())
+
+		return false
+	}
+}
+
+func (u *DispatchOutboxUseCase) sendNotification(message *domain.OutboxMessage) bool {
+	debt := message.Invoice.Debt
+	if message.Channel == "" {
+		message.Channel = domain.ChannelEmail
+	}
+
+	notifier, exists := u.notifiers[message.Channel]
+	if !exists {
+		u.giveUp(message, fmt.Sprintf("canal %s não configurado", message.Channel))
+
+		return false
+	}
+
+	if message.Channel == domain.ChannelEmail && u.invalidEmails.IsInvalid(debt.GovernmentID, message.Recipient) {
+		log.Printf("E-mail %s do débito %s está marcado como inválido, notificação cancelada", message.Recipient, debt.DebtID)
+		u.giveUp(message, "e-mail marcado como inválido")
+
+		return false
+	}
+
+	var delivery *domain.EmailDelivery
+	if message.Channel == domain.ChannelEmail {
+		now := u.now()
+		delivery = &domain.EmailDelivery{
+			ID:             fmt.Sprintf("%s#%d", message.ID, message.Attempts+1),
+			NotificationID: message.ID,
+			DebtID:         debt.DebtID,
+			GovernmentID:   debt.GovernmentID,
+			Email:          message.Recipient,
+			Attempt:        message.Attempts + 1,
+			Status:         domain.EmailDeliveryQueued,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		u.saveDelivery(*delivery)
+	}
+
+	messageID, err := notifier.Notify(message.Recipient, message.Notification())
+
+	switch {
+	case err == nil:
+		message.MarkDelivered(u.now())
+		u.publishNotification(*message, domain.WebhookEventDebtNotified, "")
+	case errors.Is(err, domain.ErrPermanentDeliveryFailure):
+		log.Printf("Destinatário %s do débito %s recusado permanentemente pelo canal %s: %v", message.Recipient, debt.DebtID, message.Channel, err)
+		u.giveUp(message, err.Error())
+	default:
+		log.Printf("Erro ao entregar mensagem %s do outbox pelo canal %s (tentativa %d): %v", message.ID, message.Channel, message.Attempts+1, err)
+		message.MarkFailed(err.Error(), u.now().Add(u.policy.backoff(message.Attempts+1)), u.policy.MaxAttempts)
+		if message.Status == domain.OutboxStatusDead {
+			u.fallback(message, err.Error())
+		}
+	}
+
+	if delivery != nil {
+		u.recordEmailResult(*delivery, messageID, err)
+	}
+
+	return err == nil
+}
+
+// giveUp abandona o canal atual, passando para o próximo da rota ou encerrando a mensagem.
+func (u *DispatchOutboxUseCase) giveUp(message *domain.OutboxMessage, reason string) {
+	message.MarkDead(reason, u.now())
+	u.fallback(message, reason)
+}
+
+func (u *DispatchOutboxUseCase) fallback(message *domain.OutboxMessage, reason string) {
+	from := message.Channel
+	if message.Fallback(reason, u.now()) {
+		log.Printf("Notificação %s passou do canal %s para %s", message.ID, from, message.Channel)
+
+		return
+	}
+
+	u.publishNotification(*message, domain.WebhookEventDebtFailed, reason)
+}
+
+// publishNotification publica a entrega ou a falha definitiva de uma notificação. O evento
+// é identificado pela mensagem do outbox, de modo que cada notificação gera um único evento.
+func (u *DispatchOutboxUseCase) publishNotification(message domain.OutboxMessage, eventType domain.WebhookEventType, reason string) {
+	data := debtWebhookData(message.Invoice)
+	data.Channel = message.Channel
+	data.Reminder = message.Kind == domain.OutboxKindDebtReminder
+	data.Reason = reason
+	if eventType == domain.WebhookEventDebtFailed {
+		data.Stage = "notification"
+	}
+
+	publishWebhook(u.webhooks, string(eventType)+":"+message.ID, eventType, message.Invoice.Debt.ClientID, data, u.now())
+}
+
+func (u *DispatchOutboxUseCase) recordEmailResult(delivery domain.EmailDelivery, messageID string, err error) {
+	delivery.ProviderMessageID = messageID
+	delivery.UpdatedAt = u.now()
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.EmailDeliverySent
+	case errors.Is(err, domain.ErrPermanentDeliveryFailure):
+		delivery.Status = domain.EmailDeliveryBounced
+		delivery.Reason = err.Error()
+		u.markInvalid(delivery)
+	default:
+		delivery.Status = domain.EmailDeliveryDeferred
+		delivery.Reason = err.Error()
+	}
+
+	u.saveDelivery(delivery)
+}
+
+func (u *DispatchOutboxUseCase) saveDelivery(delivery domain.EmailDelivery) {
+	if err := u.deliveries.Save(delivery); err != nil {
+		log.Printf("Erro ao registrar envio de e-mail %s: %v", delivery.ID, err)
+	}
+}
+
+func (u *DispatchOutboxUseCase) markInvalid(delivery domain.EmailDelivery) {
+	err := u.invalidEmails.MarkInvalid(domain.InvalidEmail{
+		GovernmentID: delivery.GovernmentID,
+		Email:        delivery.Email,
+		Reason:       delivery.Reason,
+		MarkedAt:     delivery.UpdatedAt,
+	})
+	if err != nil {
+		log.Printf("Erro ao marcar e-mail %s como inválido: %v", delivery.Email, err)
+	}
+}
@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) ClaimPending(now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	args := m.Called(now, lease, limit)
+
+	return args.Get(0).([]domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Update(message domain.OutboxMessage) error {
+	args := m.Called(message)
+
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) FindOutboxMessage(id string) (domain.OutboxMessage, bool) {
+	args := m.Called(id)
+
+	return args.Get(0).(domain.OutboxMessage), args.Bool(1)
+}
+
+func (m *MockOutboxRepository) Enqueue(messages []domain.OutboxMessage) (int, error) {
+	args := m.Called(messages)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOutboxRepository) DeliveredSince(governmentID string, since time.Time) ([]time.Time, error) {
+	args := m.Called(governmentID, since)
+
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+	channel domain.Channel
+}
+
+func (m *MockNotifier) Channel() domain.Channel {
+	return m.channel
+}
+
+func (m *MockNotifier) Provider() string {
+	return "mock-" + string(m.channel)
+}
+
+func (m *MockNotifier) Notify(recipient string, notification domain.Notification) (string, error) {
+	args := m.Called(recipient, notification)
+
+	return args.String(0), args.Error(1)
+}
+
+type MockEmailDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailDeliveryRepository) Save(delivery domain.EmailDelivery) error {
+	args := m.Called(delivery)
+
+	return args.Error(0)
+}
+
+func (m *MockEmailDeliveryRepository) FindByProviderMessageID(messageID string) (domain.EmailDelivery, bool) {
+	args := m.Called(messageID)
+
+	return args.Get(0).(domain.EmailDelivery), args.Bool(1)
+}
+
+func (m *MockEmailDeliveryRepository) FindByDebtID(debtID string) []domain.EmailDelivery {
+	args := m.Called(debtID)
+
+	return args.Get(0).([]domain.EmailDelivery)
+}
+
+type MockInvalidEmailRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvalidEmailRepository) MarkInvalid(invalid domain.InvalidEmail) error {
+	args := m.Called(invalid)
+
+	return args.Error(0)
+}
+
+func (m *MockInvalidEmailRepository) IsInvalid(governmentID, email string) bool {
+	args := m.Called(governmentID, email)
+
+	return args.Bool(0)
+}
+
+type MockOptOutRepository struct {
+	mock.Mock
+}
+
+func (m *MockOptOutRepository) Save(optOut domain.OptOut) error {
+	args := m.Called(optOut)
+
+	return args.Error(0)
+}
+
+func (m *MockOptOutRepository) Delete(governmentID, email string) error {
+	args := m.Called(governmentID, email)
+
+	return args.Error(0)
+}
+
+func (m *MockOptOutRepository) IsOptedOut(governmentID, email string) bool {
+	args := m.Called(governmentID, email)
+
+	return args.Bool(0)
+}
+
+func (m *MockOptOutRepository) FindByGovernmentID(governmentID string) []domain.OptOut {
+	args := m.Called(governmentID)
+
+	return args.Get(0).([]domain.OptOut)
+}
+
+type dispatchTestSetup struct {
+	outbox        *MockOutboxRepository
+	deliveries    *MockEmailDeliveryRepository
+	invalidEmails *MockInvalidEmailRepository
+	optOuts       *MockOptOutRepository
+	email         *MockEmailPublisher
+	sms           *MockNotifier
+	webhooks      *MockWebhookEventPublisher
+	useCase       *DispatchOutboxUseCase
+}
+
+func newDispatchTestSetup(now time.Time, policy OutboxRetryPolicy) dispatchTestSetup {
+	setup := dispatchTestSetup{
+		outbox:        new(MockOutboxRepository),
+		deliveries:    new(MockEmailDeliveryRepository),
+		invalidEmails: new(MockInvalidEmailRepository),
+		optOuts:       new(MockOptOutRepository),
+		email:         new(MockEmailPublisher),
+		sms:           &MockNotifier{channel: domain.ChannelSMS},
+		webhooks:      newMockWebhooks(),
+	}
+	notifiers := []Notifier{NewEmailNotifier(setup.email, "smtp.example.com"), setup.sms}
+	throttle := NewNotificationThrottle(ThrottlePolicy{}, setup.outbox)
+	setup.useCase = NewDispatchOutboxUseCase(setup.outbox, setup.deliveries, setup.invalidEmails, setup.optOuts, notifiers, throttle, setup.webhooks, policy)
+	setup.useCase.now = func() time.Time { return now }
+	setup.deliveries.On("Save", mock.Anything).Return(nil)
+	setup.outbox.On("Update", mock.Anything).Return(nil)
+	setup.optOuts.On("IsOptedOut", mock.Anything, mock.Anything).Return(false).Maybe()
+
+	return setup
+}
+
+func newOutboxMessage(debtID string, at time.Time, preferences ...domain.ContactPreferences) domain.OutboxMessage {
+	var contact domain.ContactPreferences
+	if len(preferences) > 0 {
+		contact = preferences[0]
+	}
+
+	debt := domain.Debt{DebtID: debtID, GovernmentID: "123" + debtID, Email: debtID + "@example.com"}
+
+	return domain.NewDebtNotification(domain.Invoice{Debt: debt, NossoNumero: "000" + debtID}, contact.Route(debt, true), at)
+}
+
+func TestDispatchOutbox_Delivers(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	message := newOutboxMessage("d1", now)
+	s.outbox.On("ClaimPending", now, 2*time.Minute, 100).Return([]domain.OutboxMessage{message}, nil)
+	s.invalidEmails.On("IsInvalid", "123d1", "d1@example.com").Return(false)
+	s.email.On("Publish", "d1@example.com", message.Notification()).Return("<abc@kanastra.com.br>", nil)
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Delivered: 1}, result)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.ID == "debt_notification:d1:000d1" && updated.Status == domain.OutboxStatusDelivered && updated.Attempts == 1
+	}))
+	s.deliveries.AssertCalled(t, "Save", mock.MatchedBy(func(delivery domain.EmailDelivery) bool {
+		return delivery.Status == domain.EmailDeliveryQueued && delivery.Attempt == 1
+	}))
+	s.deliveries.AssertCalled(t, "Save", mock.MatchedBy(func(delivery domain.EmailDelivery) bool {
+		return delivery.ID == "debt_notification:d1:000d1#1" &&
+			delivery.Status == domain.EmailDeliverySent &&
+			delivery.ProviderMessageID == "<abc@kanastra.com.br>" &&
+			delivery.GovernmentID == "123d1"
+	}))
+}
+
+func TestDispatchOutbox_DeliversReminder(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	notification := newOutboxMessage("d1", now)
+	reminder := domain.NewDebtReminder(notification.Invoice, domain.NotificationRoute{
+		Channels: notification.Route, Recipients: notification.Recipients,
+	}, -1, now)
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{reminder}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.email.On("Publish", "d1@example.com", mock.MatchedBy(func(n domain.Notification) bool {
+		return n.IsReminder() && n.DaysFromDue == -1 && n.Key == "debt_reminder:d1:000d1:D-1"
+	})).Return("<r1@kanastra.com.br>", nil)
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Delivered: 1}, result)
+}
+
+func TestDispatchOutbox_RetriesWithBackoff(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	message := newOutboxMessage("d1", now)
+	message.Attempts = 2
+	s.outbox.On("ClaimPending", now, 2*time.Minute, 100).Return([]domain.OutboxMessage{message}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.email.On("Publish", mock.Anything, mock.Anything).Return("<abc@kanastra.com.br>", errors.New("conexão recusada"))
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Failed: 1}, result)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusPending &&
+			updated.Attempts == 3 &&
+			updated.LastError == "conexão recusada" &&
+			updated.NextAttemptAt.Equal(now.Add(2*time.Minute))
+	}))
+	s.deliveries.AssertCalled(t, "Save", mock.MatchedBy(func(delivery domain.EmailDelivery) bool {
+		return delivery.Status == domain.EmailDeliveryDeferred && delivery.Attempt == 3
+	}))
+	s.invalidEmails.AssertNotCalled(t, "MarkInvalid", mock.Anything)
+}
+
+func TestDispatchOutbox_GivesUpAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	policy := DefaultOutboxRetryPolicy()
+	policy.MaxAttempts = 3
+	s := newDispatchTestSetup(now, policy)
+
+	message := newOutboxMessage("d1", now)
+	message.Attempts = 2
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{message}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.email.On("Publish", mock.Anything, mock.Anything).Return("", errors.New("tempo esgotado"))
+
+	_, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusDead && updated.Attempts == 3
+	}))
+}
+
+func TestDispatchOutbox_PermanentFailureInvalidatesEmail(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	message := newOutboxMessage("d1", now)
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{message}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.invalidEmails.On("MarkInvalid", mock.Anything).Return(nil)
+	s.email.On("Publish", mock.Anything, mock.Anything).Return("<abc@kanastra.com.br>", fmt.Errorf("%w: 550 usuário inexistente", domain.ErrPermanentDeliveryFailure))
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Failed: 1}, result)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusDead && updated.Attempts == 1
+	}))
+	s.deliveries.AssertCalled(t, "Save", mock.MatchedBy(func(delivery domain.EmailDelivery) bool {
+		return delivery.Status == domain.EmailDeliveryBounced
+	}))
+	s.invalidEmails.AssertCalled(t, "MarkInvalid", mock.MatchedBy(func(invalid domain.InvalidEmail) bool {
+		return invalid.GovernmentID == "123d1" && invalid.Email == "d1@example.com"
+	}))
+}
+
+func TestDispatchOutbox_PermanentFailureFallsBackToNextChannel(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	message := newOutboxMessage("d1", now, domain.ContactPreferences{Phone: "5511999998888"})
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{message}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.invalidEmails.On("MarkInvalid", mock.Anything).Return(nil)
+	s.email.On("Publish", mock.Anything, mock.Anything).Return("<abc@kanastra.com.br>", fmt.Errorf("%w: 550 usuário inexistente", domain.ErrPermanentDeliveryFailure))
+
+	_, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusPending &&
+			updated.Channel == domain.ChannelSMS &&
+			updated.Recipient == "5511999998888" &&
+			updated.Attempts == 0
+	}))
+}
+
+func TestDispatchOutbox_DeliversBySMS(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	message := newOutboxMessage("d1", now, domain.ContactPreferences{
+		Phone:    "5511999998888",
+		Channels: []domain.Channel{domain.ChannelSMS, domain.ChannelEmail},
+	})
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{message}, nil)
+	s.sms.On("Notify", "5511999998888", message.Notification()).Return("sms-1", nil)
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Delivered: 1}, result)
+	s.deliveries.AssertNotCalled(t, "Save", mock.Anything)
+	s.email.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestDispatchOutbox_ExhaustedRetriesFallBack(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	policy := DefaultOutboxRetryPolicy()
+	policy.MaxAttempts = 1
+	s := newDispatchTestSetup(now, policy)
+
+	message := newOutboxMessage("d1", now, domain.ContactPreferences{
+		Phone:    "5511999998888",
+		Channels: []domain.Channel{domain.ChannelSMS, domain.ChannelEmail},
+	})
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{message}, nil)
+	s.sms.On("Notify", mock.Anything, mock.Anything).Return("", errors.New("gateway indisponível"))
+
+	_, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusPending &&
+			updated.Channel == domain.ChannelEmail &&
+			updated.Recipient == "d1@example.com"
+	}))
+}
+
+func TestDispatchOutbox_SkipsInvalidEmail(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	message := newOutboxMessage("d1", now)
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{message}, nil)
+	s.invalidEmails.On("IsInvalid", "123d1", "d1@example.com").Return(true)
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Failed: 1}, result)
+	s.email.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusDead
+	}))
+}
+
+func TestDispatchOutbox_ClaimError(t *testing.T) {
+	s := newDispatchTestSetup(time.Now(), DefaultOutboxRetryPolicy())
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage(nil), errors.New("indisponível"))
+
+	_, err := s.useCase.Dispatch()
+
+	assert.Error(t, err)
+	s.email.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestOutboxRetryPolicy_Backoff(t *testing.T) {
+	policy := OutboxRetryPolicy{BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.backoff(1))
+	assert.Equal(t, time.Minute, policy.backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.backoff(4))
+	assert.Equal(t, 5*time.Minute, policy.backoff(10))
+}
+
+func TestDispatchOutbox_SuppressesRemindersOfOptedOutDebtor(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+	optOuts := new(MockOptOutRepository)
+	s.useCase.optOuts = optOuts
+
+	notification := newOutboxMessage("d1", now, domain.ContactPreferences{Phone: "5511999998888"})
+	reminder := domain.NewDebtReminder(notification.Invoice, domain.NotificationRoute{
+		Channels: notification.Route, Recipients: notification.Recipients,
+	}, 3, now)
+	optOuts.On("IsOptedOut", "123d1", "d1@example.com").Return(true)
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{notification, reminder}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.email.On("Publish", "d1@example.com", notification.Notification()).Return("<n1@kanastra.com.br>", nil)
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Delivered: 1, Suppressed: 1}, result)
+	s.email.AssertNumberOfCalls(t, "Publish", 1)
+	s.sms.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.ID == reminder.ID && updated.Status == domain.OutboxStatusSuppressed && updated.Attempts == 0
+	}))
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+// WebhookSender envia uma entrega assinada com o segredo da inscrição e devolve o status
+// HTTP recebido. Respostas fora da faixa 2xx devolvem erro.
+type WebhookSender interface {
+	Send(delivery domain.WebhookDelivery, secret string) (int, error)
+}
+
+type WebhookDispatchResult struct {
+	Delivered int
+	Failed    int
+}
+
+// DispatchWebhooksUseCase envia as entregas de webhook pendentes. Falhas são reagendadas
+// com o mesmo backoff exponencial do outbox até o limite de tentativas; depois disso a
+// entrega só é repetida por reenvio manual.
+type DispatchWebhooksUseCase struct {
+	subscriptions service.WebhookSubscriptionRepository
+	deliveries    service.WebhookDeliveryRepository
+	sender        WebhookSender
+	policy        OutboxRetryPolicy
+	now           func() time.Time
+}
+
+func NewDispatchWebhooksUseCase(
+	subscriptions service.WebhookSubscriptionRepository,
+	deliveries service.WebhookDeliveryRepository,
+	sender WebhookSender,
+	policy OutboxRetryPolicy,
+) *DispatchWebhooksUseCase {
+	return &DispatchWebhooksUseCase{
+		subscriptions: subscriptions,
+		deliveries:    deliveries,
+		sender:        sender,
+		policy:        policy,
+		now:           time.Now,
+	}
+}
+
+func (u *DispatchWebhooksUseCase) Dispatch() (WebhookDispatchResult, error) {
+	var result WebhookDispatchResult
+
+	deliveries, err := u.deliveries.ClaimPending(u.now(), u.policy.Lease, u.policy.BatchSize)
+	if err != nil {
+		return result, fmt.Errorf("erro ao buscar entregas de webhook pendentes: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if u.deliver(&delivery) {
+			result.Delivered++
+		} else {
+			result.Failed++
+		}
+
+		if err := u.deliveries.Update(delivery); err != nil {
+			log.Printf("Erro ao atualizar entrega de webhook %s: %v", delivery.ID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Run envia as entregas pendentes a cada interval até o contexto ser encerrado.
+func (u *DispatchWebhooksUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.Dispatch(); err != nil {
+			log.Printf("Erro ao despachar webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *DispatchWebhooksUseCase) deliver(delivery *domain.WebhookDelivery) bool {
+	subscription, exists := u.subscriptions.FindByID(delivery.SubscriptionID)
+	if !exists {
+		delivery.MarkDead("inscrição removida", u.now())
+
+		return false
+	}
+
+	status, err := u.sender.Send(*delivery, subscription.Secret)
+	if err != nil {
+		log.Printf("Erro ao entregar webhook %s para %s (tentativa %d): %v", delivery.ID, delivery.URL, delivery.Attempts+1, err)
+		delivery.MarkFailed(status, err.Error(), u.now(), u.now().Add(u.policy.backoff(delivery.Attempts+1)), u.policy.MaxAttempts)
+
+		return false
+	}
+
+	delivery.MarkDelivered(status, u.now())
+
+	return true
+}
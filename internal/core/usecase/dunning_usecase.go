@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+const dunningLockName = "dunning_scheduler"
+
+var ErrSchedulerBusy = errors.New("agendador em execução em outra réplica")
+
+type DunningCadenceProvider interface {
+	DunningCadence(clientID string) domain.DunningCadence
+}
+
+type DunningResult struct {
+	Scheduled int
+	Skipped   int
+}
+
+// DunningUseCase agenda os lembretes da régua de cobrança dos boletos em aberto. Os
+// lembretes são registrados no outbox e entregues pelo dispatcher; a chave de cada etapa
+// garante que um lembrete não seja enviado duas vezes, mesmo que o agendamento rode mais
+// de uma vez no dia. O lock evita que réplicas diferentes façam o mesmo trabalho.
+type DunningUseCase struct {
+	invoices      service.InvoiceRepository
+	outbox        service.OutboxRepository
+	contacts      service.ContactPreferenceRepository
+	invalidEmails service.InvalidEmailRepository
+	cadences      DunningCadenceProvider
+	lock          service.SchedulerLock
+	owner         string
+	location      *time.Location
+	lockTTL       time.Duration
+	now           func() time.Time
+}
+
+func NewDunningUseCase(
+	invoices service.InvoiceRepository,
+	outbox service.OutboxRepository,
+	contacts service.ContactPreferenceRepository,
+	invalidEmails service.InvalidEmailRepository,
+	cadences DunningCadenceProvider,
+	lock service.SchedulerLock,
+	owner string,
+	location *time.Location,
+) *DunningUseCase {
+	return &DunningUseCase{
+		invoices:      invoices,
+		outbox:        outbox,
+		contacts:      contacts,
+		invalidEmails: invalidEmails,
+		cadences:      cadences,
+		lock:          lock,
+		owner:         owner,
+		location:      location,
+		lockTTL:       10 * time.Minute,
+		now:           time.Now,
+	}
+}
+
+func (u *DunningUseCase) Schedule() (DunningResult, error) {
+	var result DunningResult
+
+	now := u.now()
+	if !u.lock.TryLock(dunningLockName, u.owner, u.lockTTL, now) {
+		return result, ErrSchedulerBusy
+	}
+	defer u.lock.Unlock(dunningLockName, u.owner)
+
+	var reminders []domain.OutboxMessage
+	for _, invoice := range u.invoices.FindOpen() {
+		reminder, ok := u.reminder(invoice, now)
+		if !ok {
+			result.Skipped++
+
+			continue
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	if len(reminders) == 0 {
+		return result, nil
+	}
+
+	scheduled, err := u.outbox.Enqueue(reminders)
+	if err != nil {
+		return result, fmt.Errorf("erro ao registrar lembretes no outbox: %w", err)
+	}
+
+	result.Scheduled = scheduled
+	result.Skipped += len(reminders) - scheduled
+
+	return result, nil
+}
+
+// reminder monta o lembrete da etapa da régua alcançada pelo boleto em now, se houver. As
+// etapas contam a partir do vencimento efetivo, quando o boleto tem um, já que é até ele
+// que o pagamento é aceito sem encargos.
+func (u *DunningUseCase) reminder(invoice domain.Invoice, now time.Time) (domain.OutboxMessage, bool) {
+	due := invoice.DueDate
+	if invoice.EffectiveDueDate != "" {
+		due = invoice.EffectiveDueDate
+	}
+
+	dueDate, err := time.Parse(time.DateOnly, due)
+	if err != nil {
+		log.Printf("Vencimento inválido no boleto do débito %s: %v", invoice.Debt.DebtID, err)
+
+		return domain.OutboxMessage{}, false
+	}
+
+	// Os dias da régua são os do calendário de location, e não os do fuso do servidor.
+	stage, ok := u.cadences.DunningCadence(invoice.Debt.ClientID).Stage(dueDate, invoice.IssuedAt.In(u.location), now.In(u.location))
+	if !ok {
+		return domain.OutboxMessage{}, false
+	}
+
+	route, _ := notificationRoute(u.contacts, u.invalidEmails, invoice.Debt)
+	if route.IsEmpty() {
+		log.Printf("Débito %s sem canal para o lembrete %s", invoice.Debt.DebtID, domain.DunningStageLabel(stage))
+
+		return domain.OutboxMessage{}, false
+	}
+
+	return domain.NewDebtReminder(invoice, route, stage, now), true
+}
+
+// Run agenda os lembretes a cada interval até o contexto ser encerrado.
+func (u *DunningUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := u.Schedule()
+		switch {
+		case errors.Is(err, ErrSchedulerBusy):
+		case err != nil:
+			log.Printf("Erro ao agendar lembretes de cobrança: %v", err)
+		case result.Scheduled > 0:
+			log.Printf("%d lembretes de cobrança agendados", result.Scheduled)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type (
+	MockSchedulerLock struct {
+		mock.Mock
+	}
+
+	stubCadences map[string]domain.DunningCadence
+)
+
+func (m *MockSchedulerLock) TryLock(name, owner string, ttl time.Duration, now time.Time) bool {
+	args := m.Called(name, owner, ttl, now)
+
+	return args.Bool(0)
+}
+
+func (m *MockSchedulerLock) Unlock(name, owner string) {
+	m.Called(name, owner)
+}
+
+func (s stubCadences) DunningCadence(clientID string) domain.DunningCadence {
+	if cadence, ok := s[clientID]; ok {
+		return cadence
+	}
+
+	return domain.DefaultDunningCadence
+}
+
+type dunningTestSetup struct {
+	invoices      *MockInvoiceRepository
+	outbox        *MockOutboxRepository
+	contacts      *MockContactPreferenceRepository
+	invalidEmails *MockInvalidEmailRepository
+	lock          *MockSchedulerLock
+	useCase       *DunningUseCase
+}
+
+func newDunningTestSetup(now time.Time, cadences stubCadences) dunningTestSetup {
+	setup := dunningTestSetup{
+		invoices:      new(MockInvoiceRepository),
+		outbox:        new(MockOutboxRepository),
+		contacts:      new(MockContactPreferenceRepository),
+		invalidEmails: new(MockInvalidEmailRepository),
+		lock:          new(MockSchedulerLock),
+	}
+	setup.useCase = NewDunningUseCase(setup.invoices, setup.outbox, setup.contacts, setup.invalidEmails, cadences, setup.lock, "replica-a", dunningLocation)
+	setup.useCase.now = func() time.Time { return now }
+	setup.lock.On("TryLock", dunningLockName, "replica-a", 10*time.Minute, now).Return(true)
+	setup.lock.On("Unlock", dunningLockName, "replica-a").Return()
+	setup.contacts.On("FindByGovernmentID", mock.Anything).Return(domain.ContactPreferences{}, false)
+	setup.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+
+	return setup
+}
+
+var dunningLocation = time.FixedZone("BRT", -3*60*60)
+
+func dunningInvoice(debtID, clientID, dueDate string, issuedAt time.Time) domain.Invoice {
+	return domain.Invoice{
+		Debt:        domain.Debt{DebtID: debtID, ClientID: clientID, GovernmentID: "123", Email: debtID + "@example.com"},
+		NossoNumero: "000" + debtID,
+		DueDate:     dueDate,
+		Status:      domain.InvoiceStatusRegistered,
+		IssuedAt:    issuedAt,
+	}
+}
+
+func TestDunning_SchedulesReminders(t *testing.T) {
+	now := time.Date(2025, 3, 9, 8, 0, 0, 0, time.UTC)
+	issued := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := newDunningTestSetup(now, stubCadences{"acme": {2}})
+
+	s.invoices.On("FindOpen").Return([]domain.Invoice{
+		dunningInvoice("d1", "", "2025-03-10", issued),
+		dunningInvoice("d2", "", "2025-03-20", issued),
+		dunningInvoice("d3", "acme", "2025-03-06", issued),
+	})
+	s.outbox.On("Enqueue", mock.Anything).Return(2, nil)
+
+	result, err := s.useCase.Schedule()
+
+	assert.NoError(t, err)
+	assert.Equal(t, DunningResult{Scheduled: 2, Skipped: 1}, result)
+	s.outbox.AssertCalled(t, "Enqueue", mock.MatchedBy(func(messages []domain.OutboxMessage) bool {
+		return len(messages) == 2 &&
+			messages[0].ID == "debt_reminder:d1:000d1:D-1" &&
+			messages[0].Kind == domain.OutboxKindDebtReminder &&
+			messages[0].Recipient == "d1@example.com" &&
+			messages[1].ID == "debt_reminder:d3:000d3:D+2"
+	}))
+	s.lock.AssertCalled(t, "Unlock", dunningLockName, "replica-a")
+}
+
+func TestDunning_UsesEffectiveDueDate(t *testing.T) {
+	now := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	issued := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := newDunningTestSetup(now, nil)
+
+	// O vencimento cai no sábado e é prorrogado para segunda: em 10/03 o boleto vence hoje.
+	invoice := dunningInvoice("d1", "", "2025-03-08", issued)
+	invoice.EffectiveDueDate = "2025-03-10"
+	s.invoices.On("FindOpen").Return([]domain.Invoice{invoice})
+	s.outbox.On("Enqueue", mock.Anything).Return(1, nil)
+
+	result, err := s.useCase.Schedule()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Scheduled)
+	s.outbox.AssertCalled(t, "Enqueue", mock.MatchedBy(func(messages []domain.OutboxMessage) bool {
+		return len(messages) == 1 && messages[0].ID == "debt_reminder:d1:000d1:D+0"
+	}))
+}
+
+func TestDunning_UsesLocationDays(t *testing.T) {
+	// 01:00 UTC de 10/03 ainda é 09/03 em São Paulo: o lembrete é o da véspera.
+	now := time.Date(2025, 3, 10, 1, 0, 0, 0, time.UTC)
+	issued := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := newDunningTestSetup(now, nil)
+
+	s.invoices.On("FindOpen").Return([]domain.Invoice{dunningInvoice("d1", "", "2025-03-10", issued)})
+	s.outbox.On("Enqueue", mock.Anything).Return(1, nil)
+
+	_, err := s.useCase.Schedule()
+
+	assert.NoError(t, err)
+	s.outbox.AssertCalled(t, "Enqueue", mock.MatchedBy(func(messages []domain.OutboxMessage) bool {
+		return len(messages) == 1 && messages[0].ID == "debt_reminder:d1:000d1:D-1"
+	}))
+}
+
+func TestDunning_RepeatedRunIsIdempotent(t *testing.T) {
+	now := time.Date(2025, 3, 9, 8, 0, 0, 0, time.UTC)
+	s := newDunningTestSetup(now, nil)
+
+	s.invoices.On("FindOpen").Return([]domain.Invoice{dunningInvoice("d1", "", "2025-03-10", time.Time{})})
+	s.outbox.On("Enqueue", mock.Anything).Return(0, nil)
+
+	result, err := s.useCase.Schedule()
+
+	assert.NoError(t, err)
+	assert.Equal(t, DunningResult{Skipped: 1}, result)
+}
+
+func TestDunning_SkipsDebtorWithoutChannel(t *testing.T) {
+	now := time.Date(2025, 3, 9, 8, 0, 0, 0, time.UTC)
+	s := newDunningTestSetup(now, nil)
+	s.invalidEmails.ExpectedCalls = nil
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(true)
+
+	s.invoices.On("FindOpen").Return([]domain.Invoice{dunningInvoice("d1", "", "2025-03-10", time.Time{})})
+
+	result, err := s.useCase.Schedule()
+
+	assert.NoError(t, err)
+	assert.Equal(t, DunningResult{Skipped: 1}, result)
+	s.outbox.AssertNotCalled(t, "Enqueue", mock.Anything)
+}
+
+func TestDunning_LockHeldByAnotherReplica(t *testing.T) {
+	now := time.Now()
+	s := newDunningTestSetup(now, nil)
+	s.lock.ExpectedCalls = nil
+	s.lock.On("TryLock", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(false)
+
+	_, err := s.useCase.Schedule()
+
+	assert.ErrorIs(t, err, ErrSchedulerBusy)
+	s.invoices.AssertNotCalled(t, "FindOpen")
+}
+
+func TestDunning_EnqueueError(t *testing.T) {
+	now := time.Date(2025, 3, 9, 8, 0, 0, 0, time.UTC)
+	s := newDunningTestSetup(now, nil)
+
+	s.invoices.On("FindOpen").Return([]domain.Invoice{dunningInvoice("d1", "", "2025-03-10", time.Time{})})
+	s.outbox.On("Enqueue", mock.Anything).Return(0, errors.New("indisponível"))
+
+	_, err := s.useCase.Schedule()
+
+	assert.Error(t, err)
+	s.lock.AssertCalled(t, "Unlock", dunningLockName, "replica-a")
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var ErrEmailDeliveryNotFound = errors.New("envio de e-mail não encontrado")
+
+// EmailFeedbackUseCase aplica bounces e reclamações aos envios registrados e marca como
+// inválidos os e-mails que não devem mais ser usados para o devedor. A notificação do
+// e-mail invalidado é reaberta no próximo canal de contato do devedor, se houver.
+type EmailFeedbackUseCase struct {
+	outbox        service.OutboxRepository
+	deliveries    service.EmailDeliveryRepository
+	invalidEmails service.InvalidEmailRepository
+	now           func() time.Time
+}
+
+func NewEmailFeedbackUseCase(
+	outbox service.OutboxRepository,
+	deliveries service.EmailDeliveryRepository,
+	invalidEmails service.InvalidEmailRepository,
+) *EmailFeedbackUseCase {
+	return &EmailFeedbackUseCase{outbox: outbox, deliveries: deliveries, invalidEmails: invalidEmails, now: time.Now}
+}
+
+func (u *EmailFeedbackUseCase) Process(feedback domain.EmailFeedback) (domain.EmailDelivery, error) {
+	delivery, exists := u.deliveries.FindByProviderMessageID(feedback.ProviderMessageID)
+	if !exists {
+		return domain.EmailDelivery{}, ErrEmailDeliveryNotFound
+	}
+
+	if feedback.OccurredAt.IsZero() {
+		feedback.OccurredAt = u.now()
+	}
+
+	delivery.ApplyFeedback(feedback)
+	if err := u.deliveries.Save(delivery); err != nil {
+		return domain.EmailDelivery{}, err
+	}
+
+	if feedback.InvalidatesEmail() {
+		log.Printf("E-mail %s do devedor %s marcado como inválido (%s): %s", delivery.Email, delivery.GovernmentID, feedback.Type, feedback.Reason)
+
+		err := u.invalidEmails.MarkInvalid(domain.InvalidEmail{
+			GovernmentID: delivery.GovernmentID,
+			Email:        delivery.Email,
+			Reason:       feedback.Reason,
+			MarkedAt:     feedback.OccurredAt,
+		})
+		if err != nil {
+			return domain.EmailDelivery{}, err
+		}
+
+		if err := u.fallback(delivery, feedback); err != nil {
+			return domain.EmailDelivery{}, err
+		}
+	}
+
+	return delivery, nil
+}
+
+// fallback passa a notificação do envio para o próximo canal enquanto ela ainda estiver
+// no e-mail; feedbacks atrasados de uma notificação já redirecionada são ignorados.
+func (u *EmailFeedbackUseCase) fallback(delivery domain.EmailDelivery, feedback domain.EmailFeedback) error {
+	message, exists := u.outbox.FindOutboxMessage(delivery.NotificationID)
+	if !exists || (message.Channel != domain.ChannelEmail && message.Channel != "") {
+		return nil
+	}
+
+	if message.Channel == "" {
+		message.Channel = domain.ChannelEmail
+	}
+
+	if !message.Fallback(fmt.Sprintf("%s: %s", feedback.Type, feedback.Reason), feedback.OccurredAt) {
+		return nil
+	}
+
+	log.Printf("Notificação %s reaberta pelo canal %s após %s do e-mail %s", message.ID, message.Channel, feedback.Type, delivery.Email)
+
+	return u.outbox.Update(message)
+}
+
+func (u *EmailFeedbackUseCase) Deliveries(debtID string) []domain.EmailDelivery {
+	return u.deliveries.FindByDebtID(debtID)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestEmailFeedback_PermanentBounce(t *testing.T) {
+	outbox := new(MockOutboxRepository)
+	deliveries := new(MockEmailDeliveryRepository)
+	invalidEmails := new(MockInvalidEmailRepository)
+	useCase := NewEmailFeedbackUseCase(outbox, deliveries, invalidEmails)
+
+	occurredAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	deliveries.On("FindByProviderMessageID", "<m1>").Return(domain.EmailDelivery{
+		ID: "n1#1", NotificationID: "n1", DebtID: "d1", GovernmentID: "123", Email: "joao@example.com", Status: domain.EmailDeliverySent,
+	}, true)
+	deliveries.On("Save", mock.Anything).Return(nil)
+	invalidEmails.On("MarkInvalid", mock.Anything).Return(nil)
+
+	message := newOutboxMessage("d1", occurredAt.Add(-time.Hour), domain.ContactPreferences{Phone: "5511999998888"})
+	message.MarkDelivered(occurredAt.Add(-time.Hour))
+	outbox.On("FindOutboxMessage", "n1").Return(message, true)
+	outbox.On("Update", mock.Anything).Return(nil)
+
+	delivery, err := useCase.Process(domain.EmailFeedback{
+		ProviderMessageID: "<m1>", Type: domain.EmailFeedbackBounce, Permanent: true, Reason: "550 5.1.1", OccurredAt: occurredAt,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EmailDeliveryBounced, delivery.Status)
+	invalidEmails.AssertCalled(t, "MarkInvalid", domain.InvalidEmail{
+		GovernmentID: "123", Email: "joao@example.com", Reason: "550 5.1.1", MarkedAt: occurredAt,
+	})
+	outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.Status == domain.OutboxStatusPending &&
+			updated.Channel == domain.ChannelSMS &&
+			updated.NextAttemptAt.Equal(occurredAt)
+	}))
+}
+
+func TestEmailFeedback_Complaint(t *testing.T) {
+	outbox := new(MockOutboxRepository)
+	deliveries := new(MockEmailDeliveryRepository)
+	invalidEmails := new(MockInvalidEmailRepository)
+	useCase := NewEmailFeedbackUseCase(outbox, deliveries, invalidEmails)
+
+	outbox.On("FindOutboxMessage", mock.Anything).Return(domain.OutboxMessage{}, false)
+	deliveries.On("FindByProviderMessageID", "<m1>").Return(domain.EmailDelivery{GovernmentID: "123", Email: "joao@example.com"}, true)
+	deliveries.On("Save", mock.Anything).Return(nil)
+	invalidEmails.On("MarkInvalid", mock.Anything).Return(nil)
+
+	delivery, err := useCase.Process(domain.EmailFeedback{ProviderMessageID: "<m1>", Type: domain.EmailFeedbackComplaint})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EmailDeliveryComplained, delivery.Status)
+	assert.False(t, delivery.UpdatedAt.IsZero())
+	invalidEmails.AssertNumberOfCalls(t, "MarkInvalid", 1)
+}
+
+func TestEmailFeedback_TemporaryBounce(t *testing.T) {
+	deliveries := new(MockEmailDeliveryRepository)
+	invalidEmails := new(MockInvalidEmailRepository)
+	useCase := NewEmailFeedbackUseCase(new(MockOutboxRepository), deliveries, invalidEmails)
+
+	deliveries.On("FindByProviderMessageID", "<m1>").Return(domain.EmailDelivery{Status: domain.EmailDeliverySent}, true)
+	deliveries.On("Save", mock.Anything).Return(nil)
+
+	delivery, err := useCase.Process(domain.EmailFeedback{ProviderMessageID: "<m1>", Type: domain.EmailFeedbackBounce, Reason: "caixa cheia"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EmailDeliveryDeferred, delivery.Status)
+	invalidEmails.AssertNotCalled(t, "MarkInvalid", mock.Anything)
+}
+
+func TestEmailFeedback_UnknownMessage(t *testing.T) {
+	deliveries := new(MockEmailDeliveryRepository)
+	useCase := NewEmailFeedbackUseCase(new(MockOutboxRepository), deliveries, new(MockInvalidEmailRepository))
+
+	deliveries.On("FindByProviderMessageID", "<x>").Return(domain.EmailDelivery{}, false)
+
+	_, err := useCase.Process(domain.EmailFeedback{ProviderMessageID: "<x>", Type: domain.EmailFeedbackBounce})
+
+	assert.ErrorIs(t, err, ErrEmailDeliveryNotFound)
+}
@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"kanastra-api/internal/core/domain"
+)
+
+var (
+	ErrInvalidColumnMapping = errors.New("mapeamento de colunas inválido")
+	ErrInvalidFileFormat    = errors.New("formato do arquivo inválido")
+)
+
+// FileJobProcessor registra os envios dos arquivos e valida e envia ao Kafka suas linhas,
+// como o ProcessFileUseCase.
+type FileJobProcessor interface {
+	StartJob(fileName, source string, options domain.FileOptions) (domain.IngestionJob, error)
+	ProcessFileAsync(file io.Reader, fileName string, options domain.FileOptions) int
+	ProcessRecords(reader RecordReader, fileName string, options domain.FileOptions) int
+	FailJob(options domain.FileOptions, err error)
+}
+
+// ColumnMappingProvider devolve o mapeamento de colunas configurado para o cliente.
+type ColumnMappingProvider interface {
+	ColumnMapping(clientID string) domain.ColumnMapping
+}
+
+// SheetReader lê as linhas de uma aba de planilha, que é liberada em Close.
+type SheetReader interface {
+	RecordReader
+	Close() error
+}
+
+// ZipEntry é um CSV de um zip, descompactado à medida que é lido.
+type ZipEntry struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// FileDecoder lê os formatos aceitos na ingestão de arquivos, aplicando os limites de
+// descompactação aos arquivos compactados e às planilhas.
+type FileDecoder interface {
+	// ParseFormat lê o separador e a codificação informados; os vazios são detectados a
+	// partir de cada arquivo.
+	ParseFormat(delimiter, encoding string) (domain.FileFormat, error)
+	// OpenCSV converte o conteúdo para UTF-8 e devolve o formato detectado.
+	OpenCSV(content io.Reader, format domain.FileFormat) (io.Reader, domain.FileFormat, error)
+	OpenGzip(content io.Reader) (io.ReadCloser, error)
+	// OpenZip confere os limites do zip pelo seu diretório, sem descompactar as entradas, e
+	// devolve seus CSVs, que devem ser lidos um de cada vez.
+	OpenZip(file io.ReaderAt, size int64) ([]ZipEntry, error)
+	OpenSheet(file io.ReaderAt, size int64, sheet string) (SheetReader, error)
+}
+
+// FileJob é o envio criado para um arquivo, ou para cada CSV de um zip.
+type FileJob struct {
+	FileName string
+	JobID    string
+}
+
+// FileIntakeUseCase identifica o formato dos arquivos de débitos pelo nome (CSV, .csv.gz,
+// .zip ou XLSX) e os entrega à validação e ao envio das linhas, seja qual for a origem do
+// arquivo: /process-files, um objeto do bucket, um upload retomável, o drop folder ou um
+// servidor SFTP.
+type FileIntakeUseCase struct {
+	files    FileJobProcessor
+	mappings ColumnMappingProvider
+	decoder  FileDecoder
+}
+
+func NewFileIntakeUseCase(files FileJobProcessor, mappings ColumnMappingProvider, decoder FileDecoder) *FileIntakeUseCase {
+	return &FileIntakeUseCase{files: files, mappings: mappings, decoder: decoder}
+}
+
+// Options lê as opções dos arquivos dos campos do formulário de /process-files: clientId,
+// sheet, columns, delimiter e encoding. O mapeamento de columns, um objeto JSON, tem
+// precedência sobre o do cliente.
+func (u *FileIntakeUseCase) Options(fields map[string]string) (domain.FileOptions, error) {
+	options := domain.FileOptions{ClientID: fields["clientId"], Sheet: fields["sheet"]}
+
+	columns, err := u.columnMapping(options.ClientID, fields["columns"])
+	if err != nil {
+		return options, fmt.Errorf("%w: %w", ErrInvalidColumnMapping, err)
+	}
+	options.Columns = columns
+
+	format, err := u.decoder.ParseFormat(fields["delimiter"], fields["encoding"])
+	if err != nil {
+		return options, fmt.Errorf("%w: %w", ErrInvalidFileFormat, err)
+	}
+	options.Format = format
+
+	return options, nil
+}
+
+func (u *FileIntakeUseCase) columnMapping(clientID, form string) (domain.ColumnMapping, error) {
+	mapping := u.mappings.ColumnMapping(clientID)
+	if form == "" {
+		return mapping, nil
+	}
+
+	var upload domain.ColumnMapping
+	if err := json.Unmarshal([]byte(form), &upload); err != nil {
+		return nil, err
+	}
+
+	if err := upload.Validate(); err != nil {
+		return nil, err
+	}
+
+	return mapping.Merge(upload), nil
+}
+
+// RandomAccess indica se o arquivo precisa estar gravado em disco para ser processado,
+// como as planilhas e os zips. Os CSVs, compactados ou não, são lidos à medida que chegam.
+func (u *FileIntakeUseCase) RandomAccess(fileName string) bool {
+	source := fileSource(fileName)
+
+	return source == "zip" || source == "xlsx"
+}
+
+// Stream registra o envio de um CSV, compactado ou não, e o processa à medida que é lido.
+// Um .csv.gz inválido devolve o envio já registrado com o erro.
+func (u *FileIntakeUseCase) Stream(content io.Reader, fileName string, options domain.FileOptions) ([]FileJob, error) {
+	source := fileSource(fileName)
+
+	job, err := u.files.StartJob(fileName, source, options)
+	if err != nil {
+		return nil, err
+	}
+	options.JobID = job.ID
+	jobs := []FileJob{{FileName: fileName, JobID: job.ID}}
+
+	if source != "csv.gz" {
+		u.processCSV(content, fileName, options)
+
+		return jobs, nil
+	}
+
+	text, err := u.decoder.OpenGzip(content)
+	if err != nil {
+		return jobs, err
+	}
+	defer text.Close()
+
+	u.processCSV(text, fileName, options)
+
+	return jobs, nil
+}
+
+// Prepare registra os envios de um arquivo já gravado em disco e devolve a tarefa que o
+// processa, para que quem chama decida quando executá-la. O arquivo continua com quem
+// chama, que o libera depois da tarefa. Um zip acima dos limites declarados no seu
+// diretório é recusado aqui, antes de qualquer envio.
+func (u *FileIntakeUseCase) Prepare(file io.ReaderAt, fileName string, size int64, options domain.FileOptions) ([]FileJob, func(), error) {
+	source := fileSource(fileName)
+	if source == "zip" {
+		return u.prepareZip(file, fileName, size, options)
+	}
+
+	job, err := u.files.StartJob(fileName, source, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	options.JobID = job.ID
+
+	task := func() {
+		switch source {
+		case "xlsx":
+			u.processSheet(file, fileName, size, options)
+		case "csv.gz":
+			content, err := u.decoder.OpenGzip(io.NewSectionReader(file, 0, size))
+			if err != nil {
+				log.Printf("Erro ao descompactar arquivo %s: %v", fileName, err)
+				u.files.FailJob(options, err)
+
+				return
+			}
+			defer content.Close()
+
+			u.processCSV(content, fileName, options)
+		default:
+			u.processCSV(io.NewSectionReader(file, 0, size), fileName, options)
+		}
+	}
+
+	return []FileJob{{FileName: fileName, JobID: job.ID}}, task, nil
+}
+
+// ProcessFile processa um arquivo até o fim, como um arquivo enviado a /process-files
+// pelo cliente clientID, e devolve os envios criados.
+func (u *FileIntakeUseCase) ProcessFile(file io.ReaderAt, fileName string, size int64, clientID string) ([]string, error) {
+	options, err := u.Options(map[string]string{"clientId": clientID})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, task, err := u.Prepare(file, fileName, size, options)
+	if err != nil {
+		return nil, err
+	}
+
+	task()
+
+	jobIDs := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.JobID)
+	}
+
+	return jobIDs, nil
+}
+
+// prepareZip registra um envio por CSV do zip e devolve a tarefa que os descompacta e
+// processa um de cada vez, na ordem dos envios.
+func (u *FileIntakeUseCase) prepareZip(file io.ReaderAt, fileName string, size int64, options domain.FileOptions) ([]FileJob, func(), error) {
+	entries, err := u.decoder.OpenZip(file, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	jobs := make([]FileJob, 0, len(entries))
+	for _, entry := range entries {
+		name := fileName + "/" + entry.Name
+		job, err := u.files.StartJob(name, "zip", options)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		jobs = append(jobs, FileJob{FileName: name, JobID: job.ID})
+	}
+
+	task := func() {
+		for i, entry := range entries {
+			entryOptions := options
+			entryOptions.JobID = jobs[i].JobID
+
+			content, err := entry.Open()
+			if err != nil {
+				log.Printf("Erro ao descompactar %s: %v", jobs[i].FileName, err)
+				u.files.FailJob(entryOptions, err)
+
+				continue
+			}
+
+			u.processCSV(content, jobs[i].FileName, entryOptions)
+			content.Close()
+		}
+	}
+
+	return jobs, task, nil
+}
+
+// processCSV converte o conteúdo para UTF-8 e o processa à medida que é lido, sem voltar
+// ao início; o cabeçalho é conferido no processamento.
+func (u *FileIntakeUseCase) processCSV(content io.Reader, fileName string, options domain.FileOptions) {
+	text, format, err := u.decoder.OpenCSV(content, options.Format)
+	if err != nil {
+		log.Printf("Erro ao ler arquivo %s: %v", fileName, err)
+		u.files.FailJob(options, err)
+
+		return
+	}
+	options.Format = format
+
+	totalLines := u.files.ProcessFileAsync(text, fileName, options)
+	log.Printf("Arquivo %s processado: Total de linhas: %d", fileName, totalLines)
+}
+
+// processSheet lê a aba da planilha linha a linha pelo mesmo caminho de validação e envio
+// ao Kafka dos arquivos CSV.
+func (u *FileIntakeUseCase) processSheet(file io.ReaderAt, fileName string, size int64, options domain.FileOptions) {
+	reader, err := u.decoder.OpenSheet(file, size, options.Sheet)
+	if err != nil {
+		log.Printf("Erro ao abrir planilha %s: %v", fileName, err)
+		u.files.FailJob(options, err)
+
+		return
+	}
+	defer reader.Close()
+
+	totalLines := u.files.ProcessRecords(reader, fileName, options)
+	log.Printf("Planilha %s processada: Total de linhas: %d", fileName, totalLines)
+}
+
+// fileSource identifica o formato do arquivo pela extensão; sem uma extensão conhecida, o
+// arquivo é lido como CSV.
+func fileSource(fileName string) string {
+	name := strings.ToLower(fileName)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".xlsx"):
+		return "xlsx"
+	case strings.HasSuffix(name, ".csv.gz"):
+		return "csv.gz"
+	default:
+		return "csv"
+	}
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// recordingFiles registra os envios criados e o conteúdo ou o erro de cada um.
+type recordingFiles struct {
+	sources  map[string]string
+	contents map[string]string
+	failures map[string]error
+}
+
+func newRecordingFiles() *recordingFiles {
+	return &recordingFiles{sources: map[string]string{}, contents: map[string]string{}, failures: map[string]error{}}
+}
+
+func (f *recordingFiles) StartJob(fileName, source string, _ domain.FileOptions) (domain.IngestionJob, error) {
+	f.sources[fileName] = source
+
+	return domain.IngestionJob{ID: "job_" + fileName}, nil
+}
+
+func (f *recordingFiles) ProcessFileAsync(file io.Reader, _ string, options domain.FileOptions) int {
+	content, _ := io.ReadAll(file)
+	f.contents[options.JobID] = string(content)
+
+	return 1
+}
+
+func (f *recordingFiles) ProcessRecords(reader RecordReader, _ string, options domain.FileOptions) int {
+	row, _ := reader.Read()
+	f.contents[options.JobID] = strings.Join(row, ",")
+
+	return 1
+}
+
+func (f *recordingFiles) FailJob(options domain.FileOptions, err error) {
+	f.failures[options.JobID] = err
+}
+
+type staticMappings domain.ColumnMapping
+
+func (m staticMappings) ColumnMapping(string) domain.ColumnMapping {
+	return domain.ColumnMapping(m)
+}
+
+// plainDecoder devolve o conteúdo sem conversão; os zips têm as entradas de entries, e as
+// planilhas falham com sheetErr ou devolvem uma linha com o nome da aba.
+type plainDecoder struct {
+	entries  []ZipEntry
+	sheetErr error
+}
+
+func (d plainDecoder) ParseFormat(delimiter, _ string) (domain.FileFormat, error) {
+	if delimiter == "?" {
+		return domain.FileFormat{}, errors.New("separador não suportado")
+	}
+
+	return domain.FileFormat{Delimiter: delimiter}, nil
+}
+
+func (d plainDecoder) OpenCSV(content io.Reader, format domain.FileFormat) (io.Reader, domain.FileFormat, error) {
+	return content, format, nil
+}
+
+func (d plainDecoder) OpenGzip(content io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(content), nil
+}
+
+func (d plainDecoder) OpenZip(io.ReaderAt, int64) ([]ZipEntry, error) {
+	return d.entries, nil
+}
+
+func (d plainDecoder) OpenSheet(_ io.ReaderAt, _ int64, sheet string) (SheetReader, error) {
+	if d.sheetErr != nil {
+		return nil, d.sheetErr
+	}
+
+	return &sheetRows{rows: [][]string{{"aba", sheet}}}, nil
+}
+
+type sheetRows struct {
+	rows [][]string
+}
+
+func (s *sheetRows) Read() ([]string, error) {
+	if len(s.rows) == 0 {
+		return nil, io.EOF
+	}
+
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+
+	return row, nil
+}
+
+func (s *sheetRows) Close() error {
+	return nil
+}
+
+func entry(name, content string) ZipEntry {
+	return ZipEntry{Name: name, Open: func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(content)), nil
+	}}
+}
+
+func TestFileIntake_Options(t *testing.T) {
+	useCase := NewFileIntakeUseCase(newRecordingFiles(), staticMappings{
+		domain.DebtFieldDebtID: {Headers: []string{"contrato"}},
+	}, plainDecoder{})
+
+	options, err := useCase.Options(map[string]string{"clientId": "acme", "sheet": "Débitos", "columns": `{"debtAmount": 5}`, "delimiter": ";"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.FileOptions{
+		ClientID: "acme",
+		Sheet:    "Débitos",
+		Columns: domain.ColumnMapping{
+			domain.DebtFieldDebtID:     {Headers: []string{"contrato"}},
+			domain.DebtFieldDebtAmount: {Position: 5},
+		},
+		Format: domain.FileFormat{Delimiter: ";"},
+	}, options)
+
+	_, err = useCase.Options(map[string]string{"columns": `{"cpf": "documento"}`})
+	assert.ErrorIs(t, err, ErrInvalidColumnMapping)
+
+	_, err = useCase.Options(map[string]string{"delimiter": "?"})
+	assert.ErrorIs(t, err, ErrInvalidFileFormat)
+}
+
+func TestFileIntake_ProcessFile(t *testing.T) {
+	process := func(decoder plainDecoder, fileName, content string) (*recordingFiles, []string, error) {
+		files := newRecordingFiles()
+		useCase := NewFileIntakeUseCase(files, staticMappings{}, decoder)
+		jobIDs, err := useCase.ProcessFile(strings.NewReader(content), fileName, int64(len(content)), "acme")
+
+		return files, jobIDs, err
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		files, jobIDs, err := process(plainDecoder{}, "remessa.csv", "name\nJohn Doe\n")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"job_remessa.csv"}, jobIDs)
+		assert.Equal(t, "csv", files.sources["remessa.csv"])
+		assert.Equal(t, "name\nJohn Doe\n", files.contents["job_remessa.csv"])
+	})
+
+	t.Run("CSV com gzip", func(t *testing.T) {
+		files, _, err := process(plainDecoder{}, "remessa.CSV.GZ", "name\n")
+
+		require.NoError(t, err)
+		assert.Equal(t, "csv.gz", files.sources["remessa.CSV.GZ"])
+		assert.Equal(t, "name\n", files.contents["job_remessa.CSV.GZ"])
+	})
+
+	t.Run("Um envio por CSV do zip", func(t *testing.T) {
+		decoder := plainDecoder{entries: []ZipEntry{entry("janeiro.csv", "name\njaneiro\n"), entry("fevereiro.csv", "name\nfevereiro\n")}}
+
+		files, jobIDs, err := process(decoder, "lote.zip", "zip")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"job_lote.zip/janeiro.csv", "job_lote.zip/fevereiro.csv"}, jobIDs)
+		assert.Equal(t, "zip", files.sources["lote.zip/janeiro.csv"])
+		assert.Equal(t, "name\nfevereiro\n", files.contents["job_lote.zip/fevereiro.csv"])
+	})
+
+	t.Run("Planilha", func(t *testing.T) {
+		files, jobIDs, err := process(plainDecoder{}, "remessa.xlsx", "xlsx")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"job_remessa.xlsx"}, jobIDs)
+		assert.Equal(t, "xlsx", files.sources["remessa.xlsx"])
+		assert.Equal(t, "aba,", files.contents["job_remessa.xlsx"])
+	})
+
+	t.Run("Planilha inválida encerra o envio", func(t *testing.T) {
+		invalid := errors.New("planilha inválida")
+
+		files, jobIDs, err := process(plainDecoder{sheetErr: invalid}, "remessa.xlsx", "xlsx")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"job_remessa.xlsx"}, jobIDs)
+		assert.ErrorIs(t, files.failures["job_remessa.xlsx"], invalid)
+	})
+}
+
+func TestFileIntake_RandomAccess(t *testing.T) {
+	useCase := NewFileIntakeUseCase(newRecordingFiles(), staticMappings{}, plainDecoder{})
+
+	assert.True(t, useCase.RandomAccess("lote.ZIP"))
+	assert.True(t, useCase.RandomAccess("remessa.xlsx"))
+	assert.False(t, useCase.RandomAccess("remessa.csv"))
+	assert.False(t, useCase.RandomAccess("remessa.csv.gz"))
+}
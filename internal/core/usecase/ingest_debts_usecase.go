@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var (
+	// ErrMalformedDebt indica um item do envio que não pôde ser interpretado como débito; o
+	// item é registrado como inválido e os demais seguem.
+	ErrMalformedDebt      = errors.New("débito malformado")
+	ErrIngestionJobAbsent = errors.New("envio de débitos não encontrado")
+)
+
+// ingestionProgressInterval é a cada quantos itens o job é salvo durante o envio, para
+// que GET /debts/jobs/:jobId acompanhe o andamento.
+const ingestionProgressInterval = 100
+
+// DebtSource devolve os débitos de um envio em ordem e io.EOF ao fim.
+type DebtSource interface {
+	Next() (domain.Debt, error)
+}
+
+// DebtSubmitter valida, deduplica e envia ao Kafka um débito.
+type DebtSubmitter interface {
+	Submit(record domain.DebtRecord, key string, options domain.FileOptions) (domain.IngestionStatus, error)
+}
+
+// IngestDebtsUseCase recebe débitos enviados em JSON pela API e os encaminha pelo mesmo
+// caminho das linhas dos arquivos CSV, registrando o resultado de cada item em um job.
+type IngestDebtsUseCase struct {
+	submitter DebtSubmitter
+	jobs      service.IngestionJobRepository
+	now       func() time.Time
+}
+
+func NewIngestDebtsUseCase(submitter DebtSubmitter, jobs service.IngestionJobRepository) *IngestDebtsUseCase {
+	return &IngestDebtsUseCase{submitter: submitter, jobs: jobs, now: time.Now}
+}
+
+// Ingest processa os débitos de source à medida que são lidos. Débitos sem ClientID usam o
+// clientID do envio. O job é salvo no início e a cada ingestionProgressInterval itens, e
+// fica sem FinishedAt até o fim do envio. Um erro de leitura encerra o envio e é devolvido
+// junto com o job parcial.
+func (u *IngestDebtsUseCase) Ingest(source DebtSource, sourceName, clientID string) (domain.IngestionJob, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return domain.IngestionJob{}, fmt.Errorf("erro ao gerar identificador do envio: %w", err)
+	}
+
+	job := domain.IngestionJob{
+		ID:        "job_" + id,
+		Source:    sourceName,
+		ClientID:  clientID,
+		Results:   []domain.IngestionResult{},
+		CreatedAt: u.now(),
+	}
+	u.save(job)
+
+	var readErr error
+	for index := 0; ; index++ {
+		debt, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil && !errors.Is(err, ErrMalformedDebt) {
+			readErr = fmt.Errorf("erro ao ler débitos do envio %s: %w", job.ID, err)
+
+			break
+		}
+
+		if err != nil {
+			job.Record(domain.IngestionResult{Index: index, Status: domain.IngestionInvalid, Error: err.Error()})
+		} else {
+			job.Record(u.submit(index, debt, job))
+		}
+
+		if job.Total%ingestionProgressInterval == 0 {
+			u.save(job)
+		}
+	}
+
+	job.FinishedAt = u.now()
+	u.save(job)
+
+	log.Printf("Envio de débitos %s processado: %d aceitos, %d duplicados, %d inválidos, %d com falha",
+		job.ID, job.Accepted, job.Duplicates, job.Invalid, job.Failed)
+
+	return job, readErr
+}
+
+// save grava o job. Os resultados guardados só recebem novos itens ao fim da lista, além do
+// tamanho do job salvo, que assim pode ser consultado sem cópia durante o envio.
+func (u *IngestDebtsUseCase) save(job domain.IngestionJob) {
+	if err := u.jobs.Save(job); err != nil {
+		log.Printf("Erro ao salvar envio de débitos %s: %v", job.ID, err)
+	}
+}
+
+func (u *IngestDebtsUseCase) Find(id string) (domain.IngestionJob, error) {
+	job, exists := u.jobs.FindByID(id)
+	if !exists {
+		return domain.IngestionJob{}, ErrIngestionJobAbsent
+	}
+
+	return job, nil
+}
+
+func (u *IngestDebtsUseCase) submit(index int, debt domain.Debt, job domain.IngestionJob) domain.IngestionResult {
+	clientID := debt.ClientID
+	if clientID == "" {
+		clientID = job.ClientID
+	}
+
+	status, err := u.submitter.Submit(domain.NewDebtRecord(debt), job.ID, domain.FileOptions{ClientID: clientID})
+
+	result := domain.IngestionResult{Index: index, DebtID: debt.DebtID, Status: status}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockDebtSubmitter struct {
+	mock.Mock
+}
+
+func (m *MockDebtSubmitter) Submit(record domain.DebtRecord, key string, options domain.FileOptions) (domain.IngestionStatus, error) {
+	args := m.Called(record, key, options)
+
+	return args.Get(0).(domain.IngestionStatus), args.Error(1)
+}
+
+type MockIngestionJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockIngestionJobRepository) Save(job domain.IngestionJob) error {
+	args := m.Called(job)
+
+	return args.Error(0)
+}
+
+func (m *MockIngestionJobRepository) FindByID(id string) (domain.IngestionJob, bool) {
+	args := m.Called(id)
+
+	return args.Get(0).(domain.IngestionJob), args.Bool(1)
+}
+
+// sliceDebtSource devolve os itens informados e depois err, ou io.EOF.
+type sliceDebtSource struct {
+	items []sliceDebtItem
+	err   error
+}
+
+type sliceDebtItem struct {
+	debt domain.Debt
+	err  error
+}
+
+func (s *sliceDebtSource) Next() (domain.Debt, error) {
+	if len(s.items) == 0 {
+		if s.err != nil {
+			return domain.Debt{}, s.err
+		}
+
+		return domain.Debt{}, io.EOF
+	}
+
+	item := s.items[0]
+	s.items = s.items[1:]
+
+	return item.debt, item.err
+}
+
+func TestIngestDebts_RecordsEachItem(t *testing.T) {
+	submitter := new(MockDebtSubmitter)
+	jobs := new(MockIngestionJobRepository)
+	useCase := NewIngestDebtsUseCase(submitter, jobs)
+
+	source := &sliceDebtSource{items: []sliceDebtItem{
+		{debt: domain.Debt{Name: "John Doe", GovernmentID: "1234", Email: "john@example.com", DebtAmount: 100.5, DebtDueDate: "2025-01-01", DebtID: "d1"}},
+		{err: ErrMalformedDebt},
+		{debt: domain.Debt{DebtID: "d2", ClientID: "globex"}},
+		{debt: domain.Debt{DebtID: "d3"}},
+	}}
+
+	jobs.On("Save", mock.Anything).Return(nil)
+	submitter.On("Submit", domain.DebtRecord{
+		domain.DebtFieldName: "John Doe", domain.DebtFieldGovernmentID: "1234", domain.DebtFieldEmail: "john@example.com",
+		domain.DebtFieldDebtAmount: "100.5", domain.DebtFieldDebtDueDate: "2025-01-01", domain.DebtFieldDebtID: "d1",
+	}, mock.Anything,
+		domain.FileOptions{ClientID: "acme"}).Return(domain.IngestionAccepted, nil)
+	submitter.On("Submit", mock.MatchedBy(func(record domain.DebtRecord) bool { return record[domain.DebtFieldDebtID] == "d2" }), mock.Anything,
+		domain.FileOptions{ClientID: "globex"}).Return(domain.IngestionDuplicate, nil)
+	submitter.On("Submit", mock.MatchedBy(func(record domain.DebtRecord) bool { return record[domain.DebtFieldDebtID] == "d3" }), mock.Anything,
+		domain.FileOptions{ClientID: "acme"}).Return(domain.IngestionInvalid, errors.New("campo Email inválido"))
+
+	job, err := useCase.Ingest(source, "json", "acme")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, job.Total)
+	assert.Equal(t, 1, job.Accepted)
+	assert.Equal(t, 1, job.Duplicates)
+	assert.Equal(t, 2, job.Invalid)
+	assert.Equal(t, []int{1, 3}, []int{job.Results[0].Index, job.Results[1].Index}, "apenas os itens inválidos têm resultado")
+	assert.Len(t, job.Results, 2)
+	assert.Equal(t, "campo Email inválido", job.Results[1].Error)
+	submitter.AssertCalled(t, "Submit", mock.Anything, job.ID, mock.Anything)
+	jobs.AssertCalled(t, "Save", job)
+}
+
+func TestIngestDebts_ReadErrorKeepsPartialJob(t *testing.T) {
+	submitter := new(MockDebtSubmitter)
+	jobs := new(MockIngestionJobRepository)
+	useCase := NewIngestDebtsUseCase(submitter, jobs)
+
+	source := &sliceDebtSource{
+		items: []sliceDebtItem{{debt: domain.Debt{DebtID: "d1"}}},
+		err:   errors.New("conexão encerrada"),
+	}
+	jobs.On("Save", mock.Anything).Return(nil)
+	submitter.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(domain.IngestionAccepted, nil)
+
+	job, err := useCase.Ingest(source, "ndjson", "")
+
+	assert.Error(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, 1, job.Accepted)
+	jobs.AssertCalled(t, "Save", job)
+}
+
+func TestIngestDebts_SavesProgress(t *testing.T) {
+	submitter := new(MockDebtSubmitter)
+	jobs := new(MockIngestionJobRepository)
+	useCase := NewIngestDebtsUseCase(submitter, jobs)
+
+	source := &sliceDebtSource{}
+	for range domain.MaxIngestionResults + 50 {
+		source.items = append(source.items, sliceDebtItem{debt: domain.Debt{DebtID: "d1"}})
+	}
+
+	var saved []domain.IngestionJob
+	jobs.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		saved = append(saved, args.Get(0).(domain.IngestionJob))
+	}).Return(nil)
+	submitter.On("Submit", mock.Anything, mock.Anything, mock.Anything).Return(domain.IngestionFailed, errors.New("kafka indisponível"))
+
+	job, err := useCase.Ingest(source, "ndjson", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.MaxIngestionResults+50, job.Failed)
+	assert.Len(t, job.Results, domain.MaxIngestionResults)
+	assert.Equal(t, 50, job.OmittedResults)
+
+	assert.Len(t, saved, 12, "o job deve ser salvo no início, a cada 100 itens e no fim")
+	assert.Equal(t, 0, saved[0].Total)
+	assert.Equal(t, 100, saved[1].Total)
+	assert.True(t, saved[1].FinishedAt.IsZero())
+	assert.False(t, saved[11].FinishedAt.IsZero())
+}
+
+func TestIngestDebts_FindUnknownJob(t *testing.T) {
+	jobs := new(MockIngestionJobRepository)
+	jobs.On("FindByID", "job_desconhecido").Return(domain.IngestionJob{}, false)
+	useCase := NewIngestDebtsUseCase(new(MockDebtSubmitter), jobs)
+
+	_, err := useCase.Find("job_desconhecido")
+
+	assert.ErrorIs(t, err, ErrIngestionJobAbsent)
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var (
+	ErrDebtNotFound              = errors.New("débito não encontrado")
+	ErrInstallmentPlanExists     = domain.ErrInstallmentPlanExists
+	ErrInstallmentPlanNotFound   = errors.New("parcelamento não encontrado")
+	ErrInvalidInstallmentRequest = errors.New("parâmetros de parcelamento inválidos")
+	ErrDebtNotInstallable        = errors.New("boleto do débito não pode ser parcelado")
+)
+
+type InstallmentPlanRequest struct {
+	DebtID                 string
+	Installments           int
+	FirstDueDate           string
+	IntervalDays           int
+	MonthlyInterestPercent float64
+}
+
+type InstallmentPlanUseCase struct {
+	invoices service.InvoiceRepository
+	plans    service.InstallmentPlanRepository
+	invoice  InvoiceGenerator
+	now      func() time.Time
+}
+
+func NewInstallmentPlanUseCase(
+	invoices service.InvoiceRepository,
+	plans service.InstallmentPlanRepository,
+	invoice InvoiceGenerator,
+) *InstallmentPlanUseCase {
+	return &InstallmentPlanUseCase{invoices: invoices, plans: plans, invoice: invoice, now: time.Now}
+}
+
+func (u *InstallmentPlanUseCase) Create(request InstallmentPlanRequest) (domain.InstallmentPlan, error) {
+	firstDueDate, err := time.Parse(time.DateOnly, request.FirstDueDate)
+	if err != nil || request.Installments < 2 || request.Installments > domain.MaxInstallments ||
+		request.IntervalDays < 1 || request.MonthlyInterestPercent < 0 {
+		return domain.InstallmentPlan{}, ErrInvalidInstallmentRequest
+	}
+
+	original, found := u.invoices.FindByDebtID(request.DebtID)
+	if !found {
+		return domain.InstallmentPlan{}, ErrDebtNotFound
+	}
+
+	if original.Status != domain.InvoiceStatusIssued && original.Status != domain.InvoiceStatusRegistered {
+		return domain.InstallmentPlan{}, fmt.Errorf("%w: situação %s", ErrDebtNotInstallable, original.Status)
+	}
+
+	plan := domain.NewInstallmentPlan(
+		request.DebtID,
+		original.Amount,
+		request.Installments,
+		firstDueDate,
+		request.IntervalDays,
+		request.MonthlyInterestPercent,
+		u.now(),
+	)
+
+	// O parcelamento é reservado antes de gerar as parcelas, para que duas requisições
+	// simultâneas não parcelem o mesmo débito. Se algo falhar, a reserva é desfeita.
+	if err := u.plans.Create(plan); err != nil {
+		return domain.InstallmentPlan{}, err
+	}
+
+	// Os boletos das parcelas já gravados também são removidos se algo falhar depois, para
+	// que não fiquem parcelas de um parcelamento que não existe.
+	var saved []string
+	created := false
+	defer func() {
+		if created {
+			return
+		}
+
+		for _, debtID := range saved {
+			u.invoices.Delete(debtID)
+		}
+		u.plans.Delete(request.DebtID)
+	}()
+
+	// Todos os boletos são gerados antes de gravar o primeiro.
+	children := make([]domain.Invoice, 0, len(plan.Installments))
+	for i := range plan.Installments {
+		installment := &plan.Installments[i]
+
+		child := original.Debt
+		child.DebtID = installment.DebtID
+		child.DebtAmount = installment.Amount
+		child.DebtDueDate = installment.DueDate
+
+		invoice, err := u.invoice.Generate(child)
+		if err != nil {
+			return domain.InstallmentPlan{}, fmt.Errorf("erro ao gerar boleto da parcela %d: %w", installment.Number, err)
+		}
+
+		installment.NossoNumero = invoice.NossoNumero
+		installment.Status = invoice.Status
+		children = append(children, invoice)
+	}
+
+	for i, invoice := range children {
+		if err := u.invoices.Save(invoice); err != nil {
+			return domain.InstallmentPlan{}, fmt.Errorf("erro ao salvar boleto da parcela %d: %w", plan.Installments[i].Number, err)
+		}
+		saved = append(saved, invoice.Debt.DebtID)
+	}
+
+	if err := original.Cancel(u.now()); err != nil {
+		return domain.InstallmentPlan{}, err
+	}
+
+	if err := u.invoices.Save(original); err != nil {
+		return domain.InstallmentPlan{}, fmt.Errorf("erro ao cancelar boleto original: %w", err)
+	}
+
+	if err := u.plans.Save(plan); err != nil {
+		return domain.InstallmentPlan{}, fmt.Errorf("erro ao salvar parcelamento: %w", err)
+	}
+	created = true
+
+	log.Printf("Parcelamento criado para o débito %s em %d parcelas", request.DebtID, len(plan.Installments))
+
+	return plan, nil
+}
+
+// Get retorna o parcelamento com a situação atual do boleto de cada parcela.
+func (u *InstallmentPlanUseCase) Get(debtID string) (domain.InstallmentPlan, error) {
+	plan, exists := u.plans.FindByDebtID(debtID)
+	if !exists {
+		return domain.InstallmentPlan{}, ErrInstallmentPlanNotFound
+	}
+
+	for i := range plan.Installments {
+		if invoice, found := u.invoices.FindByDebtID(plan.Installments[i].DebtID); found {
+			plan.Installments[i].Status = invoice.Status
+		}
+	}
+
+	return plan, nil
+}
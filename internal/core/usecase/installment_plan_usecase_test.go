@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockInstallmentPlanRepository struct {
+	mock.Mock
+}
+
+func (m *MockInstallmentPlanRepository) Create(plan domain.InstallmentPlan) error {
+	args := m.Called(plan)
+
+	return args.Error(0)
+}
+
+func (m *MockInstallmentPlanRepository) Delete(debtID string) {
+	m.Called(debtID)
+}
+
+func (m *MockInstallmentPlanRepository) Save(plan domain.InstallmentPlan) error {
+	args := m.Called(plan)
+
+	return args.Error(0)
+}
+
+func (m *MockInstallmentPlanRepository) FindByDebtID(debtID string) (domain.InstallmentPlan, bool) {
+	args := m.Called(debtID)
+
+	return args.Get(0).(domain.InstallmentPlan), args.Bool(1)
+}
+
+func TestInstallmentPlan_Create(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	plans := new(MockInstallmentPlanRepository)
+	generator := new(MockInvoiceGenerator)
+	useCase := NewInstallmentPlanUseCase(invoices, plans, generator)
+
+	original := domain.Invoice{
+		Debt:   domain.Debt{DebtID: "d1", Name: "John Doe", Email: "john@example.com", DebtAmount: 300},
+		Amount: 300,
+		Status: domain.InvoiceStatusRegistered,
+	}
+
+	plans.On("Create", mock.Anything).Return(nil)
+	plans.On("Save", mock.Anything).Return(nil)
+	invoices.On("FindByDebtID", "d1").Return(original, true)
+	invoices.On("Save", mock.Anything).Return(nil)
+	generator.On("Generate", mock.Anything).Return(domain.Invoice{NossoNumero: "123", Status: domain.InvoiceStatusIssued}, nil)
+
+	plan, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "2025-02-10", IntervalDays: 30})
+
+	assert.NoError(t, err)
+	assert.Len(t, plan.Installments, 3)
+	assert.Equal(t, 300.0, plan.Total)
+	assert.Equal(t, "123", plan.Installments[0].NossoNumero)
+
+	generator.AssertCalled(t, "Generate", domain.Debt{
+		DebtID: "d1-P02", Name: "John Doe", Email: "john@example.com", DebtAmount: 100, DebtDueDate: "2025-03-12",
+	})
+	invoices.AssertCalled(t, "Save", mock.MatchedBy(func(invoice domain.Invoice) bool {
+		return invoice.Debt.DebtID == "d1" && invoice.Status == domain.InvoiceStatusCancelled
+	}))
+	plans.AssertNumberOfCalls(t, "Save", 1)
+	plans.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestInstallmentPlan_CreateErrors(t *testing.T) {
+	t.Run("Parâmetros inválidos", func(t *testing.T) {
+		useCase := NewInstallmentPlanUseCase(new(MockInvoiceRepository), new(MockInstallmentPlanRepository), new(MockInvoiceGenerator))
+
+		_, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 1, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.ErrorIs(t, err, ErrInvalidInstallmentRequest)
+
+		_, err = useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "10/02/2025", IntervalDays: 30})
+		assert.ErrorIs(t, err, ErrInvalidInstallmentRequest)
+
+		_, err = useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 100, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.ErrorIs(t, err, ErrInvalidInstallmentRequest)
+	})
+
+	t.Run("Parcelamento já existente", func(t *testing.T) {
+		invoices := new(MockInvoiceRepository)
+		plans := new(MockInstallmentPlanRepository)
+		generator := new(MockInvoiceGenerator)
+		invoices.On("FindByDebtID", "d1").Return(domain.Invoice{Amount: 300, Status: domain.InvoiceStatusRegistered}, true)
+		plans.On("Create", mock.Anything).Return(fmt.Errorf("%w: d1", domain.ErrInstallmentPlanExists))
+		useCase := NewInstallmentPlanUseCase(invoices, plans, generator)
+
+		_, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.ErrorIs(t, err, ErrInstallmentPlanExists)
+		generator.AssertNotCalled(t, "Generate", mock.Anything)
+		plans.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("Falha ao gerar parcela desfaz a reserva", func(t *testing.T) {
+		invoices := new(MockInvoiceRepository)
+		plans := new(MockInstallmentPlanRepository)
+		generator := new(MockInvoiceGenerator)
+		invoices.On("FindByDebtID", "d1").Return(domain.Invoice{Amount: 300, Status: domain.InvoiceStatusRegistered}, true)
+		plans.On("Create", mock.Anything).Return(nil)
+		plans.On("Delete", "d1").Return()
+		generator.On("Generate", mock.Anything).Return(domain.Invoice{}, errors.New("falha"))
+		useCase := NewInstallmentPlanUseCase(invoices, plans, generator)
+
+		_, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.Error(t, err)
+		plans.AssertCalled(t, "Delete", "d1")
+	})
+
+	t.Run("Falha ao salvar parcela remove as já gravadas", func(t *testing.T) {
+		invoices := new(MockInvoiceRepository)
+		plans := new(MockInstallmentPlanRepository)
+		generator := new(MockInvoiceGenerator)
+		invoices.On("FindByDebtID", "d1").Return(domain.Invoice{Amount: 300, Status: domain.InvoiceStatusRegistered}, true)
+		invoices.On("Save", mock.MatchedBy(func(invoice domain.Invoice) bool { return invoice.Debt.DebtID == "d1-P03" })).
+			Return(errors.New("falha"))
+		invoices.On("Save", mock.Anything).Return(nil)
+		invoices.On("Delete", mock.Anything).Return()
+		plans.On("Create", mock.Anything).Return(nil)
+		plans.On("Delete", "d1").Return()
+		for _, debtID := range []string{"d1-P01", "d1-P02", "d1-P03"} {
+			generator.On("Generate", mock.MatchedBy(func(debt domain.Debt) bool { return debt.DebtID == debtID })).
+				Return(domain.Invoice{Debt: domain.Debt{DebtID: debtID}, NossoNumero: debtID, Status: domain.InvoiceStatusIssued}, nil)
+		}
+		useCase := NewInstallmentPlanUseCase(invoices, plans, generator)
+
+		_, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.Error(t, err)
+		invoices.AssertCalled(t, "Delete", "d1-P01")
+		invoices.AssertCalled(t, "Delete", "d1-P02")
+		invoices.AssertNumberOfCalls(t, "Delete", 2)
+		invoices.AssertNotCalled(t, "Save", mock.MatchedBy(func(invoice domain.Invoice) bool { return invoice.Debt.DebtID == "d1" }))
+		plans.AssertCalled(t, "Delete", "d1")
+	})
+
+	t.Run("Débito inexistente", func(t *testing.T) {
+		invoices := new(MockInvoiceRepository)
+		plans := new(MockInstallmentPlanRepository)
+		invoices.On("FindByDebtID", "d1").Return(domain.Invoice{}, false)
+		useCase := NewInstallmentPlanUseCase(invoices, plans, new(MockInvoiceGenerator))
+
+		_, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.ErrorIs(t, err, ErrDebtNotFound)
+	})
+
+	t.Run("Débito já pago", func(t *testing.T) {
+		invoices := new(MockInvoiceRepository)
+		plans := new(MockInstallmentPlanRepository)
+		generator := new(MockInvoiceGenerator)
+		invoices.On("FindByDebtID", "d1").Return(domain.Invoice{Status: domain.InvoiceStatusPaid}, true)
+		useCase := NewInstallmentPlanUseCase(invoices, plans, generator)
+
+		_, err := useCase.Create(InstallmentPlanRequest{DebtID: "d1", Installments: 3, FirstDueDate: "2025-02-10", IntervalDays: 30})
+		assert.ErrorIs(t, err, ErrDebtNotInstallable)
+		generator.AssertNotCalled(t, "Generate", mock.Anything)
+	})
+}
+
+func TestInstallmentPlan_GetRefreshesStatus(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	plans := new(MockInstallmentPlanRepository)
+	useCase := NewInstallmentPlanUseCase(invoices, plans, new(MockInvoiceGenerator))
+
+	plans.On("FindByDebtID", "d1").Return(domain.InstallmentPlan{
+		DebtID: "d1",
+		Installments: []domain.Installment{
+			{Number: 1, DebtID: "d1-P01", Status: domain.InvoiceStatusIssued},
+			{Number: 2, DebtID: "d1-P02", Status: domain.InvoiceStatusIssued},
+		},
+	}, true)
+	invoices.On("FindByDebtID", "d1-P01").Return(domain.Invoice{Status: domain.InvoiceStatusPaid}, true)
+	invoices.On("FindByDebtID", "d1-P02").Return(domain.Invoice{Status: domain.InvoiceStatusRegistered}, true)
+
+	plan, err := useCase.Get("d1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.InvoiceStatusPaid, plan.Installments[0].Status)
+	assert.Equal(t, domain.InvoiceStatusRegistered, plan.Installments[1].Status)
+
+	plans.On("FindByDebtID", "d2").Return(domain.InstallmentPlan{}, false)
+	_, err = useCase.Get("d2")
+	assert.ErrorIs(t, err, ErrInstallmentPlanNotFound)
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+// IssueInvoiceUseCase emite e salva o boleto de um débito recebido pelo consumidor e
+// prepara a notificação ao devedor, roteada pelos canais de contato disponíveis. As
+// mensagens devolvidas devem ser gravadas no outbox junto com o débito. A emissão e a
+// falha na emissão são publicadas como eventos de webhook do cliente.
+type IssueInvoiceUseCase struct {
+	invoice       InvoiceGenerator
+	invoices      service.InvoiceRepository
+	invalidEmails service.InvalidEmailRepository
+	contacts      service.ContactPreferenceRepository
+	webhooks      WebhookEventPublisher
+	now           func() time.Time
+}
+
+func NewIssueInvoiceUseCase(
+	invoice InvoiceGenerator,
+	invoices service.InvoiceRepository,
+	invalidEmails service.InvalidEmailRepository,
+	contacts service.ContactPreferenceRepository,
+	webhooks WebhookEventPublisher,
+) *IssueInvoiceUseCase {
+	return &IssueInvoiceUseCase{
+		invoice:       invoice,
+		invoices:      invoices,
+		invalidEmails: invalidEmails,
+		contacts:      contacts,
+		webhooks:      webhooks,
+		now:           time.Now,
+	}
+}
+
+// Issue devolve nenhuma mensagem quando o devedor não tem canal utilizável; nesse caso o
+// boleto é salvo com AlternateChannel para cobrança por outro meio. Um débito já emitido,
+// entregue de novo pelo Kafka ou reenviado em outro arquivo, não gera outro boleto: o
+// boleto só é substituído quando o valor ou o vencimento mudaram e ele ainda não recebeu
+// pagamento.
+func (u *IssueInvoiceUseCase) Issue(debt domain.Debt) ([]domain.OutboxMessage, error) {
+	if existing, found := u.invoices.FindByDebtID(debt.DebtID); found {
+		if !debtChanged(existing.Debt, debt) {
+			return u.redeliver(existing), nil
+		}
+
+		if !replaceable(existing) {
+			log.Printf("Débito %s alterado, mas o boleto %s está com status %s e não é substituído", debt.DebtID, existing.NossoNumero, existing.Status)
+
+			return nil, nil
+		}
+
+		log.Printf("Débito %s alterado, boleto %s substituído", debt.DebtID, existing.NossoNumero)
+	}
+
+	generated, err := u.invoice.Generate(debt)
+	if err != nil {
+		publishWebhook(u.webhooks, "debt.failed:"+debt.DebtID+":invoice", domain.WebhookEventDebtFailed, debt.ClientID,
+			domain.WebhookDebtData{DebtID: debt.DebtID, Amount: debt.DebtAmount, DueDate: debt.DebtDueDate, Stage: "invoice", Reason: err.Error()}, u.now())
+
+		return nil, fmt.Errorf("erro ao gerar boleto: %w", err)
+	}
+
+	route, emailUsable := notificationRoute(u.contacts, u.invalidEmails, debt)
+
+	generated.AlternateChannel = !emailUsable
+	if err := u.invoices.Save(generated); err != nil {
+		return nil, fmt.Errorf("erro ao salvar boleto: %w", err)
+	}
+
+	publishWebhook(u.webhooks, "debt.invoiced:"+debt.DebtID+":"+generated.NossoNumero, domain.WebhookEventDebtInvoiced,
+		debt.ClientID, debtWebhookData(generated), u.now())
+
+	if !emailUsable {
+		log.Printf("E-mail %s do devedor %s marcado como inválido, débito %s sinalizado para outro canal", debt.Email, debt.GovernmentID, debt.DebtID)
+	}
+
+	if route.IsEmpty() {
+		log.Printf("Débito %s sem canal de notificação disponível", debt.DebtID)
+
+		return nil, nil
+	}
+
+	return []domain.OutboxMessage{domain.NewDebtNotification(generated, route, u.now())}, nil
+}
+
+// redeliver devolve de novo a notificação de um boleto já emitido. A mensagem tem o mesmo
+// ID da original, e o outbox a ignora se ela já foi gravada; ela só é gravada quando a
+// gravação anterior falhou depois de o boleto ser salvo.
+func (u *IssueInvoiceUseCase) redeliver(existing domain.Invoice) []domain.OutboxMessage {
+	if !replaceable(existing) {
+		return nil
+	}
+
+	route, _ := notificationRoute(u.contacts, u.invalidEmails, existing.Debt)
+	if route.IsEmpty() {
+		return nil
+	}
+
+	return []domain.OutboxMessage{domain.NewDebtNotification(existing, route, u.now())}
+}
+
+// debtChanged indica se o débito recebido muda o valor ou o vencimento do boleto emitido.
+func debtChanged(issued, received domain.Debt) bool {
+	return issued.DebtAmount != received.DebtAmount || issued.DebtDueDate != received.DebtDueDate
+}
+
+// replaceable indica se o boleto ainda não recebeu pagamento nem foi baixado.
+func replaceable(invoice domain.Invoice) bool {
+	return invoice.Status == domain.InvoiceStatusIssued || invoice.Status == domain.InvoiceStatusRegistered
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockContactPreferenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockContactPreferenceRepository) Save(preferences domain.ContactPreferences) error {
+	args := m.Called(preferences)
+
+	return args.Error(0)
+}
+
+func (m *MockContactPreferenceRepository) FindByGovernmentID(governmentID string) (domain.ContactPreferences, bool) {
+	args := m.Called(governmentID)
+
+	return args.Get(0).(domain.ContactPreferences), args.Bool(1)
+}
+
+type issueTestSetup struct {
+	invoice       *MockInvoiceGenerator
+	invoices      *MockInvoiceRepository
+	invalidEmails *MockInvalidEmailRepository
+	contacts      *MockContactPreferenceRepository
+	webhooks      *MockWebhookEventPublisher
+	useCase       *IssueInvoiceUseCase
+}
+
+func newIssueTestSetup(now time.Time) issueTestSetup {
+	setup := issueTestSetup{
+		invoice:       new(MockInvoiceGenerator),
+		invoices:      new(MockInvoiceRepository),
+		invalidEmails: new(MockInvalidEmailRepository),
+		contacts:      new(MockContactPreferenceRepository),
+		webhooks:      newMockWebhooks(),
+	}
+	setup.useCase = NewIssueInvoiceUseCase(setup.invoice, setup.invoices, setup.invalidEmails, setup.contacts, setup.webhooks)
+	setup.useCase.now = func() time.Time { return now }
+
+	return setup
+}
+
+func TestIssueInvoice_RoutesByContactPreferences(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newIssueTestSetup(now)
+
+	debt := domain.Debt{DebtID: "d1", GovernmentID: "123", Email: "joao@example.com"}
+	s.invoice.On("Generate", debt).Return(domain.Invoice{Debt: debt, NossoNumero: "1"}, nil)
+	s.invalidEmails.On("IsInvalid", "123", "joao@example.com").Return(false)
+	s.contacts.On("FindByGovernmentID", "123").Return(domain.ContactPreferences{
+		Phone: "5511999998888", Channels: []domain.Channel{domain.ChannelSMS, domain.ChannelEmail},
+	}, true)
+	s.invoices.On("FindByDebtID", "d1").Return(domain.Invoice{}, false)
+	s.invoices.On("Save", mock.Anything).Return(nil)
+
+	messages, err := s.useCase.Issue(debt)
+
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, domain.ChannelSMS, messages[0].Channel)
+	assert.Equal(t, "5511999998888", messages[0].Recipient)
+	assert.Equal(t, []domain.Channel{domain.ChannelSMS, domain.ChannelEmail}, messages[0].Route)
+	assert.Equal(t, now, messages[0].CreatedAt)
+}
+
+func TestIssueInvoice_InvalidEmailWithoutOtherChannel(t *testing.T) {
+	s := newIssueTestSetup(time.Now())
+
+	debt := domain.Debt{DebtID: "d1", GovernmentID: "123", Email: "joao@example.com"}
+	s.invoice.On("Generate", debt).Return(domain.Invoice{Debt: debt}, nil)
+	s.invalidEmails.On("IsInvalid", "123", "joao@example.com").Return(true)
+	s.contacts.On("FindByGovernmentID", "123").Return(domain.ContactPreferences{}, false)
+	s.invoices.On("FindByDebtID", "d1").Return(domain.Invoice{}, false)
+	s.invoices.On("Save", mock.Anything).Return(nil)
+
+	messages, err := s.useCase.Issue(debt)
+
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+	s.invoices.AssertCalled(t, "Save", mock.MatchedBy(func(invoice domain.Invoice) bool {
+		return invoice.AlternateChannel
+	}))
+}
+
+func TestIssueInvoice_GenerateError(t *testing.T) {
+	s := newIssueTestSetup(time.Now())
+
+	debt := domain.Debt{DebtID: "d1"}
+	s.invoices.On("FindByDebtID", "d1").Return(domain.Invoice{}, false)
+	s.invoice.On("Generate", debt).Return(domain.Invoice{}, errors.New("cliente desconhecido"))
+
+	_, err := s.useCase.Issue(debt)
+
+	assert.Error(t, err)
+	s.invoices.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestIssueInvoice_AlreadyIssued(t *testing.T) {
+	debt := domain.Debt{DebtID: "d1", GovernmentID: "123", Email: "joao@example.com", DebtAmount: 100, DebtDueDate: "2025-03-10"}
+
+	t.Run("Débito entregue de novo", func(t *testing.T) {
+		s := newIssueTestSetup(time.Now())
+		existing := domain.Invoice{Debt: debt, NossoNumero: "1", Status: domain.InvoiceStatusRegistered}
+		s.invoices.On("FindByDebtID", "d1").Return(existing, true)
+		s.invalidEmails.On("IsInvalid", "123", "joao@example.com").Return(false)
+		s.contacts.On("FindByGovernmentID", "123").Return(domain.ContactPreferences{}, false)
+
+		messages, err := s.useCase.Issue(debt)
+
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, domain.DebtNotificationKey(existing), messages[0].ID, "o outbox ignora a mensagem já gravada")
+		s.invoice.AssertNotCalled(t, "Generate", mock.Anything)
+		s.invoices.AssertNotCalled(t, "Save", mock.Anything)
+		s.webhooks.AssertNotCalled(t, "Publish", mock.Anything)
+	})
+
+	t.Run("Boleto pago não é substituído", func(t *testing.T) {
+		s := newIssueTestSetup(time.Now())
+		changed := debt
+		changed.DebtAmount = 150
+		s.invoices.On("FindByDebtID", "d1").Return(domain.Invoice{Debt: debt, Status: domain.InvoiceStatusPartial, PaidAmount: 50}, true)
+
+		messages, err := s.useCase.Issue(changed)
+
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+		s.invoices.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("Débito alterado antes do pagamento", func(t *testing.T) {
+		s := newIssueTestSetup(time.Now())
+		changed := debt
+		changed.DebtDueDate = "2025-04-10"
+		s.invoices.On("FindByDebtID", "d1").Return(domain.Invoice{Debt: debt, NossoNumero: "1", Status: domain.InvoiceStatusIssued}, true)
+		s.invoice.On("Generate", changed).Return(domain.Invoice{Debt: changed, NossoNumero: "2"}, nil)
+		s.invalidEmails.On("IsInvalid", "123", "joao@example.com").Return(false)
+		s.contacts.On("FindByGovernmentID", "123").Return(domain.ContactPreferences{}, false)
+		s.invoices.On("Save", mock.Anything).Return(nil)
+
+		messages, err := s.useCase.Issue(changed)
+
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		s.invoices.AssertCalled(t, "Save", mock.MatchedBy(func(invoice domain.Invoice) bool { return invoice.NossoNumero == "2" }))
+	})
+}
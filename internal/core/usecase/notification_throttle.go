@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+// ThrottlePolicy reúne os limites aplicados aos envios de notificações. Limites zerados
+// não são aplicados.
+type ThrottlePolicy struct {
+	QuietHours     domain.QuietHours
+	DebtorCap      domain.RateLimit
+	ChannelLimits  map[domain.Channel]domain.RateLimit
+	ProviderLimits map[string]domain.RateLimit
+}
+
+// NotificationThrottle decide se uma notificação pode ser enviada agora ou deve ser adiada.
+// Os token buckets ficam em memória e valem para cada instância da aplicação; o limite por
+// devedor é calculado a partir das notificações já entregues.
+type NotificationThrottle struct {
+	policy  ThrottlePolicy
+	history service.OutboxRepository
+	buckets map[string]*domain.TokenBucket
+	mu      sync.Mutex
+}
+
+func NewNotificationThrottle(policy ThrottlePolicy, history service.OutboxRepository) *NotificationThrottle {
+	return &NotificationThrottle{policy: policy, history: history, buckets: make(map[string]*domain.TokenBucket)}
+}
+
+// Acquire reserva o envio da mensagem pelo provedor informado. Quando o envio não é
+// permitido, devolve até quando adiá-lo e o motivo; os tokens só são consumidos quando o
+// envio é permitido.
+func (t *NotificationThrottle) Acquire(message domain.OutboxMessage, provider string, now time.Time) (time.Time, string, bool) {
+	if until, quiet := t.policy.QuietHours.Until(now); quiet {
+		return until, "horário de silêncio", false
+	}
+
+	if until, capped := t.debtorCap(message.Invoice.Debt.GovernmentID, now); capped {
+		return until, "limite de notificações do devedor", false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var reserved []*domain.TokenBucket
+	for _, limit := range []struct {
+		key   string
+		limit domain.RateLimit
+	}{
+		{key: "channel:" + string(message.Channel), limit: t.policy.ChannelLimits[message.Channel]},
+		{key: "provider:" + provider, limit: t.policy.ProviderLimits[provider]},
+	} {
+		if limit.limit.IsZero() {
+			continue
+		}
+
+		bucket := t.bucket(limit.key, limit.limit, now)
+		if wait := bucket.Wait(now); wait > 0 {
+			return now.Add(wait), fmt.Sprintf("limite de envio %s", limit.key), false
+		}
+
+		reserved = append(reserved, bucket)
+	}
+
+	for _, bucket := range reserved {
+		bucket.Take(now)
+	}
+
+	return time.Time{}, "", true
+}
+
+func (t *NotificationThrottle) debtorCap(governmentID string, now time.Time) (time.Time, bool) {
+	if t.policy.DebtorCap.IsZero() || governmentID == "" {
+		return time.Time{}, false
+	}
+
+	delivered, err := t.history.DeliveredSince(governmentID, now.Add(-t.policy.DebtorCap.Per))
+	if err != nil {
+		log.Printf("Erro ao consultar notificações entregues ao devedor %s: %v", governmentID, err)
+
+		return time.Time{}, false
+	}
+
+	return t.policy.DebtorCap.NextAllowed(delivered, now)
+}
+
+func (t *NotificationThrottle) bucket(key string, limit domain.RateLimit, now time.Time) *domain.TokenBucket {
+	bucket, exists := t.buckets[key]
+	if !exists {
+		bucket = domain.NewTokenBucket(limit, now)
+		t.buckets[key] = bucket
+	}
+
+	return bucket
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestNotificationThrottle_QuietHours(t *testing.T) {
+	saoPaulo := time.FixedZone("BRT", -3*60*60)
+	quietHours, err := domain.ParseQuietHours("21:00-08:00", saoPaulo)
+	assert.NoError(t, err)
+	throttle := NewNotificationThrottle(ThrottlePolicy{QuietHours: quietHours}, new(MockOutboxRepository))
+
+	message := newOutboxMessage("d1", time.Now())
+	until, reason, allowed := throttle.Acquire(message, "smtp", time.Date(2025, 3, 1, 23, 0, 0, 0, saoPaulo))
+
+	assert.False(t, allowed)
+	assert.Equal(t, "horário de silêncio", reason)
+	assert.True(t, until.Equal(time.Date(2025, 3, 2, 8, 0, 0, 0, saoPaulo)))
+
+	_, _, allowed = throttle.Acquire(message, "smtp", time.Date(2025, 3, 2, 9, 0, 0, 0, saoPaulo))
+	assert.True(t, allowed)
+}
+
+func TestNotificationThrottle_DebtorCap(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	outbox := new(MockOutboxRepository)
+	throttle := NewNotificationThrottle(ThrottlePolicy{DebtorCap: domain.RateLimit{Count: 1, Per: 24 * time.Hour}}, outbox)
+
+	outbox.On("DeliveredSince", "123d1", now.Add(-24*time.Hour)).Return([]time.Time{now.Add(-2 * time.Hour)}, nil)
+	outbox.On("DeliveredSince", "123d2", now.Add(-24*time.Hour)).Return([]time.Time(nil), nil)
+
+	until, reason, allowed := throttle.Acquire(newOutboxMessage("d1", now), "smtp", now)
+	assert.False(t, allowed)
+	assert.Equal(t, "limite de notificações do devedor", reason)
+	assert.Equal(t, now.Add(22*time.Hour), until)
+
+	_, _, allowed = throttle.Acquire(newOutboxMessage("d2", now), "smtp", now)
+	assert.True(t, allowed)
+}
+
+func TestNotificationThrottle_RateLimits(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	throttle := NewNotificationThrottle(ThrottlePolicy{
+		ChannelLimits:  map[domain.Channel]domain.RateLimit{domain.ChannelEmail: {Count: 2, Per: time.Second}},
+		ProviderLimits: map[string]domain.RateLimit{"smtp-b": {Count: 1, Per: time.Minute}},
+	}, new(MockOutboxRepository))
+	message := newOutboxMessage("d1", now)
+
+	_, _, allowed := throttle.Acquire(message, "smtp-b", now)
+	assert.True(t, allowed)
+
+	until, reason, allowed := throttle.Acquire(message, "smtp-b", now)
+	assert.False(t, allowed)
+	assert.Equal(t, "limite de envio provider:smtp-b", reason)
+	assert.Equal(t, now.Add(time.Minute), until)
+
+	_, _, allowed = throttle.Acquire(message, "smtp-a", now)
+	assert.True(t, allowed, "o limite do provedor recusado não consome token do canal")
+
+	until, reason, allowed = throttle.Acquire(message, "smtp-a", now)
+	assert.False(t, allowed)
+	assert.Equal(t, "limite de envio channel:email", reason)
+	assert.Equal(t, now.Add(500*time.Millisecond), until)
+}
+
+func TestDispatchOutbox_DefersThrottledNotification(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+	s.useCase.throttle = NewNotificationThrottle(ThrottlePolicy{
+		ChannelLimits: map[domain.Channel]domain.RateLimit{domain.ChannelEmail: {Count: 1, Per: time.Minute}},
+	}, s.outbox)
+
+	first, second := newOutboxMessage("d1", now), newOutboxMessage("d2", now)
+	second.Attempts = 1
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{first, second}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.email.On("Publish", "d1@example.com", mock.Anything).Return("<m1@kanastra.com.br>", nil)
+
+	result, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OutboxDispatchResult{Delivered: 1, Deferred: 1}, result)
+	s.email.AssertNumberOfCalls(t, "Publish", 1)
+	s.outbox.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.OutboxMessage) bool {
+		return updated.ID == second.ID &&
+			updated.Status == domain.OutboxStatusPending &&
+			updated.Attempts == 1 &&
+			updated.NextAttemptAt.Equal(now.Add(time.Minute)) &&
+			updated.LastError == "limite de envio channel:email"
+	}))
+}
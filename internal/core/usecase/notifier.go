@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+// Notifier entrega a notificação de cobrança por um canal e devolve o identificador da
+// mensagem atribuído pelo provedor. Recusas definitivas do destinatário devem ser
+// devolvidas como domain.ErrPermanentDeliveryFailure. Provider identifica o provedor usado
+// no envio, para a aplicação dos limites de envio por provedor.
+type Notifier interface {
+	Channel() domain.Channel
+	Provider() string
+	Notify(recipient string, notification domain.Notification) (string, error)
+}
+
+// EmailNotifier expõe um EmailPublisher como o canal de e-mail.
+type EmailNotifier struct {
+	publisher EmailPublisher
+	provider  string
+}
+
+func NewEmailNotifier(publisher EmailPublisher, provider string) *EmailNotifier {
+	return &EmailNotifier{publisher: publisher, provider: provider}
+}
+
+func (n *EmailNotifier) Channel() domain.Channel {
+	return domain.ChannelEmail
+}
+
+func (n *EmailNotifier) Provider() string {
+	return n.provider
+}
+
+func (n *EmailNotifier) Notify(recipient string, notification domain.Notification) (string, error) {
+	return n.publisher.Publish(recipient, notification)
+}
+
+// notificationRoute monta a rota de notificação do débito a partir das preferências de
+// contato do devedor e informa se o e-mail do débito ainda pode ser usado.
+func notificationRoute(
+	contacts service.ContactPreferenceRepository,
+	invalidEmails service.InvalidEmailRepository,
+	debt domain.Debt,
+) (domain.NotificationRoute, bool) {
+	emailUsable := !invalidEmails.IsInvalid(debt.GovernmentID, debt.Email)
+	preferences, _ := contacts.FindByGovernmentID(debt.GovernmentID)
+
+	return preferences.Route(debt, emailUsable), emailUsable
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("link de descadastro inválido")
+
+// OptOutUseCase gera e valida os links assinados de descadastro de lembretes e mantém os
+// descadastros dos devedores. O token identifica o devedor e o e-mail e é assinado com
+// HMAC-SHA256, de modo que só quem recebeu o e-mail consegue descadastrá-lo.
+type OptOutUseCase struct {
+	optOuts service.OptOutRepository
+	secret  []byte
+	baseURL string
+	now     func() time.Time
+}
+
+func NewOptOutUseCase(optOuts service.OptOutRepository, secret, baseURL string) *OptOutUseCase {
+	return &OptOutUseCase{optOuts: optOuts, secret: []byte(secret), baseURL: baseURL, now: time.Now}
+}
+
+// UnsubscribeURL devolve o link de descadastro do devedor, ou vazio quando o descadastro
+// não está configurado.
+func (u *OptOutUseCase) UnsubscribeURL(governmentID, email string) string {
+	if len(u.secret) == 0 || u.baseURL == "" || governmentID == "" || email == "" {
+		return ""
+	}
+
+	separator := "?"
+	if strings.Contains(u.baseURL, "?") {
+		separator = "&"
+	}
+
+	return u.baseURL + separator + "token=" + url.QueryEscape(u.token(governmentID, email))
+}
+
+// Verify valida o token e devolve o devedor e o e-mail que ele identifica.
+func (u *OptOutUseCase) Verify(token string) (string, string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || len(u.secret) == 0 {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	received, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(received, u.sign(decoded)) {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	governmentID, email, ok := strings.Cut(string(decoded), "\n")
+	if !ok || governmentID == "" || email == "" {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	return governmentID, email, nil
+}
+
+// Unsubscribe descadastra o devedor identificado pelo token. Repetir o descadastro não
+// tem efeito.
+func (u *OptOutUseCase) Unsubscribe(token string, source domain.OptOutSource) (domain.OptOut, error) {
+	governmentID, email, err := u.Verify(token)
+	if err != nil {
+		return domain.OptOut{}, err
+	}
+
+	optOut := domain.OptOut{GovernmentID: governmentID, Email: email, Source: source, OptedOutAt: u.now()}
+	if err := u.optOuts.Save(optOut); err != nil {
+		return domain.OptOut{}, fmt.Errorf("erro ao registrar descadastro do devedor %s: %w", governmentID, err)
+	}
+
+	return optOut, nil
+}
+
+// Resubscribe volta a enviar lembretes ao e-mail do devedor.
+func (u *OptOutUseCase) Resubscribe(governmentID, email string) error {
+	if err := u.optOuts.Delete(governmentID, email); err != nil {
+		return fmt.Errorf("erro ao remover descadastro do devedor %s: %w", governmentID, err)
+	}
+
+	return nil
+}
+
+func (u *OptOutUseCase) List(governmentID string) []domain.OptOut {
+	return u.optOuts.FindByGovernmentID(governmentID)
+}
+
+func (u *OptOutUseCase) token(governmentID, email string) string {
+	payload := []byte(governmentID + "\n" + domain.NormalizeEmail(email))
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(u.sign(payload))
+}
+
+func (u *OptOutUseCase) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
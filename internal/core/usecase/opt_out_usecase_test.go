@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestOptOutUseCase_UnsubscribeURL(t *testing.T) {
+	useCase := NewOptOutUseCase(new(MockOptOutRepository), "segredo", "https://cobranca.example.com/unsubscribe")
+
+	link := useCase.UnsubscribeURL("12345678901", "Joao@Example.com")
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "/unsubscribe", parsed.Path)
+
+	governmentID, email, err := useCase.Verify(parsed.Query().Get("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, "12345678901", governmentID)
+	assert.Equal(t, "joao@example.com", email)
+
+	assert.Empty(t, NewOptOutUseCase(new(MockOptOutRepository), "", "https://cobranca.example.com/unsubscribe").UnsubscribeURL("1", "a@b.com"))
+	assert.Contains(t, NewOptOutUseCase(nil, "segredo", "https://x.example.com/u?lang=pt").UnsubscribeURL("1", "a@b.com"), "?lang=pt&token=")
+}
+
+func TestOptOutUseCase_Verify_RejectsTamperedToken(t *testing.T) {
+	useCase := NewOptOutUseCase(new(MockOptOutRepository), "segredo", "https://cobranca.example.com/unsubscribe")
+	token := useCase.token("12345678901", "joao@example.com")
+	forged := NewOptOutUseCase(nil, "outro", "").token("12345678901", "joao@example.com")
+	payload, signature, _ := strings.Cut(token, ".")
+
+	for _, invalid := range []string{"", "abc", payload, forged, payload + "x." + signature, useCase.token("123", "")} {
+		_, _, err := useCase.Verify(invalid)
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken, invalid)
+	}
+}
+
+func TestOptOutUseCase_Unsubscribe(t *testing.T) {
+	optOuts := new(MockOptOutRepository)
+	useCase := NewOptOutUseCase(optOuts, "segredo", "https://cobranca.example.com/unsubscribe")
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+	optOuts.On("Save", mock.Anything).Return(nil)
+
+	optOut, err := useCase.Unsubscribe(useCase.token("12345678901", "joao@example.com"), domain.OptOutSourceOneClick)
+
+	assert.NoError(t, err)
+	expected := domain.OptOut{GovernmentID: "12345678901", Email: "joao@example.com", Source: domain.OptOutSourceOneClick, OptedOutAt: now}
+	assert.Equal(t, expected, optOut)
+	optOuts.AssertCalled(t, "Save", expected)
+
+	_, err = useCase.Unsubscribe("invalido", domain.OptOutSourceLink)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+	optOuts.AssertNumberOfCalls(t, "Save", 1)
+}
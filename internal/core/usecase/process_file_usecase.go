@@ -2,21 +2,25 @@ package usecase
 
 import (
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"kanastra-api/internal/core/domain"
 	"kanastra-api/internal/core/service"
 	"log"
 	"regexp"
-	"strings"
+	"time"
 )
 
+// EmailPublisher envia a notificação de cobrança e devolve o Message-ID atribuído ao
+// e-mail, usado para correlacionar bounces e reclamações.
 type EmailPublisher interface {
-	Publish(email string, debt domain.Debt)
+	Publish(email string, notification domain.Notification) (string, error)
 }
 
 type InvoiceGenerator interface {
-	Generate(debt domain.Debt)
+	Generate(debt domain.Debt) (domain.Invoice, error)
 }
 
 type KafkaProducer interface {
@@ -28,111 +32,271 @@ type ProcessFileUseCase struct {
 	email    EmailPublisher
 	invoice  InvoiceGenerator
 	producer KafkaProducer
+	webhooks WebhookEventPublisher
+	jobs     service.IngestionJobRepository
+	now      func() time.Time
 }
 
-func NewProcessFileUseCase(repo service.DebtRepository, email EmailPublisher, invoice InvoiceGenerator, producer KafkaProducer) *ProcessFileUseCase {
-	return &ProcessFileUseCase{repo: repo, email: email, invoice: invoice, producer: producer}
+func NewProcessFileUseCase(
+	repo service.DebtRepository,
+	email EmailPublisher,
+	invoice InvoiceGenerator,
+	producer KafkaProducer,
+	webhooks WebhookEventPublisher,
+	jobs service.IngestionJobRepository,
+) *ProcessFileUseCase {
+	return &ProcessFileUseCase{repo: repo, email: email, invoice: invoice, producer: producer, webhooks: webhooks, jobs: jobs, now: time.Now}
 }
 
-func (u *ProcessFileUseCase) ProcessFileAsync(file io.Reader, fileName string) (totalLines int) {
+// RecordReader lê as linhas de um arquivo de débitos já separadas em campos, como o
+// csv.Reader e o leitor de planilhas XLSX.
+type RecordReader interface {
+	Read() ([]string, error)
+}
+
+// StartJob registra o envio de um arquivo antes do processamento, para que o cliente possa
+// acompanhá-lo. O ID do envio deve ser informado em FileOptions.JobID.
+func (u *ProcessFileUseCase) StartJob(fileName, source string, options domain.FileOptions) (domain.IngestionJob, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return domain.IngestionJob{}, fmt.Errorf("erro ao gerar identificador do envio: %w", err)
+	}
+
+	job := domain.IngestionJob{
+		ID:        "job_" + id,
+		Source:    source,
+		ClientID:  options.ClientID,
+		FileName:  fileName,
+		Results:   []domain.IngestionResult{},
+		CreatedAt: u.now(),
+	}
+
+	if err := u.jobs.Save(job); err != nil {
+		return domain.IngestionJob{}, fmt.Errorf("erro ao salvar envio do arquivo %s: %w", fileName, err)
+	}
+
+	return job, nil
+}
+
+// FailJob encerra com err o envio de um arquivo descartado antes da leitura das linhas,
+// como um arquivo compactado inválido.
+func (u *ProcessFileUseCase) FailJob(options domain.FileOptions, err error) {
+	job := u.loadJob(options)
+	job.Fail(err)
+	u.finishJob(job)
+}
+
+// ProcessFileAsync lê o arquivo CSV, já em UTF-8, com o separador de options.Format.
+func (u *ProcessFileUseCase) ProcessFileAsync(file io.Reader, fileName string, options domain.FileOptions) int {
 	reader := csv.NewReader(file)
-	reader.Comma = ','
+	reader.Comma = options.Format.Comma()
 	reader.FieldsPerRecord = -1
+
+	return u.ProcessRecords(reader, fileName, options)
+}
+
+// ProcessRecords valida e envia ao Kafka as linhas lidas de reader. A primeira linha é o
+// cabeçalho, usado para localizar as colunas de cada campo pelo mapeamento de options; o
+// arquivo é descartado se faltar alguma coluna obrigatória. Linhas malformadas são
+// descartadas; um erro de leitura encerra o arquivo.
+func (u *ProcessFileUseCase) ProcessRecords(reader RecordReader, fileName string, options domain.FileOptions) (totalLines int) {
 	batchSize := 1000
-	var batch [][]string
+	var batch []domain.DebtRecord
 
-	_, err := reader.Read()
+	job := u.loadJob(options)
+	defer u.finishJob(job)
+
+	header, err := reader.Read()
 	if err != nil {
-		log.Printf("Erro ao ignorar o cabeçalho do arquivo: %v", err)
+		log.Printf("Erro ao ler o cabeçalho do arquivo: %v", err)
+		job.Fail(fmt.Errorf("erro ao ler o cabeçalho do arquivo: %w", err))
+
+		return
+	}
+
+	columns, err := options.Columns.Resolve(header)
+	if err != nil {
+		log.Printf("Cabeçalho do arquivo %s inválido: %v", fileName, err)
+		job.Fail(err)
 
 		return
 	}
 
 	for {
-		record, err := reader.Read()
+		row, err := reader.Read()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
 
 			log.Printf("Erro ao ler linha do arquivo: %v", err)
+
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				job.Fail(fmt.Errorf("erro ao ler o arquivo: %w", err))
+
+				break
+			}
+
 			continue
 		}
 
 		totalLines++
 
+		record, err := columns.Record(row)
+		if err != nil {
+			log.Printf("Linha inválida: %v, Erro: %v", row, err)
+			job.Count(domain.IngestionInvalid)
+
+			continue
+		}
+
 		batch = append(batch, record)
 		if len(batch) == batchSize {
-			if err := u.sendBatch(fileName, batch); err != nil {
+			if err := u.sendBatch(fileName, batch, options, job); err != nil {
 				log.Printf("Erro ao enviar lote para o Kafka (arquivo: %s): %v", fileName, err)
-
-				continue
 			}
 			batch = nil
 		}
 	}
 
 	if len(batch) > 0 {
-		if err := u.sendBatch(fileName, batch); err != nil {
+		if err := u.sendBatch(fileName, batch, options, job); err != nil {
 			log.Printf("Erro ao enviar último lote para o Kafka (arquivo: %s): %v", fileName, err)
 		}
 	}
 
+	processedAt := u.now()
+	publishWebhook(u.webhooks, fmt.Sprintf("file.processed:%s:%d", fileName, processedAt.UnixNano()), domain.WebhookEventFileProcessed,
+		options.ClientID, domain.WebhookFileData{FileName: fileName, TotalLines: totalLines}, processedAt)
+
 	return totalLines
 }
 
-func (u *ProcessFileUseCase) sendBatch(fileName string, batch [][]string) error {
+// sendBatch envia todos os débitos do lote, somando o resultado de cada um em job, e
+// devolve o primeiro erro de envio.
+func (u *ProcessFileUseCase) sendBatch(fileName string, batch []domain.DebtRecord, options domain.FileOptions, job *fileJob) error {
+	var sendErr error
 	for _, record := range batch {
-		err := validateRecord(record)
-		if err != nil {
-			log.Printf("Linha inválida: %v, Erro: %v", record, err)
+		status, err := u.Submit(record, fileName, options)
+		job.Count(status)
 
-			continue
+		if status == domain.IngestionFailed && sendErr == nil {
+			sendErr = err
 		}
+	}
 
-		if u.repo.IsLineProcessed(record[5]) {
-			log.Printf("Linha já foi processada: %v", record)
+	return sendErr
+}
 
-			continue
-		}
+// fileJob acumula os totais de um arquivo no envio registrado por StartJob. Um fileJob nil
+// ignora os totais, para arquivos processados sem envio.
+type fileJob struct {
+	job domain.IngestionJob
+}
 
-		message := strings.Join(record, ",")
-		if err := u.producer.Produce(fileName, []byte(message)); err != nil {
-			log.Printf("Erro ao enviar mensagem ao Kafka: %v", err)
+func (j *fileJob) Count(status domain.IngestionStatus) {
+	if j != nil {
+		j.job.Count(status)
+	}
+}
 
-			return err
-		}
+func (j *fileJob) Fail(err error) {
+	if j != nil {
+		j.job.Error = err.Error()
+	}
+}
 
-		err = u.repo.Save(record[5])
-		if err != nil {
-			return err
-		}
+func (u *ProcessFileUseCase) loadJob(options domain.FileOptions) *fileJob {
+	if options.JobID == "" {
+		return nil
+	}
+
+	job, exists := u.jobs.FindByID(options.JobID)
+	if !exists {
+		log.Printf("Envio %s do arquivo não encontrado; os totais não serão registrados", options.JobID)
 
-		log.Printf("Mensagem enviada ao Kafka com sucesso: %s", message)
+		return nil
 	}
 
-	return nil
+	if options.Format != (domain.FileFormat{}) {
+		format := options.Format
+		job.Format = &format
+	}
+
+	return &fileJob{job: job}
 }
 
-func validateRecord(record []string) error {
-	if len(record) != 6 {
-		return fmt.Errorf("registro inválido: número incorreto de campos")
+func (u *ProcessFileUseCase) finishJob(job *fileJob) {
+	if job == nil {
+		return
 	}
 
-	if !IsValidGovernmentID(record[1]) {
-		return fmt.Errorf("governmentID inválido: %s", record[1])
+	job.job.FinishedAt = u.now()
+	if err := u.jobs.Save(job.job); err != nil {
+		log.Printf("Erro ao salvar envio de débitos %s: %v", job.job.ID, err)
+	}
+}
+
+// Submit valida um débito, descarta os já processados e envia os demais ao Kafka em JSON
+// com a chave informada, registrando o débito como processado.
+func (u *ProcessFileUseCase) Submit(record domain.DebtRecord, key string, options domain.FileOptions) (domain.IngestionStatus, error) {
+	if err := validateRecord(record); err != nil {
+		log.Printf("Linha inválida: %v, Erro: %v", record, err)
+
+		return domain.IngestionInvalid, err
 	}
 
-	if !IsValidEmail(record[2]) {
-		return fmt.Errorf("email inválido: %s", record[2])
+	debtID := record[domain.DebtFieldDebtID]
+	if u.repo.IsLineProcessed(debtID) {
+		log.Printf("Linha já foi processada: %v", record)
+
+		return domain.IngestionDuplicate, nil
 	}
 
-	if !IsValidDebtAmount(record[3]) {
-		return fmt.Errorf("debtAmount inválido: %s", record[3])
+	message, err := json.Marshal(record.Debt(options.ClientID))
+	if err != nil {
+		return domain.IngestionFailed, fmt.Errorf("erro ao montar mensagem do débito %s: %w", debtID, err)
 	}
 
-	if !IsValidDebtDueDate(record[4]) {
-		return fmt.Errorf("debtDueDate inválida: %s", record[4])
+	if err := u.producer.Produce(key, message); err != nil {
+		log.Printf("Erro ao enviar mensagem ao Kafka: %v", err)
+
+		return domain.IngestionFailed, err
+	}
+
+	if err := u.repo.Save(debtID); err != nil {
+		return domain.IngestionFailed, err
+	}
+
+	log.Printf("Mensagem enviada ao Kafka com sucesso: %s", message)
+
+	return domain.IngestionAccepted, nil
+}
+
+func validateRecord(record domain.DebtRecord) error {
+	if record[domain.DebtFieldDebtID] == "" {
+		return fmt.Errorf("registro inválido: debtId vazio")
+	}
+
+	if !IsValidGovernmentID(record[domain.DebtFieldGovernmentID]) {
+		return fmt.Errorf("governmentID inválido: %s", record[domain.DebtFieldGovernmentID])
+	}
+
+	if !IsValidEmail(record[domain.DebtFieldEmail]) {
+		return fmt.Errorf("email inválido: %s", record[domain.DebtFieldEmail])
+	}
+
+	if !IsValidDebtAmount(record[domain.DebtFieldDebtAmount]) {
+		return fmt.Errorf("debtAmount inválido: %s", record[domain.DebtFieldDebtAmount])
+	}
+
+	if !IsValidDebtDueDate(record[domain.DebtFieldDebtDueDate]) {
+		return fmt.Errorf("debtDueDate inválida: %s", record[domain.DebtFieldDebtDueDate])
+	}
+
+	if locale := record[domain.DebtFieldLocale]; locale != "" && !IsValidLocale(locale) {
+		return fmt.Errorf("locale inválido: %s", locale)
 	}
 
 	return nil
@@ -152,6 +316,15 @@ func IsValidDebtDueDate(date string) bool {
 	return matched
 }
 
+// IsValidLocale confere o formato da etiqueta de idioma, como pt-BR ou en-US. Idiomas sem
+// catálogo usam o idioma padrão nas notificações.
+func IsValidLocale(locale string) bool {
+	regexPattern := `^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})?$`
+	matched, _ := regexp.MatchString(regexPattern, locale)
+
+	return matched
+}
+
 func IsValidDebtAmount(amount string) bool {
 	regexPattern := `^\d+(\.\d{1,2})?$`
 	matched, _ := regexp.MatchString(regexPattern, amount)
@@ -3,6 +3,7 @@ package usecase
 import (
 	"bytes"
 	"errors"
+	"io"
 	"kanastra-api/internal/core/domain"
 	"testing"
 
@@ -40,12 +41,16 @@ func (m *MockDebtRepository) IsLineProcessed(debtID string) bool {
 	return args.Bool(0)
 }
 
-func (m *MockEmailPublisher) Publish(email string, debt domain.Debt) {
-	m.Called(email, debt)
+func (m *MockEmailPublisher) Publish(email string, notification domain.Notification) (string, error) {
+	args := m.Called(email, notification)
+
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockInvoiceGenerator) Generate(debt domain.Debt) {
-	m.Called(debt)
+func (m *MockInvoiceGenerator) Generate(debt domain.Debt) (domain.Invoice, error) {
+	args := m.Called(debt)
+
+	return args.Get(0).(domain.Invoice), args.Error(1)
 }
 
 func (m *MockKafkaProducer) Produce(key string, value []byte) error {
@@ -54,13 +59,23 @@ func (m *MockKafkaProducer) Produce(key string, value []byte) error {
 	return args.Error(0)
 }
 
+// debtRecord monta o débito com os valores na ordem do cabeçalho padrão.
+func debtRecord(values ...string) domain.DebtRecord {
+	record := domain.DebtRecord{}
+	for index, field := range domain.DebtFields {
+		record[field] = values[index]
+	}
+
+	return record
+}
+
 func TestProcessFileAsync_Success(t *testing.T) {
 	repo := new(MockDebtRepository)
 	email := new(MockEmailPublisher)
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
 	fileContent := `Name,GovernmentID,Email,DebtAmount,DebtDueDate,DebtID
 John Doe,1234,john.doe@example.com,100.00,2025-01-01,1a2b3c4d
@@ -73,7 +88,7 @@ Jane Doe,5678,jane.doe@example.com,200.50,2025-02-02,2a2b3c4d`
 
 	producer.On("Produce", mock.Anything, mock.Anything).Return(nil)
 
-	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv")
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", domain.FileOptions{})
 
 	assert.Equal(t, 2, totalLines)
 	repo.AssertNumberOfCalls(t, "Save", 2)
@@ -86,10 +101,10 @@ func TestProcessFileAsync_EmptyFile(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
 	fileContent := ``
-	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv")
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", domain.FileOptions{})
 
 	assert.Equal(t, 0, totalLines)
 	repo.AssertNotCalled(t, "Save", mock.Anything)
@@ -102,11 +117,11 @@ func TestProcessFileAsync_HeaderError(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
 	fileContent := `Name,GovernmentID,Email,DebtAmount,DebtDueDate`
 
-	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv")
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", domain.FileOptions{})
 
 	assert.Equal(t, 0, totalLines)
 	repo.AssertNotCalled(t, "Save", mock.Anything)
@@ -119,7 +134,7 @@ func TestProcessFileAsync_LastBatchError(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
 	fileContent := `Name,GovernmentID,Email,DebtAmount,DebtDueDate,DebtID
 John Doe,1234,john.doe@example.com,100.00,2025-01-01,1a2b3c4d`
@@ -127,7 +142,7 @@ John Doe,1234,john.doe@example.com,100.00,2025-01-01,1a2b3c4d`
 	producer.On("Produce", mock.Anything, mock.Anything).Return(errors.New("erro ao produzir mensagem"))
 	repo.On("IsLineProcessed", "1a2b3c4d").Return(false)
 
-	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv")
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", domain.FileOptions{})
 
 	assert.Equal(t, 1, totalLines)
 	producer.AssertCalled(t, "Produce", mock.Anything, mock.Anything)
@@ -140,8 +155,8 @@ func TestSendBatch_EmptyBatch(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
-	err := useCase.sendBatch("test.csv", [][]string{})
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
+	err := useCase.sendBatch("test.csv", nil, domain.FileOptions{}, nil)
 	assert.NoError(t, err)
 	repo.AssertNotCalled(t, "Save", mock.Anything)
 	producer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
@@ -153,14 +168,14 @@ func TestSendBatch_DuplicateLine(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
-	record := []string{"John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d"}
-	batch := [][]string{record}
+	record := debtRecord("John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d")
+	batch := []domain.DebtRecord{record}
 
 	repo.On("IsLineProcessed", "1a2b3c4d").Return(true)
 
-	err := useCase.sendBatch("test.csv", batch)
+	err := useCase.sendBatch("test.csv", batch, domain.FileOptions{}, nil)
 	assert.NoError(t, err)
 	repo.AssertNotCalled(t, "Save", "1a2b3c4d")
 	producer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
@@ -172,17 +187,17 @@ func TestSendBatch_SaveError(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
-	record := []string{"John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d"}
-	batch := [][]string{record}
+	record := debtRecord("John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d")
+	batch := []domain.DebtRecord{record}
 
 	repo.On("IsLineProcessed", "1a2b3c4d").Return(false)
 	repo.On("Save", "1a2b3c4d").Return(errors.New("erro ao salvar no repositório"))
 
 	producer.On("Produce", mock.Anything, mock.Anything).Return(nil)
 
-	err := useCase.sendBatch("test.csv", batch)
+	err := useCase.sendBatch("test.csv", batch, domain.FileOptions{}, nil)
 	assert.Error(t, err)
 	repo.AssertCalled(t, "Save", "1a2b3c4d")
 	producer.AssertCalled(t, "Produce", "test.csv", mock.Anything)
@@ -194,20 +209,39 @@ func TestSendBatch_ProduceError(t *testing.T) {
 	invoice := new(MockInvoiceGenerator)
 	producer := new(MockKafkaProducer)
 
-	useCase := NewProcessFileUseCase(repo, email, invoice, producer)
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
 
-	record := []string{"John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d"}
-	batch := [][]string{record}
+	record := debtRecord("John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d")
+	batch := []domain.DebtRecord{record}
 
 	repo.On("IsLineProcessed", "1a2b3c4d").Return(false)
 	producer.On("Produce", "test.csv", mock.Anything).Return(errors.New("erro ao enviar mensagem"))
 
-	err := useCase.sendBatch("test.csv", batch)
+	err := useCase.sendBatch("test.csv", batch, domain.FileOptions{}, nil)
 	assert.Error(t, err)
 	producer.AssertCalled(t, "Produce", "test.csv", mock.Anything)
 	repo.AssertNotCalled(t, "Save", "1a2b3c4d")
 }
 
+func TestSendBatch_WithClientID(t *testing.T) {
+	repo := new(MockDebtRepository)
+	email := new(MockEmailPublisher)
+	invoice := new(MockInvoiceGenerator)
+	producer := new(MockKafkaProducer)
+
+	useCase := NewProcessFileUseCase(repo, email, invoice, producer, newMockWebhooks(), new(MockIngestionJobRepository))
+
+	record := debtRecord("John Doe", "1234", "john.doe@example.com", "100.00", "2025-01-01", "1a2b3c4d")
+
+	repo.On("IsLineProcessed", "1a2b3c4d").Return(false)
+	repo.On("Save", "1a2b3c4d").Return(nil)
+	producer.On("Produce", "test.csv", mock.Anything).Return(nil)
+
+	err := useCase.sendBatch("test.csv", []domain.DebtRecord{record}, domain.FileOptions{ClientID: "acme"}, nil)
+	assert.NoError(t, err)
+	producer.AssertCalled(t, "Produce", "test.csv", []byte(`{"Name":"John Doe","GovernmentID":"1234","Email":"john.doe@example.com","DebtAmount":100,"DebtDueDate":"2025-01-01","DebtID":"1a2b3c4d","ClientID":"acme"}`))
+}
+
 func TestValidators(t *testing.T) {
 	assert.True(t, IsValidGovernmentID("12345"))
 	assert.False(t, IsValidGovernmentID("abc"))
@@ -220,4 +254,170 @@ func TestValidators(t *testing.T) {
 
 	assert.True(t, IsValidDebtDueDate("2025-12-31"))
 	assert.False(t, IsValidDebtDueDate("31/12/2025"))
+
+	assert.True(t, IsValidLocale("en-US"))
+	assert.False(t, IsValidLocale("inglês"))
+}
+
+// sliceRecordReader devolve as linhas informadas e depois err, ou io.EOF.
+type sliceRecordReader struct {
+	rows [][]string
+	err  error
+}
+
+func (r *sliceRecordReader) Read() ([]string, error) {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+
+		return nil, io.EOF
+	}
+
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+
+	return row, nil
+}
+
+func TestProcessRecords_StopsOnReadError(t *testing.T) {
+	repo := new(MockDebtRepository)
+	producer := new(MockKafkaProducer)
+
+	useCase := NewProcessFileUseCase(repo, new(MockEmailPublisher), new(MockInvoiceGenerator), producer, newMockWebhooks(), new(MockIngestionJobRepository))
+
+	reader := &sliceRecordReader{
+		rows: [][]string{
+			{"name", "governmentId", "email", "debtAmount", "debtDueDate", "debtId"},
+			{"John Doe", "1234", "john.doe@example.com", "100", "2025-01-01", "1a2b3c4d"},
+		},
+		err: errors.New("planilha XLSX inválida"),
+	}
+
+	repo.On("IsLineProcessed", "1a2b3c4d").Return(false)
+	repo.On("Save", "1a2b3c4d").Return(nil)
+	producer.On("Produce", "debts.xlsx", mock.Anything).Return(nil)
+
+	totalLines := useCase.ProcessRecords(reader, "debts.xlsx", domain.FileOptions{})
+
+	assert.Equal(t, 1, totalLines)
+	producer.AssertNumberOfCalls(t, "Produce", 1)
+}
+
+func TestSubmit_Statuses(t *testing.T) {
+	repo := new(MockDebtRepository)
+	producer := new(MockKafkaProducer)
+
+	useCase := NewProcessFileUseCase(repo, new(MockEmailPublisher), new(MockInvoiceGenerator), producer, newMockWebhooks(), new(MockIngestionJobRepository))
+
+	repo.On("IsLineProcessed", "d1").Return(false)
+	repo.On("IsLineProcessed", "d2").Return(true)
+	repo.On("Save", "d1").Return(nil)
+	producer.On("Produce", "job_1", mock.Anything).Return(nil)
+
+	status, err := useCase.Submit(debtRecord("Doe, John", "1234", "john.doe@example.com", "100", "2025-01-01", "d1"), "job_1", domain.FileOptions{ClientID: "acme"})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.IngestionAccepted, status)
+
+	status, err = useCase.Submit(debtRecord("Jane Doe", "5678", "jane.doe@example.com", "200", "2025-02-02", "d2"), "job_1", domain.FileOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.IngestionDuplicate, status)
+
+	status, err = useCase.Submit(debtRecord("Jane Doe", "5678", "email-invalido", "200", "2025-02-02", "d3"), "job_1", domain.FileOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, domain.IngestionInvalid, status)
+	producer.AssertNumberOfCalls(t, "Produce", 1)
+}
+
+func TestProcessFileAsync_ColumnMapping(t *testing.T) {
+	repo := new(MockDebtRepository)
+	producer := new(MockKafkaProducer)
+
+	useCase := NewProcessFileUseCase(repo, new(MockEmailPublisher), new(MockInvoiceGenerator), producer, newMockWebhooks(), new(MockIngestionJobRepository))
+
+	fileContent := `Código,Vencimento,Observação,Nome,CPF,E-mail,Valor
+1a2b3c4d,2025-01-01,cliente antigo,John Doe,1234,john.doe@example.com,100.50
+2a2b3c4d,2025-02-02`
+
+	repo.On("IsLineProcessed", "1a2b3c4d").Return(false)
+	repo.On("Save", "1a2b3c4d").Return(nil)
+	producer.On("Produce", "test.csv", []byte(`{"Name":"John Doe","GovernmentID":"1234","Email":"john.doe@example.com","DebtAmount":100.5,"DebtDueDate":"2025-01-01","DebtID":"1a2b3c4d"}`)).Return(nil)
+
+	options := domain.FileOptions{Columns: domain.ColumnMapping{domain.DebtFieldDebtID: {Headers: []string{"codigo"}}}}
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", options)
+
+	assert.Equal(t, 2, totalLines)
+	producer.AssertNumberOfCalls(t, "Produce", 1)
+}
+
+func TestProcessFileAsync_LocaleColumn(t *testing.T) {
+	repo := new(MockDebtRepository)
+	producer := new(MockKafkaProducer)
+
+	useCase := NewProcessFileUseCase(repo, new(MockEmailPublisher), new(MockInvoiceGenerator), producer, newMockWebhooks(), new(MockIngestionJobRepository))
+
+	fileContent := `name,governmentId,email,debtAmount,debtDueDate,debtId,idioma
+John Doe,1234,john.doe@example.com,100.50,2025-01-01,1a2b3c4d,en-US
+Jane Doe,1235,jane.doe@example.com,100.50,2025-01-01,2a2b3c4d,`
+
+	for _, debtID := range []string{"1a2b3c4d", "2a2b3c4d"} {
+		repo.On("IsLineProcessed", debtID).Return(false)
+		repo.On("Save", debtID).Return(nil)
+	}
+	producer.On("Produce", "test.csv", mock.Anything).Return(nil)
+
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", domain.FileOptions{ClientID: "acme"})
+
+	assert.Equal(t, 2, totalLines)
+	producer.AssertCalled(t, "Produce", "test.csv", []byte(`{"Name":"John Doe","GovernmentID":"1234","Email":"john.doe@example.com","DebtAmount":100.5,"DebtDueDate":"2025-01-01","DebtID":"1a2b3c4d","ClientID":"acme","Locale":"en-US"}`))
+	producer.AssertCalled(t, "Produce", "test.csv", []byte(`{"Name":"Jane Doe","GovernmentID":"1235","Email":"jane.doe@example.com","DebtAmount":100.5,"DebtDueDate":"2025-01-01","DebtID":"2a2b3c4d","ClientID":"acme"}`))
+}
+
+func TestProcessFileAsync_MissingColumns(t *testing.T) {
+	repo := new(MockDebtRepository)
+	producer := new(MockKafkaProducer)
+
+	useCase := NewProcessFileUseCase(repo, new(MockEmailPublisher), new(MockInvoiceGenerator), producer, newMockWebhooks(), new(MockIngestionJobRepository))
+
+	fileContent := `cpf,valor,vencimento
+1234,100.50,2025-01-01`
+
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "test.csv", domain.FileOptions{})
+
+	assert.Equal(t, 0, totalLines)
+	producer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
+}
+
+func TestProcessFileAsync_RecordsJob(t *testing.T) {
+	repo := new(MockDebtRepository)
+	producer := new(MockKafkaProducer)
+	jobs := new(MockIngestionJobRepository)
+
+	useCase := NewProcessFileUseCase(repo, new(MockEmailPublisher), new(MockInvoiceGenerator), producer, newMockWebhooks(), jobs)
+
+	jobs.On("Save", mock.Anything).Return(nil)
+	job, err := useCase.StartJob("debts.csv", "csv", domain.FileOptions{ClientID: "acme"})
+	assert.NoError(t, err)
+	jobs.On("FindByID", job.ID).Return(job, true)
+
+	fileContent := `nome;cpf;email;valor;vencimento;id
+John Doe;1234;john.doe@example.com;100.50;2025-01-01;d1
+Jane Doe;5678;jane.doe@example.com;200.50;2025-02-02;d2
+Jane Doe;5678;email-invalido;200.50;2025-02-02;d3
+Jane Doe;5678`
+
+	repo.On("IsLineProcessed", "d1").Return(false)
+	repo.On("IsLineProcessed", "d2").Return(true)
+	repo.On("Save", "d1").Return(nil)
+	producer.On("Produce", "debts.csv", mock.Anything).Return(nil)
+
+	format := domain.FileFormat{Delimiter: ";", Encoding: "windows-1252"}
+	totalLines := useCase.ProcessFileAsync(bytes.NewReader([]byte(fileContent)), "debts.csv",
+		domain.FileOptions{ClientID: "acme", Format: format, JobID: job.ID})
+
+	assert.Equal(t, 4, totalLines)
+	jobs.AssertCalled(t, "Save", mock.MatchedBy(func(saved domain.IngestionJob) bool {
+		return saved.ID == job.ID && saved.Total == 4 && saved.Accepted == 1 && saved.Duplicates == 1 && saved.Invalid == 2 &&
+			*saved.Format == format && !saved.FinishedAt.IsZero()
+	}))
 }
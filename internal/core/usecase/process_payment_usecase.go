@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var (
+	ErrPaymentInvoiceNotFound = errors.New("boleto não encontrado para o pagamento informado")
+	ErrDuplicatePaymentEvent  = errors.New("evento de pagamento já processado")
+)
+
+type ProcessPaymentUseCase struct {
+	invoices service.InvoiceRepository
+	events   service.PaymentEventRepository
+	producer KafkaProducer
+	policies ChargePolicyProvider
+	calendar BusinessCalendar
+	webhooks WebhookEventPublisher
+	now      func() time.Time
+}
+
+func NewProcessPaymentUseCase(
+	invoices service.InvoiceRepository,
+	events service.PaymentEventRepository,
+	producer KafkaProducer,
+	policies ChargePolicyProvider,
+	calendar BusinessCalendar,
+	webhooks WebhookEventPublisher,
+) *ProcessPaymentUseCase {
+	return &ProcessPaymentUseCase{
+		invoices: invoices,
+		events:   events,
+		producer: producer,
+		policies: policies,
+		calendar: calendar,
+		webhooks: webhooks,
+		now:      time.Now,
+	}
+}
+
+func (u *ProcessPaymentUseCase) Process(notification domain.PaymentNotification) (domain.PaymentReceivedEvent, error) {
+	eventKey := notification.Provider + ":" + notification.EventID
+	if !u.events.TryRegister(eventKey) {
+		return domain.PaymentReceivedEvent{}, ErrDuplicatePaymentEvent
+	}
+
+	event, err := u.settle(notification, eventKey)
+	if err != nil {
+		u.events.Forget(eventKey)
+
+		return domain.PaymentReceivedEvent{}, err
+	}
+
+	return event, nil
+}
+
+// settle registra o pagamento no boleto e emite o evento de pagamento. O evento é enviado
+// antes de o boleto ser gravado: se o envio falhar, nada é gravado, e se a gravação falhar,
+// o aviso reenviado pelo PSP emite o evento de novo, com o mesmo EventID. Um aviso já
+// registrado no boleto, cujo evento pode não ter saído, apenas emite o evento de novo.
+func (u *ProcessPaymentUseCase) settle(notification domain.PaymentNotification, eventKey string) (domain.PaymentReceivedEvent, error) {
+	invoice, found := u.findInvoice(notification)
+	if !found {
+		return domain.PaymentReceivedEvent{}, ErrPaymentInvoiceNotFound
+	}
+
+	replay := invoice.HasPaymentEvent(eventKey)
+	if !replay {
+		due := amountDue(u.policies, u.calendar, invoice, notification.PaidDate, u.now())
+		if err := invoice.MarkPaid(notification.Amount, notification.PaidDate, 0, due, u.now()); err != nil {
+			return domain.PaymentReceivedEvent{}, err
+		}
+		invoice.PaymentEvents = append(invoice.PaymentEvents, eventKey)
+	}
+
+	event := domain.PaymentReceivedEvent{
+		EventID:     notification.EventID,
+		Provider:    notification.Provider,
+		DebtID:      invoice.Debt.DebtID,
+		NossoNumero: invoice.NossoNumero,
+		Method:      notification.Method,
+		Amount:      notification.Amount,
+		PaidDate:    notification.PaidDate,
+		Settled:     invoice.Status == domain.InvoiceStatusPaid,
+		ReceivedAt:  u.now(),
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return domain.PaymentReceivedEvent{}, fmt.Errorf("erro ao serializar evento de pagamento: %w", err)
+	}
+
+	if err := u.producer.Produce(event.DebtID, message); err != nil {
+		return domain.PaymentReceivedEvent{}, fmt.Errorf("erro ao enviar evento de pagamento ao Kafka: %w", err)
+	}
+
+	if !replay {
+		if err := u.invoices.Save(invoice); err != nil {
+			return domain.PaymentReceivedEvent{}, fmt.Errorf("erro ao salvar boleto: %w", err)
+		}
+	}
+
+	// O webhook é identificado pelo boleto, então a nova emissão não gera um segundo aviso.
+	if event.Settled {
+		publishPaid(u.webhooks, invoice, notification.Method, u.now())
+	}
+
+	if replay {
+		log.Printf("Pagamento %s do provedor %s já registrado para o débito %s; evento emitido de novo", notification.EventID, notification.Provider, event.DebtID)
+	} else {
+		log.Printf("Pagamento %s do provedor %s registrado para o débito %s", notification.EventID, notification.Provider, event.DebtID)
+	}
+
+	return event, nil
+}
+
+func (u *ProcessPaymentUseCase) findInvoice(notification domain.PaymentNotification) (domain.Invoice, bool) {
+	if notification.TxID != "" {
+		if invoice, found := u.invoices.FindByPixTxID(notification.TxID); found {
+			return invoice, true
+		}
+	}
+
+	if notification.NossoNumero != "" {
+		return u.invoices.FindByNossoNumero(notification.NossoNumero)
+	}
+
+	return domain.Invoice{}, false
+}
+
+// publishPaid publica o pagamento do boleto. O evento é identificado pelo boleto, de modo
+// que o aviso do PSP e a liquidação no arquivo de retorno não geram dois eventos.
+func publishPaid(webhooks WebhookEventPublisher, invoice domain.Invoice, method domain.PaymentMethod, at time.Time) {
+	data := debtWebhookData(invoice)
+	data.Method = method
+	data.PaidAmount = invoice.PaidAmount
+	data.PaidDate = invoice.PaidDate
+
+	publishWebhook(webhooks, "debt.paid:"+invoice.Debt.DebtID+":"+invoice.NossoNumero, domain.WebhookEventDebtPaid,
+		invoice.Debt.ClientID, data, at)
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockPaymentEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentEventRepository) TryRegister(eventKey string) bool {
+	args := m.Called(eventKey)
+
+	return args.Bool(0)
+}
+
+func (m *MockPaymentEventRepository) Forget(eventKey string) {
+	m.Called(eventKey)
+}
+
+func TestProcessPayment_PixSettlement(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	invoice := domain.Invoice{Debt: domain.Debt{DebtID: "d1"}, NossoNumero: "123", PixTxID: "tx1", Amount: 100, Status: domain.InvoiceStatusRegistered}
+
+	events.On("TryRegister", "psp:evt-1").Return(true)
+	invoices.On("FindByPixTxID", "tx1").Return(invoice, true)
+	invoices.On("Save", mock.Anything).Return(nil)
+	producer.On("Produce", "d1", mock.Anything).Return(nil)
+
+	event, err := useCase.Process(domain.PaymentNotification{
+		Provider: "psp", EventID: "evt-1", Method: domain.PaymentMethodPix, TxID: "tx1", Amount: 100, PaidDate: "2025-01-10",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "d1", event.DebtID)
+	invoices.AssertCalled(t, "Save", mock.MatchedBy(func(saved domain.Invoice) bool {
+		return saved.Status == domain.InvoiceStatusPaid && saved.PaidAmount == 100 && saved.PaidDate == "2025-01-10"
+	}))
+	producer.AssertCalled(t, "Produce", "d1", mock.MatchedBy(func(value []byte) bool {
+		var produced domain.PaymentReceivedEvent
+		return json.Unmarshal(value, &produced) == nil && produced.EventID == "evt-1" && produced.NossoNumero == "123" && produced.Settled
+	}))
+}
+
+func TestProcessPayment_BoletoByNossoNumero(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	events.On("TryRegister", "psp:evt-2").Return(true)
+	invoices.On("FindByNossoNumero", "123").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}, Amount: 100}, true)
+	invoices.On("Save", mock.Anything).Return(nil)
+	producer.On("Produce", "d1", mock.Anything).Return(nil)
+
+	_, err := useCase.Process(domain.PaymentNotification{
+		Provider: "psp", EventID: "evt-2", Method: domain.PaymentMethodBoleto, NossoNumero: "123", Amount: 100,
+	})
+
+	assert.NoError(t, err)
+	invoices.AssertNotCalled(t, "FindByPixTxID", mock.Anything)
+}
+
+func TestProcessPayment_Duplicate(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	events.On("TryRegister", "psp:evt-1").Return(false)
+
+	_, err := useCase.Process(domain.PaymentNotification{Provider: "psp", EventID: "evt-1", TxID: "tx1"})
+
+	assert.ErrorIs(t, err, ErrDuplicatePaymentEvent)
+	invoices.AssertNotCalled(t, "Save", mock.Anything)
+	producer.AssertNotCalled(t, "Produce", mock.Anything, mock.Anything)
+}
+
+func TestProcessPayment_FailureReleasesEvent(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	events.On("TryRegister", "psp:evt-3").Return(true)
+	events.On("Forget", "psp:evt-3").Return()
+	invoices.On("FindByPixTxID", "tx1").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}}, true)
+	invoices.On("Save", mock.Anything).Return(nil)
+	producer.On("Produce", "d1", mock.Anything).Return(errors.New("kafka indisponível"))
+
+	_, err := useCase.Process(domain.PaymentNotification{Provider: "psp", EventID: "evt-3", TxID: "tx1"})
+
+	assert.Error(t, err)
+	events.AssertCalled(t, "Forget", "psp:evt-3")
+	invoices.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestProcessPayment_SaveFailureReleasesEvent(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	events.On("TryRegister", "psp:evt-6").Return(true)
+	events.On("Forget", "psp:evt-6").Return()
+	invoices.On("FindByPixTxID", "tx1").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}, Amount: 100}, true)
+	invoices.On("Save", mock.Anything).Return(errors.New("disco cheio"))
+	producer.On("Produce", "d1", mock.Anything).Return(nil)
+
+	_, err := useCase.Process(domain.PaymentNotification{Provider: "psp", EventID: "evt-6", TxID: "tx1", Amount: 100})
+
+	assert.Error(t, err)
+	events.AssertCalled(t, "Forget", "psp:evt-6")
+}
+
+func TestProcessPayment_RecordedPaymentIsReemitted(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	webhooks := newMockWebhooks()
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), webhooks)
+
+	paid := domain.Invoice{
+		Debt: domain.Debt{DebtID: "d1", ClientID: "acme"}, NossoNumero: "123", PixTxID: "tx1", Amount: 100,
+		Status: domain.InvoiceStatusPaid, PaidAmount: 100, PaymentEvents: []string{"psp:evt-7"},
+	}
+	events.On("TryRegister", "psp:evt-7").Return(true)
+	invoices.On("FindByPixTxID", "tx1").Return(paid, true)
+	producer.On("Produce", "d1", mock.Anything).Return(nil)
+
+	event, err := useCase.Process(domain.PaymentNotification{Provider: "psp", EventID: "evt-7", TxID: "tx1", Amount: 100})
+
+	assert.NoError(t, err)
+	assert.True(t, event.Settled)
+	producer.AssertCalled(t, "Produce", "d1", mock.Anything)
+	invoices.AssertNotCalled(t, "Save", mock.Anything)
+	webhooks.AssertCalled(t, "Publish", mock.Anything)
+
+	t.Run("Outro aviso para o boleto pago", func(t *testing.T) {
+		events.On("TryRegister", "psp:evt-8").Return(true)
+		events.On("Forget", "psp:evt-8").Return()
+
+		_, err := useCase.Process(domain.PaymentNotification{Provider: "psp", EventID: "evt-8", TxID: "tx1", Amount: 100})
+
+		assert.ErrorIs(t, err, domain.ErrInvoiceAlreadyPaid)
+	})
+}
+
+func TestProcessPayment_InvoiceNotFound(t *testing.T) {
+	invoices := new(MockInvoiceRepository)
+	events := new(MockPaymentEventRepository)
+	producer := new(MockKafkaProducer)
+	policies := new(MockChargePolicyProvider)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+	useCase := NewProcessPaymentUseCase(invoices, events, producer, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	events.On("TryRegister", "psp:evt-4").Return(true)
+	events.On("Forget", "psp:evt-4").Return()
+	invoices.On("FindByPixTxID", "unknown").Return(domain.Invoice{}, false)
+
+	_, err := useCase.Process(domain.PaymentNotification{Provider: "psp", EventID: "evt-4", TxID: "unknown"})
+
+	assert.ErrorIs(t, err, ErrPaymentInvoiceNotFound)
+}
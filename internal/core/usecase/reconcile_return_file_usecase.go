@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+type ReturnFileParser interface {
+	Parse(file io.Reader) (string, []domain.ReturnOccurrence, error)
+}
+
+type ReconcileReturnFileUseCase struct {
+	parser   ReturnFileParser
+	invoices service.InvoiceRepository
+	policies ChargePolicyProvider
+	calendar BusinessCalendar
+	webhooks WebhookEventPublisher
+	now      func() time.Time
+}
+
+func NewReconcileReturnFileUseCase(
+	parser ReturnFileParser,
+	invoices service.InvoiceRepository,
+	policies ChargePolicyProvider,
+	calendar BusinessCalendar,
+	webhooks WebhookEventPublisher,
+) *ReconcileReturnFileUseCase {
+	return &ReconcileReturnFileUseCase{
+		parser:   parser,
+		invoices: invoices,
+		policies: policies,
+		calendar: calendar,
+		webhooks: webhooks,
+		now:      time.Now,
+	}
+}
+
+func (u *ReconcileReturnFileUseCase) Reconcile(file io.Reader, fileName string) (domain.ReconciliationReport, error) {
+	layout, occurrences, err := u.parser.Parse(file)
+	if err != nil {
+		return domain.ReconciliationReport{}, fmt.Errorf("erro ao interpretar arquivo de retorno %s: %w", fileName, err)
+	}
+
+	report := domain.ReconciliationReport{
+		FileName:         fileName,
+		Layout:           layout,
+		TotalOccurrences: len(occurrences),
+		Unmatched:        []domain.ReconciliationEntry{},
+		AmountMismatches: []domain.ReconciliationEntry{},
+		Errors:           []domain.ReconciliationEntry{},
+	}
+
+	for _, occurrence := range occurrences {
+		u.apply(&report, occurrence)
+	}
+
+	log.Printf("Arquivo de retorno %s conciliado: %d ocorrências, %d liquidadas, %d não encontradas",
+		fileName, report.TotalOccurrences, report.Paid, len(report.Unmatched))
+
+	return report, nil
+}
+
+func (u *ReconcileReturnFileUseCase) apply(report *domain.ReconciliationReport, occurrence domain.ReturnOccurrence) {
+	entry := domain.ReconciliationEntry{
+		Line:        occurrence.Line,
+		NossoNumero: occurrence.NossoNumero,
+		Code:        occurrence.Code,
+		PaidAmount:  occurrence.PaidAmount,
+	}
+
+	invoice, found := u.invoices.FindByNossoNumero(occurrence.NossoNumero)
+	if !found {
+		entry.Reason = "nosso número não encontrado"
+		report.Unmatched = append(report.Unmatched, entry)
+
+		return
+	}
+
+	entry.DebtID = invoice.Debt.DebtID
+	entry.ExpectedAmount = invoice.Amount
+
+	var due float64
+	var err error
+	switch occurrence.Kind {
+	case domain.OccurrenceRegistered:
+		err = invoice.Register(u.now())
+	case domain.OccurrencePaid:
+		due = amountDue(u.policies, u.calendar, invoice, occurrence.PaidDate, u.now())
+		entry.ExpectedAmount = due
+		err = invoice.MarkPaid(occurrence.PaidAmount, occurrence.PaidDate, occurrence.Fees, due, u.now())
+	case domain.OccurrenceRejected:
+		err = invoice.Reject(occurrence.Reason, u.now())
+	default:
+		log.Printf("Ocorrência %s ignorada para o nosso número %s", occurrence.Code, occurrence.NossoNumero)
+
+		return
+	}
+
+	if err != nil {
+		entry.Reason = err.Error()
+		report.Errors = append(report.Errors, entry)
+
+		return
+	}
+
+	if err := u.invoices.Save(invoice); err != nil {
+		entry.Reason = fmt.Sprintf("erro ao salvar boleto: %v", err)
+		report.Errors = append(report.Errors, entry)
+
+		return
+	}
+
+	switch occurrence.Kind {
+	case domain.OccurrenceRegistered:
+		report.Registered++
+	case domain.OccurrenceRejected:
+		report.Rejected++
+
+		data := debtWebhookData(invoice)
+		data.Stage = "registration"
+		data.Reason = invoice.RejectionReason
+		publishWebhook(u.webhooks, "debt.failed:"+invoice.Debt.DebtID+":"+invoice.NossoNumero+":registration",
+			domain.WebhookEventDebtFailed, invoice.Debt.ClientID, data, u.now())
+	case domain.OccurrencePaid:
+		report.Paid++
+
+		if invoice.Status == domain.InvoiceStatusPaid {
+			publishPaid(u.webhooks, invoice, domain.PaymentMethodBoleto, u.now())
+		}
+
+		switch {
+		case invoice.Status == domain.InvoiceStatusPartial:
+			entry.Reason = "valor pago inferior ao valor devido com encargos"
+			report.AmountMismatches = append(report.AmountMismatches, entry)
+		case invoice.PaidAmount-due >= 0.01:
+			entry.Reason = "valor pago superior ao valor devido com encargos"
+			report.AmountMismatches = append(report.AmountMismatches, entry)
+		}
+	}
+}
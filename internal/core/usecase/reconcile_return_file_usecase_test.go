@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type (
+	MockReturnFileParser struct {
+		mock.Mock
+	}
+
+	MockInvoiceRepository struct {
+		mock.Mock
+	}
+
+	MockChargePolicyProvider struct {
+		mock.Mock
+	}
+)
+
+func (m *MockChargePolicyProvider) ChargePolicy(clientID string) domain.ChargePolicy {
+	args := m.Called(clientID)
+
+	return args.Get(0).(domain.ChargePolicy)
+}
+
+func (m *MockReturnFileParser) Parse(file io.Reader) (string, []domain.ReturnOccurrence, error) {
+	args := m.Called(file)
+
+	return args.String(0), args.Get(1).([]domain.ReturnOccurrence), args.Error(2)
+}
+
+func (m *MockInvoiceRepository) Save(invoice domain.Invoice) error {
+	args := m.Called(invoice)
+
+	return args.Error(0)
+}
+
+func (m *MockInvoiceRepository) Delete(debtID string) {
+	m.Called(debtID)
+}
+
+func (m *MockInvoiceRepository) FindByNossoNumero(nossoNumero string) (domain.Invoice, bool) {
+	args := m.Called(nossoNumero)
+
+	return args.Get(0).(domain.Invoice), args.Bool(1)
+}
+
+func (m *MockInvoiceRepository) FindByDebtID(debtID string) (domain.Invoice, bool) {
+	args := m.Called(debtID)
+
+	return args.Get(0).(domain.Invoice), args.Bool(1)
+}
+
+func (m *MockInvoiceRepository) FindOpen() []domain.Invoice {
+	args := m.Called()
+
+	return args.Get(0).([]domain.Invoice)
+}
+
+func (m *MockInvoiceRepository) FindByPixTxID(txID string) (domain.Invoice, bool) {
+	args := m.Called(txID)
+
+	return args.Get(0).(domain.Invoice), args.Bool(1)
+}
+
+func TestReconcile_TransitionsInvoices(t *testing.T) {
+	parser := new(MockReturnFileParser)
+	invoices := new(MockInvoiceRepository)
+	policies := new(MockChargePolicyProvider)
+	useCase := NewReconcileReturnFileUseCase(parser, invoices, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	occurrences := []domain.ReturnOccurrence{
+		{Line: 1, NossoNumero: "1", Code: "02", Kind: domain.OccurrenceRegistered},
+		{Line: 2, NossoNumero: "2", Code: "06", Kind: domain.OccurrencePaid, PaidAmount: 100, PaidDate: "2025-01-15", Fees: 2.5},
+		{Line: 3, NossoNumero: "3", Code: "03", Kind: domain.OccurrenceRejected, Reason: "08"},
+		{Line: 4, NossoNumero: "4", Code: "06", Kind: domain.OccurrencePaid, PaidAmount: 50},
+		{Line: 5, NossoNumero: "404", Code: "06", Kind: domain.OccurrencePaid, PaidAmount: 10},
+	}
+	parser.On("Parse", mock.Anything).Return("CNAB240", occurrences, nil)
+
+	invoices.On("FindByNossoNumero", "1").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}, Amount: 10, Status: domain.InvoiceStatusIssued}, true)
+	invoices.On("FindByNossoNumero", "2").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d2"}, Amount: 100, Status: domain.InvoiceStatusRegistered}, true)
+	invoices.On("FindByNossoNumero", "3").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d3"}, Amount: 30, Status: domain.InvoiceStatusIssued}, true)
+	invoices.On("FindByNossoNumero", "4").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d4"}, Amount: 80, Status: domain.InvoiceStatusRegistered}, true)
+	invoices.On("FindByNossoNumero", "404").Return(domain.Invoice{}, false)
+	invoices.On("Save", mock.Anything).Return(nil)
+
+	report, err := useCase.Reconcile(strings.NewReader(""), "retorno.ret")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CNAB240", report.Layout)
+	assert.Equal(t, 5, report.TotalOccurrences)
+	assert.Equal(t, 1, report.Registered)
+	assert.Equal(t, 2, report.Paid)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Len(t, report.Unmatched, 1)
+	assert.Equal(t, "404", report.Unmatched[0].NossoNumero)
+	assert.Len(t, report.AmountMismatches, 1)
+	assert.Equal(t, "d4", report.AmountMismatches[0].DebtID)
+
+	invoices.AssertCalled(t, "Save", mock.MatchedBy(func(invoice domain.Invoice) bool {
+		return invoice.Debt.DebtID == "d2" && invoice.Status == domain.InvoiceStatusPaid &&
+			invoice.PaidAmount == 100 && invoice.PaidDate == "2025-01-15" && invoice.Fees == 2.5
+	}))
+	invoices.AssertCalled(t, "Save", mock.MatchedBy(func(invoice domain.Invoice) bool {
+		return invoice.Debt.DebtID == "d3" && invoice.Status == domain.InvoiceStatusRejected && invoice.RejectionReason == "08"
+	}))
+}
+
+func TestReconcile_LatePaymentWithoutCharges(t *testing.T) {
+	parser := new(MockReturnFileParser)
+	invoices := new(MockInvoiceRepository)
+	policies := new(MockChargePolicyProvider)
+	useCase := NewReconcileReturnFileUseCase(parser, invoices, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	invoice := domain.Invoice{
+		Debt:    domain.Debt{DebtID: "d1", ClientID: "acme", DebtAmount: 100, DebtDueDate: "2025-01-10"},
+		Amount:  100,
+		DueDate: "2025-01-10",
+		Status:  domain.InvoiceStatusRegistered,
+	}
+
+	parser.On("Parse", mock.Anything).Return("CNAB240", []domain.ReturnOccurrence{
+		{Line: 1, NossoNumero: "1", Code: "06", Kind: domain.OccurrencePaid, PaidAmount: 100, PaidDate: "2025-01-20"},
+		{Line: 2, NossoNumero: "2", Code: "06", Kind: domain.OccurrencePaid, PaidAmount: 102.33, PaidDate: "2025-01-20"},
+	}, nil)
+	invoices.On("FindByNossoNumero", "1").Return(invoice, true)
+	invoices.On("FindByNossoNumero", "2").Return(invoice, true)
+	invoices.On("Save", mock.Anything).Return(nil)
+	policies.On("ChargePolicy", "acme").Return(domain.ChargePolicy{FinePercent: 2, MonthlyInterestPercent: 1})
+
+	report, err := useCase.Reconcile(strings.NewReader(""), "retorno.ret")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Paid)
+	assert.Len(t, report.AmountMismatches, 1)
+	assert.Equal(t, 102.33, report.AmountMismatches[0].ExpectedAmount)
+	invoices.AssertCalled(t, "Save", mock.MatchedBy(func(saved domain.Invoice) bool {
+		return saved.Status == domain.InvoiceStatusPartial && saved.PaidAmount == 100
+	}))
+	invoices.AssertCalled(t, "Save", mock.MatchedBy(func(saved domain.Invoice) bool {
+		return saved.Status == domain.InvoiceStatusPaid && saved.PaidAmount == 102.33
+	}))
+}
+
+func TestReconcile_AlreadyPaid(t *testing.T) {
+	parser := new(MockReturnFileParser)
+	invoices := new(MockInvoiceRepository)
+	policies := new(MockChargePolicyProvider)
+	useCase := NewReconcileReturnFileUseCase(parser, invoices, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	parser.On("Parse", mock.Anything).Return("CNAB400", []domain.ReturnOccurrence{
+		{Line: 2, NossoNumero: "1", Code: "06", Kind: domain.OccurrencePaid, PaidAmount: 10},
+	}, nil)
+	invoices.On("FindByNossoNumero", "1").Return(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}, Amount: 10, Status: domain.InvoiceStatusPaid}, true)
+	policies.On("ChargePolicy", mock.Anything).Return(domain.ChargePolicy{})
+
+	report, err := useCase.Reconcile(strings.NewReader(""), "retorno.ret")
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, domain.ErrInvoiceAlreadyPaid.Error(), report.Errors[0].Reason)
+	invoices.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestReconcile_ParseError(t *testing.T) {
+	parser := new(MockReturnFileParser)
+	invoices := new(MockInvoiceRepository)
+	policies := new(MockChargePolicyProvider)
+	useCase := NewReconcileReturnFileUseCase(parser, invoices, policies, domain.NewBusinessCalendar(), newMockWebhooks())
+
+	parser.On("Parse", mock.Anything).Return("", []domain.ReturnOccurrence(nil), errors.New("layout desconhecido"))
+
+	_, err := useCase.Reconcile(strings.NewReader(""), "retorno.ret")
+
+	assert.Error(t, err)
+	invoices.AssertNotCalled(t, "FindByNossoNumero", mock.Anything)
+}
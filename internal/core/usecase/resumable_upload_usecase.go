@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+// MaxUploadParts limita o número das partes de um upload retomável.
+const MaxUploadParts = 10000
+
+var (
+	ErrUploadAbsent           = errors.New("upload não encontrado")
+	ErrInvalidUpload          = errors.New("upload inválido")
+	ErrInvalidUploadPart      = errors.New("número da parte do upload inválido")
+	ErrUploadCompleted        = errors.New("upload já concluído")
+	ErrUploadCompleting       = errors.New("upload em conclusão")
+	ErrUploadTooLarge         = errors.New("upload acima do tamanho máximo")
+	ErrMissingUploadParts     = errors.New("partes do upload ausentes")
+	ErrUploadChecksumMismatch = errors.New("checksum do upload não confere")
+)
+
+var (
+	uploadIDPattern = regexp.MustCompile(`^upl_[0-9a-f]{16}$`)
+	sha256Pattern   = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// ResumableUploadUseCase recebe arquivos grandes em partes, que podem ser reenviadas
+// depois de uma falha de conexão, e monta o arquivo na conclusão, conferindo o checksum
+// informado no início do upload. Uploads sem alterações por expiration são removidos,
+// inclusive os concluídos.
+type ResumableUploadUseCase struct {
+	store      service.UploadStore
+	maxSize    int64
+	expiration time.Duration
+	now        func() time.Time
+	// mu serializa as alterações dos dados dos uploads; as partes são gravadas e montadas
+	// fora dele, para que possam ser enviadas em paralelo.
+	mu sync.Mutex
+}
+
+func NewResumableUploadUseCase(store service.UploadStore, maxSize int64, expiration time.Duration) *ResumableUploadUseCase {
+	return &ResumableUploadUseCase{store: store, maxSize: maxSize, expiration: expiration, now: time.Now}
+}
+
+// Initiate registra um upload do arquivo fileName cujo conteúdo tem o SHA-256 checksum.
+// As opções são os campos do formulário de /process-files, já validados por quem chama.
+func (u *ResumableUploadUseCase) Initiate(fileName, checksum string, options map[string]string) (domain.Upload, error) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if fileName == "" || !sha256Pattern.MatchString(checksum) {
+		return domain.Upload{}, fmt.Errorf("%w: nome do arquivo e SHA-256 em hexadecimal são obrigatórios", ErrInvalidUpload)
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return domain.Upload{}, fmt.Errorf("erro ao gerar identificador do upload: %w", err)
+	}
+
+	upload := domain.Upload{
+		ID:        "upl_" + id,
+		FileName:  fileName,
+		Checksum:  checksum,
+		Options:   options,
+		Status:    domain.UploadInProgress,
+		Parts:     []domain.UploadPart{},
+		CreatedAt: u.now(),
+	}
+	upload.UpdatedAt = upload.CreatedAt
+
+	if err := u.store.Save(upload); err != nil {
+		return domain.Upload{}, fmt.Errorf("erro ao registrar upload %s: %w", upload.ID, err)
+	}
+
+	return upload, nil
+}
+
+// Find devolve o upload com as partes já recebidas, para que o cliente retome o envio
+// pelas que faltam.
+func (u *ResumableUploadUseCase) Find(id string) (domain.Upload, error) {
+	// O identificador vira um caminho no disco, então só identificadores gerados aqui são
+	// aceitos.
+	if !uploadIDPattern.MatchString(id) {
+		return domain.Upload{}, ErrUploadAbsent
+	}
+
+	upload, exists := u.store.FindByID(id)
+	if !exists {
+		return domain.Upload{}, ErrUploadAbsent
+	}
+
+	return upload, nil
+}
+
+// UploadPart grava a parte number do upload, substituindo uma parte anterior de mesmo
+// número. A parte é lida só até o tamanho que ainda cabe no upload; uma parte recusada é
+// descartada e mantém a anterior.
+func (u *ResumableUploadUseCase) UploadPart(id string, number int, content io.Reader) (domain.UploadPart, error) {
+	if number < 1 || number > MaxUploadParts {
+		return domain.UploadPart{}, fmt.Errorf("%w: %d, esperado de 1 a %d", ErrInvalidUploadPart, number, MaxUploadParts)
+	}
+
+	upload, err := u.Find(id)
+	if err != nil {
+		return domain.UploadPart{}, err
+	}
+
+	if err := acceptsParts(upload); err != nil {
+		return domain.UploadPart{}, err
+	}
+
+	// Um byte além do que cabe basta para saber que a parte passa do limite.
+	remaining := u.remaining(upload, number)
+	part, staged, err := u.store.WritePart(id, number, io.LimitReader(content, remaining+1))
+	if err != nil {
+		return domain.UploadPart{}, fmt.Errorf("erro ao gravar parte %d do upload %s: %w", number, id, err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// Relê o upload, que pode ter recebido outras partes ou começado a ser concluído durante
+	// a gravação.
+	upload, err = u.Find(id)
+	if err == nil {
+		err = acceptsParts(upload)
+	}
+
+	if err == nil && part.Size > u.remaining(upload, number) {
+		err = fmt.Errorf("%w: parte %d acima do limite de %d bytes do upload", ErrUploadTooLarge, number, u.maxSize)
+	}
+
+	if err != nil {
+		u.store.DiscardPart(staged)
+
+		return domain.UploadPart{}, err
+	}
+
+	if err := u.store.CommitPart(id, part, staged); err != nil {
+		u.store.DiscardPart(staged)
+
+		return domain.UploadPart{}, fmt.Errorf("erro ao gravar parte %d do upload %s: %w", number, id, err)
+	}
+
+	upload.AddPart(part)
+	upload.UpdatedAt = u.now()
+	if err := u.store.Save(upload); err != nil {
+		return domain.UploadPart{}, fmt.Errorf("erro ao registrar parte %d do upload %s: %w", number, id, err)
+	}
+
+	return part, nil
+}
+
+// remaining devolve quantos bytes a parte number pode ter sem que o upload passe do
+// tamanho máximo, descontando a parte de mesmo número que ela substituiria.
+func (u *ResumableUploadUseCase) remaining(upload domain.Upload, number int) int64 {
+	return max(u.maxSize-upload.Size+upload.PartSize(number), 0)
+}
+
+func acceptsParts(upload domain.Upload) error {
+	switch upload.Status {
+	case domain.UploadCompleted:
+		return ErrUploadCompleted
+	case domain.UploadCompleting:
+		return ErrUploadCompleting
+	}
+
+	return nil
+}
+
+// Complete monta o arquivo a partir das partes e confere seu checksum. Devolve o caminho
+// do arquivo montado, que passa a pertencer a quem chamou. O upload continua em conclusão,
+// recusando novas partes, até que quem chamou entregue o arquivo ao processamento e chame
+// Finish, ou Reopen se a entrega falhar. Se o checksum não confere, as partes são mantidas
+// para que as corrompidas sejam reenviadas.
+func (u *ResumableUploadUseCase) Complete(id string) (domain.Upload, string, error) {
+	upload, err := u.startCompletion(id)
+	if err != nil {
+		return upload, "", err
+	}
+
+	path, checksum, err := u.store.Assemble(upload)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("erro ao montar upload %s: %w", id, err)
+	case checksum != upload.Checksum:
+		u.store.DiscardAssembled(path)
+		err = fmt.Errorf("%w: esperado %s, recebido %s", ErrUploadChecksumMismatch, upload.Checksum, checksum)
+	}
+
+	if err != nil {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+
+		return u.reopen(upload), "", err
+	}
+
+	return upload, path, nil
+}
+
+// Reopen devolve ao envio de partes o upload cujo arquivo montado não pôde ser entregue ao
+// processamento, mantendo as partes, para que a conclusão seja tentada de novo.
+func (u *ResumableUploadUseCase) Reopen(id string) (domain.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	upload, err := u.Find(id)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	if upload.Status != domain.UploadCompleting {
+		return upload, nil
+	}
+
+	return u.reopen(upload), nil
+}
+
+// reopen marca o upload como em andamento; quem chama deve ter o lock.
+func (u *ResumableUploadUseCase) reopen(upload domain.Upload) domain.Upload {
+	upload.Status = domain.UploadInProgress
+	upload.UpdatedAt = u.now()
+	if err := u.store.Save(upload); err != nil {
+		log.Printf("Erro ao reabrir upload %s: %v", upload.ID, err)
+	}
+
+	return upload
+}
+
+// startCompletion confere as partes e marca o upload como em conclusão.
+func (u *ResumableUploadUseCase) startCompletion(id string) (domain.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	upload, err := u.Find(id)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	if err := acceptsParts(upload); err != nil {
+		return upload, err
+	}
+
+	if len(upload.Parts) == 0 {
+		return upload, fmt.Errorf("%w: nenhuma parte recebida", ErrMissingUploadParts)
+	}
+
+	if missing := upload.MissingParts(); len(missing) > 0 {
+		return upload, fmt.Errorf("%w: %v", ErrMissingUploadParts, missing)
+	}
+
+	upload.Status = domain.UploadCompleting
+	upload.UpdatedAt = u.now()
+	if err := u.store.Save(upload); err != nil {
+		return upload, fmt.Errorf("erro ao concluir upload %s: %w", id, err)
+	}
+
+	return upload, nil
+}
+
+// Finish conclui o upload cujo arquivo montado foi entregue ao processamento, registrando
+// os envios criados, e remove as partes.
+func (u *ResumableUploadUseCase) Finish(id string, jobIDs []string) (domain.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	upload, err := u.Find(id)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	upload.Status = domain.UploadCompleted
+	upload.JobIDs = jobIDs
+	upload.CompletedAt = u.now()
+	upload.UpdatedAt = upload.CompletedAt
+	if err := u.store.Save(upload); err != nil {
+		return upload, fmt.Errorf("erro ao concluir upload %s: %w", id, err)
+	}
+
+	if err := u.store.RemoveParts(id); err != nil {
+		log.Printf("Erro ao remover partes do upload %s: %v", id, err)
+	}
+
+	return upload, nil
+}
+
+// RemoveExpired remove os uploads sem alterações há mais de expiration, com as partes já
+// gravadas, e devolve quantos foram removidos: os que não recebem partes, os concluídos,
+// consultáveis até lá, e os que ficaram em conclusão por uma queda do serviço.
+func (u *ResumableUploadUseCase) RemoveExpired() (int, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	deadline := u.now().Add(-u.expiration)
+	removed := 0
+	for _, upload := range u.store.FindAll() {
+		if upload.UpdatedAt.After(deadline) {
+			continue
+		}
+
+		if err := u.store.Remove(upload.ID); err != nil {
+			return removed, fmt.Errorf("erro ao remover upload expirado %s: %w", upload.ID, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// Run remove os uploads expirados a cada interval até o contexto ser encerrado.
+func (u *ResumableUploadUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := u.RemoveExpired()
+		if err != nil {
+			log.Printf("Erro ao remover uploads expirados: %v", err)
+		}
+
+		if removed > 0 {
+			log.Printf("%d uploads expirados removidos", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockUploadStore struct {
+	mock.Mock
+}
+
+func (m *MockUploadStore) Save(upload domain.Upload) error {
+	args := m.Called(upload)
+
+	return args.Error(0)
+}
+
+func (m *MockUploadStore) FindByID(id string) (domain.Upload, bool) {
+	args := m.Called(id)
+
+	return args.Get(0).(domain.Upload), args.Bool(1)
+}
+
+func (m *MockUploadStore) FindAll() []domain.Upload {
+	args := m.Called()
+
+	return args.Get(0).([]domain.Upload)
+}
+
+func (m *MockUploadStore) WritePart(uploadID string, number int, content io.Reader) (domain.UploadPart, string, error) {
+	args := m.Called(uploadID, number, content)
+
+	return args.Get(0).(domain.UploadPart), args.String(1), args.Error(2)
+}
+
+func (m *MockUploadStore) CommitPart(uploadID string, part domain.UploadPart, staged string) error {
+	args := m.Called(uploadID, part, staged)
+
+	return args.Error(0)
+}
+
+func (m *MockUploadStore) DiscardPart(staged string) {
+	m.Called(staged)
+}
+
+func (m *MockUploadStore) Assemble(upload domain.Upload) (string, string, error) {
+	args := m.Called(upload)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockUploadStore) DiscardAssembled(path string) {
+	m.Called(path)
+}
+
+func (m *MockUploadStore) RemoveParts(uploadID string) error {
+	args := m.Called(uploadID)
+
+	return args.Error(0)
+}
+
+func (m *MockUploadStore) Remove(uploadID string) error {
+	args := m.Called(uploadID)
+
+	return args.Error(0)
+}
+
+const (
+	testUploadID       = "upl_0123456789abcdef"
+	testUploadChecksum = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+)
+
+func TestResumableUpload_Initiate(t *testing.T) {
+	store := new(MockUploadStore)
+	useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+	store.On("Save", mock.Anything).Return(nil)
+
+	upload, err := useCase.Initiate("remessa.csv", strings.ToUpper(testUploadChecksum), map[string]string{"clientId": "acme"})
+
+	assert.NoError(t, err)
+	assert.Regexp(t, `^upl_[0-9a-f]{16}$`, upload.ID)
+	assert.Equal(t, testUploadChecksum, upload.Checksum)
+	assert.Equal(t, domain.UploadInProgress, upload.Status)
+
+	_, err = useCase.Initiate("remessa.csv", "md5:abc", nil)
+	assert.ErrorIs(t, err, ErrInvalidUpload)
+}
+
+func TestResumableUpload_UploadPart(t *testing.T) {
+	store := new(MockUploadStore)
+	useCase := NewResumableUploadUseCase(store, 10, time.Hour)
+
+	upload := domain.Upload{ID: testUploadID, Status: domain.UploadInProgress, Size: 4, Parts: []domain.UploadPart{{Number: 1, Size: 4}}}
+	store.On("FindByID", testUploadID).Return(upload, true)
+
+	t.Run("Parte registrada", func(t *testing.T) {
+		part := domain.UploadPart{Number: 2, Size: 5}
+		store.On("WritePart", testUploadID, 2, mock.Anything).Return(part, "part-1.tmp", nil).Once()
+		store.On("CommitPart", testUploadID, part, "part-1.tmp").Return(nil).Once()
+		store.On("Save", mock.MatchedBy(func(saved domain.Upload) bool { return saved.Size == 9 && len(saved.Parts) == 2 })).Return(nil).Once()
+
+		stored, err := useCase.UploadPart(testUploadID, 2, strings.NewReader("dados"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, stored.Number)
+	})
+
+	t.Run("Parte acima do limite é lida só até ele e descartada", func(t *testing.T) {
+		var read int
+		store.On("WritePart", testUploadID, 2, mock.Anything).Run(func(args mock.Arguments) {
+			content, _ := io.ReadAll(args.Get(2).(io.Reader))
+			read = len(content)
+		}).Return(domain.UploadPart{Number: 2, Size: 7}, "part-2.tmp", nil).Once()
+		store.On("DiscardPart", "part-2.tmp").Return().Once()
+
+		_, err := useCase.UploadPart(testUploadID, 2, strings.NewReader("dados demais"))
+
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+		assert.Equal(t, 7, read, "a leitura deve parar um byte depois dos 6 que cabem no upload")
+		store.AssertNotCalled(t, "CommitPart", testUploadID, mock.Anything, "part-2.tmp")
+	})
+
+	t.Run("Parte que substitui outra desconta a anterior", func(t *testing.T) {
+		part := domain.UploadPart{Number: 1, Size: 10}
+		store.On("WritePart", testUploadID, 1, mock.Anything).Return(part, "part-3.tmp", nil).Once()
+		store.On("CommitPart", testUploadID, part, "part-3.tmp").Return(nil).Once()
+		store.On("Save", mock.MatchedBy(func(saved domain.Upload) bool { return saved.Size == 10 })).Return(nil).Once()
+
+		_, err := useCase.UploadPart(testUploadID, 1, strings.NewReader("0123456789"))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Número da parte inválido", func(t *testing.T) {
+		_, err := useCase.UploadPart(testUploadID, 0, strings.NewReader(""))
+
+		assert.ErrorIs(t, err, ErrInvalidUploadPart)
+	})
+
+	t.Run("Identificador fora do formato não chega ao disco", func(t *testing.T) {
+		_, err := useCase.UploadPart("../../etc", 1, strings.NewReader(""))
+
+		assert.ErrorIs(t, err, ErrUploadAbsent)
+		store.AssertNotCalled(t, "FindByID", "../../etc")
+	})
+
+	t.Run("Parte recebida durante a conclusão é descartada", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 10, time.Hour)
+		store.On("FindByID", testUploadID).Return(upload, true).Once()
+		store.On("WritePart", testUploadID, 2, mock.Anything).Return(domain.UploadPart{Number: 2, Size: 5}, "part-4.tmp", nil)
+		completing := upload
+		completing.Status = domain.UploadCompleting
+		store.On("FindByID", testUploadID).Return(completing, true).Once()
+		store.On("DiscardPart", "part-4.tmp").Return().Once()
+
+		_, err := useCase.UploadPart(testUploadID, 2, strings.NewReader("dados"))
+
+		assert.ErrorIs(t, err, ErrUploadCompleting)
+		store.AssertNotCalled(t, "CommitPart", mock.Anything, mock.Anything, mock.Anything)
+		store.AssertExpectations(t)
+	})
+}
+
+func TestResumableUpload_Complete(t *testing.T) {
+	parts := []domain.UploadPart{{Number: 1, Size: 5}, {Number: 2, Size: 6}}
+	withStatus := func(status domain.UploadStatus) any {
+		return mock.MatchedBy(func(saved domain.Upload) bool { return saved.Status == status })
+	}
+
+	t.Run("Arquivo montado e conferido aguarda a entrega ao processamento", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+		upload := domain.Upload{ID: testUploadID, Checksum: testUploadChecksum, Status: domain.UploadInProgress, Parts: parts}
+
+		store.On("FindByID", testUploadID).Return(upload, true)
+		store.On("Save", withStatus(domain.UploadCompleting)).Return(nil).Once()
+		store.On("Assemble", withStatus(domain.UploadCompleting)).Return("/tmp/upl_0123456789abcdef-1.csv", testUploadChecksum, nil)
+
+		completing, path, err := useCase.Complete(testUploadID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/tmp/upl_0123456789abcdef-1.csv", path)
+		assert.Equal(t, domain.UploadCompleting, completing.Status)
+		store.AssertNotCalled(t, "RemoveParts", mock.Anything)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("Checksum diferente mantém as partes e reabre o upload", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+		upload := domain.Upload{ID: testUploadID, Checksum: testUploadChecksum, Status: domain.UploadInProgress, Parts: parts}
+
+		store.On("FindByID", testUploadID).Return(upload, true)
+		store.On("Save", withStatus(domain.UploadCompleting)).Return(nil).Once()
+		store.On("Assemble", mock.Anything).Return("/tmp/nao-existe", strings.Repeat("0", 64), nil)
+		store.On("DiscardAssembled", "/tmp/nao-existe").Return().Once()
+		store.On("Save", withStatus(domain.UploadInProgress)).Return(nil).Once()
+
+		_, _, err := useCase.Complete(testUploadID)
+
+		assert.ErrorIs(t, err, ErrUploadChecksumMismatch)
+		store.AssertNotCalled(t, "RemoveParts", mock.Anything)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("Partes ausentes", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+		store.On("FindByID", testUploadID).Return(domain.Upload{ID: testUploadID, Parts: []domain.UploadPart{{Number: 1}, {Number: 4}}}, true)
+
+		_, _, err := useCase.Complete(testUploadID)
+
+		assert.ErrorIs(t, err, ErrMissingUploadParts)
+		assert.ErrorContains(t, err, "[2 3]")
+	})
+
+	t.Run("Upload já concluído", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+		store.On("FindByID", testUploadID).Return(domain.Upload{ID: testUploadID, Status: domain.UploadCompleted, JobIDs: []string{"job_1"}}, true)
+
+		upload, _, err := useCase.Complete(testUploadID)
+
+		assert.ErrorIs(t, err, ErrUploadCompleted)
+		assert.Equal(t, []string{"job_1"}, upload.JobIDs)
+	})
+
+	t.Run("Upload em conclusão", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+		store.On("FindByID", testUploadID).Return(domain.Upload{ID: testUploadID, Status: domain.UploadCompleting, Parts: parts}, true)
+
+		_, _, err := useCase.Complete(testUploadID)
+
+		assert.ErrorIs(t, err, ErrUploadCompleting)
+		store.AssertNotCalled(t, "Assemble", mock.Anything)
+	})
+}
+
+func TestResumableUpload_FinishAndReopen(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	completing := domain.Upload{ID: testUploadID, Status: domain.UploadCompleting, Parts: []domain.UploadPart{{Number: 1, Size: 5}}}
+
+	t.Run("Entrega ao processamento conclui o upload e remove as partes", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+		useCase.now = func() time.Time { return now }
+
+		store.On("FindByID", testUploadID).Return(completing, true)
+		store.On("Save", mock.MatchedBy(func(saved domain.Upload) bool {
+			return saved.Status == domain.UploadCompleted && saved.CompletedAt.Equal(now)
+		})).Return(nil).Once()
+		store.On("RemoveParts", testUploadID).Return(nil).Once()
+
+		finished, err := useCase.Finish(testUploadID, []string{"job_1"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.UploadCompleted, finished.Status)
+		assert.Equal(t, []string{"job_1"}, finished.JobIDs)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("Falha na entrega reabre o upload com as partes", func(t *testing.T) {
+		store := new(MockUploadStore)
+		useCase := NewResumableUploadUseCase(store, 1<<20, time.Hour)
+
+		store.On("FindByID", testUploadID).Return(completing, true)
+		store.On("Save", mock.MatchedBy(func(saved domain.Upload) bool { return saved.Status == domain.UploadInProgress })).Return(nil).Once()
+
+		reopened, err := useCase.Reopen(testUploadID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.UploadInProgress, reopened.Status)
+		assert.Equal(t, completing.Parts, reopened.Parts)
+		store.AssertNotCalled(t, "RemoveParts", mock.Anything)
+		store.AssertExpectations(t)
+	})
+}
+
+func TestResumableUpload_RemoveExpired(t *testing.T) {
+	store := new(MockUploadStore)
+	useCase := NewResumableUploadUseCase(store, 1<<20, 24*time.Hour)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	useCase.now = func() time.Time { return now }
+
+	store.On("FindAll").Return([]domain.Upload{
+		{ID: "upl_1", Status: domain.UploadInProgress, UpdatedAt: now.Add(-25 * time.Hour)},
+		{ID: "upl_2", Status: domain.UploadInProgress, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "upl_3", Status: domain.UploadCompleting, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "upl_4", Status: domain.UploadCompleted, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "upl_5", Status: domain.UploadCompleted, UpdatedAt: now.Add(-time.Hour)},
+	})
+	store.On("Remove", "upl_1").Return(nil).Once()
+	store.On("Remove", "upl_3").Return(nil).Once()
+	store.On("Remove", "upl_4").Return(nil).Once()
+
+	removed, err := useCase.RemoveExpired()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	store.AssertExpectations(t)
+}
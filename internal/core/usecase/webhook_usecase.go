@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/service"
+)
+
+var (
+	ErrInvalidWebhookURL          = errors.New("URL do webhook inválida")
+	ErrInvalidWebhookEvent        = errors.New("tipo de evento do webhook inválido")
+	ErrWebhookSubscriptionMissing = errors.New("inscrição de webhook não encontrada")
+	ErrWebhookDeliveryMissing     = errors.New("entrega de webhook não encontrada")
+)
+
+// WebhookEventPublisher registra os eventos de débito para envio aos sistemas dos
+// clientes. A publicação não interrompe o fluxo que originou o evento: falhas são
+// registradas em log.
+type WebhookEventPublisher interface {
+	Publish(event domain.WebhookEvent)
+}
+
+// WebhookPublisher cria uma entrega para cada inscrição do cliente que aceita o tipo do
+// evento. As entregas são enviadas pelo DispatchWebhooksUseCase.
+type WebhookPublisher struct {
+	subscriptions service.WebhookSubscriptionRepository
+	deliveries    service.WebhookDeliveryRepository
+	now           func() time.Time
+}
+
+func NewWebhookPublisher(subscriptions service.WebhookSubscriptionRepository, deliveries service.WebhookDeliveryRepository) *WebhookPublisher {
+	return &WebhookPublisher{subscriptions: subscriptions, deliveries: deliveries, now: time.Now}
+}
+
+func (p *WebhookPublisher) Publish(event domain.WebhookEvent) {
+	if event.ClientID == "" {
+		return
+	}
+
+	var deliveries []domain.WebhookDelivery
+	for _, subscription := range p.subscriptions.FindByClientID(event.ClientID) {
+		if subscription.Accepts(event.Type) {
+			deliveries = append(deliveries, domain.NewWebhookDelivery(subscription, event, p.now()))
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if _, err := p.deliveries.Enqueue(deliveries); err != nil {
+		log.Printf("Erro ao registrar entregas do evento %s: %v", event.ID, err)
+	}
+}
+
+// publishWebhook monta e publica um evento, registrando em log quando o conteúdo não
+// pode ser serializado.
+func publishWebhook(webhooks WebhookEventPublisher, id string, eventType domain.WebhookEventType, clientID string, data any, at time.Time) {
+	if clientID == "" {
+		return
+	}
+
+	event, err := domain.NewWebhookEvent(id, eventType, clientID, data, at)
+	if err != nil {
+		log.Printf("Erro ao montar evento de webhook %s: %v", id, err)
+
+		return
+	}
+
+	webhooks.Publish(event)
+}
+
+func debtWebhookData(invoice domain.Invoice) domain.WebhookDebtData {
+	return domain.WebhookDebtData{
+		DebtID:      invoice.Debt.DebtID,
+		NossoNumero: invoice.NossoNumero,
+		Amount:      invoice.Amount,
+		DueDate:     invoice.DueDate,
+	}
+}
+
+// WebhookSubscriptionUseCase mantém as inscrições de webhook dos clientes e o histórico
+// das entregas.
+type WebhookSubscriptionUseCase struct {
+	subscriptions service.WebhookSubscriptionRepository
+	deliveries    service.WebhookDeliveryRepository
+	now           func() time.Time
+}
+
+func NewWebhookSubscriptionUseCase(subscriptions service.WebhookSubscriptionRepository, deliveries service.WebhookDeliveryRepository) *WebhookSubscriptionUseCase {
+	return &WebhookSubscriptionUseCase{subscriptions: subscriptions, deliveries: deliveries, now: time.Now}
+}
+
+// Subscribe cria a inscrição com um segredo gerado para assinar as entregas. O segredo só
+// é devolvido aqui; sem eventos informados, a inscrição recebe todos os tipos.
+func (u *WebhookSubscriptionUseCase) Subscribe(clientID, endpoint string, events []domain.WebhookEventType) (domain.WebhookSubscription, error) {
+	if err := validateWebhookURL(endpoint); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+
+	if len(events) == 0 {
+		events = domain.WebhookEventTypes
+	}
+
+	for _, event := range events {
+		if !event.IsValid() {
+			return domain.WebhookSubscription{}, fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, event)
+		}
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("erro ao gerar identificador da inscrição: %w", err)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("erro ao gerar segredo da inscrição: %w", err)
+	}
+
+	subscription := domain.WebhookSubscription{
+		ID:        "wh_" + id,
+		ClientID:  clientID,
+		URL:       endpoint,
+		Secret:    "whsec_" + secret,
+		Events:    events,
+		CreatedAt: u.now(),
+	}
+
+	if err := u.subscriptions.Save(subscription); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("erro ao salvar inscrição de webhook: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// validateWebhookURL exige https e recusa destinos na rede interna escritos na própria
+// URL. Nomes que resolvem para a rede interna são recusados na conexão, pelo WebhookSender.
+func validateWebhookURL(endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: %q, esperada uma URL https", ErrInvalidWebhookURL, endpoint)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %q aponta para a rede interna", ErrInvalidWebhookURL, endpoint)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !domain.IsPublicWebhookAddress(addr) {
+		return fmt.Errorf("%w: %q aponta para a rede interna", ErrInvalidWebhookURL, endpoint)
+	}
+
+	return nil
+}
+
+func (u *WebhookSubscriptionUseCase) List(clientID string) []domain.WebhookSubscription {
+	return u.subscriptions.FindByClientID(clientID)
+}
+
+func (u *WebhookSubscriptionUseCase) Unsubscribe(clientID, subscriptionID string) error {
+	if _, err := u.find(clientID, subscriptionID); err != nil {
+		return err
+	}
+
+	if err := u.subscriptions.Delete(subscriptionID); err != nil {
+		return fmt.Errorf("erro ao remover inscrição de webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Deliveries devolve o histórico de entregas da inscrição.
+func (u *WebhookSubscriptionUseCase) Deliveries(clientID, subscriptionID string) ([]domain.WebhookDelivery, error) {
+	if _, err := u.find(clientID, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return u.deliveries.FindBySubscriptionID(subscriptionID), nil
+}
+
+// Redeliver agenda o reenvio imediato de uma entrega, mesmo já entregue ou esgotada.
+func (u *WebhookSubscriptionUseCase) Redeliver(clientID, subscriptionID, deliveryID string) (domain.WebhookDelivery, error) {
+	if _, err := u.find(clientID, subscriptionID); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery, exists := u.deliveries.FindByID(deliveryID)
+	if !exists || delivery.SubscriptionID != subscriptionID {
+		return domain.WebhookDelivery{}, ErrWebhookDeliveryMissing
+	}
+
+	delivery.Redeliver(u.now())
+	if err := u.deliveries.Update(delivery); err != nil {
+		return domain.WebhookDelivery{}, fmt.Errorf("erro ao agendar reenvio do webhook: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func (u *WebhookSubscriptionUseCase) find(clientID, subscriptionID string) (domain.WebhookSubscription, error) {
+	subscription, exists := u.subscriptions.FindByID(subscriptionID)
+	if !exists || subscription.ClientID != clientID {
+		return domain.WebhookSubscription{}, ErrWebhookSubscriptionMissing
+	}
+
+	return subscription, nil
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
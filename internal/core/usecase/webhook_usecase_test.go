@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockWebhookEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockWebhookEventPublisher) Publish(event domain.WebhookEvent) {
+	m.Called(event)
+}
+
+// newMockWebhooks aceita qualquer evento, para os testes que não verificam webhooks.
+func newMockWebhooks() *MockWebhookEventPublisher {
+	webhooks := new(MockWebhookEventPublisher)
+	webhooks.On("Publish", mock.Anything).Maybe()
+
+	return webhooks
+}
+
+type MockWebhookSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSubscriptionRepository) Save(subscription domain.WebhookSubscription) error {
+	args := m.Called(subscription)
+
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) Delete(id string) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
+
+func (m *MockWebhookSubscriptionRepository) FindByID(id string) (domain.WebhookSubscription, bool) {
+	args := m.Called(id)
+
+	return args.Get(0).(domain.WebhookSubscription), args.Bool(1)
+}
+
+func (m *MockWebhookSubscriptionRepository) FindByClientID(clientID string) []domain.WebhookSubscription {
+	args := m.Called(clientID)
+
+	return args.Get(0).([]domain.WebhookSubscription)
+}
+
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Enqueue(deliveries []domain.WebhookDelivery) (int, error) {
+	args := m.Called(deliveries)
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) ClaimPending(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(delivery domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) FindByID(id string) (domain.WebhookDelivery, bool) {
+	args := m.Called(id)
+
+	return args.Get(0).(domain.WebhookDelivery), args.Bool(1)
+}
+
+func (m *MockWebhookDeliveryRepository) FindBySubscriptionID(subscriptionID string) []domain.WebhookDelivery {
+	args := m.Called(subscriptionID)
+
+	return args.Get(0).([]domain.WebhookDelivery)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(delivery domain.WebhookDelivery, secret string) (int, error) {
+	args := m.Called(delivery, secret)
+
+	return args.Int(0), args.Error(1)
+}
+
+func TestWebhookPublisher_EnqueuesMatchingSubscriptions(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	subscriptions := new(MockWebhookSubscriptionRepository)
+	deliveries := new(MockWebhookDeliveryRepository)
+	publisher := NewWebhookPublisher(subscriptions, deliveries)
+	publisher.now = func() time.Time { return now }
+
+	subscriptions.On("FindByClientID", "acme").Return([]domain.WebhookSubscription{
+		{ID: "wh_1", ClientID: "acme", URL: "https://acme.example.com/hooks", Events: []domain.WebhookEventType{domain.WebhookEventDebtPaid}},
+		{ID: "wh_2", ClientID: "acme", URL: "https://erp.example.com/hooks", Events: []domain.WebhookEventType{domain.WebhookEventDebtInvoiced}},
+	})
+	deliveries.On("Enqueue", mock.Anything).Return(1, nil)
+
+	event, err := domain.NewWebhookEvent("debt.paid:d1:1", domain.WebhookEventDebtPaid, "acme", domain.WebhookDebtData{DebtID: "d1"}, now)
+	assert.NoError(t, err)
+
+	publisher.Publish(event)
+
+	deliveries.AssertCalled(t, "Enqueue", mock.MatchedBy(func(enqueued []domain.WebhookDelivery) bool {
+		return len(enqueued) == 1 &&
+			enqueued[0].ID == "debt.paid:d1:1@wh_1" &&
+			enqueued[0].URL == "https://acme.example.com/hooks" &&
+			enqueued[0].Status == domain.WebhookDeliveryPending &&
+			enqueued[0].NextAttemptAt.Equal(now)
+	}))
+}
+
+func TestWebhookPublisher_IgnoresEventsWithoutClient(t *testing.T) {
+	subscriptions := new(MockWebhookSubscriptionRepository)
+	deliveries := new(MockWebhookDeliveryRepository)
+
+	NewWebhookPublisher(subscriptions, deliveries).Publish(domain.WebhookEvent{ID: "e1", Type: domain.WebhookEventDebtPaid})
+
+	subscriptions.AssertNotCalled(t, "FindByClientID", mock.Anything)
+	deliveries.AssertNotCalled(t, "Enqueue", mock.Anything)
+}
+
+func TestWebhookSubscription_Subscribe(t *testing.T) {
+	subscriptions := new(MockWebhookSubscriptionRepository)
+	useCase := NewWebhookSubscriptionUseCase(subscriptions, new(MockWebhookDeliveryRepository))
+	subscriptions.On("Save", mock.Anything).Return(nil)
+
+	subscription, err := useCase.Subscribe("acme", "https://acme.example.com/hooks", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", subscription.ClientID)
+	assert.Equal(t, domain.WebhookEventTypes, subscription.Events)
+	assert.Regexp(t, `^wh_[0-9a-f]{16}$`, subscription.ID)
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, subscription.Secret)
+}
+
+func TestWebhookSubscription_SubscribeValidates(t *testing.T) {
+	useCase := NewWebhookSubscriptionUseCase(new(MockWebhookSubscriptionRepository), new(MockWebhookDeliveryRepository))
+
+	for _, endpoint := range []string{
+		"ftp://acme.example.com",
+		"http://acme.example.com/hooks",
+		"https://localhost:8080/hooks",
+		"https://127.0.0.1/hooks",
+		"https://10.0.0.5/hooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hooks",
+		"https://[::ffff:192.168.0.1]/hooks",
+	} {
+		_, err := useCase.Subscribe("acme", endpoint, nil)
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, endpoint)
+	}
+
+	_, err := useCase.Subscribe("acme", "https://acme.example.com/hooks", []domain.WebhookEventType{"debt.created"})
+	assert.ErrorIs(t, err, ErrInvalidWebhookEvent)
+}
+
+func TestWebhookSubscription_Redeliver(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	subscriptions := new(MockWebhookSubscriptionRepository)
+	deliveries := new(MockWebhookDeliveryRepository)
+	useCase := NewWebhookSubscriptionUseCase(subscriptions, deliveries)
+	useCase.now = func() time.Time { return now }
+
+	subscriptions.On("FindByID", "wh_1").Return(domain.WebhookSubscription{ID: "wh_1", ClientID: "acme"}, true)
+	deliveries.On("FindByID", "e1@wh_1").Return(domain.WebhookDelivery{
+		ID: "e1@wh_1", SubscriptionID: "wh_1", Status: domain.WebhookDeliveryDead, Attempts: 8, LastError: "status 500",
+		History: []domain.WebhookAttempt{{StatusCode: 500}},
+	}, true)
+	deliveries.On("Update", mock.Anything).Return(nil)
+
+	delivery, err := useCase.Redeliver("acme", "wh_1", "e1@wh_1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.Attempts)
+	assert.Equal(t, now, delivery.NextAttemptAt)
+	assert.Len(t, delivery.History, 1)
+
+	_, err = useCase.Redeliver("other", "wh_1", "e1@wh_1")
+	assert.ErrorIs(t, err, ErrWebhookSubscriptionMissing)
+}
+
+func newWebhookDispatchTestSetup(now time.Time, policy OutboxRetryPolicy) (*MockWebhookSubscriptionRepository, *MockWebhookDeliveryRepository, *MockWebhookSender, *DispatchWebhooksUseCase) {
+	subscriptions := new(MockWebhookSubscriptionRepository)
+	deliveries := new(MockWebhookDeliveryRepository)
+	sender := new(MockWebhookSender)
+	useCase := NewDispatchWebhooksUseCase(subscriptions, deliveries, sender, policy)
+	useCase.now = func() time.Time { return now }
+	deliveries.On("Update", mock.Anything).Return(nil)
+
+	return subscriptions, deliveries, sender, useCase
+}
+
+func TestDispatchWebhooks_Delivers(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	subscriptions, deliveries, sender, useCase := newWebhookDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	delivery := domain.WebhookDelivery{ID: "e1@wh_1", SubscriptionID: "wh_1", Status: domain.WebhookDeliveryPending}
+	deliveries.On("ClaimPending", now, 2*time.Minute, 100).Return([]domain.WebhookDelivery{delivery}, nil)
+	subscriptions.On("FindByID", "wh_1").Return(domain.WebhookSubscription{ID: "wh_1", Secret: "whsec_1"}, true)
+	sender.On("Send", delivery, "whsec_1").Return(204, nil)
+
+	result, err := useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookDispatchResult{Delivered: 1}, result)
+	deliveries.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.Status == domain.WebhookDeliveryDelivered &&
+			updated.Attempts == 1 &&
+			len(updated.History) == 1 && updated.History[0].StatusCode == 204
+	}))
+}
+
+func TestDispatchWebhooks_RetriesWithBackoff(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	subscriptions, deliveries, sender, useCase := newWebhookDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	delivery := domain.WebhookDelivery{ID: "e1@wh_1", SubscriptionID: "wh_1", Status: domain.WebhookDeliveryPending, Attempts: 2}
+	deliveries.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil)
+	subscriptions.On("FindByID", "wh_1").Return(domain.WebhookSubscription{ID: "wh_1", Secret: "whsec_1"}, true)
+	sender.On("Send", mock.Anything, "whsec_1").Return(503, errors.New("status 503"))
+
+	result, err := useCase.Dispatch()
+
+	assert.NoError(t, err)
+	assert.Equal(t, WebhookDispatchResult{Failed: 1}, result)
+	deliveries.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.Status == domain.WebhookDeliveryPending &&
+			updated.Attempts == 3 &&
+			updated.LastError == "status 503" &&
+			updated.NextAttemptAt.Equal(now.Add(2*time.Minute)) &&
+			updated.History[0].StatusCode == 503
+	}))
+}
+
+func TestDispatchWebhooks_GivesUpAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	policy := DefaultOutboxRetryPolicy()
+	policy.MaxAttempts = 3
+	subscriptions, deliveries, sender, useCase := newWebhookDispatchTestSetup(now, policy)
+
+	delivery := domain.WebhookDelivery{ID: "e1@wh_1", SubscriptionID: "wh_1", Status: domain.WebhookDeliveryPending, Attempts: 2}
+	deliveries.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil)
+	subscriptions.On("FindByID", "wh_1").Return(domain.WebhookSubscription{ID: "wh_1"}, true)
+	sender.On("Send", mock.Anything, mock.Anything).Return(0, errors.New("tempo esgotado"))
+
+	_, err := useCase.Dispatch()
+
+	assert.NoError(t, err)
+	deliveries.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.Status == domain.WebhookDeliveryDead && updated.Attempts == 3
+	}))
+}
+
+func TestDispatchWebhooks_SubscriptionRemoved(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	subscriptions, deliveries, sender, useCase := newWebhookDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+
+	delivery := domain.WebhookDelivery{ID: "e1@wh_1", SubscriptionID: "wh_1", Status: domain.WebhookDeliveryPending}
+	deliveries.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.WebhookDelivery{delivery}, nil)
+	subscriptions.On("FindByID", "wh_1").Return(domain.WebhookSubscription{}, false)
+
+	_, err := useCase.Dispatch()
+
+	assert.NoError(t, err)
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	deliveries.AssertCalled(t, "Update", mock.MatchedBy(func(updated domain.WebhookDelivery) bool {
+		return updated.Status == domain.WebhookDeliveryDead && updated.LastError == "inscrição removida"
+	}))
+}
+
+func TestIssueInvoice_PublishesWebhookEvents(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newIssueTestSetup(now)
+	s.webhooks = new(MockWebhookEventPublisher)
+	s.useCase.webhooks = s.webhooks
+	s.webhooks.On("Publish", mock.Anything)
+
+	debt := domain.Debt{DebtID: "d1", GovernmentID: "123", Email: "joao@example.com", ClientID: "acme"}
+	s.invoice.On("Generate", debt).Return(domain.Invoice{Debt: debt, NossoNumero: "1", Amount: 100}, nil).Once()
+	s.invalidEmails.On("IsInvalid", "123", "joao@example.com").Return(false)
+	s.contacts.On("FindByGovernmentID", "123").Return(domain.ContactPreferences{}, false)
+	s.invoices.On("FindByDebtID", "d1").Return(domain.Invoice{}, false)
+	s.invoices.On("Save", mock.Anything).Return(nil)
+
+	_, err := s.useCase.Issue(debt)
+	assert.NoError(t, err)
+
+	s.invoice.On("Generate", debt).Return(domain.Invoice{}, errors.New("cliente desconhecido")).Once()
+	_, err = s.useCase.Issue(debt)
+	assert.Error(t, err)
+
+	s.webhooks.AssertCalled(t, "Publish", mock.MatchedBy(func(event domain.WebhookEvent) bool {
+		return event.ID == "debt.invoiced:d1:1" && event.Type == domain.WebhookEventDebtInvoiced && event.ClientID == "acme"
+	}))
+	s.webhooks.AssertCalled(t, "Publish", mock.MatchedBy(func(event domain.WebhookEvent) bool {
+		return event.ID == "debt.failed:d1:invoice" && event.Type == domain.WebhookEventDebtFailed
+	}))
+}
+
+func TestDispatchOutbox_PublishesWebhookEvents(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := newDispatchTestSetup(now, DefaultOutboxRetryPolicy())
+	s.webhooks = new(MockWebhookEventPublisher)
+	s.useCase.webhooks = s.webhooks
+	s.webhooks.On("Publish", mock.Anything)
+
+	delivered := newOutboxMessage("d1", now)
+	delivered.Invoice.Debt.ClientID = "acme"
+	rejected := newOutboxMessage("d2", now)
+	rejected.Invoice.Debt.ClientID = "acme"
+	s.outbox.On("ClaimPending", mock.Anything, mock.Anything, mock.Anything).Return([]domain.OutboxMessage{delivered, rejected}, nil)
+	s.invalidEmails.On("IsInvalid", mock.Anything, mock.Anything).Return(false)
+	s.invalidEmails.On("MarkInvalid", mock.Anything).Return(nil)
+	s.email.On("Publish", "d1@example.com", mock.Anything).Return("<abc@kanastra.com.br>", nil)
+	s.email.On("Publish", "d2@example.com", mock.Anything).Return("", domain.ErrPermanentDeliveryFailure)
+
+	_, err := s.useCase.Dispatch()
+
+	assert.NoError(t, err)
+	s.webhooks.AssertCalled(t, "Publish", mock.MatchedBy(func(event domain.WebhookEvent) bool {
+		return event.ID == "debt.notified:debt_notification:d1:000d1" && event.Type == domain.WebhookEventDebtNotified
+	}))
+	s.webhooks.AssertCalled(t, "Publish", mock.MatchedBy(func(event domain.WebhookEvent) bool {
+		return event.ID == "debt.failed:debt_notification:d2:000d2" && event.Type == domain.WebhookEventDebtFailed
+	}))
+}
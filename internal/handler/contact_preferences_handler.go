@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+type ContactPreferencesUseCaseInterface interface {
+	Save(preferences domain.ContactPreferences) (domain.ContactPreferences, error)
+	Get(governmentID string) (domain.ContactPreferences, error)
+}
+
+type ContactPreferencesHandler struct {
+	useCase ContactPreferencesUseCaseInterface
+}
+
+func NewContactPreferencesHandler(useCase ContactPreferencesUseCaseInterface) *ContactPreferencesHandler {
+	return &ContactPreferencesHandler{useCase: useCase}
+}
+
+func (h *ContactPreferencesHandler) RegisterRoutes(router *gin.Engine) {
+	router.PUT("/debtors/:governmentId/contact-preferences", h.Save)
+	router.GET("/debtors/:governmentId/contact-preferences", h.Get)
+}
+
+func (h *ContactPreferencesHandler) Save(c *gin.Context) {
+	var request dto.ContactPreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Failed to parse contact preferences request: %v", err)
+		c.JSON(http.StatusBadRequest, dto.ContactPreferencesResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	preferences := domain.ContactPreferences{
+		GovernmentID: c.Param("governmentId"),
+		Phone:        request.Phone,
+		WhatsApp:     request.WhatsApp,
+	}
+	for _, channel := range request.Channels {
+		preferences.Channels = append(preferences.Channels, domain.Channel(channel))
+	}
+
+	saved, err := h.useCase.Save(preferences)
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidContactPreferences):
+		c.JSON(http.StatusBadRequest, dto.ContactPreferencesResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao salvar preferências de contato do devedor %s: %v", preferences.GovernmentID, err)
+		c.JSON(http.StatusInternalServerError, dto.ContactPreferencesResponse{Message: localize(c, "Failed to save contact preferences")})
+	default:
+		c.JSON(http.StatusOK, dto.ContactPreferencesResponse{Message: localize(c, "Contact preferences saved"), Preferences: &saved})
+	}
+}
+
+func (h *ContactPreferencesHandler) Get(c *gin.Context) {
+	preferences, err := h.useCase.Get(c.Param("governmentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ContactPreferencesResponse{Message: localize(c, "Contact preferences not found")})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ContactPreferencesResponse{Message: localize(c, "Contact preferences found"), Preferences: &preferences})
+}
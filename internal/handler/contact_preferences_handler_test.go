@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+func TestContactPreferencesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	NewContactPreferencesHandler(usecase.NewContactPreferencesUseCase(persistence.NewContactPreferenceRepository())).RegisterRoutes(router)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/debtors/12345678901/contact-preferences", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	t.Run("Not found before saving", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debtors/12345678901/contact-preferences", nil))
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Save and read preferences", func(t *testing.T) {
+		resp := put(`{"phone":"+55 (11) 99999-8888","channels":["sms","email"]}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"Phone":"5511999998888"`)
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debtors/12345678901/contact-preferences", nil))
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"Channels":["sms","email"]`)
+	})
+
+	t.Run("Unknown channel", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, put(`{"channels":["pombo"]}`).Code)
+	})
+
+	t.Run("Invalid phone", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, put(`{"phone":"123"}`).Code)
+	})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+type IngestDebtsUseCaseInterface interface {
+	Ingest(source usecase.DebtSource, sourceName, clientID string) (domain.IngestionJob, error)
+	Find(id string) (domain.IngestionJob, error)
+}
+
+// maxNDJSONLineSize limita cada linha do NDJSON; uma linha maior é registrada como
+// malformada sem ser guardada em memória.
+const maxNDJSONLineSize = 1 << 20
+
+var errNDJSONLineTooLong = errors.New("linha do NDJSON muito longa")
+
+type DebtIngestionHandler struct {
+	useCase IngestDebtsUseCaseInterface
+	// maxBodySize limita o corpo dos envios: o de POST /debts é lido por inteiro antes do
+	// processamento, e o de POST /debts/ndjson, lido aos poucos, é interrompido ao atingi-lo.
+	maxBodySize int64
+}
+
+func NewDebtIngestionHandler(useCase IngestDebtsUseCaseInterface, maxBodySize int64) *DebtIngestionHandler {
+	return &DebtIngestionHandler{useCase: useCase, maxBodySize: maxBodySize}
+}
+
+func (h *DebtIngestionHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/debts", h.Ingest)
+	router.POST("/debts/ndjson", h.IngestNDJSON)
+	router.GET("/debts/jobs/:jobId", h.Job)
+}
+
+// Ingest recebe um débito ou uma lista de débitos em JSON.
+func (h *DebtIngestionHandler) Ingest(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodySize))
+
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		c.JSON(http.StatusRequestEntityTooLarge, dto.IngestionJobResponse{Message: localize(c, "Payload exceeds maximum size")})
+
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to read debts body: %v", err)
+		c.JSON(http.StatusBadRequest, dto.IngestionJobResponse{Message: localize(c, "Failed to read body")})
+
+		return
+	}
+
+	source, err := newJSONDebtSource(body)
+	if err != nil {
+		log.Printf("Failed to parse debts payload: %v", err)
+		c.JSON(http.StatusBadRequest, dto.IngestionJobResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	h.respond(c, source, "json")
+}
+
+// IngestNDJSON recebe um débito por linha, processando cada linha à medida que é lida.
+func (h *DebtIngestionHandler) IngestNDJSON(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodySize)
+	h.respond(c, &ndjsonDebtSource{reader: bufio.NewReader(body)}, "ndjson")
+}
+
+func (h *DebtIngestionHandler) Job(c *gin.Context) {
+	job, err := h.useCase.Find(c.Param("jobId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.IngestionJobResponse{Message: localizeError(c, err)})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.IngestionJobResponse{Message: localize(c, "Debts job found"), Job: &job})
+}
+
+func (h *DebtIngestionHandler) respond(c *gin.Context, source usecase.DebtSource, sourceName string) {
+	job, err := h.useCase.Ingest(source, sourceName, c.Query("clientId"))
+
+	var maxBytes *http.MaxBytesError
+	switch {
+	case err != nil && job.ID == "":
+		log.Printf("Erro ao processar envio de débitos: %v", err)
+		c.JSON(http.StatusInternalServerError, dto.IngestionJobResponse{Message: localize(c, "Failed to process debts")})
+	case errors.As(err, &maxBytes):
+		log.Printf("Envio de débitos %s interrompido no limite do corpo: %v", job.ID, err)
+		c.JSON(http.StatusRequestEntityTooLarge, dto.IngestionJobResponse{Message: localize(c, "Payload exceeds maximum size"), Job: &job})
+	case err != nil:
+		log.Printf("Envio de débitos %s interrompido: %v", job.ID, err)
+		c.JSON(http.StatusBadRequest, dto.IngestionJobResponse{Message: localize(c, "Failed to read body"), Job: &job})
+	default:
+		c.JSON(http.StatusAccepted, dto.IngestionJobResponse{Message: localize(c, "Debts are being processed"), Job: &job})
+	}
+}
+
+// jsonDebtSource percorre um débito único ou uma lista de débitos já recebida. Itens da
+// lista que não são débitos válidos em JSON são devolvidos como malformados.
+type jsonDebtSource struct {
+	items []json.RawMessage
+}
+
+func newJSONDebtSource(body []byte) (*jsonDebtSource, error) {
+	trimmed := bytes.TrimSpace(body)
+
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+
+		return &jsonDebtSource{items: items}, nil
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		return &jsonDebtSource{items: []json.RawMessage{trimmed}}, nil
+	default:
+		return nil, errors.New("esperado um objeto ou uma lista de débitos")
+	}
+}
+
+func (s *jsonDebtSource) Next() (domain.Debt, error) {
+	if len(s.items) == 0 {
+		return domain.Debt{}, io.EOF
+	}
+
+	item := s.items[0]
+	s.items = s.items[1:]
+
+	return decodeDebt(item)
+}
+
+// ndjsonDebtSource lê um débito por linha do corpo da requisição, ignorando linhas em
+// branco.
+type ndjsonDebtSource struct {
+	reader *bufio.Reader
+}
+
+func (s *ndjsonDebtSource) Next() (domain.Debt, error) {
+	for {
+		line, err := s.readLine()
+		if errors.Is(err, errNDJSONLineTooLong) {
+			return domain.Debt{}, fmt.Errorf("%w: linha acima de %d bytes", usecase.ErrMalformedDebt, maxNDJSONLineSize)
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			return decodeDebt(line)
+		}
+
+		if err != nil {
+			return domain.Debt{}, err
+		}
+	}
+}
+
+// readLine lê a próxima linha guardando no máximo maxNDJSONLineSize bytes. O restante de
+// uma linha mais longa é lido e descartado.
+func (s *ndjsonDebtSource) readLine() ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := s.reader.ReadSlice('\n')
+		if !tooLong && len(line)+len(chunk) > maxNDJSONLineSize {
+			tooLong, line = true, nil
+		}
+
+		if !tooLong {
+			line = append(line, chunk...)
+		}
+
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err != nil && err != io.EOF:
+			return nil, err
+		case tooLong:
+			return nil, errNDJSONLineTooLong
+		}
+
+		return line, err
+	}
+}
+
+func decodeDebt(data []byte) (domain.Debt, error) {
+	var debt domain.Debt
+	if err := json.Unmarshal(data, &debt); err != nil {
+		return domain.Debt{}, fmt.Errorf("%w: %v", usecase.ErrMalformedDebt, err)
+	}
+
+	return debt, nil
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+// recordingSubmitter aceita todos os débitos, exceto os já enviados, guardando as linhas
+// recebidas.
+type recordingSubmitter struct {
+	records []domain.DebtRecord
+	options []domain.FileOptions
+}
+
+func (s *recordingSubmitter) Submit(record domain.DebtRecord, _ string, options domain.FileOptions) (domain.IngestionStatus, error) {
+	for _, sent := range s.records {
+		if sent[domain.DebtFieldDebtID] == record[domain.DebtFieldDebtID] {
+			return domain.IngestionDuplicate, nil
+		}
+	}
+
+	s.records = append(s.records, record)
+	s.options = append(s.options, options)
+
+	return domain.IngestionAccepted, nil
+}
+
+func TestDebtIngestionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	submitter := &recordingSubmitter{}
+	router := gin.Default()
+	NewDebtIngestionHandler(usecase.NewIngestDebtsUseCase(submitter, persistence.NewIngestionJobRepository()), 1<<12).RegisterRoutes(router)
+
+	request := func(method, path, body string) (*httptest.ResponseRecorder, dto.IngestionJobResponse) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var payload dto.IngestionJobResponse
+		_ = json.Unmarshal(resp.Body.Bytes(), &payload)
+
+		return resp, payload
+	}
+
+	t.Run("Débito único", func(t *testing.T) {
+		resp, payload := request(http.MethodPost, "/debts?clientId=acme",
+			`{"Name":"John Doe","GovernmentID":"1234","Email":"john@example.com","DebtAmount":100.5,"DebtDueDate":"2025-01-01","DebtID":"d1"}`)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, 1, payload.Job.Accepted)
+		assert.Equal(t, "100.5", submitter.records[0][domain.DebtFieldDebtAmount])
+		assert.Equal(t, "acme", submitter.options[0].ClientID)
+	})
+
+	t.Run("Lista com item malformado e duplicado", func(t *testing.T) {
+		resp, payload := request(http.MethodPost, "/debts", `[{"DebtID":"d2"},{"DebtAmount":"cem"},{"DebtID":"d1"}]`)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, 3, payload.Job.Total)
+		assert.Equal(t, 1, payload.Job.Accepted)
+		assert.Equal(t, 1, payload.Job.Duplicates)
+		require.Len(t, payload.Job.Results, 1, "apenas o item inválido tem o resultado guardado")
+		assert.Equal(t, 1, payload.Job.Results[0].Index)
+		assert.Equal(t, domain.IngestionInvalid, payload.Job.Results[0].Status)
+
+		resp, found := request(http.MethodGet, "/debts/jobs/"+payload.Job.ID, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, payload.Job.ID, found.Job.ID)
+	})
+
+	t.Run("Débito com idioma próprio", func(t *testing.T) {
+		resp, _ := request(http.MethodPost, "/debts?clientId=acme", `{"DebtID":"d5","Locale":"en-US"}`)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, "en-US", submitter.records[len(submitter.records)-1].Debt("acme").Locale)
+	})
+
+	t.Run("Corpo acima do limite", func(t *testing.T) {
+		resp, _ := request(http.MethodPost, "/debts", `[`+strings.Repeat(`{"DebtID":"d6"},`, 300)+`{"DebtID":"d7"}]`)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	})
+
+	t.Run("Corpo inválido", func(t *testing.T) {
+		resp, _ := request(http.MethodPost, "/debts", `"d1"`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		resp, payload := request(http.MethodPost, "/debts/ndjson", "{\"DebtID\":\"d3\"}\n\n{\"DebtID\":\"d4\",\"ClientID\":\"globex\"}\nnão é json")
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, "ndjson", payload.Job.Source)
+		assert.Equal(t, 3, payload.Job.Total)
+		assert.Equal(t, 2, payload.Job.Accepted)
+		assert.Equal(t, 1, payload.Job.Invalid)
+		assert.Equal(t, "globex", submitter.options[len(submitter.options)-1].ClientID)
+	})
+
+	t.Run("NDJSON com linha acima do limite", func(t *testing.T) {
+		router := gin.Default()
+		NewDebtIngestionHandler(usecase.NewIngestDebtsUseCase(submitter, persistence.NewIngestionJobRepository()), 4<<20).RegisterRoutes(router)
+
+		long := `{"DebtID":"d8","Name":"` + strings.Repeat("x", maxNDJSONLineSize) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/debts/ndjson", strings.NewReader(long+"\n{\"DebtID\":\"d9\"}"))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var payload dto.IngestionJobResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &payload))
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, 2, payload.Job.Total)
+		assert.Equal(t, 1, payload.Job.Accepted)
+		require.Len(t, payload.Job.Results, 1)
+		assert.Equal(t, domain.IngestionInvalid, payload.Job.Results[0].Status)
+	})
+
+	t.Run("NDJSON acima do limite", func(t *testing.T) {
+		resp, payload := request(http.MethodPost, "/debts/ndjson", strings.Repeat("{\"DebtID\":\"d10\"}\n", 300))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+		assert.NotEmpty(t, payload.Job.ID)
+		assert.Less(t, payload.Job.Total, 300)
+	})
+
+	t.Run("Envio desconhecido", func(t *testing.T) {
+		resp, _ := request(http.MethodGet, "/debts/jobs/job_desconhecido", "")
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Debts job not found")
+	})
+}
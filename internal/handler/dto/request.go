@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type PaymentWebhookRequest struct {
+	EventID     string  `json:"event_id" binding:"required"`
+	Provider    string  `json:"provider" binding:"required"`
+	Method      string  `json:"method" binding:"required,oneof=pix boleto"`
+	TxID        string  `json:"txid"`
+	NossoNumero string  `json:"nosso_numero"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	PaidDate    string  `json:"paid_date" binding:"required"`
+}
+
+type CreateInstallmentPlanRequest struct {
+	Installments           int     `json:"installments" binding:"required,min=2"`
+	FirstDueDate           string  `json:"first_due_date" binding:"required"`
+	IntervalDays           int     `json:"interval_days" binding:"omitempty,min=1"`
+	MonthlyInterestPercent float64 `json:"monthly_interest_percent" binding:"omitempty,min=0"`
+}
+
+type EmailEventRequest struct {
+	MessageID  string    `json:"message_id" binding:"required"`
+	Email      string    `json:"email"`
+	Type       string    `json:"type" binding:"required,oneof=bounce complaint delay"`
+	Permanent  bool      `json:"permanent"`
+	Reason     string    `json:"reason"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type ContactPreferencesRequest struct {
+	Phone    string   `json:"phone"`
+	WhatsApp string   `json:"whatsapp"`
+	Channels []string `json:"channels"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"`
+}
+
+// FileOptionsRequest são as opções dos arquivos enviados por JSON, com os mesmos campos do
+// formulário de /process-files; Columns é o mapeamento de colunas em JSON.
+type FileOptionsRequest struct {
+	ClientID  string          `json:"client_id"`
+	Sheet     string          `json:"sheet"`
+	Columns   json.RawMessage `json:"columns"`
+	Delimiter string          `json:"delimiter"`
+	Encoding  string          `json:"encoding"`
+}
+
+// InitiateUploadRequest inicia um upload retomável.
+type InitiateUploadRequest struct {
+	FileName string `json:"file_name" binding:"required"`
+	Checksum string `json:"checksum" binding:"required"`
+	FileOptionsRequest
+}
+
+// ProcessObjectRequest aponta o arquivo a processar em um bucket compatível com S3, pela
+// URL s3://bucket/chave ou pelo bucket e pela chave.
+type ProcessObjectRequest struct {
+	URL    string `json:"url"`
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	FileOptionsRequest
+}
@@ -1,8 +1,24 @@
 package dto
 
+import "kanastra-api/internal/core/domain"
+
 type ProcessFilesResponse struct {
-	Message string `json:"message"`
+	Message string            `json:"message"`
+	Jobs    []FileJobResponse `json:"jobs,omitempty"`
 }
+
+type FileJobResponse struct {
+	FileName string `json:"file_name"`
+	JobID    string `json:"job_id"`
+}
+
+type UploadResponse struct {
+	Message string             `json:"message"`
+	Upload  *domain.Upload     `json:"upload,omitempty"`
+	Part    *domain.UploadPart `json:"part,omitempty"`
+	Jobs    []FileJobResponse  `json:"jobs,omitempty"`
+}
+
 type ProcessStatus struct {
 	FileName        string `json:"file_name"`
 	TotalLines      int    `json:"total_lines"`
@@ -10,3 +26,71 @@ type ProcessStatus struct {
 	Status          string `json:"status"`
 	LastUpdatedTime string `json:"last_updated_time"`
 }
+
+type ReturnFileResult struct {
+	FileName string                       `json:"file_name"`
+	Error    string                       `json:"error,omitempty"`
+	Report   *domain.ReconciliationReport `json:"report,omitempty"`
+}
+
+type ReturnFilesResponse struct {
+	Message string             `json:"message"`
+	Results []ReturnFileResult `json:"results"`
+}
+
+type PaymentWebhookResponse struct {
+	Message string `json:"message"`
+	DebtID  string `json:"debt_id,omitempty"`
+}
+
+type InstallmentPlanResponse struct {
+	Message string                  `json:"message"`
+	Plan    *domain.InstallmentPlan `json:"plan,omitempty"`
+}
+
+type EmailEventResponse struct {
+	Message string                     `json:"message"`
+	Status  domain.EmailDeliveryStatus `json:"status,omitempty"`
+}
+
+type EmailDeliveriesResponse struct {
+	DebtID     string                 `json:"debt_id"`
+	Deliveries []domain.EmailDelivery `json:"deliveries"`
+}
+
+type ContactPreferencesResponse struct {
+	Message     string                     `json:"message"`
+	Preferences *domain.ContactPreferences `json:"preferences,omitempty"`
+}
+
+type OptOutsResponse struct {
+	Message string          `json:"message"`
+	OptOuts []domain.OptOut `json:"opt_outs,omitempty"`
+}
+
+type WebhookSubscriptionResponse struct {
+	Message      string                      `json:"message"`
+	Subscription *domain.WebhookSubscription `json:"subscription,omitempty"`
+	// Secret só é devolvido na criação da inscrição.
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookSubscriptionsResponse struct {
+	Message       string                       `json:"message"`
+	Subscriptions []domain.WebhookSubscription `json:"subscriptions"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Message    string                   `json:"message"`
+	Deliveries []domain.WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	Message  string                  `json:"message"`
+	Delivery *domain.WebhookDelivery `json:"delivery,omitempty"`
+}
+
+type IngestionJobResponse struct {
+	Message string               `json:"message"`
+	Job     *domain.IngestionJob `json:"job,omitempty"`
+}
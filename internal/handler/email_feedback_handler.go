@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+type EmailFeedbackUseCaseInterface interface {
+	Process(feedback domain.EmailFeedback) (domain.EmailDelivery, error)
+	Deliveries(debtID string) []domain.EmailDelivery
+}
+
+type EmailFeedbackHandler struct {
+	useCase EmailFeedbackUseCaseInterface
+	secret  []byte
+}
+
+func NewEmailFeedbackHandler(useCase EmailFeedbackUseCaseInterface, secret string) *EmailFeedbackHandler {
+	return &EmailFeedbackHandler{useCase: useCase, secret: []byte(secret)}
+}
+
+func (h *EmailFeedbackHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/webhooks/email-events", h.Handle)
+	router.GET("/debts/:debtId/email-deliveries", h.List)
+}
+
+// Handle recebe bounces e reclamações enviados pelo provedor de e-mail, assinados da
+// mesma forma que o webhook de pagamentos.
+func (h *EmailFeedbackHandler) Handle(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		log.Printf("Failed to read email event body: %v", err)
+		c.JSON(http.StatusBadRequest, dto.EmailEventResponse{Message: localize(c, "Failed to read body")})
+
+		return
+	}
+
+	if !VerifySignature(h.secret, body, c.GetHeader(PaymentSignatureHeader)) {
+		log.Printf("Assinatura inválida no webhook de eventos de e-mail")
+		c.JSON(http.StatusUnauthorized, dto.EmailEventResponse{Message: localize(c, "Invalid signature")})
+
+		return
+	}
+
+	var request dto.EmailEventRequest
+	if err := binding.JSON.BindBody(body, &request); err != nil {
+		log.Printf("Failed to parse email event payload: %v", err)
+		c.JSON(http.StatusBadRequest, dto.EmailEventResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	delivery, err := h.useCase.Process(domain.EmailFeedback{
+		ProviderMessageID: request.MessageID,
+		Email:             request.Email,
+		Type:              domain.EmailFeedbackType(request.Type),
+		Permanent:         request.Permanent,
+		Reason:            request.Reason,
+		OccurredAt:        request.OccurredAt,
+	})
+
+	switch {
+	case errors.Is(err, usecase.ErrEmailDeliveryNotFound):
+		c.JSON(http.StatusUnprocessableEntity, dto.EmailEventResponse{Message: localize(c, "Email delivery not found")})
+	case err != nil:
+		log.Printf("Erro ao processar evento de e-mail %s: %v", request.MessageID, err)
+		c.JSON(http.StatusInternalServerError, dto.EmailEventResponse{Message: localize(c, "Failed to process email event")})
+	default:
+		c.JSON(http.StatusOK, dto.EmailEventResponse{Message: localize(c, "Email event received"), Status: delivery.Status})
+	}
+}
+
+func (h *EmailFeedbackHandler) List(c *gin.Context) {
+	debtID := c.Param("debtId")
+
+	c.JSON(http.StatusOK, dto.EmailDeliveriesResponse{DebtID: debtID, Deliveries: h.useCase.Deliveries(debtID)})
+}
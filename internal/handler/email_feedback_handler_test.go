@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+func signedEmailEvent(t *testing.T, router *gin.Engine, secret string, payload map[string]any) *httptest.ResponseRecorder {
+	body, err := json.Marshal(payload)
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/email-events", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(PaymentSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestEmailFeedbackHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deliveries := persistence.NewEmailDeliveryRepository()
+	invalidEmails := persistence.NewInvalidEmailRepository()
+	assert.NoError(t, deliveries.Save(domain.EmailDelivery{
+		ID:                "debt_notification:abc123:1#1",
+		DebtID:            "abc123",
+		GovernmentID:      "12345678901",
+		Email:             "joao@example.com",
+		Attempt:           1,
+		ProviderMessageID: "<m1@kanastra.com.br>",
+		Status:            domain.EmailDeliverySent,
+		CreatedAt:         time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+	}))
+
+	router := gin.Default()
+	NewEmailFeedbackHandler(usecase.NewEmailFeedbackUseCase(persistence.NewDebtRepository(), deliveries, invalidEmails), testWebhookSecret).RegisterRoutes(router)
+
+	t.Run("Permanent bounce invalidates the email", func(t *testing.T) {
+		resp := signedEmailEvent(t, router, testWebhookSecret, map[string]any{
+			"message_id": "<m1@kanastra.com.br>", "type": "bounce", "permanent": true, "reason": "550 5.1.1 user unknown",
+		})
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"status":"bounced"`)
+		assert.True(t, invalidEmails.IsInvalid("12345678901", "JOAO@example.com"))
+	})
+
+	t.Run("Unknown message", func(t *testing.T) {
+		resp := signedEmailEvent(t, router, testWebhookSecret, map[string]any{"message_id": "<x>", "type": "complaint"})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		resp := signedEmailEvent(t, router, "outro-segredo", map[string]any{"message_id": "<m1@kanastra.com.br>", "type": "complaint"})
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		resp := signedEmailEvent(t, router, testWebhookSecret, map[string]any{"message_id": "<m1@kanastra.com.br>", "type": "opened"})
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("List deliveries for a debt", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/debts/abc123/email-deliveries", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var response dto.EmailDeliveriesResponse
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Len(t, response.Deliveries, 1)
+		assert.Equal(t, domain.EmailDeliveryBounced, response.Deliveries[0].Status)
+	})
+}
@@ -1,9 +1,68 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/i18n"
 )
 
 type Handler interface {
 	RegisterRoutes(router *gin.Engine)
 }
+
+// errorMessages associa os erros de negócio expostos pela API às mensagens de resposta,
+// que são traduzidas conforme o Accept-Language da requisição.
+var errorMessages = []struct {
+	err     error
+	message string
+}{
+	{err: domain.ErrInvoiceAlreadyPaid, message: "Invoice already paid"},
+	{err: domain.ErrInvoiceRejected, message: "Invoice rejected by the bank"},
+	{err: domain.ErrInvoiceCancelled, message: "Invoice cancelled"},
+	{err: usecase.ErrInstallmentPlanExists, message: "Installment plan already exists"},
+	{err: usecase.ErrDebtNotInstallable, message: "Debt cannot be split into installments"},
+	{err: usecase.ErrInvalidContactPreferences, message: "Invalid contact preferences"},
+	{err: usecase.ErrInvalidUnsubscribeToken, message: "Invalid unsubscribe link"},
+	{err: usecase.ErrInvalidWebhookURL, message: "Invalid webhook URL"},
+	{err: usecase.ErrInvalidWebhookEvent, message: "Invalid webhook event type"},
+	{err: usecase.ErrWebhookSubscriptionMissing, message: "Webhook subscription not found"},
+	{err: usecase.ErrWebhookDeliveryMissing, message: "Webhook delivery not found"},
+	{err: usecase.ErrIngestionJobAbsent, message: "Debts job not found"},
+	{err: usecase.ErrUploadAbsent, message: "Upload not found"},
+	{err: usecase.ErrInvalidUpload, message: "Invalid upload"},
+	{err: usecase.ErrInvalidUploadPart, message: "Invalid upload part"},
+	{err: usecase.ErrUploadCompleted, message: "Upload already completed"},
+	{err: usecase.ErrUploadCompleting, message: "Upload is being completed"},
+	{err: usecase.ErrMissingUploadParts, message: "Upload parts missing"},
+	{err: usecase.ErrUploadChecksumMismatch, message: "Upload checksum mismatch"},
+}
+
+// localize traduz a mensagem de resposta para o idioma pedido no cabeçalho
+// Accept-Language. Sem idioma suportado, a resposta segue em inglês.
+func localize(c *gin.Context, message string) string {
+	return requestLocale(c, i18n.EnUS).API(message)
+}
+
+// requestLocale escolhe o idioma da resposta pelo cabeçalho Accept-Language e o informa
+// em Content-Language.
+func requestLocale(c *gin.Context, fallback i18n.Locale) i18n.Locale {
+	locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"), fallback)
+	c.Header("Content-Language", string(locale))
+
+	return locale
+}
+
+// localizeError traduz um erro de negócio conhecido para a mensagem de resposta.
+func localizeError(c *gin.Context, err error) string {
+	for _, known := range errorMessages {
+		if errors.Is(err, known.err) {
+			return localize(c, known.message)
+		}
+	}
+
+	return localize(c, "Unexpected error")
+}
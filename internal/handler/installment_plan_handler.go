@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+const defaultInstallmentIntervalDays = 30
+
+type InstallmentPlanUseCaseInterface interface {
+	Create(request usecase.InstallmentPlanRequest) (domain.InstallmentPlan, error)
+	Get(debtID string) (domain.InstallmentPlan, error)
+}
+
+type InstallmentPlanHandler struct {
+	useCase InstallmentPlanUseCaseInterface
+}
+
+func NewInstallmentPlanHandler(useCase InstallmentPlanUseCaseInterface) *InstallmentPlanHandler {
+	return &InstallmentPlanHandler{useCase: useCase}
+}
+
+func (h *InstallmentPlanHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/debts/:debtId/installments", h.Create)
+	router.GET("/debts/:debtId/installments", h.Get)
+}
+
+func (h *InstallmentPlanHandler) Create(c *gin.Context) {
+	var request dto.CreateInstallmentPlanRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Failed to parse installment plan request: %v", err)
+		c.JSON(http.StatusBadRequest, dto.InstallmentPlanResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	if request.IntervalDays == 0 {
+		request.IntervalDays = defaultInstallmentIntervalDays
+	}
+
+	plan, err := h.useCase.Create(usecase.InstallmentPlanRequest{
+		DebtID:                 c.Param("debtId"),
+		Installments:           request.Installments,
+		FirstDueDate:           request.FirstDueDate,
+		IntervalDays:           request.IntervalDays,
+		MonthlyInterestPercent: request.MonthlyInterestPercent,
+	})
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidInstallmentRequest):
+		c.JSON(http.StatusBadRequest, dto.InstallmentPlanResponse{Message: localize(c, "Invalid installment parameters")})
+	case errors.Is(err, usecase.ErrDebtNotFound):
+		c.JSON(http.StatusNotFound, dto.InstallmentPlanResponse{Message: localize(c, "Debt not found")})
+	case errors.Is(err, usecase.ErrInstallmentPlanExists), errors.Is(err, usecase.ErrDebtNotInstallable):
+		c.JSON(http.StatusConflict, dto.InstallmentPlanResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao criar parcelamento do débito %s: %v", c.Param("debtId"), err)
+		c.JSON(http.StatusInternalServerError, dto.InstallmentPlanResponse{Message: localize(c, "Failed to create installment plan")})
+	default:
+		c.JSON(http.StatusCreated, dto.InstallmentPlanResponse{Message: localize(c, "Installment plan created"), Plan: &plan})
+	}
+}
+
+func (h *InstallmentPlanHandler) Get(c *gin.Context) {
+	plan, err := h.useCase.Get(c.Param("debtId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.InstallmentPlanResponse{Message: localize(c, "Installment plan not found")})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.InstallmentPlanResponse{Message: localize(c, "Installment plan found"), Plan: &plan})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+)
+
+type MockInstallmentPlanUseCase struct {
+	lastRequest usecase.InstallmentPlanRequest
+}
+
+func (m *MockInstallmentPlanUseCase) Create(request usecase.InstallmentPlanRequest) (domain.InstallmentPlan, error) {
+	m.lastRequest = request
+	if request.DebtID == "missing" {
+		return domain.InstallmentPlan{}, usecase.ErrDebtNotFound
+	}
+
+	return domain.InstallmentPlan{DebtID: request.DebtID, Total: 300}, nil
+}
+
+func (m *MockInstallmentPlanUseCase) Get(debtID string) (domain.InstallmentPlan, error) {
+	if debtID == "missing" {
+		return domain.InstallmentPlan{}, usecase.ErrInstallmentPlanNotFound
+	}
+
+	return domain.InstallmentPlan{DebtID: debtID}, nil
+}
+
+func TestInstallmentPlanHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockInstallmentPlanUseCase{}
+	router := gin.Default()
+	NewInstallmentPlanHandler(mockUseCase).RegisterRoutes(router)
+
+	post := func(debtID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/debts/"+debtID+"/installments", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	t.Run("Create with default interval", func(t *testing.T) {
+		resp := post("d1", `{"installments": 3, "first_due_date": "2025-02-10"}`)
+
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"debt_id":"d1"`)
+		assert.Equal(t, 30, mockUseCase.lastRequest.IntervalDays)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		resp := post("d1", `{"installments": 1, "first_due_date": "2025-02-10"}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Debt not found", func(t *testing.T) {
+		resp := post("missing", `{"installments": 3, "first_due_date": "2025-02-10"}`)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"message":"Debt not found"`)
+	})
+
+	t.Run("Localized message", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/debts/missing/installments", strings.NewReader(`{"installments": 3, "first_due_date": "2025-02-10"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "pt-BR,pt;q=0.9,en;q=0.8")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, "pt-BR", resp.Header().Get("Content-Language"))
+		assert.Contains(t, resp.Body.String(), `"message":"Débito não encontrado"`)
+	})
+
+	t.Run("Get plan", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debts/d1/installments", nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debts/missing/installments", nil))
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+const PaymentSignatureHeader = "X-Webhook-Signature"
+
+// maxPaymentWebhookSize limita o corpo das notificações de pagamento, lido por inteiro
+// antes da conferência da assinatura.
+const maxPaymentWebhookSize = 64 * 1024
+
+type ProcessPaymentUseCaseInterface interface {
+	Process(notification domain.PaymentNotification) (domain.PaymentReceivedEvent, error)
+}
+
+type PaymentWebhookHandler struct {
+	useCase ProcessPaymentUseCaseInterface
+	secret  []byte
+}
+
+func NewPaymentWebhookHandler(useCase ProcessPaymentUseCaseInterface, secret string) *PaymentWebhookHandler {
+	return &PaymentWebhookHandler{useCase: useCase, secret: []byte(secret)}
+}
+
+func (h *PaymentWebhookHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/webhooks/payments", h.Handle)
+}
+
+func (h *PaymentWebhookHandler) Handle(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPaymentWebhookSize))
+
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		c.JSON(http.StatusRequestEntityTooLarge, dto.PaymentWebhookResponse{Message: localize(c, "Payload exceeds maximum size")})
+
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to read webhook body: %v", err)
+		c.JSON(http.StatusBadRequest, dto.PaymentWebhookResponse{Message: localize(c, "Failed to read body")})
+
+		return
+	}
+
+	if !VerifySignature(h.secret, body, c.GetHeader(PaymentSignatureHeader)) {
+		log.Printf("Assinatura inválida no webhook de pagamento")
+		c.JSON(http.StatusUnauthorized, dto.PaymentWebhookResponse{Message: localize(c, "Invalid signature")})
+
+		return
+	}
+
+	var request dto.PaymentWebhookRequest
+	if err := binding.JSON.BindBody(body, &request); err != nil {
+		log.Printf("Failed to parse webhook payload: %v", err)
+		c.JSON(http.StatusBadRequest, dto.PaymentWebhookResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	if request.TxID == "" && request.NossoNumero == "" {
+		c.JSON(http.StatusBadRequest, dto.PaymentWebhookResponse{Message: localize(c, "txid or nosso_numero is required")})
+
+		return
+	}
+
+	event, err := h.useCase.Process(domain.PaymentNotification{
+		Provider:    request.Provider,
+		EventID:     request.EventID,
+		Method:      domain.PaymentMethod(request.Method),
+		TxID:        request.TxID,
+		NossoNumero: request.NossoNumero,
+		Amount:      request.Amount,
+		PaidDate:    request.PaidDate,
+	})
+
+	switch {
+	case errors.Is(err, usecase.ErrDuplicatePaymentEvent):
+		c.JSON(http.StatusOK, dto.PaymentWebhookResponse{Message: localize(c, "Event already processed")})
+	case errors.Is(err, usecase.ErrPaymentInvoiceNotFound):
+		c.JSON(http.StatusUnprocessableEntity, dto.PaymentWebhookResponse{Message: localize(c, "Invoice not found")})
+	case errors.Is(err, domain.ErrInvoiceAlreadyPaid), errors.Is(err, domain.ErrInvoiceRejected):
+		c.JSON(http.StatusConflict, dto.PaymentWebhookResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao processar webhook de pagamento %s: %v", request.EventID, err)
+		c.JSON(http.StatusInternalServerError, dto.PaymentWebhookResponse{Message: localize(c, "Failed to process payment")})
+	default:
+		c.JSON(http.StatusOK, dto.PaymentWebhookResponse{Message: localize(c, "Payment received"), DebtID: event.DebtID})
+	}
+}
+
+// VerifySignature confere o HMAC-SHA256 do corpo da requisição, aceitando o valor
+// em hexadecimal com ou sem o prefixo "sha256=".
+func VerifySignature(secret, body []byte, signature string) bool {
+	if len(secret) == 0 || signature == "" {
+		return false
+	}
+
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	return hmac.Equal(received, mac.Sum(nil))
+}
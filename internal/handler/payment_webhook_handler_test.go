@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/config"
+)
+
+const testWebhookSecret = "segredo-de-teste"
+
+type recordingProducer struct {
+	mu       sync.Mutex
+	messages map[string][]byte
+}
+
+func (p *recordingProducer) Produce(key string, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages[key] = value
+
+	return nil
+}
+
+// fakePaymentProvider simula um PSP enviando notificações assinadas para o webhook.
+type fakePaymentProvider struct {
+	name   string
+	secret string
+	url    string
+}
+
+func (p fakePaymentProvider) notify(t *testing.T, payload map[string]any) *http.Response {
+	payload["provider"] = p.name
+	body, err := json.Marshal(payload)
+	assert.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, p.url+"/webhooks/payments", bytes.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(PaymentSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	return resp
+}
+
+func TestPaymentWebhookHandler_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	invoices := persistence.NewInvoiceRepository()
+	assert.NoError(t, invoices.Save(domain.Invoice{
+		Debt:        domain.Debt{DebtID: "abc123"},
+		NossoNumero: "00012345678",
+		PixTxID:     "KNStx1",
+		Amount:      100.50,
+		Status:      domain.InvoiceStatusRegistered,
+	}))
+	producer := &recordingProducer{messages: make(map[string][]byte)}
+	useCase := usecase.NewProcessPaymentUseCase(invoices, persistence.NewPaymentEventRepository(), producer, &config.Clients{}, domain.NewBusinessCalendar(),
+		usecase.NewWebhookPublisher(persistence.NewWebhookSubscriptionRepository(), persistence.NewWebhookDeliveryRepository()))
+
+	router := gin.Default()
+	NewPaymentWebhookHandler(useCase, testWebhookSecret).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	provider := fakePaymentProvider{name: "fake-psp", secret: testWebhookSecret, url: server.URL}
+
+	t.Run("Pix settlement", func(t *testing.T) {
+		resp := provider.notify(t, map[string]any{
+			"event_id": "evt-1", "method": "pix", "txid": "KNStx1", "amount": 100.50, "paid_date": "2025-01-10",
+		})
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		invoice, _ := invoices.FindByDebtID("abc123")
+		assert.Equal(t, domain.InvoiceStatusPaid, invoice.Status)
+		assert.Contains(t, string(producer.messages["abc123"]), `"event_id":"evt-1"`)
+	})
+
+	t.Run("Redelivered event is idempotent", func(t *testing.T) {
+		resp := provider.notify(t, map[string]any{
+			"event_id": "evt-1", "method": "pix", "txid": "KNStx1", "amount": 100.50, "paid_date": "2025-01-10",
+		})
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Unknown nosso número", func(t *testing.T) {
+		resp := provider.notify(t, map[string]any{
+			"event_id": "evt-2", "method": "boleto", "nosso_numero": "999", "amount": 10, "paid_date": "2025-01-10",
+		})
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		forger := fakePaymentProvider{name: "fake-psp", secret: "outro-segredo", url: server.URL}
+		resp := forger.notify(t, map[string]any{
+			"event_id": "evt-3", "method": "pix", "txid": "KNStx1", "amount": 100.50, "paid_date": "2025-01-10",
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		resp := provider.notify(t, map[string]any{"event_id": "evt-4", "method": "cheque"})
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Payload above the size limit", func(t *testing.T) {
+		resp := provider.notify(t, map[string]any{
+			"event_id": "evt-5", "method": "pix", "txid": strings.Repeat("x", maxPaymentWebhookSize), "amount": 100.50, "paid_date": "2025-01-10",
+		})
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.Contains(t, string(body), "Payload exceeds maximum size")
+	})
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("segredo")
+	body := []byte(`{"event_id":"1"}`)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, VerifySignature(secret, body, signature))
+	assert.True(t, VerifySignature(secret, body, "sha256="+signature))
+	assert.False(t, VerifySignature(secret, []byte(`{"event_id":"2"}`), signature))
+	assert.False(t, VerifySignature(nil, body, signature))
+	assert.False(t, VerifySignature(secret, body, "nao-hex"))
+}
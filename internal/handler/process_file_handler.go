@@ -1,125 +1,535 @@
 package handler
 
 import (
-	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
 	"kanastra-api/internal/handler/dto"
+	"kanastra-api/internal/infra/adapter/archive"
+	"kanastra-api/internal/infra/adapter/objectstore"
 )
 
-type ProcessFileUseCaseInterface interface {
-	ProcessFileAsync(file io.Reader, fileName string) int
+// maxFieldSize limita os campos de texto do formulário, como o mapeamento de colunas.
+const maxFieldSize = 64 * 1024
+
+var (
+	errFieldTooLarge    = errors.New("campo do formulário acima do limite")
+	errFieldAfterFiles  = errors.New("campo do formulário enviado depois dos arquivos")
+	errInvalidMultipart = errors.New("formulário multipart inválido")
+	errObjectTooLarge   = errors.New("objeto acima do tamanho máximo")
+)
+
+type FileIntakeUseCaseInterface interface {
+	Options(fields map[string]string) (domain.FileOptions, error)
+	RandomAccess(fileName string) bool
+	Stream(content io.Reader, fileName string, options domain.FileOptions) ([]usecase.FileJob, error)
+	Prepare(file io.ReaderAt, fileName string, size int64, options domain.FileOptions) ([]usecase.FileJob, func(), error)
 }
 
-type ProcessFileHandler struct {
-	useCase ProcessFileUseCaseInterface
+// ObjectStore lê e grava arquivos em um bucket compatível com S3.
+type ObjectStore interface {
+	Get(bucket, key string) (io.ReadCloser, int64, error)
+	Put(bucket, key string, content io.ReadSeeker, size int64) error
+}
+
+// ObjectStorage é o serviço de objetos de onde /process-files/from-url baixa os arquivos e
+// o bucket em que os arquivos enviados são arquivados para auditoria, sob o ID do envio.
+// Sem Store, os dois ficam desativados; sem ArchiveBucket, apenas o arquivamento. Os
+// arquivos só são baixados dos SourceBuckets, e nunca do bucket de arquivamento.
+type ObjectStorage struct {
+	Store         ObjectStore
+	SourceBuckets []string
+	ArchiveBucket string
+	ArchivePrefix string
 }
 
-var expectedHeadersFile = []string{"name", "governmentId", "email", "debtAmount", "debtDueDate", "debtId"}
+// allowsSource indica se /process-files/from-url pode baixar arquivos do bucket.
+func (s ObjectStorage) allowsSource(bucket string) bool {
+	if bucket == s.ArchiveBucket {
+		return false
+	}
+
+	return slices.Contains(s.SourceBuckets, bucket)
+}
 
-func NewProcessFileHandler(useCase ProcessFileUseCaseInterface) *ProcessFileHandler {
-	return &ProcessFileHandler{useCase: useCase}
+// ProcessFileHandler recebe os arquivos de débitos pela API e os entrega ao
+// processamento; maxUploadSize limita o corpo da requisição, com todos os arquivos
+// enviados, e os objetos baixados do bucket.
+type ProcessFileHandler struct {
+	files         FileIntakeUseCaseInterface
+	maxUploadSize int64
+	storage       ObjectStorage
+}
+
+func NewProcessFileHandler(files FileIntakeUseCaseInterface, maxUploadSize int64, storage ObjectStorage) *ProcessFileHandler {
+	return &ProcessFileHandler{files: files, maxUploadSize: maxUploadSize, storage: storage}
 }
 
 func (h *ProcessFileHandler) RegisterRoutes(router *gin.Engine) {
 	router.POST("/process-files", h.Handle)
+	router.POST("/process-files/from-url", h.HandleObject)
 }
 
+// Handle lê o formulário à medida que chega, sem c.MultipartForm, que grava o formulário
+// inteiro antes de devolvê-lo. Os CSVs são processados enquanto são recebidos; as planilhas
+// e os zips, que precisam de acesso aleatório, são gravados em arquivos temporários do
+// próprio handler e processados em background. Os campos de texto devem vir antes dos
+// arquivos, já que as opções de cada arquivo são definidas quando ele começa a chegar.
 func (h *ProcessFileHandler) Handle(c *gin.Context) {
-	form, err := c.MultipartForm()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+
+	reader, err := c.Request.MultipartReader()
 	if err != nil {
 		log.Printf("Failed to parse multipart form: %v", err)
 		c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{
-			Message: "Failed to parse form",
+			Message: localize(c, "Failed to parse form"),
 		})
 
 		return
 	}
 
-	files := form.File["files"]
-	if len(files) == 0 {
-		log.Printf("No files provided")
-		c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{
-			Message: "No files provided",
-		})
+	fields := map[string]string{}
+	var options *domain.FileOptions
+	var jobs []dto.FileJobResponse
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
 
-		return
-	}
+		if err != nil {
+			h.uploadFailed(c, fmt.Errorf("%w: %w", errInvalidMultipart, err), jobs)
+
+			return
+		}
 
-	for _, fileHeader := range files {
-		go func(fileHeader *multipart.FileHeader) {
-			file, err := fileHeader.Open()
+		if part.FileName() == "" {
+			if options != nil {
+				h.uploadFailed(c, fmt.Errorf("%w: %s", errFieldAfterFiles, part.FormName()), jobs)
+
+				return
+			}
+
+			value, err := readField(part)
 			if err != nil {
-				log.Printf("Failed to open file: %v", err)
+				h.uploadFailed(c, err, jobs)
 
 				return
 			}
+			fields[part.FormName()] = value
 
-			defer func(file multipart.File) {
-				err := file.Close()
-				if err != nil {
+			continue
+		}
 
-				}
-			}(file)
+		if part.FormName() != "files" {
+			continue
+		}
 
-			err = IsValidCSV(fileHeader.Filename, file, expectedHeadersFile)
+		if options == nil {
+			parsed, message, err := h.fileOptions(fields)
 			if err != nil {
-				log.Printf("Arquivo CSV inválido: %v", err)
+				log.Printf("%s: %v", message, err)
+				c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{
+					Message: localize(c, message),
+				})
+
+				return
 			}
+			options = &parsed
+		}
 
-			totalLines := h.useCase.ProcessFileAsync(file, fileHeader.Filename)
-			log.Printf("Arquivo %s processado: Total de linhas: %d", fileHeader.Filename, totalLines)
-		}(fileHeader)
+		fileJobs, err := h.receive(part, *options)
+		jobs = append(jobs, fileJobs...)
+		if err != nil {
+			log.Printf("Failed to receive file %s: %v", part.FileName(), err)
+			h.uploadFailed(c, err, jobs)
+
+			return
+		}
+	}
+
+	if len(jobs) == 0 {
+		log.Printf("No files provided")
+		c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{
+			Message: localize(c, "No files provided"),
+		})
+
+		return
 	}
 
 	c.JSON(http.StatusAccepted, dto.ProcessFilesResponse{
-		Message: "Files are being processed",
+		Message: localize(c, "Files are being processed"),
+		Jobs:    jobs,
 	})
 }
 
-func IsValidCSV(fileName string, file io.Reader, expectedHeaders []string) error {
-	if !strings.HasSuffix(strings.ToLower(fileName), ".csv") {
-		return errors.New("arquivo não é um CSV")
+// HandleObject processa um arquivo já publicado em um bucket, sem que ele passe pela API.
+// O objeto é baixado para um arquivo temporário e segue o processamento dos arquivos
+// gravados em disco; o nome do arquivo, que define seu formato, é o fim da chave.
+func (h *ProcessFileHandler) HandleObject(c *gin.Context) {
+	if h.storage.Store == nil {
+		c.JSON(http.StatusServiceUnavailable, dto.ProcessFilesResponse{
+			Message: localize(c, "Object storage not configured"),
+		})
+
+		return
+	}
+
+	var request dto.ProcessObjectRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Failed to parse object request: %v", err)
+		c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	bucket, key, err := objectLocation(request)
+	if err != nil {
+		log.Printf("Invalid object location: %v", err)
+		c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{Message: localize(c, "Invalid object location")})
+
+		return
 	}
 
-	reader := csv.NewReader(file)
+	if !h.storage.allowsSource(bucket) {
+		log.Printf("Bucket %s não permitido para processamento", bucket)
+		c.JSON(http.StatusForbidden, dto.ProcessFilesResponse{Message: localize(c, "Bucket not allowed")})
+
+		return
+	}
 
-	headers, err := reader.Read()
+	options, message, err := h.fileOptions(optionFields(request.FileOptionsRequest))
 	if err != nil {
-		if err == io.EOF {
-			return errors.New("arquivo CSV está vazio")
-		}
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusBadRequest, dto.ProcessFilesResponse{Message: localize(c, message)})
 
-		return errors.New("erro ao ler o cabeçalho do CSV")
+		return
+	}
+
+	file, size, err := h.download(bucket, key)
+	if err != nil {
+		log.Printf("Erro ao baixar s3://%s/%s: %v", bucket, key, err)
+		status, message := objectError(err)
+		c.JSON(status, dto.ProcessFilesResponse{Message: localize(c, message)})
+
+		return
+	}
+
+	jobs, task, err := h.files.Prepare(file, path.Base(key), size, options)
+	if err != nil {
+		removeTemp(file)
+		h.uploadFailed(c, err, nil)
+
+		return
 	}
 
-	if len(expectedHeaders) > 0 {
-		if !compareHeaders(headers, expectedHeaders) {
-			return errors.New("cabeçalho do CSV é inválido ou não corresponde ao esperado")
+	go func() {
+		defer removeTemp(file)
+		task()
+	}()
+
+	c.JSON(http.StatusAccepted, dto.ProcessFilesResponse{
+		Message: localize(c, "Files are being processed"),
+		Jobs:    fileJobs(jobs),
+	})
+}
+
+// objectLocation lê o bucket e a chave do objeto, informados pela URL s3:// ou pelos
+// campos bucket e key.
+func objectLocation(request dto.ProcessObjectRequest) (string, string, error) {
+	bucket, key := request.Bucket, request.Key
+	if request.URL != "" {
+		if bucket != "" || key != "" {
+			return "", "", errors.New("informe a URL ou o bucket e a chave, não ambos")
+		}
+
+		location, err := url.Parse(request.URL)
+		if err != nil {
+			return "", "", err
+		}
+
+		if location.Scheme != "s3" {
+			return "", "", fmt.Errorf("esquema %q não suportado, use s3://bucket/chave", location.Scheme)
 		}
+		bucket, key = location.Host, strings.TrimPrefix(location.Path, "/")
 	}
 
-	log.Printf("Arquivo CSV %s validado com sucesso", fileName)
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", errors.New("bucket e chave do objeto são obrigatórios")
+	}
+
+	if !objectstore.ValidBucketName(bucket) {
+		return "", "", fmt.Errorf("%w: %q", objectstore.ErrInvalidBucket, bucket)
+	}
 
-	return nil
+	return bucket, key, nil
 }
 
-func compareHeaders(actual, expected []string) bool {
-	if len(actual) != len(expected) {
-		return false
+// download copia o objeto para um arquivo temporário, respeitando o tamanho máximo dos
+// uploads, e devolve o arquivo posicionado no início.
+func (h *ProcessFileHandler) download(bucket, key string) (*os.File, int64, error) {
+	content, size, err := h.storage.Store.Get(bucket, key)
+	if err != nil {
+		return nil, 0, err
 	}
-	for i := range actual {
-		if strings.TrimSpace(strings.ToLower(actual[i])) != strings.TrimSpace(strings.ToLower(expected[i])) {
-			return false
-		}
+	defer content.Close()
+
+	if size > h.maxUploadSize {
+		return nil, 0, fmt.Errorf("%w: %d bytes", errObjectTooLarge, size)
+	}
+
+	file, err := os.CreateTemp("", "object-*"+path.Ext(key))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// O tamanho informado pelo serviço pode faltar; o limite vale também para o que é lido.
+	size, err = io.Copy(file, io.LimitReader(content, h.maxUploadSize+1))
+	if err == nil && size > h.maxUploadSize {
+		err = fmt.Errorf("%w: %d bytes", errObjectTooLarge, size)
+	}
+
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
 	}
 
-	return true
+	if err != nil {
+		removeTemp(file)
+
+		return nil, 0, err
+	}
+
+	return file, size, nil
+}
+
+func objectError(err error) (int, string) {
+	switch {
+	case errors.Is(err, objectstore.ErrObjectNotFound):
+		return http.StatusNotFound, "Object not found"
+	case errors.Is(err, errObjectTooLarge):
+		return http.StatusRequestEntityTooLarge, "Upload exceeds maximum size"
+	default:
+		return http.StatusBadGateway, "Failed to download object"
+	}
+}
+
+// optionFields converte as opções enviadas por JSON nos campos do formulário de
+// /process-files.
+func optionFields(request dto.FileOptionsRequest) map[string]string {
+	fields := map[string]string{
+		"clientId":  request.ClientID,
+		"sheet":     request.Sheet,
+		"delimiter": request.Delimiter,
+		"encoding":  request.Encoding,
+	}
+	if len(request.Columns) > 0 && string(request.Columns) != "null" {
+		fields["columns"] = string(request.Columns)
+	}
+
+	return fields
+}
+
+// fileOptions lê as opções dos arquivos dos campos recebidos, devolvendo a mensagem da
+// resposta quando algum campo é inválido.
+func (h *ProcessFileHandler) fileOptions(fields map[string]string) (domain.FileOptions, string, error) {
+	options, err := h.files.Options(fields)
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidColumnMapping):
+		return options, "Invalid column mapping", err
+	case errors.Is(err, usecase.ErrInvalidFileFormat):
+		return options, "Invalid file format", err
+	case err != nil:
+		return options, "Failed to process files", err
+	}
+
+	return options, "", nil
+}
+
+func fileJobs(jobs []usecase.FileJob) []dto.FileJobResponse {
+	responses := make([]dto.FileJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, dto.FileJobResponse{FileName: job.FileName, JobID: job.JobID})
+	}
+
+	return responses
+}
+
+func readField(part *multipart.Part) (string, error) {
+	value, err := io.ReadAll(io.LimitReader(part, maxFieldSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errInvalidMultipart, err)
+	}
+
+	if len(value) > maxFieldSize {
+		return "", fmt.Errorf("%w: %s", errFieldTooLarge, part.FormName())
+	}
+
+	return string(value), nil
+}
+
+// uploadFailed responde com o erro e os envios dos arquivos já recebidos, que continuam
+// sendo processados.
+func (h *ProcessFileHandler) uploadFailed(c *gin.Context, err error, jobs []dto.FileJobResponse) {
+	log.Printf("Falha no upload: %v", err)
+	status, message := uploadError(err)
+	c.JSON(status, dto.ProcessFilesResponse{
+		Message: localize(c, message),
+		Jobs:    jobs,
+	})
+}
+
+func uploadError(err error) (int, string) {
+	var maxBytes *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytes):
+		return http.StatusRequestEntityTooLarge, "Upload exceeds maximum size"
+	case errors.Is(err, archive.ErrTooManyEntries), errors.Is(err, archive.ErrDecompressedLimit):
+		return http.StatusRequestEntityTooLarge, "Archive exceeds limits"
+	case errors.Is(err, archive.ErrInvalidArchive):
+		return http.StatusBadRequest, "Invalid archive"
+	case errors.Is(err, errFieldAfterFiles):
+		return http.StatusBadRequest, "Form fields must precede files"
+	case errors.Is(err, errInvalidMultipart), errors.Is(err, errFieldTooLarge):
+		return http.StatusBadRequest, "Failed to parse form"
+	default:
+		return http.StatusInternalServerError, "Failed to process files"
+	}
+}
+
+// receive registra os envios do arquivo e o processa. CSVs, compactados ou não, são lidos
+// direto da requisição; planilhas e zips são gravados em disco antes.
+func (h *ProcessFileHandler) receive(part *multipart.Part, options domain.FileOptions) ([]dto.FileJobResponse, error) {
+	fileName := part.FileName()
+	if h.files.RandomAccess(fileName) {
+		return h.spool(part, options)
+	}
+
+	if !h.archiving() {
+		return h.stream(part, fileName, options)
+	}
+
+	// Para o arquivamento, o CSV é copiado para o disco enquanto é processado e enviado ao
+	// bucket depois de recebido por inteiro.
+	original, err := os.CreateTemp("", "upload-*"+filepath.Ext(fileName))
+	if err != nil {
+		return nil, err
+	}
+
+	content := io.TeeReader(part, original)
+	jobs, err := h.stream(content, fileName, options)
+	if err == nil {
+		// Lê o que o processamento deixou, como dados depois do fim do gzip.
+		_, err = io.Copy(io.Discard, content)
+	}
+
+	if err != nil {
+		removeTemp(original)
+
+		return jobs, err
+	}
+
+	go h.archive(original, fileName, jobs)
+
+	return jobs, nil
+}
+
+// stream registra o envio de um CSV, compactado ou não, e o processa à medida que é lido.
+func (h *ProcessFileHandler) stream(content io.Reader, fileName string, options domain.FileOptions) ([]dto.FileJobResponse, error) {
+	jobs, err := h.files.Stream(content, fileName, options)
+
+	return fileJobs(jobs), err
+}
+
+// spool grava o arquivo em um temporário, que pertence ao handler, e não ao formulário da
+// requisição, para continuar disponível depois da resposta.
+func (h *ProcessFileHandler) spool(part *multipart.Part, options domain.FileOptions) ([]dto.FileJobResponse, error) {
+	fileName := part.FileName()
+
+	file, err := os.CreateTemp("", "upload-*"+filepath.Ext(fileName))
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := io.Copy(file, part)
+	if err != nil {
+		removeTemp(file)
+
+		return nil, err
+	}
+
+	return h.processStored(file, fileName, size, options)
+}
+
+// processStored registra os envios de um arquivo enviado e gravado em disco e o processa
+// em background, arquivando-o e removendo-o ao fim. O arquivo é removido também quando o
+// registro falha.
+func (h *ProcessFileHandler) processStored(file *os.File, fileName string, size int64, options domain.FileOptions) ([]dto.FileJobResponse, error) {
+	prepared, task, err := h.files.Prepare(file, fileName, size, options)
+	if err != nil {
+		removeTemp(file)
+
+		return nil, err
+	}
+
+	jobs := fileJobs(prepared)
+	go func() {
+		task()
+		h.archive(file, fileName, jobs)
+	}()
+
+	return jobs, nil
+}
+
+func (h *ProcessFileHandler) archiving() bool {
+	return h.storage.Store != nil && h.storage.ArchiveBucket != ""
+}
+
+// archive grava o arquivo original no bucket de arquivamento, sob o ID do seu primeiro
+// envio, e o remove do disco. Uma falha no arquivamento não afeta o processamento.
+func (h *ProcessFileHandler) archive(file *os.File, fileName string, jobs []dto.FileJobResponse) {
+	defer removeTemp(file)
+
+	if !h.archiving() || len(jobs) == 0 {
+		return
+	}
+
+	key := path.Join(h.storage.ArchivePrefix, jobs[0].JobID, path.Base(filepath.ToSlash(fileName)))
+
+	info, err := file.Stat()
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+
+	if err == nil {
+		err = h.storage.Store.Put(h.storage.ArchiveBucket, key, file, info.Size())
+	}
+
+	if err != nil {
+		log.Printf("Erro ao arquivar %s em s3://%s/%s: %v", fileName, h.storage.ArchiveBucket, key, err)
+
+		return
+	}
+
+	log.Printf("Arquivo %s arquivado em s3://%s/%s", fileName, h.storage.ArchiveBucket, key)
+}
+
+func removeTemp(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
 }
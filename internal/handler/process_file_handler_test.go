@@ -1,21 +1,62 @@
 package handler
 
 import (
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/archive"
+	"kanastra-api/internal/infra/adapter/fileformat"
+	"kanastra-api/internal/infra/adapter/objectstore"
 )
 
-type MockUseCase struct{}
+// MockUseCase repassa as linhas lidas das planilhas para records, as opções dos arquivos
+// CSV para options, o conteúdo dos arquivos CSV para contents e os erros dos envios
+// encerrados para failures, quando informados.
+type MockUseCase struct {
+	records  chan [][]string
+	options  chan domain.FileOptions
+	contents chan string
+	failures chan error
+}
+
+const testMaxUploadSize = 4 << 20
+
+// fileIntake entrega os arquivos a useCase pelo processamento real dos formatos, com os
+// limites de descompactação dos testes.
+func fileIntake(useCase *MockUseCase, mappings usecase.ColumnMappingProvider) *usecase.FileIntakeUseCase {
+	decoder := fileformat.NewDecoder(archive.Limits{MaxDecompressedSize: 1 << 20, MaxEntries: 5})
+
+	return usecase.NewFileIntakeUseCase(useCase, mappings, decoder)
+}
+
+func (m *MockUseCase) StartJob(fileName, source string, _ domain.FileOptions) (domain.IngestionJob, error) {
+	return domain.IngestionJob{ID: "job_" + fileName, Source: source, FileName: fileName}, nil
+}
+
+func (m *MockUseCase) ProcessFileAsync(file io.Reader, fileName string, options domain.FileOptions) int {
+	if m.options != nil {
+		m.options <- options
+	}
+
+	if m.contents != nil {
+		content, _ := io.ReadAll(file)
+		m.contents <- fileName + ":" + string(content)
+	}
 
-func (m *MockUseCase) ProcessFileAsync(_ io.Reader, fileName string) int {
 	if fileName == "error.csv" {
 		return 0
 	}
@@ -23,11 +64,81 @@ func (m *MockUseCase) ProcessFileAsync(_ io.Reader, fileName string) int {
 	return 100
 }
 
+func (m *MockUseCase) FailJob(_ domain.FileOptions, err error) {
+	if m.failures != nil {
+		m.failures <- err
+	}
+}
+
+func (m *MockUseCase) ProcessRecords(reader usecase.RecordReader, _ string, _ domain.FileOptions) int {
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break
+		}
+
+		rows = append(rows, row)
+	}
+
+	if m.records != nil {
+		m.records <- rows
+	}
+
+	return len(rows) - 1
+}
+
+// buildXLSX monta uma planilha mínima com uma aba por nome, com as células como texto.
+func buildXLSX(t *testing.T, sheets map[string][][]string, order ...string) []byte {
+	t.Helper()
+
+	var workbook, rels strings.Builder
+	parts := map[string]string{}
+	for i, name := range order {
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+
+		var data strings.Builder
+		for _, row := range sheets[name] {
+			data.WriteString("<row>")
+			for _, cell := range row {
+				fmt.Fprintf(&data, `<c t="inlineStr"><is><t>%s</t></is></c>`, cell)
+			}
+			data.WriteString("</row>")
+		}
+		parts[fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)] = "<worksheet><sheetData>" + data.String() + "</sheetData></worksheet>"
+	}
+
+	parts["xl/workbook.xml"] = `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + workbook.String() + `</sheets></workbook>`
+	parts["xl/_rels/workbook.xml.rels"] = "<Relationships>" + rels.String() + "</Relationships>"
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range parts {
+		part, err := writer.Create(name)
+		assert.NoError(t, err)
+		_, err = io.WriteString(part, content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+// clientColumns devolve o mesmo mapeamento de colunas para qualquer cliente.
+type clientColumns domain.ColumnMapping
+
+func (c clientColumns) ColumnMapping(string) domain.ColumnMapping {
+	return domain.ColumnMapping(c)
+}
+
+var xlsxHeader = []string{"name", "governmentId", "email", "debtAmount", "debtDueDate", "debtId"}
+
 func TestProcessFileHandler_Handle(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	mockUseCase := &MockUseCase{}
-	processFileHandler := NewProcessFileHandler(mockUseCase)
+	mockUseCase := &MockUseCase{records: make(chan [][]string, 1)}
+	processFileHandler := NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{})
 
 	router := gin.Default()
 	processFileHandler.RegisterRoutes(router)
@@ -56,6 +167,33 @@ func TestProcessFileHandler_Handle(t *testing.T) {
 		assert.Contains(t, resp.Body.String(), "Files are being processed")
 	})
 
+	t.Run("Success with named XLSX sheet", func(t *testing.T) {
+		content := buildXLSX(t, map[string][][]string{
+			"Resumo":  {{"Total", "1"}},
+			"Débitos": {xlsxHeader, {"John Doe", "1234567890", "john@example.com", "1000.50", "2025-01-01", "abc123"}},
+		}, "Resumo", "Débitos")
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		assert.NoError(t, writer.WriteField("sheet", "Débitos"))
+		part, err := writer.CreateFormFile("files", "debts.XLSX")
+		assert.NoError(t, err)
+		_, err = part.Write(content)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		rows := <-mockUseCase.records
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "abc123", rows[1][5])
+	})
+
 	t.Run("Fail to parse multipart form", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/process-files", nil)
 		req.Header.Set("Content-Type", "application/json")
@@ -110,35 +248,450 @@ func TestProcessFileHandler_Handle(t *testing.T) {
 	})
 }
 
-func TestIsValidCSV(t *testing.T) {
-	t.Run("Valid CSV", func(t *testing.T) {
-		fileContent := `name,governmentId,email,debtAmount,debtDueDate,debtId
-John Doe,1234567890,john@example.com,1000.50,2025-01-01,abc123`
-		r := strings.NewReader(fileContent)
-		err := IsValidCSV("valid.csv", r, expectedHeadersFile)
+func TestProcessFileHandler_ColumnMapping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockUseCase{options: make(chan domain.FileOptions, 1)}
+	clientMapping := clientColumns{
+		domain.DebtFieldDebtID:      {Headers: []string{"contrato"}},
+		domain.DebtFieldDebtDueDate: {Headers: []string{"vence em"}},
+	}
+	router := gin.Default()
+	NewProcessFileHandler(fileIntake(mockUseCase, clientMapping), testMaxUploadSize, ObjectStorage{}).RegisterRoutes(router)
 
+	upload := func(columns string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		assert.NoError(t, writer.WriteField("columns", columns))
+		part, err := writer.CreateFormFile("files", "debts.csv")
+		assert.NoError(t, err)
+		_, err = io.WriteString(part, "contrato,cpf,nome,email,valor,vencimento\nabc123,1234567890,John Doe,john@example.com,1000.50,2025-01-01")
 		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	t.Run("Mapeamento do envio sobre o do cliente", func(t *testing.T) {
+		resp := upload(`{"debtDueDate": "vencimento", "debtAmount": 5}`)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, domain.ColumnMapping{
+			domain.DebtFieldDebtID:      {Headers: []string{"contrato"}},
+			domain.DebtFieldDebtDueDate: {Headers: []string{"vencimento"}},
+			domain.DebtFieldDebtAmount:  {Position: 5},
+		}, (<-mockUseCase.options).Columns)
+	})
+
+	t.Run("Envio de cada arquivo na resposta", func(t *testing.T) {
+		resp := upload(`{"debtDueDate": "vencimento"}`)
+		<-mockUseCase.options
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"jobs":[{"file_name":"debts.csv","job_id":"job_debts.csv"}]`)
 	})
 
-	t.Run("Invalid CSV extension", func(t *testing.T) {
-		r := strings.NewReader(``)
-		err := IsValidCSV("invalid.txt", r, expectedHeadersFile)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "arquivo não é um CSV")
+	t.Run("Mapeamento inválido", func(t *testing.T) {
+		resp := upload(`{"cpf": "documento"}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Invalid column mapping")
+	})
+}
+
+func TestProcessFileHandler_FileFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockUseCase{options: make(chan domain.FileOptions, 1)}
+	router := gin.Default()
+	NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{}).RegisterRoutes(router)
+
+	upload := func(content string, fields map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for name, value := range fields {
+			assert.NoError(t, writer.WriteField(name, value))
+		}
+		part, err := writer.CreateFormFile("files", "excel.csv")
+		assert.NoError(t, err)
+		_, err = io.WriteString(part, content)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	t.Run("Exportação do Excel detectada", func(t *testing.T) {
+		resp := upload("\xef\xbb\xbfnome;cpf;email;valor;vencimento;id\r\nJoão;1234;joao@example.com;100.50;2025-01-01;d1\r\n", nil)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		options := <-mockUseCase.options
+		assert.Equal(t, domain.FileFormat{Delimiter: ";", Encoding: "utf-8", BOM: true}, options.Format)
+		assert.Equal(t, "job_excel.csv", options.JobID)
 	})
 
-	t.Run("Empty CSV file", func(t *testing.T) {
-		r := strings.NewReader(``)
-		err := IsValidCSV("empty.csv", r, expectedHeadersFile)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "arquivo CSV está vazio")
+	t.Run("Separador e codificação informados", func(t *testing.T) {
+		resp := upload("nome|cpf\n", map[string]string{"delimiter": "tab", "encoding": "latin1"})
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, domain.FileFormat{Delimiter: "\t", Encoding: "iso-8859-1"}, (<-mockUseCase.options).Format)
 	})
 
-	t.Run("Invalid headers", func(t *testing.T) {
-		fileContent := `wrong,header,format\n`
-		r := strings.NewReader(fileContent)
-		err := IsValidCSV("invalid.csv", r, expectedHeadersFile)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "cabeçalho do CSV é inválido ou não corresponde ao esperado")
+	t.Run("Codificação desconhecida", func(t *testing.T) {
+		resp := upload("nome\n", map[string]string{"encoding": "ebcdic"})
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Invalid file format")
 	})
 }
+
+func TestProcessFileHandler_CompressedUploads(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockUseCase{contents: make(chan string, 3), failures: make(chan error, 1)}
+	router := gin.Default()
+	NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{}).RegisterRoutes(router)
+
+	upload := func(fileName string, content []byte) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("files", fileName)
+		assert.NoError(t, err)
+		_, err = part.Write(content)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	buildZip := func(entries map[string]string) []byte {
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		for _, name := range []string{"janeiro.csv", "leia-me.txt", "__MACOSX/._janeiro.csv", "fevereiro.CSV"} {
+			content, ok := entries[name]
+			if !ok {
+				continue
+			}
+
+			part, err := writer.Create(name)
+			assert.NoError(t, err)
+			_, err = io.WriteString(part, content)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, writer.Close())
+
+		return buf.Bytes()
+	}
+
+	t.Run("CSV com gzip", func(t *testing.T) {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err := io.WriteString(writer, "name,governmentId\nJohn Doe,1234\n")
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		resp := upload("debts.csv.gz", compressed.Bytes())
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"job_id":"job_debts.csv.gz"`)
+		assert.Equal(t, "debts.csv.gz:name,governmentId\nJohn Doe,1234\n", <-mockUseCase.contents)
+	})
+
+	t.Run("Um envio por CSV do zip", func(t *testing.T) {
+		resp := upload("lote.zip", buildZip(map[string]string{
+			"janeiro.csv":            "name\njaneiro\n",
+			"leia-me.txt":            "ignorado",
+			"__MACOSX/._janeiro.csv": "ignorado",
+			"fevereiro.CSV":          "name\nfevereiro\n",
+		}))
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"job_id":"job_lote.zip/janeiro.csv"`)
+		assert.Contains(t, resp.Body.String(), `"job_id":"job_lote.zip/fevereiro.CSV"`)
+		assert.Equal(t, "lote.zip/janeiro.csv:name\njaneiro\n", <-mockUseCase.contents)
+		assert.Equal(t, "lote.zip/fevereiro.CSV:name\nfevereiro\n", <-mockUseCase.contents)
+	})
+
+	t.Run("Zip acima do limite descompactado", func(t *testing.T) {
+		resp := upload("bomba.zip", buildZip(map[string]string{"janeiro.csv": strings.Repeat("0", 2<<20)}))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Archive exceeds limits")
+	})
+
+	t.Run("Planilha acima do limite descompactado", func(t *testing.T) {
+		content := buildXLSX(t, map[string][][]string{"Plan1": {xlsxHeader, {strings.Repeat("a", 2<<20)}}}, "Plan1")
+
+		resp := upload("bomba.xlsx", content)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"job_id":"job_bomba.xlsx"`)
+		assert.ErrorIs(t, <-mockUseCase.failures, archive.ErrDecompressedLimit)
+	})
+
+	t.Run("Zip inválido", func(t *testing.T) {
+		resp := upload("quebrado.zip", []byte("não é um zip"))
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Invalid archive")
+	})
+}
+
+func TestProcessFileHandler_Streaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockUseCase{contents: make(chan string, 2)}
+	router := gin.Default()
+	NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{}).RegisterRoutes(router)
+
+	t.Run("CSV processado antes do fim da requisição", func(t *testing.T) {
+		body, bodyWriter := io.Pipe()
+		writer := multipart.NewWriter(bodyWriter)
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			router.ServeHTTP(resp, req)
+			close(done)
+		}()
+
+		part, err := writer.CreateFormFile("files", "primeiro.csv")
+		assert.NoError(t, err)
+		_, err = io.WriteString(part, "name\nprimeiro\n")
+		assert.NoError(t, err)
+
+		// O segundo arquivo só começa depois que o primeiro foi processado.
+		part, err = writer.CreateFormFile("files", "segundo.csv")
+		assert.NoError(t, err)
+		assert.Equal(t, "primeiro.csv:name\nprimeiro\n", <-mockUseCase.contents)
+
+		_, err = io.WriteString(part, "name\nsegundo\n")
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+		assert.NoError(t, bodyWriter.Close())
+		<-done
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Equal(t, "segundo.csv:name\nsegundo\n", <-mockUseCase.contents)
+		assert.Contains(t, resp.Body.String(), `"job_id":"job_segundo.csv"`)
+	})
+
+	upload := func(build func(writer *multipart.Writer)) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		build(writer)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	t.Run("Upload acima do tamanho máximo", func(t *testing.T) {
+		resp := upload(func(writer *multipart.Writer) {
+			part, err := writer.CreateFormFile("files", "grande.csv")
+			assert.NoError(t, err)
+			_, err = io.WriteString(part, "name\n"+strings.Repeat("0", 5<<20))
+			assert.NoError(t, err)
+		})
+		<-mockUseCase.contents
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Upload exceeds maximum size")
+		assert.Contains(t, resp.Body.String(), `"job_id":"job_grande.csv"`)
+	})
+
+	t.Run("Campo depois dos arquivos", func(t *testing.T) {
+		resp := upload(func(writer *multipart.Writer) {
+			part, err := writer.CreateFormFile("files", "debts.csv")
+			assert.NoError(t, err)
+			_, err = io.WriteString(part, "name\nJohn Doe\n")
+			assert.NoError(t, err)
+			assert.NoError(t, writer.WriteField("clientId", "acme"))
+		})
+		<-mockUseCase.contents
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Form fields must precede files")
+	})
+}
+
+// memoryObjects guarda os objetos em memória, indexados por bucket/chave, e repassa as
+// chaves gravadas para puts.
+type memoryObjects struct {
+	mu      sync.Mutex
+	objects map[string]string
+	puts    chan string
+}
+
+func (m *memoryObjects) Get(bucket, key string) (io.ReadCloser, int64, error) {
+	m.mu.Lock()
+	content, exists := m.objects[bucket+"/"+key]
+	m.mu.Unlock()
+	if !exists {
+		return nil, 0, objectstore.ErrObjectNotFound
+	}
+
+	return io.NopCloser(strings.NewReader(content)), int64(len(content)), nil
+}
+
+func (m *memoryObjects) Put(bucket, key string, content io.ReadSeeker, _ int64) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.objects[bucket+"/"+key] = string(data)
+	m.mu.Unlock()
+	m.puts <- bucket + "/" + key
+
+	return nil
+}
+
+func TestProcessFileHandler_HandleObject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockUseCase{contents: make(chan string, 1)}
+	objects := &memoryObjects{objects: map[string]string{
+		"remessas/2025/03/remessa.csv": "name\nJohn Doe\n",
+		"remessas/grande.csv":          strings.Repeat("x", int(testMaxUploadSize)+1),
+	}}
+	router := gin.Default()
+	NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{
+		Store:         objects,
+		SourceBuckets: []string{"remessas", "arquivo"},
+		ArchiveBucket: "arquivo",
+	}).RegisterRoutes(router)
+
+	request := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process-files/from-url", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	t.Run("URL s3", func(t *testing.T) {
+		resp := request(`{"url": "s3://remessas/2025/03/remessa.csv", "client_id": "acme"}`)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Contains(t, resp.Body.String(), "job_remessa.csv")
+		assert.Equal(t, "remessa.csv:name\nJohn Doe\n", <-mockUseCase.contents)
+	})
+
+	t.Run("Bucket e chave", func(t *testing.T) {
+		resp := request(`{"bucket": "remessas", "key": "2025/03/remessa.csv"}`)
+
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		<-mockUseCase.contents
+	})
+
+	t.Run("Objeto inexistente", func(t *testing.T) {
+		resp := request(`{"bucket": "remessas", "key": "ausente.csv"}`)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Object not found")
+	})
+
+	t.Run("Objeto acima do tamanho máximo", func(t *testing.T) {
+		resp := request(`{"url": "s3://remessas/grande.csv"}`)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+	})
+
+	t.Run("Localização inválida", func(t *testing.T) {
+		for _, body := range []string{
+			`{"url": "https://remessas/remessa.csv"}`,
+			`{"bucket": "remessas"}`,
+			`{"url": "s3://remessas/remessa.csv", "key": "outra.csv"}`,
+			`{"bucket": "evil.com#", "key": "remessa.csv"}`,
+		} {
+			resp := request(body)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+			assert.Contains(t, resp.Body.String(), "Invalid object location")
+		}
+	})
+
+	t.Run("Bucket não permitido", func(t *testing.T) {
+		for _, body := range []string{`{"url": "s3://outro/remessa.csv"}`, `{"bucket": "arquivo", "key": "uploads/job_1/remessa.csv"}`} {
+			resp := request(body)
+
+			assert.Equal(t, http.StatusForbidden, resp.Code, body)
+			assert.Contains(t, resp.Body.String(), "Bucket not allowed")
+		}
+	})
+
+	t.Run("Sem armazenamento de objetos", func(t *testing.T) {
+		router := gin.Default()
+		NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{}).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodPost, "/process-files/from-url", strings.NewReader(`{"url": "s3://remessas/remessa.csv"}`))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	})
+}
+
+func TestProcessFileHandler_Archive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUseCase := &MockUseCase{contents: make(chan string, 2)}
+	objects := &memoryObjects{objects: map[string]string{}, puts: make(chan string, 2)}
+	router := gin.Default()
+	NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{
+		Store:         objects,
+		ArchiveBucket: "auditoria",
+		ArchivePrefix: "uploads",
+	}).RegisterRoutes(router)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := io.WriteString(gz, "name\nJane Doe\n")
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, content := range map[string][]byte{"remessa.csv": []byte("name\nJohn Doe\n"), "remessa.csv.gz": compressed.Bytes()} {
+		part, err := writer.CreateFormFile("files", name)
+		assert.NoError(t, err)
+		_, err = part.Write(content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/process-files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	archived := []string{<-objects.puts, <-objects.puts}
+	assert.ElementsMatch(t, []string{"auditoria/uploads/job_remessa.csv/remessa.csv", "auditoria/uploads/job_remessa.csv.gz/remessa.csv.gz"}, archived)
+	assert.Equal(t, "name\nJohn Doe\n", objects.objects["auditoria/uploads/job_remessa.csv/remessa.csv"])
+	assert.Equal(t, compressed.String(), objects.objects["auditoria/uploads/job_remessa.csv.gz/remessa.csv.gz"], "o original é arquivado, sem descompactar")
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/handler/dto"
+)
+
+type ReconcileReturnFileUseCaseInterface interface {
+	Reconcile(file io.Reader, fileName string) (domain.ReconciliationReport, error)
+}
+
+type ReturnFileHandler struct {
+	useCase ReconcileReturnFileUseCaseInterface
+}
+
+func NewReturnFileHandler(useCase ReconcileReturnFileUseCaseInterface) *ReturnFileHandler {
+	return &ReturnFileHandler{useCase: useCase}
+}
+
+func (h *ReturnFileHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/return-files", h.Handle)
+}
+
+func (h *ReturnFileHandler) Handle(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		log.Printf("Failed to parse multipart form: %v", err)
+		c.JSON(http.StatusBadRequest, dto.ReturnFilesResponse{
+			Message: localize(c, "Failed to parse form"),
+		})
+
+		return
+	}
+
+	files := form.File["files"]
+	if len(files) == 0 {
+		log.Printf("No files provided")
+		c.JSON(http.StatusBadRequest, dto.ReturnFilesResponse{
+			Message: localize(c, "No files provided"),
+		})
+
+		return
+	}
+
+	results := make([]dto.ReturnFileResult, 0, len(files))
+	for _, fileHeader := range files {
+		result := dto.ReturnFileResult{FileName: fileHeader.Filename}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("Failed to open file: %v", err)
+			result.Error = "Failed to open file"
+			results = append(results, result)
+
+			continue
+		}
+
+		report, err := h.useCase.Reconcile(file, fileHeader.Filename)
+		_ = file.Close()
+		if err != nil {
+			log.Printf("Erro ao conciliar arquivo de retorno %s: %v", fileHeader.Filename, err)
+			result.Error = err.Error()
+		} else {
+			result.Report = &report
+		}
+
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, dto.ReturnFilesResponse{
+		Message: localize(c, "Return files reconciled"),
+		Results: results,
+	})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type MockReconcileUseCase struct{}
+
+func (m *MockReconcileUseCase) Reconcile(_ io.Reader, fileName string) (domain.ReconciliationReport, error) {
+	if fileName == "error.ret" {
+		return domain.ReconciliationReport{}, errors.New("layout de retorno desconhecido")
+	}
+
+	return domain.ReconciliationReport{FileName: fileName, Layout: "CNAB240", Paid: 1}, nil
+}
+
+func TestReturnFileHandler_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.Default()
+	NewReturnFileHandler(&MockReconcileUseCase{}).RegisterRoutes(router)
+
+	t.Run("Reconciles each file", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, name := range []string{"valid.ret", "error.ret"} {
+			part, err := writer.CreateFormFile("files", name)
+			assert.NoError(t, err)
+			_, err = part.Write([]byte("conteudo"))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/return-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"layout":"CNAB240"`)
+		assert.Contains(t, resp.Body.String(), "layout de retorno desconhecido")
+	})
+
+	t.Run("No files provided", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		assert.NoError(t, writer.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/return-files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "No files provided")
+	})
+}
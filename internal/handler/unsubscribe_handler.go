@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+	"kanastra-api/internal/infra/i18n"
+)
+
+type OptOutUseCaseInterface interface {
+	Verify(token string) (string, string, error)
+	Unsubscribe(token string, source domain.OptOutSource) (domain.OptOut, error)
+	Resubscribe(governmentID, email string) error
+	List(governmentID string) []domain.OptOut
+}
+
+// unsubscribePage é a página exibida ao devedor que abre o link de descadastro. A
+// confirmação é feita por POST para que a pré-visualização de links pelos provedores de
+// e-mail não descadastre o devedor.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+  <meta charset="UTF-8">
+  <title>{{.Title}}</title>
+</head>
+<body style="font-family: Arial, sans-serif; color: #222;">
+  <h1>{{.Title}}</h1>
+  <p>{{.Message}}</p>
+  {{- if .Token}}
+  <form method="post" action="?token={{.Token}}">
+    <button type="submit">{{.Confirm}}</button>
+  </form>
+  {{- end}}
+</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Lang    string
+	Title   string
+	Message string
+	Confirm string
+	Token   string
+}
+
+type UnsubscribeHandler struct {
+	useCase OptOutUseCaseInterface
+}
+
+func NewUnsubscribeHandler(useCase OptOutUseCaseInterface) *UnsubscribeHandler {
+	return &UnsubscribeHandler{useCase: useCase}
+}
+
+func (h *UnsubscribeHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/unsubscribe", h.Confirm)
+	router.POST("/unsubscribe", h.Unsubscribe)
+	router.GET("/debtors/:governmentId/opt-outs", h.List)
+	router.DELETE("/debtors/:governmentId/opt-outs", h.Resubscribe)
+}
+
+// Confirm exibe a confirmação do descadastro aberto pelo link do e-mail.
+func (h *UnsubscribeHandler) Confirm(c *gin.Context) {
+	locale := requestLocale(c, i18n.Default)
+	token := c.Query("token")
+
+	_, email, err := h.useCase.Verify(token)
+	if err != nil {
+		h.render(c, http.StatusBadRequest, locale, locale.API("Invalid unsubscribe link"), "")
+
+		return
+	}
+
+	message := fmt.Sprintf(locale.API("Stop receiving payment reminders at %s? Notices of newly issued bills will still be sent."), email)
+	h.render(c, http.StatusOK, locale, message, token)
+}
+
+// Unsubscribe registra o descadastro, tanto pelo formulário de confirmação quanto pelo
+// descadastro com um clique dos provedores de e-mail (RFC 8058).
+func (h *UnsubscribeHandler) Unsubscribe(c *gin.Context) {
+	locale := requestLocale(c, i18n.Default)
+
+	source := domain.OptOutSourceLink
+	if c.PostForm("List-Unsubscribe") == "One-Click" {
+		source = domain.OptOutSourceOneClick
+	}
+
+	optOut, err := h.useCase.Unsubscribe(c.Query("token"), source)
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidUnsubscribeToken):
+		h.render(c, http.StatusBadRequest, locale, locale.API("Invalid unsubscribe link"), "")
+	case err != nil:
+		log.Printf("Erro ao registrar descadastro: %v", err)
+		h.render(c, http.StatusInternalServerError, locale, locale.API("Failed to unsubscribe"), "")
+	default:
+		message := fmt.Sprintf(locale.API("You will no longer receive payment reminders at %s. Notices of newly issued bills will still be sent."), optOut.Email)
+		h.render(c, http.StatusOK, locale, message, "")
+	}
+}
+
+func (h *UnsubscribeHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.OptOutsResponse{
+		Message: localize(c, "Opt-outs found"),
+		OptOuts: h.useCase.List(c.Param("governmentId")),
+	})
+}
+
+func (h *UnsubscribeHandler) Resubscribe(c *gin.Context) {
+	email := strings.TrimSpace(c.Query("email"))
+	if email == "" {
+		c.JSON(http.StatusBadRequest, dto.OptOutsResponse{Message: localize(c, "email is required")})
+
+		return
+	}
+
+	if err := h.useCase.Resubscribe(c.Param("governmentId"), email); err != nil {
+		log.Printf("Erro ao remover descadastro do devedor %s: %v", c.Param("governmentId"), err)
+		c.JSON(http.StatusInternalServerError, dto.OptOutsResponse{Message: localize(c, "Failed to remove opt-out")})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.OptOutsResponse{Message: localize(c, "Opt-out removed")})
+}
+
+func (h *UnsubscribeHandler) render(c *gin.Context, status int, locale i18n.Locale, message, token string) {
+	var page bytes.Buffer
+	err := unsubscribePage.Execute(&page, unsubscribePageData{
+		Lang:    string(locale),
+		Title:   locale.API("Stop payment reminders"),
+		Message: message,
+		Confirm: locale.API("Confirm"),
+		Token:   token,
+	})
+	if err != nil {
+		log.Printf("Erro ao renderizar página de descadastro: %v", err)
+		c.Status(http.StatusInternalServerError)
+
+		return
+	}
+
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
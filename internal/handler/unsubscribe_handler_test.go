@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+func TestUnsubscribeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	optOuts := persistence.NewOptOutRepository()
+	useCase := usecase.NewOptOutUseCase(optOuts, "segredo", "https://cobranca.example.com/unsubscribe")
+	router := gin.Default()
+	NewUnsubscribeHandler(useCase).RegisterRoutes(router)
+
+	link, err := url.Parse(useCase.UnsubscribeURL("12345678901", "joao@example.com"))
+	assert.NoError(t, err)
+	path := "/unsubscribe?" + link.RawQuery
+
+	t.Run("Link opens the confirmation without unsubscribing", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Deseja parar de receber lembretes de cobrança em joao@example.com?")
+		assert.Contains(t, resp.Body.String(), `<form method="post"`)
+		assert.False(t, optOuts.IsOptedOut("12345678901", "joao@example.com"))
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/unsubscribe?token=forjado", nil))
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Link de descadastro inválido")
+	})
+
+	t.Run("One-click unsubscribe", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("List-Unsubscribe=One-Click"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept-Language", "en-US")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "You will no longer receive payment reminders at joao@example.com.")
+		assert.True(t, optOuts.IsOptedOut("12345678901", "joao@example.com"))
+	})
+
+	t.Run("List and remove opt-outs", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debtors/12345678901/opt-outs", nil))
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"Source":"one_click"`)
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/debtors/12345678901/opt-outs", nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/debtors/12345678901/opt-outs?email=JOAO@example.com", nil))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.False(t, optOuts.IsOptedOut("12345678901", "joao@example.com"))
+	})
+}
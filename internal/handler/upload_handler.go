@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+type ResumableUploadUseCaseInterface interface {
+	Initiate(fileName, checksum string, options map[string]string) (domain.Upload, error)
+	Find(id string) (domain.Upload, error)
+	UploadPart(id string, number int, content io.Reader) (domain.UploadPart, error)
+	Complete(id string) (domain.Upload, string, error)
+	Reopen(id string) (domain.Upload, error)
+	Finish(id string, jobIDs []string) (domain.Upload, error)
+}
+
+// UploadHandler recebe arquivos em partes para os links instáveis, em que um upload único
+// de vários GB falha no meio. Concluído o upload, o arquivo segue o mesmo processamento
+// dos arquivos enviados a /process-files.
+type UploadHandler struct {
+	useCase ResumableUploadUseCaseInterface
+	files   *ProcessFileHandler
+}
+
+func NewUploadHandler(useCase ResumableUploadUseCaseInterface, files *ProcessFileHandler) *UploadHandler {
+	return &UploadHandler{useCase: useCase, files: files}
+}
+
+func (h *UploadHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/uploads", h.Initiate)
+	router.GET("/uploads/:uploadId", h.Find)
+	router.PUT("/uploads/:uploadId/parts/:partNumber", h.UploadPart)
+	router.POST("/uploads/:uploadId/complete", h.Complete)
+}
+
+func (h *UploadHandler) Initiate(c *gin.Context) {
+	var request dto.InitiateUploadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Failed to parse upload request: %v", err)
+		c.JSON(http.StatusBadRequest, dto.UploadResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	options := optionFields(request.FileOptionsRequest)
+
+	// As opções são conferidas já no início, para que o erro não apareça só depois do
+	// envio de todas as partes.
+	if _, message, err := h.files.fileOptions(options); err != nil {
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusBadRequest, dto.UploadResponse{Message: localize(c, message)})
+
+		return
+	}
+
+	upload, err := h.useCase.Initiate(request.FileName, request.Checksum, options)
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidUpload):
+		c.JSON(http.StatusBadRequest, dto.UploadResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao iniciar upload de %s: %v", request.FileName, err)
+		c.JSON(http.StatusInternalServerError, dto.UploadResponse{Message: localize(c, "Failed to store upload")})
+	default:
+		c.JSON(http.StatusCreated, dto.UploadResponse{Message: localize(c, "Upload created"), Upload: &upload})
+	}
+}
+
+func (h *UploadHandler) Find(c *gin.Context) {
+	upload, err := h.useCase.Find(c.Param("uploadId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.UploadResponse{Message: localizeError(c, err)})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UploadResponse{Message: localize(c, "Upload found"), Upload: &upload})
+}
+
+// UploadPart recebe o conteúdo da parte no corpo da requisição. Reenviar uma parte
+// substitui a anterior, e uma parte interrompida não substitui a que já estava gravada.
+func (h *UploadHandler) UploadPart(c *gin.Context) {
+	number, err := strconv.Atoi(c.Param("partNumber"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.UploadResponse{Message: localizeError(c, usecase.ErrInvalidUploadPart)})
+
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.files.maxUploadSize)
+	part, err := h.useCase.UploadPart(c.Param("uploadId"), number, body)
+
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.Is(err, usecase.ErrUploadAbsent):
+		c.JSON(http.StatusNotFound, dto.UploadResponse{Message: localizeError(c, err)})
+	case errors.Is(err, usecase.ErrInvalidUploadPart):
+		c.JSON(http.StatusBadRequest, dto.UploadResponse{Message: localizeError(c, err)})
+	case errors.Is(err, usecase.ErrUploadCompleted), errors.Is(err, usecase.ErrUploadCompleting):
+		c.JSON(http.StatusConflict, dto.UploadResponse{Message: localizeError(c, err)})
+	case errors.Is(err, usecase.ErrUploadTooLarge), errors.As(err, &maxBytes):
+		c.JSON(http.StatusRequestEntityTooLarge, dto.UploadResponse{Message: localize(c, "Upload exceeds maximum size")})
+	case err != nil:
+		log.Printf("Erro ao gravar parte %d do upload %s: %v", number, c.Param("uploadId"), err)
+		c.JSON(http.StatusInternalServerError, dto.UploadResponse{Message: localize(c, "Failed to store upload")})
+	default:
+		c.JSON(http.StatusOK, dto.UploadResponse{Message: localize(c, "Upload part stored"), Part: &part})
+	}
+}
+
+// Complete monta o arquivo, confere o checksum e o processa como um arquivo enviado a
+// /process-files, respondendo com os envios criados. O upload só é concluído depois que o
+// arquivo é entregue ao processamento; se a entrega falha, ele é reaberto e a conclusão
+// pode ser repetida.
+func (h *UploadHandler) Complete(c *gin.Context) {
+	upload, path, err := h.useCase.Complete(c.Param("uploadId"))
+
+	switch {
+	case errors.Is(err, usecase.ErrUploadAbsent):
+		c.JSON(http.StatusNotFound, dto.UploadResponse{Message: localizeError(c, err)})
+
+		return
+	case errors.Is(err, usecase.ErrUploadCompleted), errors.Is(err, usecase.ErrUploadCompleting), errors.Is(err, usecase.ErrMissingUploadParts):
+		c.JSON(http.StatusConflict, dto.UploadResponse{Message: localizeError(c, err), Upload: &upload})
+
+		return
+	case errors.Is(err, usecase.ErrUploadChecksumMismatch):
+		log.Printf("Upload %s corrompido: %v", upload.ID, err)
+		c.JSON(http.StatusUnprocessableEntity, dto.UploadResponse{Message: localizeError(c, err), Upload: &upload})
+
+		return
+	case err != nil:
+		log.Printf("Erro ao concluir upload %s: %v", c.Param("uploadId"), err)
+		c.JSON(http.StatusInternalServerError, dto.UploadResponse{Message: localize(c, "Failed to process files")})
+
+		return
+	}
+
+	jobs, err := h.process(upload, path)
+	if err != nil {
+		log.Printf("Erro ao processar upload %s: %v", upload.ID, err)
+		if reopened, reopenErr := h.useCase.Reopen(upload.ID); reopenErr != nil {
+			log.Printf("Erro ao reabrir upload %s: %v", upload.ID, reopenErr)
+		} else {
+			upload = reopened
+		}
+
+		status, message := uploadError(err)
+		c.JSON(status, dto.UploadResponse{Message: localize(c, message), Upload: &upload})
+
+		return
+	}
+
+	jobIDs := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		jobIDs = append(jobIDs, job.JobID)
+	}
+
+	if finished, err := h.useCase.Finish(upload.ID, jobIDs); err != nil {
+		log.Printf("Erro ao concluir upload %s: %v", upload.ID, err)
+	} else {
+		upload = finished
+	}
+
+	c.JSON(http.StatusAccepted, dto.UploadResponse{
+		Message: localize(c, "Files are being processed"),
+		Upload:  &upload,
+		Jobs:    jobs,
+	})
+}
+
+// process entrega o arquivo montado ao processamento em background, que o remove ao fim.
+func (h *UploadHandler) process(upload domain.Upload, path string) ([]dto.FileJobResponse, error) {
+	options, _, err := h.files.fileOptions(upload.Options)
+	if err != nil {
+		os.Remove(path)
+
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		os.Remove(path)
+
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+
+		return nil, err
+	}
+
+	return h.files.processStored(file, upload.FileName, info.Size(), options)
+}
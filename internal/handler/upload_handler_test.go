@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+func TestUploadHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := persistence.NewUploadStore(t.TempDir())
+	require.NoError(t, err)
+
+	mockUseCase := &MockUseCase{contents: make(chan string, 1), options: make(chan domain.FileOptions, 1)}
+	files := NewProcessFileHandler(fileIntake(mockUseCase, clientColumns{}), testMaxUploadSize, ObjectStorage{})
+	router := gin.Default()
+	NewUploadHandler(usecase.NewResumableUploadUseCase(store, testMaxUploadSize, time.Hour), files).RegisterRoutes(router)
+
+	send := func(method, path, body string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var decoded map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &decoded))
+
+		return resp, decoded
+	}
+
+	content := "nome;cpf\nJoão;1234\n"
+	sum := sha256.Sum256([]byte(content))
+
+	initiate := func(checksum string) string {
+		resp, body := send(http.MethodPost, "/uploads", fmt.Sprintf(`{"file_name": "remessa.csv", "checksum": %q, "client_id": "acme", "delimiter": ";"}`, checksum))
+		require.Equal(t, http.StatusCreated, resp.Code)
+
+		var upload domain.Upload
+		require.NoError(t, json.Unmarshal(body["upload"], &upload))
+
+		return upload.ID
+	}
+
+	t.Run("Partes fora de ordem, com reenvio, processadas na conclusão", func(t *testing.T) {
+		id := initiate(hex.EncodeToString(sum[:]))
+
+		resp, _ := send(http.MethodPut, "/uploads/"+id+"/parts/2", content[10:])
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp, _ = send(http.MethodPost, "/uploads/"+id+"/complete", "")
+		assert.Equal(t, http.StatusConflict, resp.Code, "a parte 1 ainda não chegou")
+
+		send(http.MethodPut, "/uploads/"+id+"/parts/1", "corrompido")
+		resp, _ = send(http.MethodPut, "/uploads/"+id+"/parts/1", content[:10])
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp, body := send(http.MethodGet, "/uploads/"+id, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, string(body["upload"]), `"Number":1`)
+		assert.Contains(t, string(body["upload"]), `"Number":2`)
+
+		resp, body = send(http.MethodPost, "/uploads/"+id+"/complete", "")
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.JSONEq(t, `[{"file_name":"remessa.csv","job_id":"job_remessa.csv"}]`, string(body["jobs"]))
+		assert.Contains(t, string(body["upload"]), `"JobIDs":["job_remessa.csv"]`)
+
+		options := <-mockUseCase.options
+		assert.Equal(t, "acme", options.ClientID)
+		assert.Equal(t, ";", options.Format.Delimiter)
+		assert.Equal(t, "remessa.csv:"+content, <-mockUseCase.contents)
+
+		resp, _ = send(http.MethodPost, "/uploads/"+id+"/complete", "")
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Checksum diferente", func(t *testing.T) {
+		id := initiate(strings.Repeat("0", 64))
+		send(http.MethodPut, "/uploads/"+id+"/parts/1", content)
+
+		resp, body := send(http.MethodPost, "/uploads/"+id+"/complete", "")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Contains(t, string(body["message"]), "Upload checksum mismatch")
+	})
+
+	t.Run("Falha na entrega ao processamento reabre o upload", func(t *testing.T) {
+		broken := "não é um zip"
+		brokenSum := sha256.Sum256([]byte(broken))
+		resp, body := send(http.MethodPost, "/uploads", fmt.Sprintf(`{"file_name": "lote.zip", "checksum": %q}`, hex.EncodeToString(brokenSum[:])))
+		require.Equal(t, http.StatusCreated, resp.Code)
+
+		var upload domain.Upload
+		require.NoError(t, json.Unmarshal(body["upload"], &upload))
+		send(http.MethodPut, "/uploads/"+upload.ID+"/parts/1", broken)
+
+		resp, body = send(http.MethodPost, "/uploads/"+upload.ID+"/complete", "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, string(body["upload"]), `"Status":"uploading"`)
+
+		resp, _ = send(http.MethodPost, "/uploads/"+upload.ID+"/complete", "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "a conclusão pode ser repetida com as partes mantidas")
+	})
+
+	t.Run("Opções inválidas recusadas no início", func(t *testing.T) {
+		resp, body := send(http.MethodPost, "/uploads", `{"file_name": "remessa.csv", "checksum": "`+strings.Repeat("0", 64)+`", "encoding": "ebcdic"}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, string(body["message"]), "Invalid file format")
+	})
+
+	t.Run("Upload inexistente", func(t *testing.T) {
+		resp, _ := send(http.MethodPut, "/uploads/upl_ffffffffffffffff/parts/1", content)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+)
+
+type WebhookSubscriptionUseCaseInterface interface {
+	Subscribe(clientID, endpoint string, events []domain.WebhookEventType) (domain.WebhookSubscription, error)
+	List(clientID string) []domain.WebhookSubscription
+	Unsubscribe(clientID, subscriptionID string) error
+	Deliveries(clientID, subscriptionID string) ([]domain.WebhookDelivery, error)
+	Redeliver(clientID, subscriptionID, deliveryID string) (domain.WebhookDelivery, error)
+}
+
+type WebhookSubscriptionHandler struct {
+	useCase WebhookSubscriptionUseCaseInterface
+}
+
+func NewWebhookSubscriptionHandler(useCase WebhookSubscriptionUseCaseInterface) *WebhookSubscriptionHandler {
+	return &WebhookSubscriptionHandler{useCase: useCase}
+}
+
+func (h *WebhookSubscriptionHandler) RegisterRoutes(router *gin.Engine) {
+	router.POST("/clients/:clientId/webhooks", h.Subscribe)
+	router.GET("/clients/:clientId/webhooks", h.List)
+	router.DELETE("/clients/:clientId/webhooks/:webhookId", h.Unsubscribe)
+	router.GET("/clients/:clientId/webhooks/:webhookId/deliveries", h.Deliveries)
+	router.POST("/clients/:clientId/webhooks/:webhookId/deliveries/:deliveryId/redeliver", h.Redeliver)
+}
+
+func (h *WebhookSubscriptionHandler) Subscribe(c *gin.Context) {
+	var request dto.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("Failed to parse webhook subscription request: %v", err)
+		c.JSON(http.StatusBadRequest, dto.WebhookSubscriptionResponse{Message: localize(c, "Invalid payload")})
+
+		return
+	}
+
+	events := make([]domain.WebhookEventType, 0, len(request.Events))
+	for _, event := range request.Events {
+		events = append(events, domain.WebhookEventType(event))
+	}
+
+	subscription, err := h.useCase.Subscribe(c.Param("clientId"), request.URL, events)
+
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWebhookURL), errors.Is(err, usecase.ErrInvalidWebhookEvent):
+		c.JSON(http.StatusBadRequest, dto.WebhookSubscriptionResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao criar webhook do cliente %s: %v", c.Param("clientId"), err)
+		c.JSON(http.StatusInternalServerError, dto.WebhookSubscriptionResponse{Message: localize(c, "Failed to create webhook")})
+	default:
+		c.JSON(http.StatusCreated, dto.WebhookSubscriptionResponse{
+			Message:      localize(c, "Webhook created"),
+			Subscription: &subscription,
+			Secret:       subscription.Secret,
+		})
+	}
+}
+
+func (h *WebhookSubscriptionHandler) List(c *gin.Context) {
+	subscriptions := h.useCase.List(c.Param("clientId"))
+	if subscriptions == nil {
+		subscriptions = []domain.WebhookSubscription{}
+	}
+
+	c.JSON(http.StatusOK, dto.WebhookSubscriptionsResponse{Message: localize(c, "Webhooks found"), Subscriptions: subscriptions})
+}
+
+func (h *WebhookSubscriptionHandler) Unsubscribe(c *gin.Context) {
+	err := h.useCase.Unsubscribe(c.Param("clientId"), c.Param("webhookId"))
+
+	switch {
+	case errors.Is(err, usecase.ErrWebhookSubscriptionMissing):
+		c.JSON(http.StatusNotFound, dto.WebhookSubscriptionResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao remover webhook %s: %v", c.Param("webhookId"), err)
+		c.JSON(http.StatusInternalServerError, dto.WebhookSubscriptionResponse{Message: localize(c, "Failed to remove webhook")})
+	default:
+		c.JSON(http.StatusOK, dto.WebhookSubscriptionResponse{Message: localize(c, "Webhook removed")})
+	}
+}
+
+func (h *WebhookSubscriptionHandler) Deliveries(c *gin.Context) {
+	deliveries, err := h.useCase.Deliveries(c.Param("clientId"), c.Param("webhookId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.WebhookDeliveriesResponse{Message: localizeError(c, err)})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.WebhookDeliveriesResponse{Message: localize(c, "Webhook deliveries found"), Deliveries: deliveries})
+}
+
+func (h *WebhookSubscriptionHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.useCase.Redeliver(c.Param("clientId"), c.Param("webhookId"), c.Param("deliveryId"))
+
+	switch {
+	case errors.Is(err, usecase.ErrWebhookSubscriptionMissing), errors.Is(err, usecase.ErrWebhookDeliveryMissing):
+		c.JSON(http.StatusNotFound, dto.WebhookDeliveryResponse{Message: localizeError(c, err)})
+	case err != nil:
+		log.Printf("Erro ao agendar reenvio do webhook %s: %v", c.Param("deliveryId"), err)
+		c.JSON(http.StatusInternalServerError, dto.WebhookDeliveryResponse{Message: localize(c, "Failed to schedule redelivery")})
+	default:
+		c.JSON(http.StatusAccepted, dto.WebhookDeliveryResponse{Message: localize(c, "Webhook delivery scheduled"), Delivery: &delivery})
+	}
+}
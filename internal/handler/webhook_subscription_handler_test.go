@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/handler/dto"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+func TestWebhookSubscriptionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	subscriptions := persistence.NewWebhookSubscriptionRepository()
+	deliveries := persistence.NewWebhookDeliveryRepository()
+	router := gin.Default()
+	NewWebhookSubscriptionHandler(usecase.NewWebhookSubscriptionUseCase(subscriptions, deliveries)).RegisterRoutes(router)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	resp := request(http.MethodPost, "/clients/acme/webhooks", `{"url":"https://acme.example.com/hooks","events":["debt.paid"]}`)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var created dto.WebhookSubscriptionResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, []domain.WebhookEventType{domain.WebhookEventDebtPaid}, created.Subscription.Events)
+	webhookPath := "/clients/acme/webhooks/" + created.Subscription.ID
+
+	t.Run("Segredo não aparece na listagem", func(t *testing.T) {
+		resp := request(http.MethodGet, "/clients/acme/webhooks", "")
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), created.Subscription.ID)
+		assert.NotContains(t, resp.Body.String(), created.Secret)
+	})
+
+	t.Run("Evento desconhecido", func(t *testing.T) {
+		resp := request(http.MethodPost, "/clients/acme/webhooks", `{"url":"https://acme.example.com/hooks","events":["debt.created"]}`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "Invalid webhook event type")
+	})
+
+	t.Run("Reenvio de uma entrega", func(t *testing.T) {
+		publisher := usecase.NewWebhookPublisher(subscriptions, deliveries)
+		event, err := domain.NewWebhookEvent("debt.paid:d1:1", domain.WebhookEventDebtPaid, "acme", domain.WebhookDebtData{DebtID: "d1"}, time.Now())
+		assert.NoError(t, err)
+		publisher.Publish(event)
+
+		resp := request(http.MethodGet, webhookPath+"/deliveries", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), "debt.paid:d1:1@"+created.Subscription.ID)
+
+		resp = request(http.MethodPost, webhookPath+"/deliveries/debt.paid:d1:1@"+created.Subscription.ID+"/redeliver", "")
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+
+		resp = request(http.MethodPost, webhookPath+"/deliveries/desconhecida/redeliver", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Inscrição de outro cliente", func(t *testing.T) {
+		resp := request(http.MethodDelete, "/clients/other/webhooks/"+created.Subscription.ID, "")
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Remoção", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodDelete, webhookPath, "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, webhookPath+"/deliveries", "").Code)
+	})
+}
@@ -0,0 +1,142 @@
+package archive
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	ErrInvalidArchive    = errors.New("arquivo compactado inválido")
+	ErrTooManyEntries    = errors.New("arquivo zip com entradas demais")
+	ErrDecompressedLimit = errors.New("tamanho descompactado acima do limite")
+)
+
+// Limits protege o processamento contra arquivos compactados maliciosos (zip bombs).
+// MaxDecompressedSize vale para o total descompactado de cada upload, somando as entradas
+// de um zip, e MaxEntries para o número de entradas de um zip, incluindo as ignoradas.
+type Limits struct {
+	MaxDecompressedSize int64
+	MaxEntries          int
+}
+
+// OpenGzip descompacta file à medida que é lido, falhando com ErrDecompressedLimit ao
+// passar do limite.
+func OpenGzip(file io.Reader, limits Limits) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	budget := limits.MaxDecompressedSize
+
+	return &limitedReadCloser{reader: reader, closer: reader, budget: &budget}, nil
+}
+
+// LimitDecompressed limita a leitura de reader ao que resta de budget, que pode ser
+// compartilhado pelas entradas de um mesmo pacote, como as de uma planilha XLSX.
+func LimitDecompressed(reader io.ReadCloser, budget *int64) io.ReadCloser {
+	return &limitedReadCloser{reader: reader, closer: reader, budget: budget}
+}
+
+// Entry é um arquivo CSV dentro de um zip.
+type Entry struct {
+	Name string
+	file *zip.File
+	zip  *Zip
+}
+
+// Open descompacta a entrada à medida que é lida. As entradas de um mesmo zip dividem o
+// limite de tamanho descompactado e devem ser lidas uma de cada vez.
+func (e Entry) Open() (io.ReadCloser, error) {
+	reader, err := e.file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	return &limitedReadCloser{reader: reader, closer: reader, budget: &e.zip.budget}, nil
+}
+
+// Zip é um arquivo zip aberto sem descompactar suas entradas.
+type Zip struct {
+	Entries []Entry
+	budget  int64
+}
+
+// OpenZip lê o diretório do zip e devolve suas entradas CSV, ignorando pastas e os
+// metadados gravados pelo macOS. O tamanho declarado das entradas é conferido aqui, e o
+// tamanho real durante a leitura, já que o declarado pode ser falso.
+func OpenZip(file io.ReaderAt, size int64, limits Limits) (*Zip, error) {
+	reader, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	if len(reader.File) > limits.MaxEntries {
+		return nil, fmt.Errorf("%w: %d entradas, limite de %d", ErrTooManyEntries, len(reader.File), limits.MaxEntries)
+	}
+
+	archive := &Zip{budget: limits.MaxDecompressedSize}
+
+	var declared uint64
+	for _, file := range reader.File {
+		if !isCSVEntry(file) {
+			continue
+		}
+
+		declared += file.UncompressedSize64
+		archive.Entries = append(archive.Entries, Entry{Name: file.Name, file: file, zip: archive})
+	}
+
+	if declared > uint64(limits.MaxDecompressedSize) {
+		return nil, fmt.Errorf("%w: %d bytes declarados, limite de %d", ErrDecompressedLimit, declared, limits.MaxDecompressedSize)
+	}
+
+	return archive, nil
+}
+
+func isCSVEntry(file *zip.File) bool {
+	if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") || strings.HasPrefix(path.Base(file.Name), "._") {
+		return false
+	}
+
+	return strings.HasSuffix(strings.ToLower(file.Name), ".csv")
+}
+
+// limitedReadCloser falha com ErrDecompressedLimit quando a leitura passa do que resta de
+// budget.
+type limitedReadCloser struct {
+	reader io.Reader
+	closer io.Closer
+	budget *int64
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	if *r.budget <= 0 {
+		// Confere se ainda há conteúdo antes de acusar o limite, para aceitar arquivos com
+		// exatamente o tamanho máximo.
+		var probe [1]byte
+		if n, err := r.reader.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+
+		return 0, ErrDecompressedLimit
+	}
+
+	if int64(len(p)) > *r.budget {
+		p = p[:*r.budget]
+	}
+
+	n, err := r.reader.Read(p)
+	*r.budget -= int64(n)
+
+	return n, err
+}
+
+func (r *limitedReadCloser) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := io.WriteString(writer, content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func zipped(t *testing.T, entries ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for i := 0; i < len(entries); i += 2 {
+		part, err := writer.Create(entries[i])
+		require.NoError(t, err)
+		_, err = io.WriteString(part, entries[i+1])
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestOpenGzip(t *testing.T) {
+	content := strings.Repeat("a", 1000)
+
+	reader, err := OpenGzip(bytes.NewReader(gzipped(t, content)), Limits{MaxDecompressedSize: 1000})
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(decoded))
+
+	reader, err = OpenGzip(bytes.NewReader(gzipped(t, content)), Limits{MaxDecompressedSize: 999})
+	require.NoError(t, err)
+	_, err = io.ReadAll(reader)
+	assert.ErrorIs(t, err, ErrDecompressedLimit)
+
+	_, err = OpenGzip(strings.NewReader("texto"), Limits{MaxDecompressedSize: 1000})
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
+
+func TestOpenZip(t *testing.T) {
+	limits := Limits{MaxDecompressedSize: 10, MaxEntries: 4}
+
+	t.Run("Entradas CSV", func(t *testing.T) {
+		content := zipped(t, "a.csv", "12345", "docs/", "", "b.txt", "x", "dir/c.CSV", "678")
+
+		archive, err := OpenZip(bytes.NewReader(content), int64(len(content)), limits)
+		require.NoError(t, err)
+		require.Len(t, archive.Entries, 2)
+		assert.Equal(t, "dir/c.CSV", archive.Entries[1].Name)
+
+		reader, err := archive.Entries[1].Open()
+		require.NoError(t, err)
+		defer reader.Close()
+
+		decoded, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, "678", string(decoded))
+	})
+
+	t.Run("Entradas demais", func(t *testing.T) {
+		content := zipped(t, "a.csv", "", "b.csv", "", "c.csv", "", "d.csv", "", "e.csv", "")
+
+		_, err := OpenZip(bytes.NewReader(content), int64(len(content)), limits)
+		assert.ErrorIs(t, err, ErrTooManyEntries)
+	})
+
+	t.Run("Tamanho declarado acima do limite", func(t *testing.T) {
+		content := zipped(t, "a.csv", "123456", "b.csv", "123456")
+
+		_, err := OpenZip(bytes.NewReader(content), int64(len(content)), limits)
+		assert.ErrorIs(t, err, ErrDecompressedLimit)
+	})
+
+	t.Run("Limite dividido entre as entradas", func(t *testing.T) {
+		content := zipped(t, "a.csv", "123456", "b.csv", "1234")
+
+		archive, err := OpenZip(bytes.NewReader(content), int64(len(content)), Limits{MaxDecompressedSize: 10, MaxEntries: 4})
+		require.NoError(t, err)
+
+		// Simula um tamanho declarado falso consumindo o limite antes da segunda entrada.
+		archive.budget = 8
+		first, err := archive.Entries[0].Open()
+		require.NoError(t, err)
+		_, err = io.ReadAll(first)
+		assert.NoError(t, err)
+
+		second, err := archive.Entries[1].Open()
+		require.NoError(t, err)
+		_, err = io.ReadAll(second)
+		assert.ErrorIs(t, err, ErrDecompressedLimit)
+	})
+}
@@ -0,0 +1,158 @@
+package boleto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAmount      = errors.New("valor do boleto inválido")
+	ErrInvalidBeneficiary = errors.New("dados do beneficiário inválidos")
+)
+
+const currencyReal = "9"
+
+// baseDate é a data base do fator de vencimento definida pela FEBRABAN. Ao atingir 9999
+// o fator recomeça em 1000 (a partir de 22/02/2025).
+var baseDate = time.Date(1997, time.October, 7, 0, 0, 0, 0, time.UTC)
+
+// Beneficiary identifica a conta de cobrança que emite os boletos. O campo livre segue o
+// leiaute de agência, carteira, nosso número e conta.
+type Beneficiary struct {
+	Name     string
+	Document string
+	BankCode string
+	Agency   string
+	Wallet   string
+	Account  string
+}
+
+func (b Beneficiary) Validate() error {
+	fields := []struct {
+		value  string
+		length int
+	}{
+		{b.BankCode, 3},
+		{b.Agency, 4},
+		{b.Wallet, 2},
+		{b.Account, 7},
+	}
+
+	for _, field := range fields {
+		if len(field.value) != field.length || !isDigits(field.value) {
+			return ErrInvalidBeneficiary
+		}
+	}
+
+	return nil
+}
+
+// Barcode monta o código de barras de 44 posições do boleto.
+func Barcode(beneficiary Beneficiary, nossoNumero string, amount float64, dueDate string) (string, error) {
+	if err := beneficiary.Validate(); err != nil {
+		return "", err
+	}
+
+	if amount < 0 || amount > 99999999.99 {
+		return "", ErrInvalidAmount
+	}
+
+	factor, err := dueDateFactor(dueDate)
+	if err != nil {
+		return "", err
+	}
+
+	if len(nossoNumero) > 11 || !isDigits(nossoNumero) {
+		return "", fmt.Errorf("nosso número inválido: %s", nossoNumero)
+	}
+
+	freeField := beneficiary.Agency + beneficiary.Wallet + strings.Repeat("0", 11-len(nossoNumero)) + nossoNumero + beneficiary.Account + "0"
+	value := fmt.Sprintf("%04d%010d", factor, int64(amount*100+0.5))
+
+	withoutDV := beneficiary.BankCode + currencyReal + value + freeField
+
+	return withoutDV[:4] + barcodeDV(withoutDV) + withoutDV[4:], nil
+}
+
+// DigitableLine converte o código de barras na linha digitável com os três campos
+// protegidos por módulo 10.
+func DigitableLine(barcode string) (string, error) {
+	if len(barcode) != 44 || !isDigits(barcode) {
+		return "", fmt.Errorf("código de barras inválido: %s", barcode)
+	}
+
+	field1 := barcode[0:4] + barcode[19:24]
+	field2 := barcode[24:34]
+	field3 := barcode[34:44]
+	field1 += mod10(field1)
+	field2 += mod10(field2)
+	field3 += mod10(field3)
+
+	return fmt.Sprintf("%s.%s %s.%s %s.%s %s %s",
+		field1[:5], field1[5:],
+		field2[:5], field2[5:],
+		field3[:5], field3[5:],
+		barcode[4:5],
+		barcode[5:19],
+	), nil
+}
+
+func dueDateFactor(dueDate string) (int, error) {
+	date, err := time.Parse(time.DateOnly, dueDate)
+	if err != nil {
+		return 0, fmt.Errorf("data de vencimento inválida: %w", err)
+	}
+
+	days := int(date.Sub(baseDate).Hours() / 24)
+	if days < 1000 {
+		return 0, fmt.Errorf("data de vencimento fora do intervalo: %s", dueDate)
+	}
+
+	if days > 9999 {
+		days = (days-10000)%9000 + 1000
+	}
+
+	return days, nil
+}
+
+// barcodeDV calcula o dígito verificador geral (módulo 11, pesos de 2 a 9).
+func barcodeDV(digits string) string {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+
+	dv := 11 - sum%11
+	if dv == 0 || dv == 10 || dv == 11 {
+		dv = 1
+	}
+
+	return fmt.Sprintf("%d", dv)
+}
+
+func mod10(digits string) string {
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		product := int(digits[i]-'0') * weight
+		sum += product/10 + product%10
+		weight = 3 - weight
+	}
+
+	return fmt.Sprintf("%d", (10-sum%10)%10)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
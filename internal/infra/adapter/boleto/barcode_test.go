@@ -0,0 +1,130 @@
+package boleto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+var beneficiary = Beneficiary{Name: "Kanastra", BankCode: "237", Agency: "1234", Wallet: "09", Account: "0012345"}
+
+func TestBarcode(t *testing.T) {
+	barcode, err := Barcode(beneficiary, "12345678901", 1234.5, "2025-03-10")
+	require.NoError(t, err)
+
+	assert.Len(t, barcode, 44)
+	assert.Equal(t, "2379", barcode[:4])
+	assert.Equal(t, "1016", barcode[5:9], "fator de vencimento após o reinício em 22/02/2025")
+	assert.Equal(t, "0000123450", barcode[9:19])
+	assert.Equal(t, "1234"+"09"+"12345678901"+"0012345"+"0", barcode[19:])
+	assert.Equal(t, barcodeDV(barcode[:4]+barcode[5:]), barcode[4:5])
+}
+
+func TestBarcode_Invalid(t *testing.T) {
+	_, err := Barcode(Beneficiary{BankCode: "237"}, "1", 10, "2025-03-10")
+	assert.ErrorIs(t, err, ErrInvalidBeneficiary)
+
+	_, err = Barcode(beneficiary, "1", -10, "2025-03-10")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Barcode(beneficiary, "1", 10, "10/03/2025")
+	assert.Error(t, err)
+
+	_, err = Barcode(beneficiary, "ABC", 10, "2025-03-10")
+	assert.Error(t, err)
+}
+
+func TestDueDateFactor(t *testing.T) {
+	tests := map[string]int{
+		"2000-07-03": 1000,
+		"2025-02-21": 9999,
+		"2025-02-22": 1000,
+		"2025-03-10": 1016,
+	}
+
+	for date, expected := range tests {
+		factor, err := dueDateFactor(date)
+		require.NoError(t, err)
+		assert.Equal(t, expected, factor, date)
+	}
+}
+
+func TestDigitableLine(t *testing.T) {
+	barcode, err := Barcode(beneficiary, "12345678901", 1234.5, "2025-03-10")
+	require.NoError(t, err)
+
+	line, err := DigitableLine(barcode)
+	require.NoError(t, err)
+
+	fields := strings.Fields(line)
+	require.Len(t, fields, 5)
+
+	for _, field := range fields[:3] {
+		digits := strings.ReplaceAll(field, ".", "")
+		assert.Equal(t, mod10(digits[:len(digits)-1]), digits[len(digits)-1:], field)
+	}
+
+	assert.Equal(t, "23791.23405", fields[0])
+	assert.Equal(t, barcode[4:5], fields[3])
+	assert.Equal(t, barcode[5:19], fields[4])
+
+	_, err = DigitableLine("123")
+	assert.Error(t, err)
+}
+
+func TestModulo(t *testing.T) {
+	assert.Equal(t, "5", mod10("001905009"))
+	assert.Equal(t, "1", barcodeDV("0000000000000000000000000000000000000000000"))
+}
+
+func TestRenderPDF(t *testing.T) {
+	barcode, err := Barcode(beneficiary, "12345678901", 1234.5, "2025-03-10")
+	require.NoError(t, err)
+	line, _ := DigitableLine(barcode)
+
+	pdf, err := RenderPDF(domain.Invoice{
+		Debt:          domain.Debt{Name: "João (Silva)", DebtID: "abc123"},
+		NossoNumero:   "12345678901",
+		Amount:        1234.5,
+		DueDate:       "2025-03-10",
+		Barcode:       barcode,
+		DigitableLine: line,
+	}, beneficiary)
+	require.NoError(t, err)
+
+	content := string(pdf)
+	assert.True(t, strings.HasPrefix(content, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(content, "%%EOF\n"))
+	assert.Contains(t, content, "(Jo\\343o \\(Silva\\))")
+	assert.Contains(t, content, "("+line+")")
+	assert.Contains(t, content, "(R$ 1.234,50)")
+	assert.Contains(t, content, "(10/03/2025)")
+	assert.NotContains(t, content, "(Multa e juros)")
+
+	t.Run("Boleto reemitido com encargos", func(t *testing.T) {
+		pdf, err := RenderPDF(domain.Invoice{
+			Debt:            domain.Debt{Name: "John Doe", DebtID: "abc123", Locale: "en-US"},
+			NossoNumero:     "12345678901",
+			Amount:          1260.75,
+			DueDate:         "2025-04-01",
+			OriginalDueDate: "2025-03-10",
+			Charges:         &domain.Charges{Principal: 1234.5, Fine: 24.69, Interest: 1.56, Total: 1260.75},
+			Barcode:         barcode,
+			DigitableLine:   line,
+		}, beneficiary)
+		require.NoError(t, err)
+
+		content := string(pdf)
+		assert.Contains(t, content, "(R$ 1.260,75)")
+		assert.Contains(t, content, "(01/04/2025)")
+		assert.Contains(t, content, "(10/03/2025)")
+		assert.Contains(t, content, "(R$ 26,25)")
+	})
+
+	_, err = RenderPDF(domain.Invoice{}, beneficiary)
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package boleto
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/i18n"
+)
+
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 40
+)
+
+// itfPatterns codifica os dígitos no padrão Intercalado 2 de 5 usado pelos boletos
+// (n = barra/espaço estreito, w = largo).
+var itfPatterns = [10]string{
+	"nnwwn", "wnnnw", "nwnnw", "wwnnn", "nnwnw",
+	"wnwnn", "nwwnn", "nnnww", "wnnwn", "nwnwn",
+}
+
+// RenderPDF gera a ficha de compensação do boleto em PDF, com a linha digitável e o
+// código de barras desenhado em vetor. A ficha segue o padrão da FEBRABAN, em português,
+// e por isso valores e datas usam sempre o formato pt-BR, qualquer que seja o idioma do
+// débito.
+func RenderPDF(invoice domain.Invoice, beneficiary Beneficiary) ([]byte, error) {
+	if len(invoice.Barcode) != 44 {
+		return nil, fmt.Errorf("boleto do débito %s sem código de barras", invoice.Debt.DebtID)
+	}
+
+	var content bytes.Buffer
+	y := float64(pageHeight - margin - 20)
+
+	writeText(&content, "F2", 16, margin, y, "Boleto de Cobrança")
+	y -= 30
+	writeText(&content, "F2", 12, margin, y, invoice.DigitableLine)
+	y -= 30
+
+	rows := [][2]string{
+		{"Beneficiário", strings.TrimSpace(beneficiary.Name + " " + beneficiary.Document)},
+		{"Pagador", strings.TrimSpace(invoice.Debt.Name + " " + invoice.Debt.GovernmentID)},
+		{"Nosso número", invoice.NossoNumero},
+		{"Vencimento", i18n.PtBR.FormatDate(invoice.DueDate)},
+		{"Valor do documento", i18n.PtBR.FormatMoney(invoice.Amount)},
+		{"Identificação do débito", invoice.Debt.DebtID},
+	}
+
+	if invoice.Charges != nil {
+		rows = append(rows,
+			[2]string{"Vencimento original", i18n.PtBR.FormatDate(invoice.OriginalDueDate)},
+			[2]string{"Multa e juros", i18n.PtBR.FormatMoney(invoice.Charges.Fine + invoice.Charges.Interest)},
+		)
+	}
+
+	for _, row := range rows {
+		writeText(&content, "F1", 9, margin, y, row[0])
+		writeText(&content, "F2", 11, margin, y-13, row[1])
+		content.WriteString(fmt.Sprintf("%d %.2f m %d %.2f l S\n", margin, y-18, pageWidth-margin, y-18))
+		y -= 32
+	}
+
+	writeBarcode(&content, invoice.Barcode, margin, y-60, 50)
+
+	return buildDocument(content.Bytes()), nil
+}
+
+func writeText(content *bytes.Buffer, font string, size int, x int, y float64, text string) {
+	fmt.Fprintf(content, "BT /%s %d Tf %d %.2f Td (%s) Tj ET\n", font, size, x, y, escapeText(text))
+}
+
+// writeBarcode desenha o código de barras Intercalado 2 de 5: os dígitos são agrupados
+// em pares, o primeiro codificado nas barras e o segundo nos espaços.
+func writeBarcode(content *bytes.Buffer, barcode string, x int, y, height float64) {
+	const narrow, wide = 0.96, 2.88
+
+	position := float64(x)
+	draw := func(pattern string) {
+		for i, width := range pattern {
+			size := narrow
+			if width == 'w' {
+				size = wide
+			}
+
+			if i%2 == 0 {
+				fmt.Fprintf(content, "%.2f %.2f %.2f %.2f re f\n", position, y, size, height)
+			}
+			position += size
+		}
+	}
+
+	draw("nnnn")
+	for i := 0; i+1 < len(barcode); i += 2 {
+		bars := itfPatterns[barcode[i]-'0']
+		spaces := itfPatterns[barcode[i+1]-'0']
+
+		var interleaved strings.Builder
+		for j := 0; j < 5; j++ {
+			interleaved.WriteByte(bars[j])
+			interleaved.WriteByte(spaces[j])
+		}
+		draw(interleaved.String())
+	}
+	draw("wnn")
+}
+
+func buildDocument(content []byte) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pageWidth, pageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return document.Bytes()
+}
+
+// escapeText converte o texto para WinAnsi (Latin-1 cobre os acentos do português) e
+// escapa os caracteres reservados das strings PDF.
+func escapeText(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r < 0x80:
+			escaped.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+
+	return escaped.String()
+}
@@ -0,0 +1,184 @@
+package cnab
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+const (
+	Layout240 = "CNAB240"
+	Layout400 = "CNAB400"
+)
+
+var (
+	ErrEmptyFile     = errors.New("arquivo de retorno está vazio")
+	ErrUnknownLayout = errors.New("layout de retorno desconhecido: esperado CNAB 240 ou 400")
+)
+
+// Códigos de movimento de retorno do padrão FEBRABAN (240) e dos layouts 400 mais comuns.
+var occurrenceKinds240 = map[string]domain.OccurrenceKind{
+	"02": domain.OccurrenceRegistered,
+	"03": domain.OccurrenceRejected,
+	"06": domain.OccurrencePaid,
+	"17": domain.OccurrencePaid,
+	"26": domain.OccurrenceRejected,
+	"30": domain.OccurrenceRejected,
+}
+
+var occurrenceKinds400 = map[string]domain.OccurrenceKind{
+	"02": domain.OccurrenceRegistered,
+	"03": domain.OccurrenceRejected,
+	"06": domain.OccurrencePaid,
+	"15": domain.OccurrencePaid,
+	"17": domain.OccurrencePaid,
+	"24": domain.OccurrenceRejected,
+	"32": domain.OccurrenceRejected,
+}
+
+type Parser struct{}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+func (p *Parser) Parse(file io.Reader) (string, []domain.ReturnOccurrence, error) {
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("erro ao ler arquivo de retorno: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return "", nil, ErrEmptyFile
+	}
+
+	switch len(lines[0]) {
+	case 240:
+		occurrences, err := parse240(lines)
+
+		return Layout240, occurrences, err
+	case 400:
+		occurrences, err := parse400(lines)
+
+		return Layout400, occurrences, err
+	default:
+		return "", nil, ErrUnknownLayout
+	}
+}
+
+func parse240(lines []string) ([]domain.ReturnOccurrence, error) {
+	var occurrences []domain.ReturnOccurrence
+
+	for i, line := range lines {
+		if len(line) != 240 {
+			return nil, fmt.Errorf("linha %d com tamanho inválido para CNAB 240: %d", i+1, len(line))
+		}
+
+		if field(line, 8, 8) != "3" {
+			continue
+		}
+
+		switch field(line, 14, 14) {
+		case "T":
+			code := field(line, 16, 17)
+			occurrences = append(occurrences, domain.ReturnOccurrence{
+				Line:        i + 1,
+				NossoNumero: domain.NormalizeNossoNumero(field(line, 38, 57)),
+				Code:        code,
+				Kind:        kindOf(occurrenceKinds240, code),
+				Amount:      amount(field(line, 82, 96)),
+				Fees:        amount(field(line, 199, 213)),
+				Reason:      strings.TrimSpace(field(line, 214, 223)),
+			})
+		case "U":
+			if len(occurrences) == 0 {
+				return nil, fmt.Errorf("linha %d: segmento U sem segmento T correspondente", i+1)
+			}
+
+			last := &occurrences[len(occurrences)-1]
+			last.PaidAmount = amount(field(line, 78, 92))
+			last.PaidDate = date(field(line, 138, 145), "02012006")
+		}
+	}
+
+	return occurrences, nil
+}
+
+func parse400(lines []string) ([]domain.ReturnOccurrence, error) {
+	var occurrences []domain.ReturnOccurrence
+
+	for i, line := range lines {
+		if len(line) != 400 {
+			return nil, fmt.Errorf("linha %d com tamanho inválido para CNAB 400: %d", i+1, len(line))
+		}
+
+		if field(line, 1, 1) != "1" {
+			continue
+		}
+
+		code := field(line, 109, 110)
+		occurrences = append(occurrences, domain.ReturnOccurrence{
+			Line:        i + 1,
+			NossoNumero: domain.NormalizeNossoNumero(field(line, 71, 81)),
+			Code:        code,
+			Kind:        kindOf(occurrenceKinds400, code),
+			Amount:      amount(field(line, 153, 165)),
+			Fees:        amount(field(line, 176, 188)),
+			PaidAmount:  amount(field(line, 254, 266)),
+			PaidDate:    date(field(line, 111, 116), "020106"),
+			Reason:      strings.TrimSpace(field(line, 319, 328)),
+		})
+	}
+
+	return occurrences, nil
+}
+
+// field retorna o trecho da linha entre as posições informadas, numeradas a partir de 1
+// e inclusivas, como nos manuais de layout dos bancos.
+func field(line string, start, end int) string {
+	return line[start-1 : end]
+}
+
+func kindOf(kinds map[string]domain.OccurrenceKind, code string) domain.OccurrenceKind {
+	if kind, ok := kinds[code]; ok {
+		return kind
+	}
+
+	return domain.OccurrenceOther
+}
+
+func amount(value string) float64 {
+	cents, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return float64(cents) / 100
+}
+
+func date(value, layout string) string {
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Format(time.DateOnly)
+}
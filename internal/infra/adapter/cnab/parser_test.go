@@ -0,0 +1,92 @@
+package cnab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// line monta uma linha de tamanho fixo preenchendo as posições informadas (base 1).
+func line(size int, fields map[int]string) string {
+	buffer := []byte(strings.Repeat(" ", size))
+	for position, value := range fields {
+		copy(buffer[position-1:], value)
+	}
+
+	return string(buffer)
+}
+
+func TestParser_Parse240(t *testing.T) {
+	content := strings.Join([]string{
+		line(240, map[int]string{8: "0"}),
+		line(240, map[int]string{8: "1"}),
+		line(240, map[int]string{8: "3", 14: "T", 16: "06", 38: "00000012345678901   ", 82: "000000000010050", 199: "000000000000250"}),
+		line(240, map[int]string{8: "3", 14: "U", 16: "06", 78: "000000000010050", 138: "15012025"}),
+		line(240, map[int]string{8: "3", 14: "T", 16: "03", 38: "00000000000000000042", 82: "000000000020000", 214: "08"}),
+		line(240, map[int]string{8: "3", 14: "U", 16: "03"}),
+		line(240, map[int]string{8: "5"}),
+		line(240, map[int]string{8: "9"}),
+	}, "\r\n")
+
+	layout, occurrences, err := NewParser().Parse(strings.NewReader(content))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Layout240, layout)
+	assert.Len(t, occurrences, 2)
+
+	assert.Equal(t, "12345678901", occurrences[0].NossoNumero)
+	assert.Equal(t, domain.OccurrencePaid, occurrences[0].Kind)
+	assert.Equal(t, 100.50, occurrences[0].Amount)
+	assert.Equal(t, 100.50, occurrences[0].PaidAmount)
+	assert.Equal(t, 2.50, occurrences[0].Fees)
+	assert.Equal(t, "2025-01-15", occurrences[0].PaidDate)
+
+	assert.Equal(t, "42", occurrences[1].NossoNumero)
+	assert.Equal(t, domain.OccurrenceRejected, occurrences[1].Kind)
+	assert.Equal(t, "08", occurrences[1].Reason)
+}
+
+func TestParser_Parse400(t *testing.T) {
+	content := strings.Join([]string{
+		line(400, map[int]string{1: "0"}),
+		line(400, map[int]string{1: "1", 71: "12345678901", 109: "02", 153: "0000000010050"}),
+		line(400, map[int]string{1: "1", 71: "00000000042", 109: "06", 111: "150125", 153: "0000000020000", 176: "0000000000250", 254: "0000000020150"}),
+		line(400, map[int]string{1: "9"}),
+	}, "\n")
+
+	layout, occurrences, err := NewParser().Parse(strings.NewReader(content))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Layout400, layout)
+	assert.Len(t, occurrences, 2)
+
+	assert.Equal(t, domain.OccurrenceRegistered, occurrences[0].Kind)
+	assert.Equal(t, "12345678901", occurrences[0].NossoNumero)
+
+	assert.Equal(t, domain.OccurrencePaid, occurrences[1].Kind)
+	assert.Equal(t, "42", occurrences[1].NossoNumero)
+	assert.Equal(t, 201.50, occurrences[1].PaidAmount)
+	assert.Equal(t, 2.50, occurrences[1].Fees)
+	assert.Equal(t, "2025-01-15", occurrences[1].PaidDate)
+}
+
+func TestParser_ParseErrors(t *testing.T) {
+	t.Run("Empty file", func(t *testing.T) {
+		_, _, err := NewParser().Parse(strings.NewReader(""))
+		assert.ErrorIs(t, err, ErrEmptyFile)
+	})
+
+	t.Run("Unknown layout", func(t *testing.T) {
+		_, _, err := NewParser().Parse(strings.NewReader("name,governmentId\n"))
+		assert.ErrorIs(t, err, ErrUnknownLayout)
+	})
+
+	t.Run("Segment U without T", func(t *testing.T) {
+		content := line(240, map[int]string{8: "3", 14: "U"})
+		_, _, err := NewParser().Parse(strings.NewReader(content))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,358 @@
+package csvformat
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"kanastra-api/internal/core/domain"
+)
+
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1252 = "windows-1252"
+	EncodingLatin1      = "iso-8859-1"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+)
+
+var (
+	ErrUnsupportedEncoding  = errors.New("codificação não suportada")
+	ErrUnsupportedDelimiter = errors.New("separador não suportado")
+)
+
+// sampleSize é o trecho do início do arquivo usado para detectar a codificação e o
+// separador.
+const sampleSize = 64 * 1024
+
+// maxSampleLines limita as linhas comparadas na detecção do separador.
+const maxSampleLines = 20
+
+// delimiters são os separadores detectados, em ordem de preferência no empate.
+var delimiters = []string{",", ";", "\t", "|"}
+
+var encodingAliases = map[string]string{
+	"utf-8":        EncodingUTF8,
+	"utf8":         EncodingUTF8,
+	"windows-1252": EncodingWindows1252,
+	"cp1252":       EncodingWindows1252,
+	"iso-8859-1":   EncodingLatin1,
+	"latin1":       EncodingLatin1,
+	"latin-1":      EncodingLatin1,
+	"utf-16le":     EncodingUTF16LE,
+	"utf-16be":     EncodingUTF16BE,
+}
+
+var delimiterAliases = map[string]string{
+	",":     ",",
+	";":     ";",
+	"|":     "|",
+	"\t":    "\t",
+	"tab":   "\t",
+	"\\t":   "\t",
+	"comma": ",",
+}
+
+// ParseEncoding normaliza o nome de uma codificação informada pelo cliente. Vazio
+// significa detectar a partir do arquivo.
+func ParseEncoding(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", nil
+	}
+
+	encoding, ok := encodingAliases[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedEncoding, name)
+	}
+
+	return encoding, nil
+}
+
+// ParseDelimiter normaliza o separador informado pelo cliente, aceitando "tab" para a
+// tabulação. Vazio significa detectar a partir do arquivo.
+func ParseDelimiter(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	delimiter, ok := delimiterAliases[strings.ToLower(value)]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedDelimiter, value)
+	}
+
+	return delimiter, nil
+}
+
+// Open devolve o conteúdo de file em UTF-8, sem o BOM, e o formato do arquivo. Os campos
+// de override têm precedência sobre a detecção, exceto BOM, que é sempre detectado; um BOM
+// também define a codificação quando override não a informa. Sem BOM, o arquivo é UTF-8
+// quando o início do arquivo é UTF-8 válido, e Windows-1252 caso contrário.
+func Open(file io.Reader, override domain.FileFormat) (io.Reader, domain.FileFormat, error) {
+	format := override
+	if _, err := ParseEncoding(format.Encoding); err != nil {
+		return nil, format, err
+	}
+
+	reader := bufio.NewReaderSize(file, sampleSize)
+	sample, err := reader.Peek(sampleSize)
+	if err != nil && err != io.EOF {
+		return nil, format, fmt.Errorf("erro ao ler o início do arquivo: %w", err)
+	}
+	truncated := err == nil
+
+	if bom, encoding := detectBOM(sample); bom > 0 {
+		format.BOM = true
+		if format.Encoding == "" {
+			format.Encoding = encoding
+		}
+
+		sample = sample[bom:]
+		if _, err := reader.Discard(bom); err != nil {
+			return nil, format, err
+		}
+	}
+
+	if format.Encoding == "" {
+		format.Encoding = detectEncoding(sample, truncated)
+	}
+
+	format.Encoding, _ = ParseEncoding(format.Encoding)
+
+	if format.Delimiter == "" {
+		format.Delimiter = detectDelimiter(decodeSample(sample, format.Encoding), truncated)
+	}
+
+	return decoder(reader, format.Encoding), format, nil
+}
+
+func detectBOM(sample []byte) (int, string) {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return 3, EncodingUTF8
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return 2, EncodingUTF16LE
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return 2, EncodingUTF16BE
+	default:
+		return 0, ""
+	}
+}
+
+// detectEncoding confere se o trecho é UTF-8 válido, ignorando um caractere cortado no fim
+// do trecho quando o arquivo é maior que ele.
+func detectEncoding(sample []byte, truncated bool) string {
+	if truncated {
+		for cut := 0; cut < utf8.UTFMax && cut < len(sample); cut++ {
+			if utf8.Valid(sample[:len(sample)-cut]) {
+				return EncodingUTF8
+			}
+		}
+
+		return EncodingWindows1252
+	}
+
+	if utf8.Valid(sample) {
+		return EncodingUTF8
+	}
+
+	return EncodingWindows1252
+}
+
+// detectDelimiter escolhe o separador que aparece o mesmo número de vezes, fora de aspas,
+// em todas as linhas do trecho. Se nenhum for consistente, vale o que mais aparece no
+// cabeçalho, e a vírgula se nenhum aparecer.
+func detectDelimiter(sample string, truncated bool) string {
+	lines := strings.Split(strings.ReplaceAll(sample, "\r\n", "\n"), "\n")
+	if truncated && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+
+	var nonEmpty []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonEmpty = append(nonEmpty, line)
+		}
+
+		if len(nonEmpty) == maxSampleLines {
+			break
+		}
+	}
+
+	if len(nonEmpty) == 0 {
+		return ","
+	}
+
+	best, bestCount, consistent := ",", 0, false
+	for _, delimiter := range delimiters {
+		count := countOutsideQuotes(nonEmpty[0], delimiter)
+		if count == 0 {
+			continue
+		}
+
+		same := true
+		for _, line := range nonEmpty[1:] {
+			if countOutsideQuotes(line, delimiter) != count {
+				same = false
+
+				break
+			}
+		}
+
+		switch {
+		case same && (!consistent || count > bestCount):
+			best, bestCount, consistent = delimiter, count, true
+		case !same && !consistent && count > bestCount:
+			best, bestCount = delimiter, count
+		}
+	}
+
+	return best
+}
+
+func countOutsideQuotes(line, delimiter string) int {
+	count, quoted := 0, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && string(r) == delimiter:
+			count++
+		}
+	}
+
+	return count
+}
+
+// decodeSample converte o trecho para texto. Os separadores são ASCII, então apenas o
+// UTF-16 precisa ser decodificado para encontrá-los.
+func decodeSample(sample []byte, encoding string) string {
+	if encoding != EncodingUTF16LE && encoding != EncodingUTF16BE {
+		return string(sample)
+	}
+
+	units := make([]uint16, 0, len(sample)/2)
+	for i := 0; i+1 < len(sample); i += 2 {
+		units = append(units, utf16Unit(sample[i], sample[i+1], encoding))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+func utf16Unit(first, second byte, encoding string) uint16 {
+	if encoding == EncodingUTF16BE {
+		return uint16(first)<<8 | uint16(second)
+	}
+
+	return uint16(second)<<8 | uint16(first)
+}
+
+func decoder(reader *bufio.Reader, encoding string) io.Reader {
+	switch encoding {
+	case EncodingWindows1252:
+		return &runeReader{src: reader, next: func() (rune, error) { return nextSingleByte(reader, &windows1252) }}
+	case EncodingLatin1:
+		return &runeReader{src: reader, next: func() (rune, error) { return nextSingleByte(reader, nil) }}
+	case EncodingUTF16LE, EncodingUTF16BE:
+		return &runeReader{src: reader, next: func() (rune, error) { return nextUTF16(reader, encoding) }}
+	default:
+		return reader
+	}
+}
+
+// runeReader converte para UTF-8 os caracteres devolvidos por next.
+type runeReader struct {
+	src     *bufio.Reader
+	next    func() (rune, error)
+	pending []byte
+	err     error
+}
+
+func (r *runeReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.pending) > 0 {
+			copied := copy(p[n:], r.pending)
+			r.pending = r.pending[copied:]
+			n += copied
+
+			continue
+		}
+
+		if r.err != nil || (n > 0 && r.src.Buffered() == 0) {
+			break
+		}
+
+		char, err := r.next()
+		if err != nil {
+			r.err = err
+
+			break
+		}
+
+		r.pending = utf8.AppendRune(r.pending[:0], char)
+	}
+
+	if n > 0 {
+		return n, nil
+	}
+
+	return 0, r.err
+}
+
+// windows1252 são os caracteres da faixa 0x80-0x9F do Windows-1252; o restante coincide
+// com o ISO-8859-1. Posições não definidas mantêm o código do ISO-8859-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+func nextSingleByte(reader *bufio.Reader, table *[32]rune) (rune, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if table != nil && b >= 0x80 && b <= 0x9F {
+		return table[b-0x80], nil
+	}
+
+	return rune(b), nil
+}
+
+func nextUTF16(reader *bufio.Reader, encoding string) (rune, error) {
+	unit, err := readUTF16Unit(reader, encoding)
+	if err != nil {
+		return 0, err
+	}
+
+	if !utf16.IsSurrogate(rune(unit)) {
+		return rune(unit), nil
+	}
+
+	low, err := readUTF16Unit(reader, encoding)
+	if err != nil {
+		return utf8.RuneError, nil
+	}
+
+	return utf16.DecodeRune(rune(unit), rune(low)), nil
+}
+
+func readUTF16Unit(reader *bufio.Reader, encoding string) (uint16, error) {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	second, err := reader.ReadByte()
+	if err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	return utf16Unit(first, second, encoding), nil
+}
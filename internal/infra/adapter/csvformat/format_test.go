@@ -0,0 +1,111 @@
+package csvformat
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func read(t *testing.T, content []byte, override domain.FileFormat) (string, domain.FileFormat) {
+	t.Helper()
+
+	reader, format, err := Open(bytes.NewReader(content), override)
+	require.NoError(t, err)
+
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return string(decoded), format
+}
+
+func TestOpen_ExcelExport(t *testing.T) {
+	// Exportação do Excel em português: ponto e vírgula e Windows-1252.
+	content := []byte("nome;cpf;observa\xe7\xe3o\r\nJo\xe3o;1234;\x93VIP\x94\r\n")
+
+	text, format := read(t, content, domain.FileFormat{})
+
+	assert.Equal(t, "nome;cpf;observação\r\nJoão;1234;“VIP”\r\n", text)
+	assert.Equal(t, domain.FileFormat{Delimiter: ";", Encoding: EncodingWindows1252}, format)
+}
+
+func TestOpen_UTF8WithBOM(t *testing.T) {
+	content := append([]byte{0xEF, 0xBB, 0xBF}, "name,email\nJoão,\"a;b\"\n"...)
+
+	text, format := read(t, content, domain.FileFormat{})
+
+	assert.Equal(t, "name,email\nJoão,\"a;b\"\n", text)
+	assert.Equal(t, domain.FileFormat{Delimiter: ",", Encoding: EncodingUTF8, BOM: true}, format)
+}
+
+func TestOpen_UTF16WithBOM(t *testing.T) {
+	var content bytes.Buffer
+	content.Write([]byte{0xFF, 0xFE})
+	for _, unit := range utf16.Encode([]rune("nome\tcpf\nJoão\t1234\n")) {
+		content.Write([]byte{byte(unit), byte(unit >> 8)})
+	}
+
+	text, format := read(t, content.Bytes(), domain.FileFormat{})
+
+	assert.Equal(t, "nome\tcpf\nJoão\t1234\n", text)
+	assert.Equal(t, domain.FileFormat{Delimiter: "\t", Encoding: EncodingUTF16LE, BOM: true}, format)
+}
+
+func TestOpen_Override(t *testing.T) {
+	content := []byte("a|b;c\nd|e;f\n\x80")
+
+	text, format := read(t, content, domain.FileFormat{Delimiter: ";", Encoding: "Latin1"})
+
+	assert.Equal(t, "a|b;c\nd|e;f\n\u0080", text)
+	assert.Equal(t, domain.FileFormat{Delimiter: ";", Encoding: EncodingLatin1}, format)
+
+	_, _, err := Open(bytes.NewReader(content), domain.FileFormat{Encoding: "ebcdic"})
+	assert.ErrorIs(t, err, ErrUnsupportedEncoding)
+}
+
+func TestOpen_LargeFile(t *testing.T) {
+	// O trecho de detecção termina no meio de um caractere UTF-8 e de uma linha.
+	line := "Débora,1234,débora@example.com\n"
+	content := strings.Repeat(line, sampleSize/len(line)+10)
+
+	text, format := read(t, []byte(content), domain.FileFormat{})
+
+	assert.Equal(t, content, text)
+	assert.Equal(t, domain.FileFormat{Delimiter: ",", Encoding: EncodingUTF8}, format)
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample string
+		want   string
+	}{
+		{"Vírgula", "a,b,c\n1,2,3\n", ","},
+		{"Ponto e vírgula com vírgula decimal", "nome;valor\nJoão;100,50\nMaria;7\n", ";"},
+		{"Barra vertical", "a|b|c\n1|2|3", "|"},
+		{"Separador entre aspas é ignorado", "\"a;b\",c\n\"d;e\",f\n", ","},
+		{"Sem separador", "nome\n", ","},
+		{"Inconsistente usa o cabeçalho", "a;b;c\n1;2\n", ";"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectDelimiter(tt.sample, false))
+		})
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	delimiter, err := ParseDelimiter("TAB")
+	assert.NoError(t, err)
+	assert.Equal(t, "\t", delimiter)
+
+	_, err = ParseDelimiter("::")
+	assert.ErrorIs(t, err, ErrUnsupportedDelimiter)
+}
@@ -0,0 +1,194 @@
+package dropfolder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/infra/adapter/filesource"
+)
+
+const (
+	processedDir = "processed"
+	failedDir    = "failed"
+	// doneSuffix marca um arquivo como completo: ao terminar de gravar remessa.csv, o
+	// parceiro cria remessa.csv.done, e o arquivo é lido sem esperar o tamanho estabilizar.
+	doneSuffix = ".done"
+)
+
+// partialSuffixes são as extensões usadas pelos clientes de FTP e SFTP enquanto gravam o
+// arquivo, que só é renomeado para o nome final ao terminar.
+var partialSuffixes = []string{".tmp", ".part", ".partial", ".filepart"}
+
+// observation é o tamanho e a data de alteração de um arquivo na última verificação, e
+// desde quando eles não mudam.
+type observation struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// Watcher lê os arquivos de débitos depositados pelos parceiros em um diretório. Um
+// arquivo é lido quando tem um marcador .done ou quando seu tamanho não muda por
+// stableFor. Depois de processado, o arquivo é movido para processed/, ou para failed/
+// quando não pôde ser lido, com o ID do envio no início do nome.
+type Watcher struct {
+	dir          string
+	stableFor    time.Duration
+	process      filesource.ProcessFunc
+	jobs         filesource.JobFinder
+	now          func() time.Time
+	observations map[string]observation
+}
+
+func NewWatcher(dir string, stableFor time.Duration, process filesource.ProcessFunc, jobs filesource.JobFinder) *Watcher {
+	return &Watcher{
+		dir:          dir,
+		stableFor:    stableFor,
+		process:      process,
+		jobs:         jobs,
+		now:          time.Now,
+		observations: make(map[string]observation),
+	}
+}
+
+// Poll processa os arquivos completos do diretório e devolve quantos foram processados
+// com sucesso.
+func (w *Watcher) Poll() (int, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar diretório de arquivos %s: %w", w.dir, err)
+	}
+
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+
+	seen := make(map[string]bool, len(entries))
+	processed := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || ignored(name) {
+			continue
+		}
+		seen[name] = true
+
+		marked := names[name+doneSuffix]
+		if !marked && !w.stable(entry) {
+			continue
+		}
+		delete(w.observations, name)
+
+		if w.processFile(name) {
+			processed++
+		}
+
+		if marked {
+			if err := os.Remove(filepath.Join(w.dir, name+doneSuffix)); err != nil {
+				log.Printf("Erro ao remover marcador de %s: %v", name, err)
+			}
+		}
+	}
+
+	// Esquece os arquivos removidos ou renomeados desde a última verificação.
+	for name := range w.observations {
+		if !seen[name] {
+			delete(w.observations, name)
+		}
+	}
+
+	return processed, nil
+}
+
+// Run verifica o diretório a cada interval até o contexto ser encerrado.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Poll(); err != nil {
+			log.Printf("Erro ao verificar diretório de arquivos: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func ignored(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, doneSuffix) {
+		return true
+	}
+
+	for _, suffix := range partialSuffixes {
+		if strings.HasSuffix(strings.ToLower(name), suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stable confere se o tamanho e a data de alteração do arquivo não mudaram desde a
+// verificação anterior, há pelo menos stableFor.
+func (w *Watcher) stable(entry os.DirEntry) bool {
+	info, err := entry.Info()
+	if err != nil {
+		return false
+	}
+
+	now := w.now()
+	previous, exists := w.observations[entry.Name()]
+	if !exists || previous.size != info.Size() || !previous.modTime.Equal(info.ModTime()) {
+		w.observations[entry.Name()] = observation{size: info.Size(), modTime: info.ModTime(), since: now}
+
+		return false
+	}
+
+	return now.Sub(previous.since) >= w.stableFor
+}
+
+// processFile processa o arquivo e o move para processed/ ou failed/, devolvendo se foi
+// processado com sucesso.
+func (w *Watcher) processFile(name string) bool {
+	path := filepath.Join(w.dir, name)
+
+	jobIDs, err := filesource.Process(w.process, w.jobs, path)
+
+	destination := processedDir
+	if err != nil {
+		log.Printf("Erro ao processar arquivo %s do diretório: %v", name, err)
+		destination = failedDir
+	} else {
+		log.Printf("Arquivo %s do diretório processado: envios %v", name, jobIDs)
+	}
+
+	prefix := w.now().UTC().Format("20060102T150405")
+	if len(jobIDs) > 0 {
+		prefix = jobIDs[0]
+	}
+
+	if err := w.move(path, destination, prefix+"_"+name); err != nil {
+		// Sem mover o arquivo, ele seria processado de novo na próxima verificação.
+		log.Printf("Erro ao mover arquivo %s para %s: %v", name, destination, err)
+	}
+
+	return err == nil
+}
+
+func (w *Watcher) move(path, destination, name string) error {
+	dir := filepath.Join(w.dir, destination)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %w", dir, err)
+	}
+
+	return os.Rename(path, filepath.Join(dir, name))
+}
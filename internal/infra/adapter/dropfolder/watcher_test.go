@@ -0,0 +1,87 @@
+package dropfolder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type jobsByID map[string]domain.IngestionJob
+
+func (j jobsByID) FindByID(id string) (domain.IngestionJob, bool) {
+	job, exists := j[id]
+
+	return job, exists
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	jobs := jobsByID{"job_b": {ID: "job_b", Error: "colunas obrigatórias ausentes: debtId"}}
+
+	var processed []string
+	watcher := NewWatcher(dir, time.Minute, func(path string) ([]string, error) {
+		name := filepath.Base(path)
+		processed = append(processed, name)
+
+		switch name {
+		case "a.csv":
+			return []string{"job_a"}, nil
+		case "b.csv":
+			return []string{"job_b"}, nil
+		default:
+			return nil, errors.New("arquivo ilegível")
+		}
+	}, jobs)
+
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	watcher.now = func() time.Time { return now }
+
+	writeFile(t, dir, "a.csv", "name\n")
+	writeFile(t, dir, "b.csv", "name\n")
+	writeFile(t, dir, "c.csv", "name\n")
+	writeFile(t, dir, "c.csv.done", "")
+	writeFile(t, dir, "d.csv.part", "name\n")
+	writeFile(t, dir, ".e.csv", "name\n")
+
+	count, err := watcher.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, []string{"c.csv"}, processed, "apenas o arquivo com marcador é lido de imediato")
+	assert.FileExists(t, filepath.Join(dir, failedDir, "20250301T100000_c.csv"))
+	assert.NoFileExists(t, filepath.Join(dir, "c.csv.done"))
+
+	t.Run("Arquivo ainda crescendo", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		writeFile(t, dir, "b.csv", "name\nJohn Doe\n")
+
+		count, err := watcher.Poll()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, []string{"c.csv", "a.csv"}, processed)
+		assert.FileExists(t, filepath.Join(dir, processedDir, "job_a_a.csv"))
+	})
+
+	t.Run("Envio descartado vai para failed", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		count, err := watcher.Poll()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.FileExists(t, filepath.Join(dir, failedDir, "job_b_b.csv"))
+	})
+
+	assert.FileExists(t, filepath.Join(dir, "d.csv.part"))
+	assert.FileExists(t, filepath.Join(dir, ".e.csv"))
+}
@@ -0,0 +1,109 @@
+package dsn
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+const (
+	processedDir = "processed"
+	failedDir    = "failed"
+)
+
+// DirectoryPoller lê as mensagens de DSN depositadas em um diretório (por exemplo, pela
+// entrega local da caixa de bounces). Mensagens lidas são movidas para processed/ e as
+// que não puderem ser interpretadas, para failed/.
+type DirectoryPoller struct {
+	dir    string
+	handle func(feedback domain.EmailFeedback) error
+}
+
+func NewDirectoryPoller(dir string, handle func(feedback domain.EmailFeedback) error) *DirectoryPoller {
+	return &DirectoryPoller{dir: dir, handle: handle}
+}
+
+func (p *DirectoryPoller) Poll() (int, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar diretório de DSN %s: %w", p.dir, err)
+	}
+
+	processed := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		path := filepath.Join(p.dir, entry.Name())
+		destination := processedDir
+		if err := p.processFile(path); err != nil {
+			log.Printf("Erro ao processar DSN %s: %v", entry.Name(), err)
+			destination = failedDir
+		} else {
+			processed++
+		}
+
+		if err := p.move(path, destination); err != nil {
+			return processed, err
+		}
+	}
+
+	return processed, nil
+}
+
+// Run verifica o diretório a cada interval até o contexto ser encerrado.
+func (p *DirectoryPoller) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Poll(); err != nil {
+			log.Printf("Erro ao verificar diretório de DSN: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *DirectoryPoller) processFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	feedbacks, err := Parse(file)
+	if err != nil {
+		return err
+	}
+
+	for _, feedback := range feedbacks {
+		if err := p.handle(feedback); err != nil {
+			log.Printf("Erro ao aplicar retorno de e-mail %s para %s: %v", feedback.ProviderMessageID, feedback.Email, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *DirectoryPoller) move(path, destination string) error {
+	dir := filepath.Join(p.dir, destination)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %w", dir, err)
+	}
+
+	return os.Rename(path, filepath.Join(dir, filepath.Base(path)))
+}
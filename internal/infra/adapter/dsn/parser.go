@@ -0,0 +1,182 @@
+package dsn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+var (
+	ErrNotReport       = errors.New("mensagem não é um relatório de entrega (multipart/report)")
+	ErrMissingOriginal = errors.New("relatório sem o Message-ID da mensagem original")
+)
+
+const (
+	reportDeliveryStatus = "delivery-status"
+	reportFeedback       = "feedback-report"
+)
+
+// Parse lê uma notificação de status de entrega (DSN, RFC 3464) ou um relatório de
+// reclamação (ARF, RFC 5965) e devolve um retorno por destinatário. Destinatários com
+// entrega confirmada são ignorados.
+func Parse(r io.Reader) ([]domain.EmailFeedback, error) {
+	message, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler mensagem: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" {
+		return nil, ErrNotReport
+	}
+
+	occurredAt, _ := message.Header.Date()
+
+	var report report
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler parte do relatório: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/feedback-report":
+			report.fields, err = readFieldGroups(part)
+		case "message/rfc822", "text/rfc822-headers":
+			report.originalMessageID, err = readMessageID(part)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if report.originalMessageID == "" {
+		return nil, ErrMissingOriginal
+	}
+
+	if params["report-type"] == reportFeedback {
+		return report.complaints(occurredAt), nil
+	}
+
+	return report.bounces(occurredAt), nil
+}
+
+type report struct {
+	fields            []textproto.MIMEHeader
+	originalMessageID string
+}
+
+// bounces converte os campos por destinatário do DSN. O primeiro grupo de campos
+// descreve a mensagem e os seguintes, um destinatário cada.
+func (r report) bounces(occurredAt time.Time) []domain.EmailFeedback {
+	var feedbacks []domain.EmailFeedback
+	for i, fields := range r.fields {
+		if i == 0 && fields.Get("Final-Recipient") == "" {
+			if arrival, err := mail.ParseDate(fields.Get("Arrival-Date")); err == nil {
+				occurredAt = arrival
+			}
+
+			continue
+		}
+
+		feedback := domain.EmailFeedback{
+			ProviderMessageID: r.originalMessageID,
+			Email:             addressValue(fields.Get("Final-Recipient")),
+			Reason:            reason(fields),
+			OccurredAt:        occurredAt,
+		}
+
+		switch strings.ToLower(strings.TrimSpace(fields.Get("Action"))) {
+		case "failed":
+			feedback.Type = domain.EmailFeedbackBounce
+			feedback.Permanent = !strings.HasPrefix(strings.TrimSpace(fields.Get("Status")), "4")
+		case "delayed":
+			feedback.Type = domain.EmailFeedbackDelay
+		default:
+			continue
+		}
+
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	return feedbacks
+}
+
+func (r report) complaints(occurredAt time.Time) []domain.EmailFeedback {
+	if len(r.fields) == 0 {
+		return nil
+	}
+
+	fields := r.fields[0]
+	if arrival, err := mail.ParseDate(fields.Get("Arrival-Date")); err == nil {
+		occurredAt = arrival
+	}
+
+	return []domain.EmailFeedback{{
+		ProviderMessageID: r.originalMessageID,
+		Email:             addressValue(fields.Get("Original-Rcpt-To")),
+		Type:              domain.EmailFeedbackComplaint,
+		Permanent:         true,
+		Reason:            "feedback-type: " + fields.Get("Feedback-Type"),
+		OccurredAt:        occurredAt,
+	}}
+}
+
+func readFieldGroups(part io.Reader) ([]textproto.MIMEHeader, error) {
+	reader := textproto.NewReader(bufio.NewReader(part))
+
+	var groups []textproto.MIMEHeader
+	for {
+		fields, err := reader.ReadMIMEHeader()
+		if len(fields) > 0 {
+			groups = append(groups, fields)
+		}
+
+		if err == io.EOF {
+			return groups, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler campos do relatório: %w", err)
+		}
+	}
+}
+
+func readMessageID(part io.Reader) (string, error) {
+	headers, err := textproto.NewReader(bufio.NewReader(part)).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("erro ao ler cabeçalhos da mensagem original: %w", err)
+	}
+
+	return strings.TrimSpace(headers.Get("Message-ID")), nil
+}
+
+// addressValue remove o tipo do endereço ("rfc822; fulano@example.com").
+func addressValue(value string) string {
+	if _, address, found := strings.Cut(value, ";"); found {
+		value = address
+	}
+
+	return strings.Trim(strings.TrimSpace(value), "<>")
+}
+
+func reason(fields textproto.MIMEHeader) string {
+	if diagnostic := fields.Get("Diagnostic-Code"); diagnostic != "" {
+		return strings.TrimSpace(addressValue(diagnostic))
+	}
+
+	return "status " + strings.TrimSpace(fields.Get("Status"))
+}
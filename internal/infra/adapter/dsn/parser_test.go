@@ -0,0 +1,89 @@
+package dsn
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func parseFile(t *testing.T, name string) []domain.EmailFeedback {
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+
+	feedbacks, err := Parse(file)
+	require.NoError(t, err)
+
+	return feedbacks
+}
+
+func TestParse_DeliveryStatus(t *testing.T) {
+	feedbacks := parseFile(t, "bounce.eml")
+
+	require.Len(t, feedbacks, 2)
+
+	assert.Equal(t, "<a1b2c3@kanastra.com.br>", feedbacks[0].ProviderMessageID)
+	assert.Equal(t, "joao@example.com", feedbacks[0].Email)
+	assert.Equal(t, domain.EmailFeedbackBounce, feedbacks[0].Type)
+	assert.True(t, feedbacks[0].Permanent)
+	assert.Contains(t, feedbacks[0].Reason, "550 5.1.1")
+	assert.True(t, feedbacks[0].OccurredAt.Equal(time.Date(2025, 3, 1, 13, 4, 58, 0, time.UTC)))
+
+	assert.Equal(t, "maria@example.com", feedbacks[1].Email)
+	assert.Equal(t, domain.EmailFeedbackDelay, feedbacks[1].Type)
+	assert.False(t, feedbacks[1].Permanent)
+}
+
+func TestParse_FeedbackReport(t *testing.T) {
+	feedbacks := parseFile(t, "complaint.eml")
+
+	require.Len(t, feedbacks, 1)
+	assert.Equal(t, "<d4e5f6@kanastra.com.br>", feedbacks[0].ProviderMessageID)
+	assert.Equal(t, "maria@example.com", feedbacks[0].Email)
+	assert.Equal(t, domain.EmailFeedbackComplaint, feedbacks[0].Type)
+	assert.True(t, feedbacks[0].InvalidatesEmail())
+}
+
+func TestParse_NotReport(t *testing.T) {
+	_, err := Parse(strings.NewReader("From: a@example.com\r\nContent-Type: text/plain\r\n\r\nolá\r\n"))
+
+	assert.ErrorIs(t, err, ErrNotReport)
+}
+
+func TestDirectoryPoller_Poll(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"bounce.eml", "complaint.eml"} {
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lixo.eml"), []byte("não é um e-mail"), 0o644))
+
+	var received []domain.EmailFeedback
+	poller := NewDirectoryPoller(dir, func(feedback domain.EmailFeedback) error {
+		received = append(received, feedback)
+
+		return nil
+	})
+
+	processed, err := poller.Poll()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Len(t, received, 3)
+	assert.FileExists(t, filepath.Join(dir, processedDir, "bounce.eml"))
+	assert.FileExists(t, filepath.Join(dir, processedDir, "complaint.eml"))
+	assert.FileExists(t, filepath.Join(dir, failedDir, "lixo.eml"))
+	assert.NoFileExists(t, filepath.Join(dir, "bounce.eml"))
+
+	processed, err = poller.Poll()
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+}
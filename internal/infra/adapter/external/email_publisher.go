@@ -12,6 +12,9 @@ func NewEmailPublisher() *EmailPublisher {
 	return &EmailPublisher{}
 }
 
-func (e *EmailPublisher) Publish(email string, debt domain.Debt) {
-	log.Printf("E-mail enviado com sucesso para %s sobre débito: %+v", email, debt)
+func (e *EmailPublisher) Publish(email string, notification domain.Notification) (string, error) {
+	invoice := notification.Invoice
+	log.Printf("E-mail enviado com sucesso para %s sobre débito: %+v (nosso número %s, %s)", email, invoice.Debt, invoice.NossoNumero, notification.Key)
+
+	return notification.Key, nil
 }
@@ -62,7 +62,7 @@ func TestEmailPublisher_Publish(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			logBuffer.Reset()
 
-			emailPublisher.Publish(tt.email, tt.debt)
+			emailPublisher.Publish(tt.email, domain.Notification{Invoice: domain.Invoice{Debt: tt.debt}})
 
 			assert.Contains(t, logBuffer.String(), "E-mail enviado com sucesso para", "O log deve conter a mensagem de envio.")
 			assert.Contains(t, logBuffer.String(), tt.email, "O log deve conter o e-mail fornecido.")
@@ -0,0 +1,164 @@
+package external
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/i18n"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+const debtNotificationTemplate = "debt_notification"
+
+// QRCodeContentID identifica a imagem do QR Code Pix embutida no e-mail (cid:).
+const QRCodeContentID = "pix-qrcode@kanastra"
+
+type EmailTemplateData struct {
+	Name                string
+	Amount              string
+	DueDate             string
+	DebtID              string
+	DigitableLine       string
+	PixCopyPaste        string
+	QRCodeCID           string
+	PaymentInstructions []string
+	// Reminder indica um lembrete da régua de cobrança; DaysUntilDue e DaysOverdue
+	// informam a distância até o vencimento ou desde ele.
+	Reminder     bool
+	DaysUntilDue int
+	DaysOverdue  int
+	// UnsubscribeURL é o link para o devedor deixar de receber lembretes; vazio quando o
+	// descadastro não está configurado.
+	UnsubscribeURL string
+}
+
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+	Locale  i18n.Locale
+}
+
+// EmailTemplates carrega uma versão dos templates de e-mail embutidos no binário,
+// mantendo as versões anteriores disponíveis enquanto uma nova é adotada. Cada versão tem
+// um diretório por idioma; idiomas sem template usam o idioma padrão.
+type EmailTemplates struct {
+	locales map[i18n.Locale]localeTemplates
+}
+
+type localeTemplates struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+func NewEmailTemplates(version string) (*EmailTemplates, error) {
+	entries, err := fs.ReadDir(templateFS, "templates/"+version)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar templates %s: %w", version, err)
+	}
+
+	templates := &EmailTemplates{locales: make(map[i18n.Locale]localeTemplates)}
+	for _, entry := range entries {
+		locale, ok := i18n.Parse(entry.Name())
+		if !entry.IsDir() || !ok {
+			continue
+		}
+
+		loaded, err := loadLocaleTemplates(fmt.Sprintf("templates/%s/%s/%s", version, entry.Name(), debtNotificationTemplate))
+		if err != nil {
+			return nil, fmt.Errorf("templates %s/%s: %w", version, entry.Name(), err)
+		}
+
+		templates.locales[locale] = loaded
+	}
+
+	if _, ok := templates.locales[i18n.Default]; !ok {
+		return nil, fmt.Errorf("templates %s sem o idioma padrão %s", version, i18n.Default)
+	}
+
+	return templates, nil
+}
+
+func loadLocaleTemplates(prefix string) (localeTemplates, error) {
+	subject, err := texttemplate.ParseFS(templateFS, prefix+".subject.tmpl")
+	if err != nil {
+		return localeTemplates{}, fmt.Errorf("erro ao carregar template de assunto: %w", err)
+	}
+
+	text, err := texttemplate.ParseFS(templateFS, prefix+".txt.tmpl")
+	if err != nil {
+		return localeTemplates{}, fmt.Errorf("erro ao carregar template de texto: %w", err)
+	}
+
+	html, err := htmltemplate.ParseFS(templateFS, prefix+".html.tmpl")
+	if err != nil {
+		return localeTemplates{}, fmt.Errorf("erro ao carregar template HTML: %w", err)
+	}
+
+	return localeTemplates{subject: subject, text: text, html: html}, nil
+}
+
+// Render monta o e-mail a partir da notificação do boleto, no idioma do devedor;
+// withQRCode indica se a imagem do QR Code Pix segue embutida na mensagem e unsubscribeURL
+// é o link de descadastro de lembretes, omitido quando vazio.
+func (t *EmailTemplates) Render(notification domain.Notification, withQRCode bool, unsubscribeURL string) (RenderedEmail, error) {
+	invoice := notification.Invoice
+	locale := i18n.Resolve(invoice.Debt.Locale)
+	templates, ok := t.locales[locale]
+	if !ok {
+		locale, templates = i18n.Default, t.locales[i18n.Default]
+	}
+
+	data := EmailTemplateData{
+		Name:           invoice.Debt.Name,
+		Amount:         locale.FormatMoney(invoice.Amount),
+		DueDate:        locale.FormatDate(invoice.DueDate),
+		DebtID:         invoice.Debt.DebtID,
+		DigitableLine:  invoice.DigitableLine,
+		PixCopyPaste:   invoice.PixCopyPaste,
+		UnsubscribeURL: unsubscribeURL,
+		PaymentInstructions: []string{
+			locale.Sprintf("instructions.pay"),
+			locale.Sprintf("instructions.late"),
+		},
+	}
+
+	if withQRCode {
+		data.QRCodeCID = QRCodeContentID
+	}
+
+	if notification.IsReminder() {
+		data.Reminder = true
+		data.DaysUntilDue = max(-notification.DaysFromDue, 0)
+		data.DaysOverdue = max(notification.DaysFromDue, 0)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := templates.subject.Execute(&subject, data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("erro ao renderizar assunto do e-mail: %w", err)
+	}
+
+	if err := templates.text.Execute(&text, data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("erro ao renderizar texto do e-mail: %w", err)
+	}
+
+	if err := templates.html.Execute(&html, data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("erro ao renderizar HTML do e-mail: %w", err)
+	}
+
+	return RenderedEmail{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+		Locale:  locale,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package external
+
+import (
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/adapter/boleto"
+	"kanastra-api/internal/infra/adapter/pix"
+)
+
+const qrCodeSize = 256
+
+// PaymentDocuments gera o PDF do boleto e a imagem do QR Code Pix enviados ao devedor.
+type PaymentDocuments struct {
+	beneficiary boleto.Beneficiary
+}
+
+func NewPaymentDocuments(beneficiary boleto.Beneficiary) *PaymentDocuments {
+	return &PaymentDocuments{beneficiary: beneficiary}
+}
+
+func (d *PaymentDocuments) BoletoPDF(invoice domain.Invoice) ([]byte, error) {
+	return boleto.RenderPDF(invoice, d.beneficiary)
+}
+
+func (d *PaymentDocuments) PixQRCode(invoice domain.Invoice) ([]byte, error) {
+	return pix.QRCodePNG(invoice.PixCopyPaste, qrCodeSize)
+}
@@ -1,17 +1,162 @@
 package external
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
 	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/adapter/boleto"
+	"kanastra-api/internal/infra/adapter/pix"
 )
 
-type InvoiceGenerator struct{}
+// ErrNossoNumeroExhausted indica que a conta de cobrança já usou todos os nossos números.
+var ErrNossoNumeroExhausted = errors.New("faixa de nosso número esgotada")
 
-func NewInvoiceGenerator() *InvoiceGenerator {
-	return &InvoiceGenerator{}
+// maxNossoNumero é o maior nosso número de 11 dígitos do campo livre.
+const maxNossoNumero = 99999999999
+
+// ClientSettingsProvider fornece as configurações do cliente aplicadas ao boleto.
+type ClientSettingsProvider interface {
+	ChargePolicy(clientID string) domain.ChargePolicy
+	Locale(clientID string) string
+}
+
+type BusinessCalendar interface {
+	NextBusinessDay(day time.Time) time.Time
+}
+
+// NossoNumeroSequence entrega o próximo nosso número livre da conta de cobrança. Os
+// números nunca se repetem, mesmo entre réplicas que compartilham a sequência.
+type NossoNumeroSequence interface {
+	Next(beneficiary string) (int64, error)
+}
+
+type InvoiceGenerator struct {
+	policies    ClientSettingsProvider
+	calendar    BusinessCalendar
+	sequence    NossoNumeroSequence
+	beneficiary boleto.Beneficiary
+	receiver    pix.Receiver
+	now         func() time.Time
+}
+
+func NewInvoiceGenerator(
+	policies ClientSettingsProvider,
+	calendar BusinessCalendar,
+	sequence NossoNumeroSequence,
+	beneficiary boleto.Beneficiary,
+	receiver pix.Receiver,
+) *InvoiceGenerator {
+	return &InvoiceGenerator{
+		policies:    policies,
+		calendar:    calendar,
+		sequence:    sequence,
+		beneficiary: beneficiary,
+		receiver:    receiver,
+		now:         time.Now,
+	}
+}
+
+func (b InvoiceGenerator) Generate(debt domain.Debt) (domain.Invoice, error) {
+	if debt.Locale == "" {
+		debt.Locale = b.policies.Locale(debt.ClientID)
+	}
+
+	nossoNumero, err := b.nextNossoNumero()
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	now := b.now()
+	invoice := domain.Invoice{
+		Debt:        debt,
+		NossoNumero: nossoNumero,
+		PixTxID:     pixTxID(debt.DebtID),
+		Amount:      debt.DebtAmount,
+		DueDate:     debt.DebtDueDate,
+		Status:      domain.InvoiceStatusIssued,
+		IssuedAt:    now,
+		UpdatedAt:   now,
+	}
+
+	b.applyCharges(&invoice)
+	b.applyPaymentCodes(&invoice)
+
+	log.Printf("Boleto gerado com sucesso para o débito: %+v (nosso número %s)", debt, invoice.NossoNumero)
+
+	return invoice, nil
 }
 
-func (b InvoiceGenerator) Generate(debt domain.Debt) {
-	log.Printf("Boleto gerado com sucesso para o débito: %+v", debt)
+// applyCharges reemite com vencimento na data atual os boletos de débitos já vencidos,
+// somando multa e juros de mora ao valor original. Um débito vencido em fim de semana ou
+// feriado só é considerado em atraso depois do dia útil seguinte.
+func (b InvoiceGenerator) applyCharges(invoice *domain.Invoice) {
+	dueDate, err := time.Parse(time.DateOnly, invoice.Debt.DebtDueDate)
+	if err != nil {
+		return
+	}
+
+	effectiveDueDate := b.calendar.NextBusinessDay(dueDate)
+	invoice.EffectiveDueDate = effectiveDueDate.Format(time.DateOnly)
+
+	today := b.now()
+	charges := b.policies.ChargePolicy(invoice.Debt.ClientID).Calculate(invoice.Debt.DebtAmount, dueDate, effectiveDueDate, today)
+	if charges.DaysLate == 0 {
+		return
+	}
+
+	reissueDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	invoice.OriginalDueDate = invoice.DueDate
+	invoice.DueDate = reissueDate.Format(time.DateOnly)
+	invoice.EffectiveDueDate = b.calendar.NextBusinessDay(reissueDate).Format(time.DateOnly)
+	invoice.Amount = charges.Total
+	invoice.Charges = &charges
+}
+
+// applyPaymentCodes preenche o código de barras, a linha digitável e o Pix copia-e-cola.
+// Débitos com dados inválidos para o boleto seguem sem os códigos, apenas registrados no log.
+func (b InvoiceGenerator) applyPaymentCodes(invoice *domain.Invoice) {
+	barcode, err := boleto.Barcode(b.beneficiary, invoice.NossoNumero, invoice.Amount, invoice.DueDate)
+	if err != nil {
+		log.Printf("Erro ao gerar código de barras do débito %s: %v", invoice.Debt.DebtID, err)
+	} else {
+		invoice.Barcode = barcode
+		invoice.DigitableLine, _ = boleto.DigitableLine(barcode)
+	}
+
+	copyPaste, err := pix.CopyPaste(b.receiver, invoice.PixTxID, invoice.Amount)
+	if err != nil {
+		log.Printf("Erro ao gerar Pix copia-e-cola do débito %s: %v", invoice.Debt.DebtID, err)
+
+		return
+	}
+
+	invoice.PixCopyPaste = copyPaste
+}
+
+// nextNossoNumero reserva o próximo nosso número da conta de cobrança, identificada pela
+// agência e pela conta. Uma mensagem reprocessada não chega até aqui: o débito já emitido
+// é reaproveitado pelo caso de uso.
+func (b InvoiceGenerator) nextNossoNumero() (string, error) {
+	number, err := b.sequence.Next(b.beneficiary.Agency + "/" + b.beneficiary.Account)
+	if err != nil {
+		return "", fmt.Errorf("erro ao reservar nosso número: %w", err)
+	}
+
+	if number > maxNossoNumero {
+		return "", ErrNossoNumeroExhausted
+	}
+
+	return fmt.Sprintf("%011d", number), nil
+}
+
+// pixTxID segue o formato do identificador de cobrança Pix (26 a 35 caracteres alfanuméricos).
+func pixTxID(debtID string) string {
+	sum := sha256.Sum256([]byte(debtID))
+
+	return "KNS" + hex.EncodeToString(sum[:])[:29]
 }
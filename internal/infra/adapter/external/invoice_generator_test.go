@@ -3,17 +3,46 @@ package external
 import (
 	"bytes"
 	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/adapter/boleto"
+	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/adapter/pix"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+var (
+	testBeneficiary = boleto.Beneficiary{Name: "Kanastra", BankCode: "237", Agency: "1234", Wallet: "09", Account: "0012345"}
+	testReceiver    = pix.Receiver{Name: "Kanastra", City: "Sao Paulo", LocationURL: "pix.kanastra.com.br/qr/v2"}
+)
+
+type stubChargePolicies struct {
+	policy domain.ChargePolicy
+}
+
+func (s stubChargePolicies) Locale(_ string) string {
+	return "pt-BR"
+}
+
+func (s stubChargePolicies) ChargePolicy(_ string) domain.ChargePolicy {
+	return s.policy
+}
+
+type stubSequence struct {
+	next int64
+}
+
+func (s stubSequence) Next(_ string) (int64, error) {
+	return s.next, nil
+}
+
 func TestInvoiceGenerator_Generate(t *testing.T) {
 	var logBuffer bytes.Buffer
 	log.SetOutput(&logBuffer)
 
-	invoiceGenerator := NewInvoiceGenerator()
+	invoiceGenerator := NewInvoiceGenerator(stubChargePolicies{}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
 
 	tests := []struct {
 		name string
@@ -68,8 +97,106 @@ func TestInvoiceGenerator_Generate(t *testing.T) {
 }
 
 func TestNewInvoiceGenerator(t *testing.T) {
-	invoiceGenerator := NewInvoiceGenerator()
+	invoiceGenerator := NewInvoiceGenerator(stubChargePolicies{}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
 
 	assert.NotNil(t, invoiceGenerator, "NewInvoiceGenerator() deve retornar uma instância não nula")
 	assert.IsType(t, &InvoiceGenerator{}, invoiceGenerator, "NewInvoiceGenerator() deve retornar uma instância do tipo InvoiceGenerator")
 }
+
+func TestInvoiceGenerator_GenerateOverdue(t *testing.T) {
+	invoiceGenerator := NewInvoiceGenerator(stubChargePolicies{policy: domain.ChargePolicy{FinePercent: 2, MonthlyInterestPercent: 1}}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
+	invoiceGenerator.now = func() time.Time { return time.Date(2025, 2, 9, 10, 0, 0, 0, time.UTC) }
+
+	t.Run("Débito vencido é reemitido com encargos", func(t *testing.T) {
+		invoice, err := invoiceGenerator.Generate(domain.Debt{DebtID: "001", DebtAmount: 1000, DebtDueDate: "2025-01-10"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-02-09", invoice.DueDate)
+		assert.Equal(t, "2025-01-10", invoice.OriginalDueDate)
+		assert.Equal(t, 1030.0, invoice.Amount)
+		assert.Equal(t, 30, invoice.Charges.DaysLate)
+	})
+
+	t.Run("Débito a vencer mantém valor e vencimento", func(t *testing.T) {
+		invoice, err := invoiceGenerator.Generate(domain.Debt{DebtID: "002", DebtAmount: 1000, DebtDueDate: "2025-03-10"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-10", invoice.DueDate)
+		assert.Empty(t, invoice.OriginalDueDate)
+		assert.Equal(t, 1000.0, invoice.Amount)
+		assert.Nil(t, invoice.Charges)
+	})
+
+	t.Run("Vencimento em feriado é pago sem encargos no dia útil seguinte", func(t *testing.T) {
+		generator := NewInvoiceGenerator(stubChargePolicies{policy: domain.ChargePolicy{FinePercent: 2}}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
+		generator.now = func() time.Time { return time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC) }
+
+		invoice, err := generator.Generate(domain.Debt{DebtID: "004", DebtAmount: 1000, DebtDueDate: "2025-03-03"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-03-03", invoice.DueDate)
+		assert.Equal(t, "2025-03-05", invoice.EffectiveDueDate)
+		assert.Equal(t, 1000.0, invoice.Amount)
+	})
+
+	t.Run("Identificadores do boleto e do Pix", func(t *testing.T) {
+		first, _ := invoiceGenerator.Generate(domain.Debt{DebtID: "003"})
+		second, _ := invoiceGenerator.Generate(domain.Debt{DebtID: "003"})
+		other, _ := invoiceGenerator.Generate(domain.Debt{DebtID: "005"})
+
+		assert.Len(t, first.NossoNumero, 11)
+		assert.NotEqual(t, first.NossoNumero, second.NossoNumero, "a reemissão deve usar um novo nosso número")
+		assert.NotEqual(t, second.NossoNumero, other.NossoNumero)
+		assert.Equal(t, first.PixTxID, second.PixTxID)
+		assert.Len(t, first.PixTxID, 32)
+	})
+
+	t.Run("Faixa de nosso número esgotada", func(t *testing.T) {
+		generator := NewInvoiceGenerator(stubChargePolicies{}, domain.NewBusinessCalendar(), stubSequence{next: maxNossoNumero + 1}, testBeneficiary, testReceiver)
+
+		_, err := generator.Generate(domain.Debt{DebtID: "006"})
+
+		assert.ErrorIs(t, err, ErrNossoNumeroExhausted)
+	})
+}
+
+func TestInvoiceGenerator_Locale(t *testing.T) {
+	invoiceGenerator := NewInvoiceGenerator(stubChargePolicies{}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
+
+	t.Run("Idioma do débito prevalece sobre o do cliente", func(t *testing.T) {
+		invoice, err := invoiceGenerator.Generate(domain.Debt{DebtID: "007", ClientID: "acme", Locale: "en-US"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "en-US", invoice.Debt.Locale)
+	})
+
+	t.Run("Débito sem idioma usa o do cliente", func(t *testing.T) {
+		invoice, err := invoiceGenerator.Generate(domain.Debt{DebtID: "008", ClientID: "acme"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pt-BR", invoice.Debt.Locale)
+	})
+}
+
+func TestInvoiceGenerator_GeneratePaymentCodes(t *testing.T) {
+	invoiceGenerator := NewInvoiceGenerator(stubChargePolicies{}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
+	invoiceGenerator.now = func() time.Time { return time.Date(2025, 2, 9, 10, 0, 0, 0, time.UTC) }
+
+	invoice, err := invoiceGenerator.Generate(domain.Debt{DebtID: "001", DebtAmount: 1000, DebtDueDate: "2025-03-10"})
+
+	assert.NoError(t, err)
+	assert.Len(t, invoice.Barcode, 44)
+	assert.Equal(t, "2379", invoice.Barcode[:4])
+	assert.Len(t, invoice.DigitableLine, 54)
+	assert.Contains(t, invoice.PixCopyPaste, "pix.kanastra.com.br/qr/v2/"+invoice.PixTxID)
+	assert.Contains(t, invoice.PixCopyPaste, "54071000.00")
+
+	t.Run("Débito sem vencimento válido fica sem código de barras", func(t *testing.T) {
+		invoice, err := invoiceGenerator.Generate(domain.Debt{DebtID: "002", DebtAmount: 1000, DebtDueDate: "2025/03/10"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, invoice.Barcode)
+		assert.Empty(t, invoice.DigitableLine)
+		assert.NotEmpty(t, invoice.PixCopyPaste)
+	})
+}
@@ -0,0 +1,166 @@
+package external
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func testNotification() domain.Notification {
+	invoice := domain.Invoice{
+		Debt:          domain.Debt{DebtID: "abc123", Name: "João da Silva"},
+		NossoNumero:   "00012345678",
+		Amount:        1234.5,
+		DueDate:       "2025-03-10",
+		DigitableLine: "23790.00109 90001.234567 78000.000013 1 10250000123450",
+	}
+
+	return notificationOf(invoice)
+}
+
+func TestSMSNotifier_Notify(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "Bearer token-sms", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		if received["to"] == "5511000000000" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"error":"invalid number"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"id":"sms-1"}`))
+	}))
+	defer server.Close()
+
+	notifier := NewSMSNotifier(SMSConfig{BaseURL: server.URL + "/", Token: "token-sms", Sender: "Kanastra"})
+	assert.Equal(t, domain.ChannelSMS, notifier.Channel())
+
+	t.Run("Envio aceito", func(t *testing.T) {
+		id, err := notifier.Notify("5511999998888", testNotification())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "sms-1", id)
+		assert.Equal(t, "5511999998888", received["to"])
+		assert.Equal(t, "debt_notification:abc123:00012345678", received["reference"])
+		assert.Contains(t, received["text"], "João, seu boleto de R$ 1.234,50 vence em 10/03/2025.")
+		assert.Contains(t, received["text"], "23790.00109")
+	})
+
+	t.Run("Número recusado", func(t *testing.T) {
+		_, err := notifier.Notify("5511000000000", testNotification())
+
+		assert.ErrorIs(t, err, domain.ErrPermanentDeliveryFailure)
+	})
+}
+
+func TestSMSText_Reminders(t *testing.T) {
+	notification := testNotification()
+	notification.Kind = domain.OutboxKindDebtReminder
+
+	notification.DaysFromDue = -1
+	assert.Contains(t, smsText(notification), "Lembrete: João, seu boleto de R$ 1.234,50 vence em 10/03/2025.")
+
+	notification.DaysFromDue = 0
+	assert.Contains(t, smsText(notification), "vence hoje")
+
+	notification.DaysFromDue = 3
+	assert.Contains(t, smsText(notification), "venceu em 10/03/2025")
+}
+
+func TestSMSNotifier_TemporaryFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := NewSMSNotifier(SMSConfig{BaseURL: server.URL}).Notify("5511999998888", testNotification())
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrPermanentDeliveryFailure)
+}
+
+func TestWhatsAppNotifier_Notify(t *testing.T) {
+	var received whatsAppRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v20.0/998877/messages", r.URL.Path)
+		assert.Equal(t, "Bearer token-wa", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer server.Close()
+
+	notifier := NewWhatsAppNotifier(WhatsAppConfig{
+		BaseURL:       server.URL + "/v20.0",
+		Token:         "token-wa",
+		PhoneNumberID: "998877",
+		Template:      "debt_notification",
+		Language:      "pt_BR",
+	})
+
+	id, err := notifier.Notify("5511999998888", testNotification())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "wamid.1", id)
+	assert.Equal(t, domain.ChannelWhatsApp, notifier.Channel())
+	assert.Equal(t, "template", received.Type)
+	assert.Equal(t, "debt_notification", received.Template.Name)
+	assert.Equal(t, "pt_BR", received.Template.Language.Code)
+	assert.Equal(t, []whatsAppParameter{
+		{Type: "text", Text: "João"},
+		{Type: "text", Text: "R$ 1.234,50"},
+		{Type: "text", Text: "10/03/2025"},
+		{Type: "text", Text: "23790.00109 90001.234567 78000.000013 1 10250000123450"},
+	}, received.Template.Components[0].Parameters)
+}
+
+func TestWhatsAppNotifier_MissingMessageID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"messages":[]}`))
+	}))
+	defer server.Close()
+
+	_, err := NewWhatsAppNotifier(WhatsAppConfig{BaseURL: server.URL}).Notify("5511999998888", testNotification())
+
+	assert.ErrorIs(t, err, ErrWhatsAppMessageIDMissing)
+}
+
+func TestSMSText_EnglishLocale(t *testing.T) {
+	notification := testNotification()
+	notification.Invoice.Debt.Locale = "en-US"
+
+	assert.Equal(t,
+		"João, your bill of R$1,234.50 is due on 03/10/2025. Payment code: 23790.00109 90001.234567 78000.000013 1 10250000123450",
+		smsText(notification),
+	)
+}
+
+func TestWhatsAppNotifier_EnglishLocale(t *testing.T) {
+	var received whatsAppRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		_, _ = w.Write([]byte(`{"messages":[{"id":"wamid.2"}]}`))
+	}))
+	defer server.Close()
+
+	notification := testNotification()
+	notification.Invoice.Debt.Locale = "en-US"
+
+	_, err := NewWhatsAppNotifier(WhatsAppConfig{BaseURL: server.URL, Template: "debt_notification", Language: "pt_BR"}).
+		Notify("5511999998888", notification)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "en_US", received.Template.Language.Code)
+	assert.Equal(t, "R$1,234.50", received.Template.Components[0].Parameters[1].Text)
+	assert.Equal(t, "03/10/2025", received.Template.Components[0].Parameters[2].Text)
+}
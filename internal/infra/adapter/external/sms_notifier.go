@@ -0,0 +1,156 @@
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/i18n"
+)
+
+type SMSConfig struct {
+	BaseURL string
+	Token   string
+	Sender  string
+}
+
+// SMSNotifier envia a notificação de cobrança por um gateway HTTP de SMS. A mensagem traz
+// o valor, o vencimento e a linha digitável, para que o devedor possa pagar sem o e-mail.
+type SMSNotifier struct {
+	config SMSConfig
+	client *http.Client
+}
+
+func NewSMSNotifier(config SMSConfig) *SMSNotifier {
+	return &SMSNotifier{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *SMSNotifier) Channel() domain.Channel {
+	return domain.ChannelSMS
+}
+
+// Provider identifica o provedor pelo host da API.
+func (n *SMSNotifier) Provider() string {
+	return providerHost(n.config.BaseURL)
+}
+
+type smsRequest struct {
+	From      string `json:"from,omitempty"`
+	To        string `json:"to"`
+	Text      string `json:"text"`
+	Reference string `json:"reference"`
+}
+
+type smsResponse struct {
+	ID string `json:"id"`
+}
+
+func (n *SMSNotifier) Notify(recipient string, notification domain.Notification) (string, error) {
+	request := smsRequest{
+		From:      n.config.Sender,
+		To:        recipient,
+		Text:      smsText(notification),
+		Reference: notification.Key,
+	}
+
+	var response smsResponse
+	if err := postJSON(n.client, strings.TrimRight(n.config.BaseURL, "/")+"/messages", n.config.Token, request, &response); err != nil {
+		return "", fmt.Errorf("erro ao enviar SMS para %s: %w", recipient, err)
+	}
+
+	return response.ID, nil
+}
+
+func smsText(notification domain.Notification) string {
+	invoice := notification.Invoice
+	locale := i18n.Resolve(invoice.Debt.Locale)
+	name := firstName(invoice.Debt.Name, locale)
+	amount, dueDate := locale.FormatMoney(invoice.Amount), locale.FormatDate(invoice.DueDate)
+
+	var text string
+	switch {
+	case notification.IsReminder() && notification.DaysFromDue > 0:
+		text = locale.Sprintf("sms.reminder.overdue", name, amount, dueDate)
+	case notification.IsReminder() && notification.DaysFromDue == 0:
+		text = locale.Sprintf("sms.reminder.due", name, amount)
+	case notification.IsReminder():
+		text = locale.Sprintf("sms.reminder.before", name, amount, dueDate)
+	default:
+		text = locale.Sprintf("sms.notification", name, amount, dueDate)
+	}
+
+	if invoice.DigitableLine != "" {
+		text += locale.Sprintf("sms.digitable_line", invoice.DigitableLine)
+	}
+
+	return text
+}
+
+// providerHost devolve o host de baseURL, ou a própria URL quando ela não pode ser
+// interpretada.
+func providerHost(baseURL string) string {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Host == "" {
+		return baseURL
+	}
+
+	return parsed.Hostname()
+}
+
+func firstName(name string, locale i18n.Locale) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return locale.Sprintf("greeting.fallback")
+}
+
+// postJSON envia payload como JSON e decodifica a resposta em result. Respostas 4xx, exceto
+// 429, indicam que o provedor recusou o destinatário e viram
+// domain.ErrPermanentDeliveryFailure; as demais falhas podem ser repetidas.
+func postJSON(client *http.Client, url, token string, payload, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return fmt.Errorf("%w: status %d: %s", domain.ErrPermanentDeliveryFailure, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("resposta inválida do provedor: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,381 @@
+package external
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+var ErrStartTLSUnsupported = errors.New("servidor SMTP não suporta STARTTLS")
+
+// smtpTimeout limita a conexão e a sessão inteira com o servidor SMTP, para que um servidor
+// que para de responder não prenda o worker de envio.
+const smtpTimeout = time.Minute
+
+type SMTPConfig struct {
+	Host            string
+	Port            string
+	Username        string
+	Password        string
+	From            string
+	FromName        string
+	StartTLS        bool
+	TLSConfig       *tls.Config
+	TemplateVersion string
+}
+
+// InvoiceDocuments gera os anexos do e-mail de cobrança a partir do boleto emitido.
+type InvoiceDocuments interface {
+	BoletoPDF(invoice domain.Invoice) ([]byte, error)
+	PixQRCode(invoice domain.Invoice) ([]byte, error)
+}
+
+// UnsubscribeLinks gera o link assinado para o devedor deixar de receber lembretes.
+type UnsubscribeLinks interface {
+	UnsubscribeURL(governmentID, email string) string
+}
+
+type SMTPEmailPublisher struct {
+	config      SMTPConfig
+	templates   *EmailTemplates
+	documents   InvoiceDocuments
+	unsubscribe UnsubscribeLinks
+	timeout     time.Duration
+	now         func() time.Time
+}
+
+// NewSMTPEmailPublisher cria o publicador SMTP. Com unsubscribe nil, os e-mails seguem sem
+// link de descadastro.
+func NewSMTPEmailPublisher(config SMTPConfig, documents InvoiceDocuments, unsubscribe UnsubscribeLinks) (*SMTPEmailPublisher, error) {
+	templates, err := NewEmailTemplates(config.TemplateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("remetente de e-mail inválido: %w", err)
+	}
+
+	return &SMTPEmailPublisher{config: config, templates: templates, documents: documents, unsubscribe: unsubscribe, timeout: smtpTimeout, now: time.Now}, nil
+}
+
+// Publish envia a notificação e devolve o Message-ID do e-mail. Recusas definitivas do
+// servidor (respostas 5xx) são devolvidas como domain.ErrPermanentDeliveryFailure.
+func (p *SMTPEmailPublisher) Publish(email string, notification domain.Notification) (string, error) {
+	invoice := notification.Invoice
+	messageID := p.messageID(notification.Key)
+
+	attachments, err := p.attachments(invoice)
+	if err != nil {
+		return messageID, err
+	}
+
+	var unsubscribeURL string
+	if p.unsubscribe != nil {
+		unsubscribeURL = p.unsubscribe.UnsubscribeURL(invoice.Debt.GovernmentID, email)
+	}
+
+	rendered, err := p.templates.Render(notification, attachments.qrCode != nil, unsubscribeURL)
+	if err != nil {
+		return messageID, err
+	}
+
+	message, err := p.buildMessage(email, rendered, attachments, messageID, unsubscribeURL)
+	if err != nil {
+		return messageID, err
+	}
+
+	if err := p.send(email, message); err != nil {
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			return messageID, fmt.Errorf("%w: %s: %v", domain.ErrPermanentDeliveryFailure, email, err)
+		}
+
+		return messageID, fmt.Errorf("erro ao enviar e-mail para %s: %w", email, err)
+	}
+
+	log.Printf("E-mail enviado com sucesso para %s sobre débito: %s", email, invoice.Debt.DebtID)
+
+	return messageID, nil
+}
+
+type emailAttachments struct {
+	boletoName string
+	boletoPDF  []byte
+	qrCode     []byte
+}
+
+// attachments gera o PDF do boleto e o QR Code do Pix. Um boleto sem código de barras ou
+// sem Pix copia-e-cola é enviado sem o anexo correspondente.
+func (p *SMTPEmailPublisher) attachments(invoice domain.Invoice) (emailAttachments, error) {
+	var attachments emailAttachments
+
+	if invoice.Barcode != "" {
+		pdf, err := p.documents.BoletoPDF(invoice)
+		if err != nil {
+			return attachments, fmt.Errorf("erro ao gerar PDF do boleto do débito %s: %w", invoice.Debt.DebtID, err)
+		}
+
+		attachments.boletoName = fmt.Sprintf("boleto-%s.pdf", invoice.NossoNumero)
+		attachments.boletoPDF = pdf
+	}
+
+	if invoice.PixCopyPaste != "" {
+		qrCode, err := p.documents.PixQRCode(invoice)
+		if err != nil {
+			return attachments, fmt.Errorf("erro ao gerar QR Code Pix do débito %s: %w", invoice.Debt.DebtID, err)
+		}
+
+		attachments.qrCode = qrCode
+	}
+
+	return attachments, nil
+}
+
+func (p *SMTPEmailPublisher) send(to string, message []byte) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(p.config.Host, p.config.Port), p.timeout)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(p.timeout)); err != nil {
+		_ = conn.Close()
+
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, p.config.Host)
+	if err != nil {
+		_ = conn.Close()
+
+		return err
+	}
+
+	defer func(client *smtp.Client) {
+		_ = client.Close()
+	}(client)
+
+	if p.config.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrStartTLSUnsupported
+		}
+
+		tlsConfig := p.config.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: p.config.Host, MinVersion: tls.VersionTLS12}
+		}
+
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("erro ao iniciar STARTTLS: %w", err)
+		}
+	}
+
+	if p.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host)); err != nil {
+			return fmt.Errorf("erro de autenticação SMTP: %w", err)
+		}
+	}
+
+	if err := client.Mail(p.config.From); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage monta a mensagem como multipart/mixed: o corpo multipart/related traz as
+// versões texto e HTML e o QR Code referenciado pelo HTML, e o boleto segue como anexo. Com
+// link de descadastro, a mensagem traz os cabeçalhos de descadastro com um clique (RFC 8058).
+func (p *SMTPEmailPublisher) buildMessage(
+	to string,
+	rendered RenderedEmail,
+	attachments emailAttachments,
+	messageID string,
+	unsubscribeURL string,
+) ([]byte, error) {
+	alternative, err := buildMultipart("alternative", func(writer *multipart.Writer) error {
+		if err := writeQuotedPrintablePart(writer, "text/plain; charset=UTF-8", rendered.Text); err != nil {
+			return err
+		}
+
+		return writeQuotedPrintablePart(writer, "text/html; charset=UTF-8", rendered.HTML)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := buildMultipart("related", func(writer *multipart.Writer) error {
+		if err := writePart(writer, textproto.MIMEHeader{"Content-Type": {alternative.contentType}}, alternative.body); err != nil {
+			return err
+		}
+
+		if attachments.qrCode == nil {
+			return nil
+		}
+
+		return writeBase64Part(writer, textproto.MIMEHeader{
+			"Content-Type":        {"image/png"},
+			"Content-ID":          {"<" + QRCodeContentID + ">"},
+			"Content-Disposition": {`inline; filename="pix-qrcode.png"`},
+		}, attachments.qrCode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mixed, err := buildMultipart("mixed", func(writer *multipart.Writer) error {
+		if err := writePart(writer, textproto.MIMEHeader{"Content-Type": {related.contentType + `; type="multipart/alternative"`}}, related.body); err != nil {
+			return err
+		}
+
+		if attachments.boletoPDF == nil {
+			return nil
+		}
+
+		return writeBase64Part(writer, textproto.MIMEHeader{
+			"Content-Type":        {fmt.Sprintf("application/pdf; name=%q", attachments.boletoName)},
+			"Content-Disposition": {fmt.Sprintf("attachment; filename=%q", attachments.boletoName)},
+		}, attachments.boletoPDF)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	from := mail.Address{Name: p.config.FromName, Address: p.config.From}
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", (&mail.Address{Address: to}).String()},
+		{"Subject", mime.QEncoding.Encode("UTF-8", rendered.Subject)},
+		{"Date", p.now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+	}
+
+	if unsubscribeURL != "" {
+		headers = append(headers,
+			[2]string{"List-Unsubscribe", "<" + unsubscribeURL + ">"},
+			[2]string{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+		)
+	}
+
+	headers = append(headers, [2]string{"MIME-Version", "1.0"}, [2]string{"Content-Type", mixed.contentType})
+
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+
+	message.WriteString("\r\n")
+	message.Write(mixed.body)
+
+	return message.Bytes(), nil
+}
+
+type multipartBody struct {
+	contentType string
+	body        []byte
+}
+
+// buildMultipart monta um corpo multipart completo, permitindo aninhá-lo como parte de
+// outro multipart com o boundary já conhecido.
+func buildMultipart(subtype string, write func(writer *multipart.Writer) error) (multipartBody, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	if err := write(writer); err != nil {
+		return multipartBody{}, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return multipartBody{}, err
+	}
+
+	return multipartBody{contentType: "multipart/" + subtype + "; boundary=" + writer.Boundary(), body: body.Bytes()}, nil
+}
+
+func writePart(writer *multipart.Writer, header textproto.MIMEHeader, content []byte) error {
+	partWriter, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = partWriter.Write(content)
+
+	return err
+}
+
+func writeQuotedPrintablePart(writer *multipart.Writer, contentType, content string) error {
+	partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := quotedprintable.NewWriter(partWriter)
+	if _, err := encoder.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// writeBase64Part codifica o conteúdo em base64 com linhas de 76 caracteres (RFC 2045).
+func writeBase64Part(writer *multipart.Writer, header textproto.MIMEHeader, content []byte) error {
+	header.Set("Content-Transfer-Encoding", "base64")
+
+	encoded := base64.StdEncoding.EncodeToString(content)
+	var wrapped bytes.Buffer
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded + "\r\n")
+
+	return writePart(writer, header, wrapped.Bytes())
+}
+
+// messageID deriva o Message-ID da chave de idempotência da notificação, para que as
+// tentativas de uma mesma notificação sejam correlacionadas nos bounces e reclamações. A
+// entrega é pelo menos uma vez: se o envio for aceito pelo servidor SMTP e a falha vier
+// antes do registro no outbox, a notificação é enviada de novo, e os servidores de e-mail
+// não descartam a cópia pelo Message-ID repetido.
+func (p *SMTPEmailPublisher) messageID(idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(idempotencyKey))
+	_, domainPart, _ := strings.Cut(p.config.From, "@")
+
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(sum[:16]), domainPart)
+}
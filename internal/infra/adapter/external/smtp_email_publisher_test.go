@@ -0,0 +1,496 @@
+package external
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/adapter/persistence"
+)
+
+type receivedEmail struct {
+	From     string
+	To       []string
+	Auth     string
+	TLS      bool
+	Data     string
+	Greeting string
+}
+
+// fakeSMTPServer implementa o mínimo do protocolo SMTP para os testes, guardando as
+// mensagens recebidas em memória.
+type fakeSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	mu        sync.Mutex
+	received  []receivedEmail
+}
+
+func newFakeSMTPServer(t *testing.T, tlsConfig *tls.Config) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener, tlsConfig: tlsConfig}
+	go server.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return server
+}
+
+func (s *fakeSMTPServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+
+	return port
+}
+
+func (s *fakeSMTPServer) messages() []receivedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]receivedEmail(nil), s.received...)
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 fake-smtp pronto")
+
+	var current receivedEmail
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command, argument, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			current.Greeting = argument
+			_ = text.PrintfLine("250-fake-smtp")
+			if s.tlsConfig != nil && !current.TLS {
+				_ = text.PrintfLine("250-STARTTLS")
+			}
+			_ = text.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			_ = text.PrintfLine("220 pronto para TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn = tlsConn
+			text = textproto.NewConn(conn)
+			current.TLS = true
+		case "AUTH":
+			_, credentials, _ := strings.Cut(argument, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(credentials)
+			current.Auth = string(decoded)
+			_ = text.PrintfLine("235 autenticado")
+		case "MAIL":
+			current.From = strings.Trim(strings.TrimPrefix(argument, "FROM:"), "<>")
+			_ = text.PrintfLine("250 ok")
+		case "RCPT":
+			recipient := strings.Trim(strings.TrimPrefix(argument, "TO:"), "<>")
+			if strings.HasPrefix(recipient, "inexistente@") {
+				_ = text.PrintfLine("550 5.1.1 usuário inexistente")
+
+				continue
+			}
+
+			current.To = append(current.To, recipient)
+			_ = text.PrintfLine("250 ok")
+		case "DATA":
+			_ = text.PrintfLine("354 envie a mensagem")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			current.Data = string(data)
+			s.mu.Lock()
+			s.received = append(s.received, current)
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 mensagem aceita")
+		case "QUIT":
+			_ = text.PrintfLine("221 tchau")
+
+			return
+		default:
+			_ = text.PrintfLine("250 ok")
+		}
+	}
+}
+
+func selfSignedTLSConfig(t *testing.T) (*tls.Config, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}, pool
+}
+
+func testInvoice(t *testing.T) domain.Invoice {
+	generator := NewInvoiceGenerator(stubChargePolicies{}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), testBeneficiary, testReceiver)
+	generator.now = func() time.Time { return time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC) }
+
+	invoice, err := generator.Generate(domain.Debt{
+		Name:         "João Silva",
+		GovernmentID: "12345678901",
+		Email:        "joao@example.com",
+		DebtAmount:   1234.5,
+		DebtDueDate:  "2025-03-10",
+		DebtID:       "abc123",
+	})
+	require.NoError(t, err)
+
+	return invoice
+}
+
+func notificationOf(invoice domain.Invoice) domain.Notification {
+	return domain.Notification{Key: domain.DebtNotificationKey(invoice), Kind: domain.OutboxKindDebtNotification, Invoice: invoice}
+}
+
+type messagePart struct {
+	header  textproto.MIMEHeader
+	content string
+}
+
+// readParts percorre recursivamente os multiparts da mensagem e indexa as partes folha
+// pelo tipo de conteúdo.
+func readParts(t *testing.T, data string) map[string]messagePart {
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	require.NoError(t, err)
+
+	parts := make(map[string]messagePart)
+	collectParts(t, message.Header.Get("Content-Type"), message.Body, parts)
+
+	return parts
+}
+
+func collectParts(t *testing.T, contentType string, body io.Reader, parts map[string]messagePart) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, mediaType)
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if strings.HasPrefix(partType, "multipart/") {
+			collectParts(t, part.Header.Get("Content-Type"), part, parts)
+
+			continue
+		}
+
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			content, err = base64.StdEncoding.DecodeString(strings.ReplaceAll(string(content), "\r\n", ""))
+			require.NoError(t, err)
+		}
+
+		parts[partType] = messagePart{header: part.Header, content: string(content)}
+	}
+}
+
+type stubUnsubscribeLinks struct{}
+
+func (stubUnsubscribeLinks) UnsubscribeURL(governmentID, email string) string {
+	return "https://cobranca.example.com/unsubscribe?token=" + governmentID + "|" + email
+}
+
+func TestSMTPEmailPublisher_Publish(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	publisher, err := NewSMTPEmailPublisher(SMTPConfig{
+		Host:            "127.0.0.1",
+		Port:            server.port(),
+		Username:        "usuario",
+		Password:        "senha",
+		From:            "cobranca@kanastra.com.br",
+		FromName:        "Kanastra Cobrança",
+		TemplateVersion: "v1",
+	}, NewPaymentDocuments(testBeneficiary), stubUnsubscribeLinks{})
+	require.NoError(t, err)
+
+	messageID, err := publisher.Publish("joao@example.com", notificationOf(testInvoice(t)))
+	require.NoError(t, err)
+
+	messages := server.messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "cobranca@kanastra.com.br", messages[0].From)
+	assert.Equal(t, []string{"joao@example.com"}, messages[0].To)
+	assert.Equal(t, "\x00usuario\x00senha", messages[0].Auth)
+
+	message, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Boleto disponível: R$ 1.234,50 com vencimento em 10/03/2025", subject)
+	assert.Contains(t, message.Header.Get("Message-ID"), "@kanastra.com.br>")
+	assert.Equal(t, messageID, message.Header.Get("Message-ID"))
+	assert.Equal(t, publisher.messageID(domain.DebtNotificationKey(testInvoice(t))), messageID, "o Message-ID deve ser estável para a mesma notificação")
+
+	assert.True(t, strings.HasPrefix(message.Header.Get("Content-Type"), "multipart/mixed"))
+	assert.Equal(t, "<https://cobranca.example.com/unsubscribe?token=12345678901|joao@example.com>", message.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", message.Header.Get("List-Unsubscribe-Post"))
+
+	invoice := testInvoice(t)
+	parts := readParts(t, messages[0].Data)
+	assert.Contains(t, parts["text/plain"].content, "Olá, João Silva.")
+	assert.Contains(t, parts["text/plain"].content, "abc123")
+	assert.Contains(t, parts["text/plain"].content, invoice.DigitableLine)
+	assert.Contains(t, parts["text/plain"].content, invoice.PixCopyPaste)
+	assert.Contains(t, parts["text/html"].content, "<strong>R$ 1.234,50</strong>")
+	assert.Contains(t, parts["text/html"].content, `src="cid:`+QRCodeContentID+`"`)
+	assert.Contains(t, parts["text/html"].content, `<a href="https://cobranca.example.com/unsubscribe?token=12345678901%7cjoao@example.com">Descadastre-se</a>`)
+
+	qrCode := parts["image/png"]
+	assert.Equal(t, "<"+QRCodeContentID+">", qrCode.header.Get("Content-ID"))
+	assert.True(t, strings.HasPrefix(qrCode.content, "\x89PNG"))
+
+	pdf := parts["application/pdf"]
+	assert.Contains(t, pdf.header.Get("Content-Disposition"), "attachment; filename=\"boleto-"+invoice.NossoNumero+".pdf\"")
+	assert.True(t, strings.HasPrefix(pdf.content, "%PDF-1.4"))
+}
+
+func TestSMTPEmailPublisher_StartTLS(t *testing.T) {
+	serverTLS, pool := selfSignedTLSConfig(t)
+	server := newFakeSMTPServer(t, serverTLS)
+
+	publisher, err := NewSMTPEmailPublisher(SMTPConfig{
+		Host:            "127.0.0.1",
+		Port:            server.port(),
+		From:            "cobranca@kanastra.com.br",
+		StartTLS:        true,
+		TLSConfig:       &tls.Config{RootCAs: pool, ServerName: "127.0.0.1", MinVersion: tls.VersionTLS12},
+		TemplateVersion: "v1",
+	}, NewPaymentDocuments(testBeneficiary), nil)
+	require.NoError(t, err)
+
+	_, err = publisher.Publish("joao@example.com", notificationOf(testInvoice(t)))
+	require.NoError(t, err)
+
+	messages := server.messages()
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].TLS)
+}
+
+func TestSMTPEmailPublisher_WithoutPaymentCodes(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	publisher, err := NewSMTPEmailPublisher(SMTPConfig{
+		Host:            "127.0.0.1",
+		Port:            server.port(),
+		From:            "cobranca@kanastra.com.br",
+		TemplateVersion: "v1",
+	}, NewPaymentDocuments(testBeneficiary), nil)
+	require.NoError(t, err)
+
+	invoice := testInvoice(t)
+	invoice.Barcode, invoice.DigitableLine, invoice.PixCopyPaste = "", "", ""
+	_, err = publisher.Publish("joao@example.com", notificationOf(invoice))
+	require.NoError(t, err)
+
+	messages := server.messages()
+	require.Len(t, messages, 1)
+
+	parts := readParts(t, messages[0].Data)
+	assert.Contains(t, parts, "text/html")
+	assert.NotContains(t, parts, "image/png")
+	assert.NotContains(t, parts, "application/pdf")
+	assert.NotContains(t, parts["text/html"].content, "cid:")
+}
+
+func TestSMTPEmailPublisher_PermanentFailure(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	publisher, err := NewSMTPEmailPublisher(SMTPConfig{
+		Host:            "127.0.0.1",
+		Port:            server.port(),
+		From:            "cobranca@kanastra.com.br",
+		TemplateVersion: "v1",
+	}, NewPaymentDocuments(testBeneficiary), stubUnsubscribeLinks{})
+	require.NoError(t, err)
+
+	messageID, err := publisher.Publish("inexistente@example.com", notificationOf(testInvoice(t)))
+
+	assert.ErrorIs(t, err, domain.ErrPermanentDeliveryFailure)
+	assert.NotEmpty(t, messageID)
+	assert.Empty(t, server.messages())
+}
+
+func TestSMTPEmailPublisher_StartTLSRequired(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+
+	publisher, err := NewSMTPEmailPublisher(SMTPConfig{
+		Host:            "127.0.0.1",
+		Port:            server.port(),
+		From:            "cobranca@kanastra.com.br",
+		StartTLS:        true,
+		TemplateVersion: "v1",
+	}, NewPaymentDocuments(testBeneficiary), nil)
+	require.NoError(t, err)
+
+	_, err = publisher.Publish("joao@example.com", notificationOf(testInvoice(t)))
+	assert.ErrorIs(t, err, ErrStartTLSUnsupported)
+	assert.NotErrorIs(t, err, domain.ErrPermanentDeliveryFailure)
+	assert.Empty(t, server.messages())
+}
+
+func TestSMTPEmailPublisher_Timeout(t *testing.T) {
+	// O servidor aceita a conexão e nunca envia a saudação.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	publisher, err := NewSMTPEmailPublisher(SMTPConfig{
+		Host:            "127.0.0.1",
+		Port:            port,
+		From:            "cobranca@kanastra.com.br",
+		TemplateVersion: "v1",
+	}, NewPaymentDocuments(testBeneficiary), nil)
+	require.NoError(t, err)
+	publisher.timeout = 100 * time.Millisecond
+
+	started := time.Now()
+	_, err = publisher.Publish("joao@example.com", notificationOf(testInvoice(t)))
+
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
+
+func TestNewSMTPEmailPublisher_InvalidConfig(t *testing.T) {
+	_, err := NewSMTPEmailPublisher(SMTPConfig{From: "cobranca@kanastra.com.br", TemplateVersion: "v999"}, NewPaymentDocuments(testBeneficiary), nil)
+	assert.Error(t, err)
+
+	_, err = NewSMTPEmailPublisher(SMTPConfig{From: "invalido", TemplateVersion: "v1"}, NewPaymentDocuments(testBeneficiary), nil)
+	assert.Error(t, err)
+}
+
+func TestEmailTemplates_EscapesHTML(t *testing.T) {
+	templates, err := NewEmailTemplates("v1")
+	require.NoError(t, err)
+
+	invoice := testInvoice(t)
+	invoice.Debt.Name = "<script>alert(1)</script>"
+	rendered, err := templates.Render(notificationOf(invoice), false, "")
+	require.NoError(t, err)
+
+	assert.NotContains(t, rendered.HTML, "<script>")
+	assert.Contains(t, rendered.Text, "<script>")
+}
+
+func TestEmailTemplates_Reminders(t *testing.T) {
+	templates, err := NewEmailTemplates("v1")
+	require.NoError(t, err)
+
+	invoice := testInvoice(t)
+	reminder := func(daysFromDue int) RenderedEmail {
+		rendered, err := templates.Render(domain.Notification{
+			Key: domain.DebtReminderKey(invoice, daysFromDue), Kind: domain.OutboxKindDebtReminder, Invoice: invoice, DaysFromDue: daysFromDue,
+		}, false, "")
+		require.NoError(t, err)
+
+		return rendered
+	}
+
+	before := reminder(-5)
+	assert.Equal(t, "Lembrete: seu boleto de R$ 1.234,50 vence em 10/03/2025", before.Subject)
+	assert.Contains(t, before.Text, "daqui a 5 dias")
+
+	assert.Equal(t, "Seu boleto de R$ 1.234,50 vence hoje", reminder(0).Subject)
+
+	overdue := reminder(1)
+	assert.Equal(t, "Boleto vencido há 1 dia: R$ 1.234,50", overdue.Subject)
+	assert.Contains(t, overdue.HTML, "ainda não identificamos o pagamento")
+}
+
+func TestEmailTemplates_EnglishLocale(t *testing.T) {
+	templates, err := NewEmailTemplates("v1")
+	require.NoError(t, err)
+
+	invoice := testInvoice(t)
+	invoice.Debt.Locale = "en-US"
+	rendered, err := templates.Render(notificationOf(invoice), false, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "en-US", string(rendered.Locale))
+	assert.Equal(t, "Your bill is available: R$1,234.50 due on 03/10/2025", rendered.Subject)
+	assert.Contains(t, rendered.Text, "Pay the bank slip")
+	assert.Contains(t, rendered.HTML, `lang="en-US"`)
+
+	invoice.Debt.Locale = "fr-FR"
+	rendered, err = templates.Render(notificationOf(invoice), false, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "pt-BR", string(rendered.Locale))
+}
@@ -0,0 +1,110 @@
+package external
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookAttemptHeader   = "X-Webhook-Attempt"
+)
+
+// ErrWebhookTargetNotAllowed indica um destino de webhook na rede interna.
+var ErrWebhookTargetNotAllowed = errors.New("destino do webhook não permitido")
+
+// WebhookSender envia os eventos aos sistemas dos clientes. A assinatura é o HMAC-SHA256,
+// com o segredo da inscrição, do horário do envio em segundos Unix seguido de um ponto e
+// do corpo, enviada como t=<segundos>,sha256=<hex>; o cliente recusa assinaturas antigas
+// para que uma entrega capturada não seja repetida.
+type WebhookSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookSender só conecta a endereços públicos. O endereço é conferido na conexão,
+// depois da resolução do nome, inclusive nos redirecionamentos, e o envio não passa por
+// proxy, que esconderia o destino real.
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	dialer := &net.Dialer{Timeout: timeout, Control: publicAddressOnly}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+
+	return &WebhookSender{client: &http.Client{Timeout: timeout, Transport: transport}, now: time.Now}
+}
+
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, address)
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !domain.IsPublicWebhookAddress(addr) {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, address)
+	}
+
+	return nil
+}
+
+func (s *WebhookSender) Send(delivery domain.WebhookDelivery, secret string) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao serializar evento %s: %w", delivery.Event.ID, err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao montar requisição do webhook: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	request.Header.Set(WebhookSignatureHeader, "t="+timestamp+",sha256="+signWebhook([]byte(secret), timestamp, body))
+	request.Header.Set(WebhookEventHeader, string(delivery.Event.Type))
+	request.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	request.Header.Set(WebhookAttemptHeader, strconv.Itoa(delivery.Attempts+1))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao enviar webhook para %s: %w", delivery.URL, err)
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook %s respondeu com status %d", delivery.URL, response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// signWebhook devolve o HMAC-SHA256 de "<timestamp>.<body>" em hexadecimal.
+func signWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
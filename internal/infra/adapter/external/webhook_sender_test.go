@@ -0,0 +1,76 @@
+package external
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestWebhookSender_Send(t *testing.T) {
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("whsec_1"))
+		mac.Write([]byte("1740823200."))
+		mac.Write(body)
+		assert.Equal(t, "t=1740823200,sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(WebhookSignatureHeader))
+		assert.Equal(t, "debt.paid", r.Header.Get(WebhookEventHeader))
+		assert.Equal(t, "debt.paid:d1:1@wh_1", r.Header.Get(WebhookDeliveryHeader))
+		assert.Equal(t, "3", r.Header.Get(WebhookAttemptHeader))
+
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, "debt.paid:d1:1", event["id"])
+		assert.Equal(t, "acme", event["client_id"])
+		assert.Equal(t, "d1", event["data"].(map[string]any)["debt_id"])
+
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	event, err := domain.NewWebhookEvent("debt.paid:d1:1", domain.WebhookEventDebtPaid, "acme", domain.WebhookDebtData{DebtID: "d1"}, time.Now())
+	assert.NoError(t, err)
+
+	delivery := domain.NewWebhookDelivery(domain.WebhookSubscription{ID: "wh_1", ClientID: "acme", URL: server.URL}, event, time.Now())
+	delivery.Attempts = 2
+	sender := NewWebhookSender(5 * time.Second)
+	sender.now = func() time.Time { return time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC) }
+
+	t.Run("Destino na rede interna", func(t *testing.T) {
+		_, err := sender.Send(delivery, "whsec_1")
+
+		assert.ErrorIs(t, err, ErrWebhookTargetNotAllowed)
+	})
+
+	// O servidor de teste escuta em 127.0.0.1, então os demais envios usam um cliente sem
+	// a restrição de endereço.
+	sender.client = server.Client()
+
+	t.Run("Entrega aceita", func(t *testing.T) {
+		code, err := sender.Send(delivery, "whsec_1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, code)
+	})
+
+	t.Run("Resposta de erro do cliente", func(t *testing.T) {
+		status = http.StatusInternalServerError
+
+		code, err := sender.Send(delivery, "whsec_1")
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+}
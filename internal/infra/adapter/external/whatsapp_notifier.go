@@ -0,0 +1,129 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/i18n"
+)
+
+var ErrWhatsAppMessageIDMissing = errors.New("resposta do WhatsApp sem identificador da mensagem")
+
+type WhatsAppConfig struct {
+	BaseURL       string
+	Token         string
+	PhoneNumberID string
+	Template      string
+	// ReminderTemplate é o template dos lembretes da régua de cobrança; quando vazio, os
+	// lembretes usam Template.
+	ReminderTemplate string
+	// Language é o código de idioma dos templates para devedores no idioma padrão; os
+	// demais idiomas usam o código correspondente (por exemplo, en_US).
+	Language string
+}
+
+// WhatsAppNotifier envia a notificação de cobrança pela WhatsApp Cloud API. Mensagens
+// iniciadas pela empresa exigem um template aprovado; os parâmetros do corpo são, em ordem,
+// o nome do devedor, o valor, o vencimento e a linha digitável.
+type WhatsAppNotifier struct {
+	config WhatsAppConfig
+	client *http.Client
+}
+
+func NewWhatsAppNotifier(config WhatsAppConfig) *WhatsAppNotifier {
+	return &WhatsAppNotifier{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WhatsAppNotifier) Channel() domain.Channel {
+	return domain.ChannelWhatsApp
+}
+
+// Provider identifica o provedor pelo host da API.
+func (n *WhatsAppNotifier) Provider() string {
+	return providerHost(n.config.BaseURL)
+}
+
+type whatsAppRequest struct {
+	MessagingProduct string           `json:"messaging_product"`
+	To               string           `json:"to"`
+	Type             string           `json:"type"`
+	Template         whatsAppTemplate `json:"template"`
+}
+
+type whatsAppTemplate struct {
+	Name       string              `json:"name"`
+	Language   whatsAppLanguage    `json:"language"`
+	Components []whatsAppComponent `json:"components"`
+}
+
+type whatsAppLanguage struct {
+	Code string `json:"code"`
+}
+
+type whatsAppComponent struct {
+	Type       string              `json:"type"`
+	Parameters []whatsAppParameter `json:"parameters"`
+}
+
+type whatsAppParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type whatsAppResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+func (n *WhatsAppNotifier) Notify(recipient string, notification domain.Notification) (string, error) {
+	invoice := notification.Invoice
+	template := n.config.Template
+	if notification.IsReminder() && n.config.ReminderTemplate != "" {
+		template = n.config.ReminderTemplate
+	}
+
+	locale := i18n.Resolve(invoice.Debt.Locale)
+	language := n.config.Language
+	if locale != i18n.Default || language == "" {
+		language = locale.WhatsAppLanguage()
+	}
+
+	var parameters []whatsAppParameter
+	for _, value := range []string{
+		firstName(invoice.Debt.Name, locale),
+		locale.FormatMoney(invoice.Amount),
+		locale.FormatDate(invoice.DueDate),
+		invoice.DigitableLine,
+	} {
+		parameters = append(parameters, whatsAppParameter{Type: "text", Text: value})
+	}
+
+	request := whatsAppRequest{
+		MessagingProduct: "whatsapp",
+		To:               recipient,
+		Type:             "template",
+		Template: whatsAppTemplate{
+			Name:       template,
+			Language:   whatsAppLanguage{Code: language},
+			Components: []whatsAppComponent{{Type: "body", Parameters: parameters}},
+		},
+	}
+
+	url := fmt.Sprintf("%s/%s/messages", strings.TrimRight(n.config.BaseURL, "/"), n.config.PhoneNumberID)
+
+	var response whatsAppResponse
+	if err := postJSON(n.client, url, n.config.Token, request, &response); err != nil {
+		return "", fmt.Errorf("erro ao enviar WhatsApp para %s: %w", recipient, err)
+	}
+
+	if len(response.Messages) == 0 || response.Messages[0].ID == "" {
+		return "", ErrWhatsAppMessageIDMissing
+	}
+
+	return response.Messages[0].ID, nil
+}
@@ -0,0 +1,68 @@
+package fileformat
+
+import (
+	"io"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/archive"
+	"kanastra-api/internal/infra/adapter/csvformat"
+	"kanastra-api/internal/infra/adapter/xlsx"
+)
+
+// Decoder lê os CSVs, compactados ou não, e as planilhas XLSX da ingestão de arquivos.
+// Os limites valem para cada arquivo: para um .csv.gz, para os CSVs de um zip, somados, e
+// para o pacote de uma planilha.
+type Decoder struct {
+	limits archive.Limits
+}
+
+func NewDecoder(limits archive.Limits) *Decoder {
+	return &Decoder{limits: limits}
+}
+
+func (d *Decoder) ParseFormat(delimiter, encoding string) (domain.FileFormat, error) {
+	var format domain.FileFormat
+	var err error
+
+	if format.Delimiter, err = csvformat.ParseDelimiter(delimiter); err != nil {
+		return format, err
+	}
+
+	if format.Encoding, err = csvformat.ParseEncoding(encoding); err != nil {
+		return format, err
+	}
+
+	return format, nil
+}
+
+func (d *Decoder) OpenCSV(content io.Reader, format domain.FileFormat) (io.Reader, domain.FileFormat, error) {
+	return csvformat.Open(content, format)
+}
+
+func (d *Decoder) OpenGzip(content io.Reader) (io.ReadCloser, error) {
+	return archive.OpenGzip(content, d.limits)
+}
+
+func (d *Decoder) OpenZip(file io.ReaderAt, size int64) ([]usecase.ZipEntry, error) {
+	zipFile, err := archive.OpenZip(file, size, d.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]usecase.ZipEntry, 0, len(zipFile.Entries))
+	for _, entry := range zipFile.Entries {
+		entries = append(entries, usecase.ZipEntry{Name: entry.Name, Open: entry.Open})
+	}
+
+	return entries, nil
+}
+
+func (d *Decoder) OpenSheet(file io.ReaderAt, size int64, sheet string) (usecase.SheetReader, error) {
+	reader, err := xlsx.Open(file, size, sheet, d.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
@@ -0,0 +1,67 @@
+package filesource
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// ProcessFunc processa o arquivo até o fim e devolve os envios criados.
+type ProcessFunc func(path string) ([]string, error)
+
+// FileProcessor processa um arquivo até o fim como um arquivo enviado a /process-files
+// pelo cliente clientID, como o FileIntakeUseCase.
+type FileProcessor interface {
+	ProcessFile(file io.ReaderAt, fileName string, size int64, clientID string) ([]string, error)
+}
+
+// ForClient devolve a ProcessFunc que abre o arquivo e o entrega a processor como enviado
+// pelo cliente clientID. O arquivo não é removido.
+func ForClient(processor FileProcessor, clientID string) ProcessFunc {
+	return func(path string) ([]string, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		return processor.ProcessFile(file, filepath.Base(path), info.Size(), clientID)
+	}
+}
+
+// JobFinder consulta os envios criados para o arquivo.
+type JobFinder interface {
+	FindByID(id string) (domain.IngestionJob, bool)
+}
+
+// Process processa o arquivo lido de uma origem, como o drop folder ou um servidor SFTP, e
+// devolve os envios criados. O arquivo falha quando não cria nenhum envio ou quando um
+// envio é descartado por inteiro, como um arquivo sem as colunas obrigatórias; nesse caso,
+// o erro traz o motivo do primeiro envio descartado.
+func Process(process ProcessFunc, jobs JobFinder, path string) ([]string, error) {
+	jobIDs, err := process(path)
+	if err != nil {
+		return jobIDs, err
+	}
+
+	if len(jobIDs) == 0 {
+		return jobIDs, errors.New("nenhum arquivo CSV encontrado")
+	}
+
+	for _, id := range jobIDs {
+		if job, exists := jobs.FindByID(id); exists && job.Error != "" {
+			return jobIDs, fmt.Errorf("envio %s descartado: %s", id, job.Error)
+		}
+	}
+
+	return jobIDs, nil
+}
@@ -0,0 +1,66 @@
+package filesource
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type jobsByID map[string]domain.IngestionJob
+
+func (j jobsByID) FindByID(id string) (domain.IngestionJob, bool) {
+	job, exists := j[id]
+
+	return job, exists
+}
+
+func TestProcess(t *testing.T) {
+	jobs := jobsByID{"job_b": {ID: "job_b", Error: "colunas obrigatórias ausentes: debtId"}}
+	returning := func(jobIDs []string, err error) ProcessFunc {
+		return func(string) ([]string, error) { return jobIDs, err }
+	}
+
+	jobIDs, err := Process(returning([]string{"job_a"}, nil), jobs, "a.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"job_a"}, jobIDs)
+
+	jobIDs, err = Process(returning([]string{"job_a", "job_b"}, nil), jobs, "b.zip")
+	assert.EqualError(t, err, "envio job_b descartado: colunas obrigatórias ausentes: debtId")
+	assert.Equal(t, []string{"job_a", "job_b"}, jobIDs)
+
+	_, err = Process(returning(nil, nil), jobs, "vazio.zip")
+	assert.EqualError(t, err, "nenhum arquivo CSV encontrado")
+
+	_, err = Process(returning(nil, errors.New("arquivo ilegível")), jobs, "c.csv")
+	assert.EqualError(t, err, "arquivo ilegível")
+}
+
+// readingProcessor lê o arquivo inteiro e devolve um envio com o nome, o conteúdo e o
+// cliente recebidos.
+type readingProcessor struct{}
+
+func (readingProcessor) ProcessFile(file io.ReaderAt, fileName string, size int64, clientID string) ([]string, error) {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+
+	return []string{fileName + ":" + string(content) + ":" + clientID}, err
+}
+
+func TestForClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remessa.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("name\nJohn Doe\n"), 0o644))
+
+	jobIDs, err := ForClient(readingProcessor{}, "acme")(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remessa.csv:name\nJohn Doe\n:acme"}, jobIDs)
+	assert.FileExists(t, path, "o arquivo é mantido para quem chamou")
+
+	_, err = ForClient(readingProcessor{}, "acme")(filepath.Join(t.TempDir(), "ausente.csv"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
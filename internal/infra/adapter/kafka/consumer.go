@@ -1,13 +1,15 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -16,67 +18,88 @@ import (
 )
 
 type Reader interface {
-	ReadMessage(ctx context.Context) (kafka.Message, error)
+	FetchMessage(ctx context.Context) (kafka.Message, error)
 	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
 	Close() error
 }
 
 type DebtRepositoryInterface interface {
-	Save(debtID string) error
+	SaveWithOutbox(debtID string, messages []domain.OutboxMessage) error
 }
 
+const (
+	numWorkers = 10
+	// maxAttempts é o número de tentativas de uma mensagem antes de ela ir para o tópico de
+	// mensagens mortas.
+	maxAttempts = 5
+)
+
 type Consumer struct {
 	reader         Reader
 	DebtRepository DebtRepositoryInterface
+	deadLetter     WriterInterface
+	retryDelay     time.Duration
 }
 
-func NewKafkaConsumer(brokerAddress, topic, groupID string, repo DebtRepositoryInterface) *Consumer {
-	reader := kafka.NewReader(kafka.ReaderConfig{
+// NewKafkaConsumer consome o tópico sem commit automático: o offset só é confirmado depois
+// que o débito é gravado. O tópico de mensagens mortas é opcional: sem deadLetterTopic,
+// uma mensagem que falha é tentada de novo até ser processada, e a partição fica parada
+// até lá. Devolve erro quando não consegue criar o tópico de mensagens mortas.
+func NewKafkaConsumer(brokerAddress, topic, groupID, deadLetterTopic string, repo DebtRepositoryInterface) (*Consumer, error) {
+	consumer := &Consumer{
+		DebtRepository: repo,
+		retryDelay:     time.Second,
+	}
+
+	if deadLetterTopic != "" {
+		if err := createTopic(brokerAddress, deadLetterTopic, 1, 1); err != nil {
+			return nil, fmt.Errorf("erro ao criar tópico de mensagens mortas %s: %w", deadLetterTopic, err)
+		}
+
+		consumer.deadLetter = kafka.NewWriter(kafka.WriterConfig{
+			Brokers: []string{brokerAddress},
+			Topic:   deadLetterTopic,
+		})
+	}
+
+	consumer.reader = kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        []string{brokerAddress},
 		Topic:          topic,
 		GroupID:        groupID,
 		StartOffset:    kafka.FirstOffset,
 		CommitInterval: time.Second,
 	})
-	return &Consumer{
-		reader:         reader,
-		DebtRepository: repo,
-	}
+
+	return consumer, nil
 }
 
-func (c *Consumer) Consume(processMessage func(debt domain.Debt, fileName string)) error {
+// Consume processa as mensagens do tópico. Os efeitos colaterais devolvidos por
+// processMessage são gravados no outbox junto com o débito antes do commit do offset.
+// Cada partição é atendida por um único worker, na ordem dos offsets, e uma mensagem que
+// falha é tentada de novo no lugar, até maxAttempts vezes, antes de ir para o tópico de
+// mensagens mortas. Assim, o commit de um offset só acontece depois que todos os
+// anteriores da partição foram gravados ou desviados; uma mensagem sem commit é entregue
+// de novo depois de um reinício.
+func (c *Consumer) Consume(processMessage func(debt domain.Debt, fileName string) ([]domain.OutboxMessage, error)) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	messageChan := make(chan kafka.Message, 1000)
-	defer close(messageChan)
+	var workers sync.WaitGroup
+	channels := make([]chan kafka.Message, numWorkers)
+	for i := range channels {
+		channels[i] = make(chan kafka.Message, 100)
 
-	numWorkers := 10
+		workers.Add(1)
+		go func(messages <-chan kafka.Message) {
+			defer workers.Done()
 
-	for i := 0; i < numWorkers; i++ {
-		go func() {
-			for message := range messageChan {
-				fileName := string(message.Key)
-				reader := csv.NewReader(strings.NewReader(string(message.Value)))
-				record, err := reader.Read()
-				if err != nil {
-					log.Printf("Erro ao processar CSV: %v, Mensagem: %s", err, string(message.Value))
-					continue
-				}
-
-				debt := domain.Debt{
-					Name:         record[0],
-					GovernmentID: record[1],
-					Email:        record[2],
-					DebtAmount:   parseFloat(record[3]),
-					DebtDueDate:  record[4],
-					DebtID:       record[5],
+			for message := range messages {
+				// Encerrado o consumidor, as mensagens restantes ficam sem commit.
+				if ctx.Err() != nil {
+					return
 				}
 
-				processMessage(debt, fileName)
-
-				if err := c.DebtRepository.Save(debt.DebtID); err != nil {
-					log.Printf("Erro ao salvar no repositório: %v", err)
+				if !c.handle(ctx, message, processMessage) {
 					continue
 				}
 
@@ -84,11 +107,19 @@ func (c *Consumer) Consume(processMessage func(debt domain.Debt, fileName string
 					log.Printf("Erro ao confirmar mensagem: %v", err)
 				}
 			}
-		}()
+		}(channels[i])
 	}
 
+	defer func() {
+		for _, messages := range channels {
+			close(messages)
+		}
+		cancel()
+		workers.Wait()
+	}()
+
 	for {
-		message, err := c.reader.ReadMessage(ctx)
+		message, err := c.reader.FetchMessage(ctx)
 		if err != nil {
 			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
 				log.Println("Contexto encerrado ou timeout atingido, encerrando loop.")
@@ -105,25 +136,140 @@ func (c *Consumer) Consume(processMessage func(debt domain.Debt, fileName string
 			continue
 		}
 
-		messageChan <- message
+		channels[message.Partition%numWorkers] <- message
 	}
 
 	return nil
 }
 
-func parseFloat(value string) float64 {
-	parsedValue, err := strconv.ParseFloat(value, 64)
+// handle grava o débito da mensagem, tentando de novo enquanto falhar, e devolve se a
+// mensagem pode ser confirmada: gravada ou desviada para o tópico de mensagens mortas.
+// Devolve false apenas quando o consumidor é encerrado antes disso.
+func (c *Consumer) handle(ctx context.Context, message kafka.Message, processMessage func(debt domain.Debt, fileName string) ([]domain.OutboxMessage, error)) bool {
+	debt, err := decodeDebt(message.Value)
+	if err != nil {
+		// Uma mensagem ilegível não melhora com novas tentativas.
+		log.Printf("Erro ao interpretar débito: %v, Mensagem: %s", err, string(message.Value))
+
+		return c.divert(ctx, message, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.save(debt, string(message.Key), processMessage)
+		if err == nil {
+			return true
+		}
+		log.Printf("Erro ao processar débito %s (tentativa %d): %v", debt.DebtID, attempt, err)
+
+		if attempt >= maxAttempts && c.deadLetter != nil {
+			return c.divert(ctx, message, err)
+		}
+
+		if !wait(ctx, c.retryDelay) {
+			return false
+		}
+	}
+}
+
+func (c *Consumer) save(debt domain.Debt, fileName string, processMessage func(debt domain.Debt, fileName string) ([]domain.OutboxMessage, error)) error {
+	messages, err := processMessage(debt, fileName)
+	if err != nil {
+		return err
+	}
+
+	return c.DebtRepository.SaveWithOutbox(debt.DebtID, messages)
+}
+
+// divert publica a mensagem no tópico de mensagens mortas, com o motivo e a origem nos
+// cabeçalhos, tentando de novo enquanto o envio falhar. Sem tópico configurado, a mensagem
+// é mantida sem commit.
+func (c *Consumer) divert(ctx context.Context, message kafka.Message, reason error) bool {
+	if c.deadLetter == nil {
+		log.Printf("Mensagem %d da partição %d mantida sem commit: tópico de mensagens mortas não configurado", message.Offset, message.Partition)
+
+		return false
+	}
+
+	dead := kafka.Message{
+		Key:   message.Key,
+		Value: message.Value,
+		Headers: append(message.Headers,
+			kafka.Header{Key: "error", Value: []byte(reason.Error())},
+			kafka.Header{Key: "source", Value: []byte(fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset))},
+		),
+	}
+
+	for {
+		err := c.deadLetter.WriteMessages(ctx, dead)
+		if err == nil {
+			log.Printf("Mensagem %d da partição %d enviada ao tópico de mensagens mortas: %v", message.Offset, message.Partition, reason)
+
+			return true
+		}
+		log.Printf("Erro ao enviar mensagem ao tópico de mensagens mortas: %v", err)
+
+		if !wait(ctx, c.retryDelay) {
+			return false
+		}
+	}
+}
+
+// wait espera delay e devolve false se o contexto for encerrado antes.
+func wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// decodeDebt interpreta a mensagem do débito, em JSON. Mensagens em CSV, com as colunas na
+// ordem do cabeçalho padrão, são as produzidas antes do mapeamento de colunas e continuam
+// aceitas enquanto houver mensagens antigas no tópico.
+func decodeDebt(value []byte) (domain.Debt, error) {
+	if trimmed := bytes.TrimSpace(value); len(trimmed) > 0 && trimmed[0] == '{' {
+		var debt domain.Debt
+		if err := json.Unmarshal(trimmed, &debt); err != nil {
+			return domain.Debt{}, err
+		}
+
+		return debt, nil
+	}
+
+	record, err := csv.NewReader(bytes.NewReader(value)).Read()
 	if err != nil {
-		log.Printf("Erro ao converter string para float: %v", err)
+		return domain.Debt{}, err
+	}
+
+	if len(record) < len(domain.DebtFields) {
+		return domain.Debt{}, fmt.Errorf("mensagem CSV com %d campos", len(record))
+	}
+
+	fields := make(domain.DebtRecord, len(domain.DebtFields))
+	for index, field := range domain.DebtFields {
+		fields[field] = record[index]
+	}
 
-		return 0
+	var clientID string
+	if len(record) > len(domain.DebtFields) {
+		clientID = record[len(domain.DebtFields)]
 	}
 
-	return parsedValue
+	return fields.Debt(clientID), nil
 }
 
 func (c *Consumer) Close() {
 	if err := c.reader.Close(); err != nil {
 		log.Printf("Erro ao fechar o consumer Kafka: %v", err)
 	}
+
+	if c.deadLetter != nil {
+		if err := c.deadLetter.Close(); err != nil {
+			log.Printf("Erro ao fechar o writer de mensagens mortas: %v", err)
+		}
+	}
 }
@@ -0,0 +1,157 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// fakeReader entrega as mensagens de messages e registra os offsets confirmados.
+type fakeReader struct {
+	messages  chan kafka.Message
+	mu        sync.Mutex
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case message, ok := <-r.messages:
+		if !ok {
+			return kafka.Message{}, io.EOF
+		}
+
+		return message, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range msgs {
+		r.committed = append(r.committed, message.Offset)
+	}
+
+	return nil
+}
+
+func (r *fakeReader) Committed() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]int64(nil), r.committed...)
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+type fakeRepository struct{}
+
+func (fakeRepository) SaveWithOutbox(string, []domain.OutboxMessage) error { return nil }
+
+type fakeWriter struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, msgs...)
+
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func debtMessage(offset int64, debtID string) kafka.Message {
+	return kafka.Message{Partition: 0, Offset: offset, Value: []byte(`{"DebtID":"` + debtID + `"}`)}
+}
+
+// failing recusa o débito debt-falha e conta as tentativas de cada débito.
+type failing struct {
+	mu       sync.Mutex
+	attempts map[string]int
+}
+
+func (f *failing) process(debt domain.Debt, _ string) ([]domain.OutboxMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts[debt.DebtID]++
+
+	if debt.DebtID == "debt-falha" {
+		return nil, errors.New("banco indisponível")
+	}
+
+	return nil, nil
+}
+
+func (f *failing) Attempts(debtID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.attempts[debtID]
+}
+
+func TestConsumer_Consume(t *testing.T) {
+	t.Run("Mensagem com falha não é confirmada", func(t *testing.T) {
+		reader := &fakeReader{messages: make(chan kafka.Message, 2)}
+		consumer := &Consumer{reader: reader, DebtRepository: fakeRepository{}, retryDelay: time.Millisecond}
+		processor := &failing{attempts: map[string]int{}}
+
+		reader.messages <- debtMessage(1, "debt-falha")
+		reader.messages <- debtMessage(2, "debt-ok")
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.NoError(t, consumer.Consume(processor.process))
+		}()
+
+		assert.Eventually(t, func() bool { return processor.Attempts("debt-falha") > maxAttempts }, time.Second, time.Millisecond)
+		assert.Empty(t, reader.Committed())
+		assert.Zero(t, processor.Attempts("debt-ok"), "a mensagem seguinte da partição espera a anterior")
+
+		close(reader.messages)
+		<-done
+		assert.Empty(t, reader.Committed())
+	})
+
+	t.Run("Mensagem desviada para o tópico de mensagens mortas", func(t *testing.T) {
+		reader := &fakeReader{messages: make(chan kafka.Message, 3)}
+		deadLetter := &fakeWriter{}
+		consumer := &Consumer{reader: reader, DebtRepository: fakeRepository{}, deadLetter: deadLetter, retryDelay: time.Millisecond}
+		processor := &failing{attempts: map[string]int{}}
+
+		reader.messages <- debtMessage(1, "debt-falha")
+		reader.messages <- kafka.Message{Partition: 0, Offset: 2, Value: []byte(`{"DebtID":`)}
+		reader.messages <- debtMessage(3, "debt-ok")
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.NoError(t, consumer.Consume(processor.process))
+		}()
+
+		assert.Eventually(t, func() bool { return len(reader.Committed()) == 3 }, time.Second, time.Millisecond)
+		assert.Equal(t, []int64{1, 2, 3}, reader.Committed())
+		assert.Equal(t, maxAttempts, processor.Attempts("debt-falha"))
+
+		require.Len(t, deadLetter.messages, 2)
+		assert.Equal(t, "error", deadLetter.messages[0].Headers[0].Key)
+		assert.Equal(t, "banco indisponível", string(deadLetter.messages[0].Headers[0].Value))
+
+		close(reader.messages)
+		<-done
+	})
+}
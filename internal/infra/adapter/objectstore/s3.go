@@ -0,0 +1,282 @@
+package objectstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectNotFound = errors.New("objeto não encontrado")
+	ErrInvalidBucket  = errors.New("nome de bucket inválido")
+)
+
+const (
+	amzDateFormat  = "20060102T150405Z"
+	requestTimeout = 30 * time.Second
+)
+
+// Config aponta para um serviço compatível com S3, como a AWS ou um MinIO local. Com
+// PathStyle, o bucket vai no caminho (http://minio:9000/bucket/key), como o MinIO espera;
+// sem ele, no host (https://bucket.s3.amazonaws.com/key).
+type Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// S3 lê e grava objetos pela API REST do S3, com as requisições assinadas pelo AWS
+// Signature Version 4.
+type S3 struct {
+	endpoint *url.URL
+	config   Config
+	client   *http.Client
+	now      func() time.Time
+}
+
+func NewS3(config Config) (*S3, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint do S3 inválido: %q", config.Endpoint)
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	// O timeout vale para a conexão e os cabeçalhos; o corpo dos objetos pode levar mais.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = requestTimeout
+
+	return &S3{endpoint: endpoint, config: config, client: &http.Client{Transport: transport}, now: time.Now}, nil
+}
+
+// Get devolve o conteúdo e o tamanho do objeto. Quem chama deve fechar o conteúdo.
+func (s *S3) Get(bucket, key string) (io.ReadCloser, int64, error) {
+	objectURL, err := s.objectURL(bucket, key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.sign(req, emptyPayloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao baixar s3://%s/%s: %w", bucket, key, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		return nil, 0, responseError(resp, bucket, key)
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// Put grava o objeto com os size bytes de content. O conteúdo é lido duas vezes: uma para
+// assinar seu SHA-256 e outra para o envio.
+func (s *S3) Put(bucket, key string, content io.ReadSeeker, size int64) error {
+	objectURL, err := s.objectURL(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return err
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, objectURL, io.NopCloser(content))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	s.sign(req, hex.EncodeToString(hash.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar s3://%s/%s: %w", bucket, key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, bucket, key)
+	}
+
+	return nil
+}
+
+func responseError(resp *http.Response, bucket, key string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: s3://%s/%s", ErrObjectNotFound, bucket, key)
+	}
+
+	return fmt.Errorf("S3 respondeu %d para s3://%s/%s: %s", resp.StatusCode, bucket, key, strings.TrimSpace(string(body)))
+}
+
+// objectURL monta a URL do objeto. O bucket é validado antes, já que no endereçamento
+// virtual ele vira parte do host da requisição.
+func (s *S3) objectURL(bucket, key string) (string, error) {
+	if !ValidBucketName(bucket) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBucket, bucket)
+	}
+
+	objectURL := *s.endpoint
+	if s.config.PathStyle {
+		objectURL.Path = strings.TrimSuffix(objectURL.Path, "/") + "/" + bucket + "/" + key
+	} else {
+		objectURL.Host = bucket + "." + objectURL.Host
+		objectURL.Path = "/" + key
+	}
+	objectURL.RawPath = uriEncode(objectURL.Path, false)
+
+	return objectURL.String(), nil
+}
+
+// ValidBucketName segue as regras de nomes de bucket do S3: de 3 a 63 caracteres entre
+// letras minúsculas, dígitos, pontos e hífens, começando e terminando com letra ou dígito,
+// sem pontos seguidos e sem o formato de um endereço IP.
+func ValidBucketName(name string) bool {
+	if len(name) < 3 || len(name) > 63 || strings.Contains(name, "..") {
+		return false
+	}
+
+	for i, c := range []byte(name) {
+		alphanumeric := 'a' <= c && c <= 'z' || '0' <= c && c <= '9'
+		if (i == 0 || i == len(name)-1) && !alphanumeric {
+			return false
+		}
+
+		if !alphanumeric && c != '.' && c != '-' {
+			return false
+		}
+	}
+
+	_, err := netip.ParseAddr(name)
+
+	return err != nil
+}
+
+// emptyPayloadHash é o SHA-256 de um corpo vazio.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sign assina a requisição com todos os seus cabeçalhos, seguindo o AWS Signature
+// Version 4 para o serviço s3.
+func (s *S3) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// uriEncode codifica tudo exceto os caracteres não reservados do RFC 3986, como pede a
+// assinatura; a barra é mantida nos caminhos.
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return encoded.String()
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+
+	return mac.Sum(nil)
+}
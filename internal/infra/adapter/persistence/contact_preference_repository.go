@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type ContactPreferenceRepository struct {
+	preferences map[string]domain.ContactPreferences
+	mu          sync.Mutex
+}
+
+func NewContactPreferenceRepository() *ContactPreferenceRepository {
+	return &ContactPreferenceRepository{
+		preferences: make(map[string]domain.ContactPreferences),
+	}
+}
+
+func (r *ContactPreferenceRepository) Save(preferences domain.ContactPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.preferences[preferences.GovernmentID] = preferences
+
+	return nil
+}
+
+func (r *ContactPreferenceRepository) FindByGovernmentID(governmentID string) (domain.ContactPreferences, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	preferences, exists := r.preferences[governmentID]
+
+	return preferences, exists
+}
@@ -1,17 +1,28 @@
 package persistence
 
 import (
+	"errors"
+	"sort"
 	"sync"
+	"time"
+
+	"kanastra-api/internal/core/domain"
 )
 
+var ErrOutboxMessageNotFound = errors.New("mensagem do outbox não encontrada")
+
+// DebtRepository guarda os débitos processados e o outbox de efeitos colaterais sob o
+// mesmo lock, de forma que ambos sejam gravados de forma atômica.
 type DebtRepository struct {
-	store map[string]struct{}
-	mu    sync.Mutex
+	store  map[string]struct{}
+	outbox map[string]domain.OutboxMessage
+	mu     sync.Mutex
 }
 
 func NewDebtRepository() *DebtRepository {
 	return &DebtRepository{
-		store: make(map[string]struct{}),
+		store:  make(map[string]struct{}),
+		outbox: make(map[string]domain.OutboxMessage),
 	}
 }
 
@@ -23,6 +34,41 @@ func (r *DebtRepository) Save(debtID string) error {
 	return nil
 }
 
+// SaveWithOutbox marca o débito como processado e registra as mensagens do outbox na
+// mesma operação. Mensagens já registradas (mesma chave de idempotência) são ignoradas,
+// o que torna seguro reprocessar uma mensagem reentregue pelo Kafka.
+func (r *DebtRepository) SaveWithOutbox(debtID string, messages []domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store[debtID] = struct{}{}
+	r.enqueue(messages)
+
+	return nil
+}
+
+// Enqueue registra mensagens no outbox sem alterar o débito e devolve quantas eram novas.
+func (r *DebtRepository) Enqueue(messages []domain.OutboxMessage) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.enqueue(messages), nil
+}
+
+func (r *DebtRepository) enqueue(messages []domain.OutboxMessage) int {
+	enqueued := 0
+	for _, message := range messages {
+		if _, exists := r.outbox[message.ID]; exists {
+			continue
+		}
+
+		r.outbox[message.ID] = message
+		enqueued++
+	}
+
+	return enqueued
+}
+
 func (r *DebtRepository) IsLineProcessed(debtID string) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -30,3 +76,73 @@ func (r *DebtRepository) IsLineProcessed(debtID string) bool {
 	_, exists := r.store[debtID]
 	return exists
 }
+
+func (r *DebtRepository) ClaimPending(now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []domain.OutboxMessage
+	for _, message := range r.outbox {
+		if message.Status == domain.OutboxStatusPending && !message.NextAttemptAt.After(now) {
+			due = append(due, message)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, message := range due {
+		claimed := r.outbox[message.ID]
+		claimed.NextAttemptAt = now.Add(lease)
+		r.outbox[message.ID] = claimed
+	}
+
+	return due, nil
+}
+
+func (r *DebtRepository) Update(message domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.outbox[message.ID]; !exists {
+		return ErrOutboxMessageNotFound
+	}
+
+	r.outbox[message.ID] = message
+
+	return nil
+}
+
+func (r *DebtRepository) FindOutboxMessage(id string) (domain.OutboxMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, exists := r.outbox[id]
+
+	return message, exists
+}
+
+func (r *DebtRepository) DeliveredSince(governmentID string, since time.Time) ([]time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var delivered []time.Time
+	for _, message := range r.outbox {
+		if message.Status == domain.OutboxStatusDelivered &&
+			message.Invoice.Debt.GovernmentID == governmentID &&
+			!message.DeliveredAt.Before(since) {
+			delivered = append(delivered, message.DeliveredAt)
+		}
+	}
+
+	sort.Slice(delivered, func(i, j int) bool {
+		return delivered[i].Before(delivered[j])
+	})
+
+	return delivered, nil
+}
@@ -1,9 +1,13 @@
 package persistence
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
 )
 
 func TestDebtRepository_Save(t *testing.T) {
@@ -42,3 +46,86 @@ func TestDebtRepository_IsLineProcessed(t *testing.T) {
 		assert.True(t, repo.IsLineProcessed(debtID))
 	})
 }
+
+func TestDebtRepository_SaveWithOutbox(t *testing.T) {
+	repo := NewDebtRepository()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	message := domain.NewDebtNotification(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}, NossoNumero: "1"}, domain.NotificationRoute{}, now)
+
+	assert.NoError(t, repo.SaveWithOutbox("d1", []domain.OutboxMessage{message}))
+	assert.True(t, repo.IsLineProcessed("d1"))
+
+	t.Run("Mensagem reentregue não duplica a notificação", func(t *testing.T) {
+		delivered := message
+		delivered.MarkDelivered(now)
+		assert.NoError(t, repo.Update(delivered))
+
+		assert.NoError(t, repo.SaveWithOutbox("d1", []domain.OutboxMessage{message}))
+
+		stored, exists := repo.FindOutboxMessage(message.ID)
+		assert.True(t, exists)
+		assert.Equal(t, domain.OutboxStatusDelivered, stored.Status)
+	})
+
+	t.Run("Enqueue registra apenas mensagens novas", func(t *testing.T) {
+		reminder := domain.NewDebtReminder(message.Invoice, domain.NotificationRoute{}, -1, now)
+
+		enqueued, err := repo.Enqueue([]domain.OutboxMessage{message, reminder, reminder})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, enqueued)
+	})
+}
+
+func TestDebtRepository_ClaimPending(t *testing.T) {
+	repo := NewDebtRepository()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	first := domain.NewDebtNotification(domain.Invoice{Debt: domain.Debt{DebtID: "d1"}}, domain.NotificationRoute{}, now.Add(-time.Minute))
+	second := domain.NewDebtNotification(domain.Invoice{Debt: domain.Debt{DebtID: "d2"}}, domain.NotificationRoute{}, now)
+	future := domain.NewDebtNotification(domain.Invoice{Debt: domain.Debt{DebtID: "d3"}}, domain.NotificationRoute{}, now.Add(time.Hour))
+	assert.NoError(t, repo.SaveWithOutbox("d1", []domain.OutboxMessage{second, first, future}))
+
+	claimed, err := repo.ClaimPending(now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	assert.Equal(t, first.ID, claimed[0].ID)
+
+	t.Run("Mensagens reservadas não são entregues novamente durante o lease", func(t *testing.T) {
+		claimed, err := repo.ClaimPending(now.Add(30*time.Second), time.Minute, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, claimed)
+
+		claimed, err = repo.ClaimPending(now.Add(2*time.Minute), time.Minute, 1)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+	})
+
+	t.Run("Atualizar mensagem inexistente", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Update(domain.OutboxMessage{ID: "x"}), ErrOutboxMessageNotFound)
+	})
+}
+
+func TestDebtRepository_DeliveredSince(t *testing.T) {
+	repo := NewDebtRepository()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	var messages []domain.OutboxMessage
+	for i, deliveredAt := range []time.Time{now.Add(-time.Hour), now.Add(-48 * time.Hour), now.Add(-2 * time.Hour)} {
+		message := domain.NewDebtNotification(domain.Invoice{
+			Debt: domain.Debt{DebtID: fmt.Sprintf("d%d", i), GovernmentID: "123"},
+		}, domain.NotificationRoute{}, now)
+		message.MarkDelivered(deliveredAt)
+		messages = append(messages, message)
+	}
+
+	pending := domain.NewDebtNotification(domain.Invoice{Debt: domain.Debt{DebtID: "d9", GovernmentID: "123"}}, domain.NotificationRoute{}, now)
+	other := domain.NewDebtNotification(domain.Invoice{Debt: domain.Debt{DebtID: "o1", GovernmentID: "456"}}, domain.NotificationRoute{}, now)
+	other.MarkDelivered(now)
+	_, err := repo.Enqueue(append(messages, pending, other))
+	assert.NoError(t, err)
+
+	delivered, err := repo.DeliveredSince("123", now.Add(-24*time.Hour))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Hour)}, delivered)
+}
@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"sort"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type EmailDeliveryRepository struct {
+	byID        map[string]domain.EmailDelivery
+	byMessageID map[string]string
+	byDebtID    map[string][]string
+	mu          sync.Mutex
+}
+
+func NewEmailDeliveryRepository() *EmailDeliveryRepository {
+	return &EmailDeliveryRepository{
+		byID:        make(map[string]domain.EmailDelivery),
+		byMessageID: make(map[string]string),
+		byDebtID:    make(map[string][]string),
+	}
+}
+
+func (r *EmailDeliveryRepository) Save(delivery domain.EmailDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byID[delivery.ID]; !exists {
+		r.byDebtID[delivery.DebtID] = append(r.byDebtID[delivery.DebtID], delivery.ID)
+	}
+
+	r.byID[delivery.ID] = delivery
+	if delivery.ProviderMessageID != "" {
+		r.byMessageID[delivery.ProviderMessageID] = delivery.ID
+	}
+
+	return nil
+}
+
+// FindByProviderMessageID devolve a tentativa mais recente enviada com o Message-ID
+// informado; as retentativas de uma mesma notificação compartilham o Message-ID.
+func (r *EmailDeliveryRepository) FindByProviderMessageID(messageID string) (domain.EmailDelivery, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, exists := r.byMessageID[messageID]
+	if !exists {
+		return domain.EmailDelivery{}, false
+	}
+
+	delivery, exists := r.byID[id]
+
+	return delivery, exists
+}
+
+func (r *EmailDeliveryRepository) FindByDebtID(debtID string) []domain.EmailDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := make([]domain.EmailDelivery, 0, len(r.byDebtID[debtID]))
+	for _, id := range r.byDebtID[debtID] {
+		deliveries = append(deliveries, r.byID[id])
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+
+	return deliveries
+}
@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestEmailDeliveryRepository(t *testing.T) {
+	repo := NewEmailDeliveryRepository()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	first := domain.EmailDelivery{ID: "n1#1", DebtID: "d1", ProviderMessageID: "<m1>", Status: domain.EmailDeliveryDeferred, CreatedAt: now}
+	second := domain.EmailDelivery{ID: "n1#2", DebtID: "d1", ProviderMessageID: "<m1>", Status: domain.EmailDeliverySent, CreatedAt: now.Add(time.Minute)}
+	assert.NoError(t, repo.Save(second))
+	assert.NoError(t, repo.Save(first))
+
+	t.Run("Retentativas compartilham o Message-ID", func(t *testing.T) {
+		latest := second
+		latest.UpdatedAt = now.Add(2 * time.Minute)
+		assert.NoError(t, repo.Save(latest))
+
+		delivery, exists := repo.FindByProviderMessageID("<m1>")
+		assert.True(t, exists)
+		assert.Equal(t, "n1#2", delivery.ID)
+	})
+
+	t.Run("Tentativas do débito em ordem", func(t *testing.T) {
+		deliveries := repo.FindByDebtID("d1")
+
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, "n1#1", deliveries[0].ID)
+		assert.Equal(t, "n1#2", deliveries[1].ID)
+		assert.Empty(t, repo.FindByDebtID("d2"))
+	})
+}
+
+func TestInvalidEmailRepository(t *testing.T) {
+	repo := NewInvalidEmailRepository()
+
+	assert.NoError(t, repo.MarkInvalid(domain.InvalidEmail{GovernmentID: "123", Email: " Joao@Example.com "}))
+
+	assert.True(t, repo.IsInvalid("123", "joao@example.com"))
+	assert.False(t, repo.IsInvalid("123", "joao.silva@example.com"))
+	assert.False(t, repo.IsInvalid("456", "joao@example.com"))
+}
@@ -0,0 +1,36 @@
+package persistence
+
+import (
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type IngestionJobRepository struct {
+	jobs map[string]domain.IngestionJob
+	mu   sync.Mutex
+}
+
+func NewIngestionJobRepository() *IngestionJobRepository {
+	return &IngestionJobRepository{
+		jobs: make(map[string]domain.IngestionJob),
+	}
+}
+
+func (r *IngestionJobRepository) Save(job domain.IngestionJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID] = job
+
+	return nil
+}
+
+func (r *IngestionJobRepository) FindByID(id string) (domain.IngestionJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+
+	return job, exists
+}
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"fmt"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type InstallmentPlanRepository struct {
+	store map[string]domain.InstallmentPlan
+	mu    sync.Mutex
+}
+
+func NewInstallmentPlanRepository() *InstallmentPlanRepository {
+	return &InstallmentPlanRepository{
+		store: make(map[string]domain.InstallmentPlan),
+	}
+}
+
+func (r *InstallmentPlanRepository) Create(plan domain.InstallmentPlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store[plan.DebtID]; exists {
+		return fmt.Errorf("%w: %s", domain.ErrInstallmentPlanExists, plan.DebtID)
+	}
+
+	r.store[plan.DebtID] = plan
+
+	return nil
+}
+
+func (r *InstallmentPlanRepository) Save(plan domain.InstallmentPlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store[plan.DebtID] = plan
+
+	return nil
+}
+
+func (r *InstallmentPlanRepository) FindByDebtID(debtID string) (domain.InstallmentPlan, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan, exists := r.store[debtID]
+
+	return plan, exists
+}
+
+func (r *InstallmentPlanRepository) Delete(debtID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.store, debtID)
+}
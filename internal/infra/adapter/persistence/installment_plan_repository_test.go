@@ -0,0 +1,34 @@
+package persistence
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestInstallmentPlanRepository_Create(t *testing.T) {
+	repo := NewInstallmentPlanRepository()
+
+	var created atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if repo.Create(domain.InstallmentPlan{DebtID: "d1"}) == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load(), "apenas uma requisição cria o parcelamento")
+	assert.ErrorIs(t, repo.Create(domain.InstallmentPlan{DebtID: "d1"}), domain.ErrInstallmentPlanExists)
+
+	repo.Delete("d1")
+	assert.NoError(t, repo.Create(domain.InstallmentPlan{DebtID: "d1"}))
+}
@@ -0,0 +1,42 @@
+package persistence
+
+import (
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type InvalidEmailRepository struct {
+	byGovernmentID map[string]map[string]domain.InvalidEmail
+	mu             sync.Mutex
+}
+
+func NewInvalidEmailRepository() *InvalidEmailRepository {
+	return &InvalidEmailRepository{
+		byGovernmentID: make(map[string]map[string]domain.InvalidEmail),
+	}
+}
+
+func (r *InvalidEmailRepository) MarkInvalid(invalid domain.InvalidEmail) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	emails, exists := r.byGovernmentID[invalid.GovernmentID]
+	if !exists {
+		emails = make(map[string]domain.InvalidEmail)
+		r.byGovernmentID[invalid.GovernmentID] = emails
+	}
+
+	emails[domain.NormalizeEmail(invalid.Email)] = invalid
+
+	return nil
+}
+
+func (r *InvalidEmailRepository) IsInvalid(governmentID, email string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.byGovernmentID[governmentID][domain.NormalizeEmail(email)]
+
+	return exists
+}
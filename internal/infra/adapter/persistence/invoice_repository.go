@@ -0,0 +1,119 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+var ErrNossoNumeroInUse = errors.New("nosso número já pertence a outro boleto")
+
+type InvoiceRepository struct {
+	byDebtID      map[string]domain.Invoice
+	byNossoNumero map[string]string
+	byPixTxID     map[string]string
+	mu            sync.Mutex
+}
+
+func NewInvoiceRepository() *InvoiceRepository {
+	return &InvoiceRepository{
+		byDebtID:      make(map[string]domain.Invoice),
+		byNossoNumero: make(map[string]string),
+		byPixTxID:     make(map[string]string),
+	}
+}
+
+// Save recusa um nosso número que já identifica o boleto de outro débito. Quando o boleto
+// do débito é substituído, o nosso número anterior deixa de localizá-lo.
+func (r *InvoiceRepository) Save(invoice domain.Invoice) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nossoNumero := domain.NormalizeNossoNumero(invoice.NossoNumero)
+	if owner, exists := r.byNossoNumero[nossoNumero]; exists && owner != invoice.Debt.DebtID {
+		return fmt.Errorf("%w: %s (débito %s)", ErrNossoNumeroInUse, invoice.NossoNumero, owner)
+	}
+
+	if previous, exists := r.byDebtID[invoice.Debt.DebtID]; exists {
+		delete(r.byNossoNumero, domain.NormalizeNossoNumero(previous.NossoNumero))
+		delete(r.byPixTxID, previous.PixTxID)
+	}
+
+	r.byDebtID[invoice.Debt.DebtID] = invoice
+	r.byNossoNumero[nossoNumero] = invoice.Debt.DebtID
+	if invoice.PixTxID != "" {
+		r.byPixTxID[invoice.PixTxID] = invoice.Debt.DebtID
+	}
+
+	return nil
+}
+
+func (r *InvoiceRepository) Delete(debtID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoice, exists := r.byDebtID[debtID]
+	if !exists {
+		return
+	}
+
+	delete(r.byNossoNumero, domain.NormalizeNossoNumero(invoice.NossoNumero))
+	delete(r.byPixTxID, invoice.PixTxID)
+	delete(r.byDebtID, debtID)
+}
+
+func (r *InvoiceRepository) FindByNossoNumero(nossoNumero string) (domain.Invoice, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	debtID, exists := r.byNossoNumero[domain.NormalizeNossoNumero(nossoNumero)]
+	if !exists {
+		return domain.Invoice{}, false
+	}
+
+	invoice, exists := r.byDebtID[debtID]
+
+	return invoice, exists
+}
+
+func (r *InvoiceRepository) FindByDebtID(debtID string) (domain.Invoice, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invoice, exists := r.byDebtID[debtID]
+
+	return invoice, exists
+}
+
+func (r *InvoiceRepository) FindByPixTxID(txID string) (domain.Invoice, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	debtID, exists := r.byPixTxID[txID]
+	if !exists {
+		return domain.Invoice{}, false
+	}
+
+	invoice, exists := r.byDebtID[debtID]
+
+	return invoice, exists
+}
+
+func (r *InvoiceRepository) FindOpen() []domain.Invoice {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var open []domain.Invoice
+	for _, invoice := range r.byDebtID {
+		if invoice.IsOpen() {
+			open = append(open, invoice)
+		}
+	}
+
+	sort.Slice(open, func(i, j int) bool { return open[i].Debt.DebtID < open[j].Debt.DebtID })
+
+	return open
+}
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestInvoiceRepository_Save(t *testing.T) {
+	repo := NewInvoiceRepository()
+	invoice := domain.Invoice{
+		Debt:        domain.Debt{DebtID: "abc123"},
+		NossoNumero: "00012345678",
+		PixTxID:     "KNSabc123",
+		Amount:      100.50,
+	}
+
+	err := repo.Save(invoice)
+	assert.NoError(t, err)
+
+	t.Run("Find by DebtID", func(t *testing.T) {
+		found, exists := repo.FindByDebtID("abc123")
+		assert.True(t, exists)
+		assert.Equal(t, invoice, found)
+	})
+
+	t.Run("Find by nosso número ignoring leading zeros", func(t *testing.T) {
+		found, exists := repo.FindByNossoNumero("000000000012345678")
+		assert.True(t, exists)
+		assert.Equal(t, "abc123", found.Debt.DebtID)
+	})
+
+	t.Run("Find by Pix txid", func(t *testing.T) {
+		found, exists := repo.FindByPixTxID("KNSabc123")
+		assert.True(t, exists)
+		assert.Equal(t, "abc123", found.Debt.DebtID)
+	})
+
+	t.Run("Unknown nosso número", func(t *testing.T) {
+		_, exists := repo.FindByNossoNumero("999")
+		assert.False(t, exists)
+	})
+
+	t.Run("Nosso número de outro débito", func(t *testing.T) {
+		err := repo.Save(domain.Invoice{Debt: domain.Debt{DebtID: "def456"}, NossoNumero: "12345678"})
+		assert.ErrorIs(t, err, ErrNossoNumeroInUse)
+
+		_, exists := repo.FindByDebtID("def456")
+		assert.False(t, exists)
+	})
+
+	t.Run("Boleto substituído", func(t *testing.T) {
+		replaced := invoice
+		replaced.NossoNumero = "00012345679"
+		assert.NoError(t, repo.Save(replaced))
+
+		_, exists := repo.FindByNossoNumero("00012345678")
+		assert.False(t, exists)
+
+		found, exists := repo.FindByNossoNumero("00012345679")
+		assert.True(t, exists)
+		assert.Equal(t, "abc123", found.Debt.DebtID)
+	})
+
+	t.Run("Boleto removido", func(t *testing.T) {
+		repo.Delete("abc123")
+
+		_, exists := repo.FindByDebtID("abc123")
+		assert.False(t, exists)
+		_, exists = repo.FindByNossoNumero("00012345679")
+		assert.False(t, exists)
+	})
+}
+
+func TestInvoiceRepository_FindOpen(t *testing.T) {
+	repo := NewInvoiceRepository()
+	for debtID, status := range map[string]domain.InvoiceStatus{
+		"d3": domain.InvoiceStatusRegistered,
+		"d1": domain.InvoiceStatusIssued,
+		"d2": domain.InvoiceStatusPaid,
+		"d4": domain.InvoiceStatusCancelled,
+		"d5": domain.InvoiceStatusPartial,
+	} {
+		assert.NoError(t, repo.Save(domain.Invoice{Debt: domain.Debt{DebtID: debtID}, NossoNumero: debtID, Status: status}))
+	}
+
+	var debtIDs []string
+	for _, invoice := range repo.FindOpen() {
+		debtIDs = append(debtIDs, invoice.Debt.DebtID)
+	}
+
+	assert.Equal(t, []string{"d1", "d3", "d5"}, debtIDs)
+}
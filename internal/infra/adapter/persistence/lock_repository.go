@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"sync"
+	"time"
+)
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// LockRepository guarda os locks na memória do processo, como os demais repositórios. Ele só
+// exclui execuções concorrentes dentro da mesma instância: réplicas diferentes não o
+// compartilham, por isso o agendador da régua fica desativado por padrão e deve ser ativado
+// (DUNNING_SCHEDULER_ENABLED=true) em uma única réplica até que o lock passe para um
+// armazenamento compartilhado.
+type LockRepository struct {
+	leases map[string]lease
+	mu     sync.Mutex
+}
+
+func NewLockRepository() *LockRepository {
+	return &LockRepository{leases: make(map[string]lease)}
+}
+
+// TryLock adquire o lock ou renova o que owner já detém. Um lock expirado pode ser
+// assumido por qualquer réplica.
+func (r *LockRepository) TryLock(name, owner string, ttl time.Duration, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.leases[name]
+	if exists && current.owner != owner && now.Before(current.expiresAt) {
+		return false
+	}
+
+	r.leases[name] = lease{owner: owner, expiresAt: now.Add(ttl)}
+
+	return true
+}
+
+func (r *LockRepository) Unlock(name, owner string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, exists := r.leases[name]; exists && current.owner == owner {
+		delete(r.leases, name)
+	}
+}
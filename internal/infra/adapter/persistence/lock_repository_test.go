@@ -0,0 +1,29 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockRepository(t *testing.T) {
+	repo := NewLockRepository()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.True(t, repo.TryLock("dunning", "replica-a", time.Minute, now))
+	assert.False(t, repo.TryLock("dunning", "replica-b", time.Minute, now.Add(30*time.Second)))
+	assert.True(t, repo.TryLock("dunning", "replica-a", time.Minute, now.Add(30*time.Second)), "o dono pode renovar o lock")
+
+	t.Run("Lock expirado pode ser assumido", func(t *testing.T) {
+		assert.True(t, repo.TryLock("dunning", "replica-b", time.Minute, now.Add(2*time.Minute)))
+	})
+
+	t.Run("Apenas o dono libera o lock", func(t *testing.T) {
+		repo.Unlock("dunning", "replica-a")
+		assert.False(t, repo.TryLock("dunning", "replica-a", time.Minute, now.Add(2*time.Minute)))
+
+		repo.Unlock("dunning", "replica-b")
+		assert.True(t, repo.TryLock("dunning", "replica-a", time.Minute, now.Add(2*time.Minute)))
+	})
+}
@@ -0,0 +1,22 @@
+package persistence
+
+import "sync"
+
+// NossoNumeroSequence numera os boletos de cada conta de cobrança, sem repetir números.
+type NossoNumeroSequence struct {
+	last map[string]int64
+	mu   sync.Mutex
+}
+
+func NewNossoNumeroSequence() *NossoNumeroSequence {
+	return &NossoNumeroSequence{last: make(map[string]int64)}
+}
+
+func (s *NossoNumeroSequence) Next(beneficiary string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.last[beneficiary]++
+
+	return s.last[beneficiary], nil
+}
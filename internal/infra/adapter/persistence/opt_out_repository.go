@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"sort"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type OptOutRepository struct {
+	byGovernmentID map[string]map[string]domain.OptOut
+	mu             sync.Mutex
+}
+
+func NewOptOutRepository() *OptOutRepository {
+	return &OptOutRepository{
+		byGovernmentID: make(map[string]map[string]domain.OptOut),
+	}
+}
+
+// Save registra o descadastro, mantendo o registro original quando o devedor já havia se
+// descadastrado.
+func (r *OptOutRepository) Save(optOut domain.OptOut) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	emails, exists := r.byGovernmentID[optOut.GovernmentID]
+	if !exists {
+		emails = make(map[string]domain.OptOut)
+		r.byGovernmentID[optOut.GovernmentID] = emails
+	}
+
+	email := domain.NormalizeEmail(optOut.Email)
+	if _, exists := emails[email]; !exists {
+		emails[email] = optOut
+	}
+
+	return nil
+}
+
+func (r *OptOutRepository) Delete(governmentID, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byGovernmentID[governmentID], domain.NormalizeEmail(email))
+
+	return nil
+}
+
+func (r *OptOutRepository) IsOptedOut(governmentID, email string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.byGovernmentID[governmentID][domain.NormalizeEmail(email)]
+
+	return exists
+}
+
+func (r *OptOutRepository) FindByGovernmentID(governmentID string) []domain.OptOut {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	optOuts := make([]domain.OptOut, 0, len(r.byGovernmentID[governmentID]))
+	for _, optOut := range r.byGovernmentID[governmentID] {
+		optOuts = append(optOuts, optOut)
+	}
+
+	sort.Slice(optOuts, func(i, j int) bool {
+		return optOuts[i].OptedOutAt.Before(optOuts[j].OptedOutAt)
+	})
+
+	return optOuts
+}
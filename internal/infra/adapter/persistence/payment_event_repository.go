@@ -0,0 +1,37 @@
+package persistence
+
+import (
+	"sync"
+)
+
+type PaymentEventRepository struct {
+	store map[string]struct{}
+	mu    sync.Mutex
+}
+
+func NewPaymentEventRepository() *PaymentEventRepository {
+	return &PaymentEventRepository{
+		store: make(map[string]struct{}),
+	}
+}
+
+// TryRegister reserva a chave do evento e retorna false se ela já tiver sido registrada.
+func (r *PaymentEventRepository) TryRegister(eventKey string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.store[eventKey]; exists {
+		return false
+	}
+
+	r.store[eventKey] = struct{}{}
+
+	return true
+}
+
+func (r *PaymentEventRepository) Forget(eventKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.store, eventKey)
+}
@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// RemoteFileRepository registra os arquivos remotos já baixados. Com path informado, o
+// registro é gravado em disco a cada arquivo, para que um reinício do serviço não baixe
+// de novo os arquivos que continuam publicados nos servidores.
+type RemoteFileRepository struct {
+	path  string
+	files map[string]domain.RemoteFile
+	mu    sync.Mutex
+}
+
+func NewRemoteFileRepository(path string) (*RemoteFileRepository, error) {
+	repo := &RemoteFileRepository{path: path, files: make(map[string]domain.RemoteFile)}
+	if path == "" {
+		return repo, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return repo, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler registro de arquivos remotos: %w", err)
+	}
+
+	var files []domain.RemoteFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar registro de arquivos remotos: %w", err)
+	}
+
+	for _, file := range files {
+		repo.files[file.Key()] = file
+	}
+
+	return repo, nil
+}
+
+func (r *RemoteFileRepository) Ingested(file domain.RemoteFile) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.files[file.Key()]
+
+	return exists
+}
+
+func (r *RemoteFileRepository) Save(file domain.RemoteFile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.files[file.Key()] = file
+	if r.path == "" {
+		return nil
+	}
+
+	files := make([]domain.RemoteFile, 0, len(r.files))
+	for _, existing := range r.files {
+		files = append(files, existing)
+	}
+
+	data, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(r.path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(r.path+".tmp", r.path)
+}
@@ -0,0 +1,196 @@
+package persistence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// UploadStore grava os uploads retomáveis em disco, um diretório por upload com os dados
+// do upload em upload.json e cada parte em seu próprio arquivo, para que os envios
+// sobrevivam a reinícios do serviço. Os identificadores são conferidos pelo caso de uso
+// antes de chegar aqui.
+type UploadStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewUploadStore(dir string) (*UploadStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de uploads %s: %w", dir, err)
+	}
+
+	return &UploadStore{dir: dir}, nil
+}
+
+func (s *UploadStore) Save(upload domain.Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.uploadDir(upload.ID), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	// Grava em um temporário e renomeia, para nunca deixar upload.json pela metade.
+	path := filepath.Join(s.uploadDir(upload.ID), "upload.json")
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func (s *UploadStore) FindByID(id string) (domain.Upload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(s.uploadDir(id), "upload.json"))
+	if err != nil {
+		return domain.Upload{}, false
+	}
+
+	var upload domain.Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return domain.Upload{}, false
+	}
+
+	return upload, true
+}
+
+func (s *UploadStore) FindAll() []domain.Upload {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil
+	}
+
+	var uploads []domain.Upload
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		if upload, exists := s.FindByID(entry.Name()); exists {
+			uploads = append(uploads, upload)
+		}
+	}
+
+	return uploads
+}
+
+// WritePart grava a parte em part-*.tmp, que só toma o lugar da parte em CommitPart.
+func (s *UploadStore) WritePart(uploadID string, number int, content io.Reader) (domain.UploadPart, string, error) {
+	file, err := os.CreateTemp(s.uploadDir(uploadID), "part-*.tmp")
+	if err != nil {
+		return domain.UploadPart{}, "", err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+
+		return domain.UploadPart{}, "", err
+	}
+
+	return domain.UploadPart{Number: number, Size: size, Checksum: hex.EncodeToString(hash.Sum(nil))}, file.Name(), nil
+}
+
+func (s *UploadStore) CommitPart(uploadID string, part domain.UploadPart, staged string) error {
+	if filepath.Dir(staged) != s.uploadDir(uploadID) {
+		return fmt.Errorf("parte provisória %s fora do upload %s", staged, uploadID)
+	}
+
+	return os.Rename(staged, s.partPath(uploadID, part.Number))
+}
+
+func (s *UploadStore) DiscardPart(staged string) {
+	os.Remove(staged)
+}
+
+func (s *UploadStore) Assemble(upload domain.Upload) (string, string, error) {
+	file, err := os.CreateTemp(s.dir, upload.ID+"-*"+filepath.Ext(upload.FileName))
+	if err != nil {
+		return "", "", err
+	}
+
+	hash := sha256.New()
+	err = s.concatenate(io.MultiWriter(file, hash), upload)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+
+		return "", "", err
+	}
+
+	return file.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (s *UploadStore) DiscardAssembled(path string) {
+	os.Remove(path)
+}
+
+func (s *UploadStore) concatenate(writer io.Writer, upload domain.Upload) error {
+	for _, part := range upload.Parts {
+		file, err := os.Open(s.partPath(upload.ID, part.Number))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(writer, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *UploadStore) RemoveParts(uploadID string) error {
+	parts, err := filepath.Glob(filepath.Join(s.uploadDir(uploadID), "part-*"))
+	if err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if err := os.Remove(part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *UploadStore) Remove(uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return os.RemoveAll(s.uploadDir(uploadID))
+}
+
+func (s *UploadStore) uploadDir(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *UploadStore) partPath(uploadID string, number int) string {
+	return filepath.Join(s.uploadDir(uploadID), fmt.Sprintf("part-%05d", number))
+}
@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// failingReader devolve content e depois falha, como uma conexão interrompida.
+type failingReader struct {
+	content io.Reader
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	n, err := r.content.Read(p)
+	if err == io.EOF {
+		return n, errors.New("conexão interrompida")
+	}
+
+	return n, err
+}
+
+func TestUploadStore(t *testing.T) {
+	store, err := NewUploadStore(t.TempDir())
+	require.NoError(t, err)
+
+	upload := domain.Upload{ID: "upl_0123456789abcdef", FileName: "remessa.csv", Status: domain.UploadInProgress}
+	require.NoError(t, store.Save(upload))
+
+	write := func(number int, content string) domain.UploadPart {
+		part, staged, err := store.WritePart(upload.ID, number, strings.NewReader(content))
+		require.NoError(t, err)
+		require.NoError(t, store.CommitPart(upload.ID, part, staged))
+
+		return part
+	}
+
+	second := write(2, "John Doe\n")
+	write(1, "nome antigo\n")
+	first := write(1, "name\n")
+
+	t.Run("Parte interrompida mantém a anterior", func(t *testing.T) {
+		_, _, err := store.WritePart(upload.ID, 1, failingReader{content: strings.NewReader("pela met")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Parte descartada mantém a anterior", func(t *testing.T) {
+		_, staged, err := store.WritePart(upload.ID, 1, strings.NewReader("recusada\n"))
+		require.NoError(t, err)
+		store.DiscardPart(staged)
+
+		_, err = os.Stat(staged)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	upload.AddPart(second)
+	upload.AddPart(first)
+	require.NoError(t, store.Save(upload))
+
+	found, exists := store.FindByID(upload.ID)
+	require.True(t, exists)
+	assert.Equal(t, []domain.UploadPart{first, second}, found.Parts)
+	assert.Equal(t, int64(14), found.Size)
+
+	path, checksum, err := store.Assemble(found)
+	require.NoError(t, err)
+	defer os.Remove(path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "name\nJohn Doe\n", string(content))
+
+	sum := sha256.Sum256(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), checksum)
+
+	store.DiscardAssembled(path)
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, store.RemoveParts(upload.ID))
+	_, _, err = store.Assemble(found)
+	assert.Error(t, err)
+
+	_, exists = store.FindByID("upl_ffffffffffffffff")
+	assert.False(t, exists)
+
+	t.Run("Upload removido", func(t *testing.T) {
+		assert.Len(t, store.FindAll(), 1)
+
+		require.NoError(t, store.Remove(upload.ID))
+
+		assert.Empty(t, store.FindAll())
+	})
+}
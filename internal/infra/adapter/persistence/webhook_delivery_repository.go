@@ -0,0 +1,108 @@
+package persistence
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+var ErrWebhookDeliveryNotFound = errors.New("entrega de webhook não encontrada")
+
+type WebhookDeliveryRepository struct {
+	deliveries map[string]domain.WebhookDelivery
+	mu         sync.Mutex
+}
+
+func NewWebhookDeliveryRepository() *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		deliveries: make(map[string]domain.WebhookDelivery),
+	}
+}
+
+func (r *WebhookDeliveryRepository) Enqueue(deliveries []domain.WebhookDelivery) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enqueued := 0
+	for _, delivery := range deliveries {
+		if _, exists := r.deliveries[delivery.ID]; exists {
+			continue
+		}
+
+		r.deliveries[delivery.ID] = delivery
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+func (r *WebhookDeliveryRepository) ClaimPending(now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].CreatedAt.Before(due[j].CreatedAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for _, delivery := range due {
+		claimed := r.deliveries[delivery.ID]
+		claimed.NextAttemptAt = now.Add(lease)
+		r.deliveries[delivery.ID] = claimed
+	}
+
+	return due, nil
+}
+
+func (r *WebhookDeliveryRepository) Update(delivery domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.deliveries[delivery.ID]; !exists {
+		return ErrWebhookDeliveryNotFound
+	}
+
+	r.deliveries[delivery.ID] = delivery
+
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) FindByID(id string) (domain.WebhookDelivery, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, exists := r.deliveries[id]
+
+	return delivery, exists
+}
+
+func (r *WebhookDeliveryRepository) FindBySubscriptionID(subscriptionID string) []domain.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+
+	return deliveries
+}
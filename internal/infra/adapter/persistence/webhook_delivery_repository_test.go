@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestWebhookDeliveryRepository(t *testing.T) {
+	repo := NewWebhookDeliveryRepository()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	subscription := domain.WebhookSubscription{ID: "wh_1", ClientID: "acme", URL: "https://acme.example.com/hooks"}
+	first := domain.NewWebhookDelivery(subscription, domain.WebhookEvent{ID: "e1"}, now)
+	second := domain.NewWebhookDelivery(subscription, domain.WebhookEvent{ID: "e2"}, now.Add(time.Minute))
+
+	t.Run("Eventos repetidos não geram nova entrega", func(t *testing.T) {
+		enqueued, err := repo.Enqueue([]domain.WebhookDelivery{first, second})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, enqueued)
+
+		enqueued, err = repo.Enqueue([]domain.WebhookDelivery{first})
+		assert.NoError(t, err)
+		assert.Zero(t, enqueued)
+	})
+
+	t.Run("Entregas reservadas não são reservadas de novo durante o lease", func(t *testing.T) {
+		claimed, err := repo.ClaimPending(now.Add(time.Minute), time.Minute, 10)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 2)
+		assert.Equal(t, "e1@wh_1", claimed[0].ID)
+
+		claimed, err = repo.ClaimPending(now.Add(90*time.Second), time.Minute, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	t.Run("Histórico da inscrição", func(t *testing.T) {
+		delivered := first
+		delivered.MarkDelivered(200, now)
+		assert.NoError(t, repo.Update(delivered))
+
+		deliveries := repo.FindBySubscriptionID("wh_1")
+		assert.Len(t, deliveries, 2)
+		assert.Equal(t, domain.WebhookDeliveryDelivered, deliveries[0].Status)
+		assert.Empty(t, repo.FindBySubscriptionID("wh_2"))
+		assert.ErrorIs(t, repo.Update(domain.WebhookDelivery{ID: "desconhecida"}), ErrWebhookDeliveryNotFound)
+	})
+}
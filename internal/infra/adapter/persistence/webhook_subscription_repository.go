@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"sort"
+	"sync"
+
+	"kanastra-api/internal/core/domain"
+)
+
+type WebhookSubscriptionRepository struct {
+	subscriptions map[string]domain.WebhookSubscription
+	mu            sync.Mutex
+}
+
+func NewWebhookSubscriptionRepository() *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{
+		subscriptions: make(map[string]domain.WebhookSubscription),
+	}
+}
+
+func (r *WebhookSubscriptionRepository) Save(subscription domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[subscription.ID] = subscription
+
+	return nil
+}
+
+func (r *WebhookSubscriptionRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.subscriptions, id)
+
+	return nil
+}
+
+func (r *WebhookSubscriptionRepository) FindByID(id string) (domain.WebhookSubscription, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, exists := r.subscriptions[id]
+
+	return subscription, exists
+}
+
+func (r *WebhookSubscriptionRepository) FindByClientID(clientID string) []domain.WebhookSubscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var subscriptions []domain.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.ClientID == clientID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+
+	return subscriptions
+}
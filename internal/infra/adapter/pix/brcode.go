@@ -0,0 +1,96 @@
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var ErrInvalidReceiver = errors.New("dados do recebedor Pix inválidos")
+
+const (
+	pixGUI         = "br.gov.bcb.pix"
+	currencyReal   = "986"
+	countryCode    = "BR"
+	dynamicPayment = "12"
+	merchantCode   = "0000"
+)
+
+// Receiver identifica o recebedor das cobranças Pix. As cobranças são dinâmicas: o QR
+// aponta para LocationURL/txid, onde o PSP expõe o payload da cobrança.
+type Receiver struct {
+	Name        string
+	City        string
+	LocationURL string
+}
+
+// CopyPaste monta o BR Code (padrão EMV) do Pix copia-e-cola de uma cobrança dinâmica.
+func CopyPaste(receiver Receiver, txID string, amount float64) (string, error) {
+	if receiver.Name == "" || receiver.City == "" || receiver.LocationURL == "" {
+		return "", ErrInvalidReceiver
+	}
+
+	if txID == "" {
+		return "", errors.New("txid da cobrança Pix não informado")
+	}
+
+	location := strings.TrimSuffix(strings.TrimPrefix(receiver.LocationURL, "https://"), "/") + "/" + txID
+
+	var payload strings.Builder
+	payload.WriteString(field("00", "01"))
+	payload.WriteString(field("01", dynamicPayment))
+	payload.WriteString(field("26", field("00", pixGUI)+field("25", location)))
+	payload.WriteString(field("52", merchantCode))
+	payload.WriteString(field("53", currencyReal))
+	if amount > 0 {
+		payload.WriteString(field("54", fmt.Sprintf("%.2f", amount)))
+	}
+	payload.WriteString(field("58", countryCode))
+	payload.WriteString(field("59", truncate(receiver.Name, 25)))
+	payload.WriteString(field("60", truncate(receiver.City, 15)))
+	payload.WriteString(field("62", field("05", "***")))
+	payload.WriteString("6304")
+
+	return payload.String() + crc16(payload.String()), nil
+}
+
+// QRCodePNG gera a imagem do QR Code a partir do Pix copia-e-cola.
+func QRCodePNG(copyPaste string, size int) ([]byte, error) {
+	if copyPaste == "" {
+		return nil, errors.New("payload Pix vazio")
+	}
+
+	return qrcode.Encode(copyPaste, qrcode.Medium, size)
+}
+
+func field(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) > size {
+		runes = runes[:size]
+	}
+
+	return string(runes)
+}
+
+// crc16 calcula o CRC16-CCITT (polinômio 0x1021, valor inicial 0xFFFF) exigido no campo 63.
+func crc16(payload string) string {
+	crc := uint16(0xFFFF)
+	for _, b := range []byte(payload) {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%04X", crc)
+}
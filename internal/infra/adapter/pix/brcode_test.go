@@ -0,0 +1,43 @@
+package pix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyPaste(t *testing.T) {
+	receiver := Receiver{Name: "Kanastra", City: "SAO PAULO", LocationURL: "https://pix.kanastra.com.br/qr/v2/"}
+
+	payload, err := CopyPaste(receiver, "KNS123", 150.5)
+	require.NoError(t, err)
+
+	expected := "000201" + "010212" +
+		"2654" + "0014br.gov.bcb.pix" + "2532pix.kanastra.com.br/qr/v2/KNS123" +
+		"52040000" + "5303986" + "5406150.50" + "5802BR" +
+		"5908Kanastra" + "6009SAO PAULO" + "62070503***" + "6304"
+	assert.Equal(t, expected+crc16(expected), payload)
+}
+
+func TestCopyPaste_Invalid(t *testing.T) {
+	_, err := CopyPaste(Receiver{}, "KNS123", 10)
+	assert.ErrorIs(t, err, ErrInvalidReceiver)
+
+	_, err = CopyPaste(Receiver{Name: "Kanastra", City: "SAO PAULO", LocationURL: "pix.kanastra.com.br"}, "", 10)
+	assert.Error(t, err)
+}
+
+func TestCRC16(t *testing.T) {
+	assert.Equal(t, "29B1", crc16("123456789"))
+}
+
+func TestQRCodePNG(t *testing.T) {
+	image, err := QRCodePNG("00020101021226500014br.gov.bcb.pix", 128)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(image), "\x89PNG"))
+
+	_, err = QRCodePNG("", 128)
+	assert.Error(t, err)
+}
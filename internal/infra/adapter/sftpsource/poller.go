@@ -0,0 +1,271 @@
+package sftpsource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/adapter/filesource"
+)
+
+const (
+	dialTimeout = 30 * time.Second
+	// idleTimeout encerra a conexão quando o servidor fica esse tempo sem responder, para
+	// que um servidor travado não prenda a verificação para sempre.
+	idleTimeout = 2 * time.Minute
+)
+
+// Server é um servidor SFTP verificado pelo Poller. HostKey é obrigatória: a conexão é
+// recusada se o servidor apresentar outra chave.
+type Server struct {
+	Name      string
+	Address   string
+	User      string
+	Auth      []ssh.AuthMethod
+	HostKey   ssh.PublicKey
+	Directory string
+	Pattern   string
+	MinAge    time.Duration
+}
+
+// Ledger registra os arquivos remotos já baixados.
+type Ledger interface {
+	Ingested(file domain.RemoteFile) bool
+	Save(file domain.RemoteFile) error
+}
+
+// Poller baixa os arquivos novos de uma pasta de um servidor SFTP e os processa um de cada
+// vez. Os arquivos remotos não são alterados; o Ledger evita que sejam baixados de novo.
+type Poller struct {
+	server  Server
+	ledger  Ledger
+	jobs    filesource.JobFinder
+	process filesource.ProcessFunc
+	idle    time.Duration
+	now     func() time.Time
+}
+
+func NewPoller(server Server, ledger Ledger, jobs filesource.JobFinder, process filesource.ProcessFunc) *Poller {
+	return &Poller{server: server, ledger: ledger, jobs: jobs, process: process, idle: idleTimeout, now: time.Now}
+}
+
+// Poll processa os arquivos novos do servidor e devolve quantos foram baixados. Uma falha
+// de conexão ou de download interrompe a verificação, e o arquivo é baixado de novo na
+// seguinte; arquivos baixados e descartados no processamento não são baixados de novo.
+func (p *Poller) Poll() (int, error) {
+	conn, err := p.dial()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao conectar ao SFTP %s: %w", p.server.Name, err)
+	}
+	defer conn.Close()
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar sessão SFTP em %s: %w", p.server.Name, err)
+	}
+	defer client.Close()
+
+	entries, err := client.ReadDir(p.server.Directory)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar %s no SFTP %s: %w", p.server.Directory, p.server.Name, err)
+	}
+
+	ingested := 0
+	for _, entry := range entries {
+		file := domain.RemoteFile{
+			Source:  p.server.Name,
+			Path:    path.Join(p.server.Directory, entry.Name()),
+			Size:    entry.Size(),
+			ModTime: entry.ModTime(),
+		}
+
+		if !p.pending(entry, file) {
+			continue
+		}
+
+		if err := p.ingest(client, file); err != nil {
+			return ingested, err
+		}
+		ingested++
+	}
+
+	return ingested, nil
+}
+
+// dial abre a conexão SSH sobre uma conexão TCP com prazo de inatividade, que vale também
+// para o handshake e para os downloads. Enquanto um arquivo é processado, keepalives
+// mantêm a conexão ativa; se o servidor deixar de respondê-los, ela expira.
+func (p *Poller) dial() (*ssh.Client, error) {
+	netConn, err := net.DialTimeout("tcp", p.server.Address, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, chans, reqs, err := ssh.NewClientConn(&idleConn{Conn: netConn, timeout: p.idle}, p.server.Address, &ssh.ClientConfig{
+		User:            p.server.User,
+		Auth:            p.server.Auth,
+		HostKeyCallback: ssh.FixedHostKey(p.server.HostKey),
+	})
+	if err != nil {
+		_ = netConn.Close()
+
+		return nil, err
+	}
+
+	client := ssh.NewClient(conn, chans, reqs)
+	go keepAlive(client, p.idle/2)
+
+	return client, nil
+}
+
+// keepAlive envia um keepalive a cada interval até a conexão ser encerrada.
+func keepAlive(client *ssh.Client, interval time.Duration) {
+	closed := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// idleConn renova o prazo da conexão a cada leitura e escrita, de modo que ela só expira
+// depois de timeout sem tráfego.
+type idleConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *idleConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+
+	return c.Conn.Read(b)
+}
+
+func (c *idleConn) Write(b []byte) (int, error) {
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+
+	return c.Conn.Write(b)
+}
+
+// Run verifica o servidor a cada interval até o contexto ser encerrado.
+func (p *Poller) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Poll(); err != nil {
+			log.Printf("Erro ao verificar SFTP %s: %v", p.server.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) pending(entry os.FileInfo, file domain.RemoteFile) bool {
+	if !entry.Mode().IsRegular() {
+		return false
+	}
+
+	if matched, _ := path.Match(p.server.Pattern, entry.Name()); !matched {
+		return false
+	}
+
+	if p.now().Sub(entry.ModTime()) < p.server.MinAge {
+		return false
+	}
+
+	return !p.ledger.Ingested(file)
+}
+
+// ingest baixa o arquivo para um diretório temporário, com o nome original, que define o
+// formato do arquivo no processamento, e registra o resultado.
+func (p *Poller) ingest(client *sftp.Client, file domain.RemoteFile) error {
+	dir, err := os.MkdirTemp("", "sftp-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, path.Base(file.Path))
+	if err := download(client, file, local); err != nil {
+		return fmt.Errorf("erro ao baixar %s do SFTP %s: %w", file.Path, p.server.Name, err)
+	}
+
+	jobIDs, err := filesource.Process(p.process, p.jobs, local)
+
+	file.JobIDs = jobIDs
+	file.IngestedAt = p.now()
+	if err != nil {
+		log.Printf("Arquivo %s do SFTP %s descartado: %v", file.Path, p.server.Name, err)
+		file.Error = err.Error()
+	} else {
+		log.Printf("Arquivo %s do SFTP %s processado: envios %v", file.Path, p.server.Name, jobIDs)
+	}
+
+	if err := p.ledger.Save(file); err != nil {
+		return fmt.Errorf("erro ao registrar %s do SFTP %s: %w", file.Path, p.server.Name, err)
+	}
+
+	return nil
+}
+
+// download copia o arquivo remoto e confere se o tamanho baixado é o listado, para não
+// processar um arquivo alterado durante o download.
+func download(client *sftp.Client, file domain.RemoteFile, local string) error {
+	remote, err := client.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer remote.Close()
+
+	destination, err := os.Create(local)
+	if err != nil {
+		return err
+	}
+
+	size, err := io.Copy(destination, remote)
+	if closeErr := destination.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if size != file.Size {
+		return fmt.Errorf("arquivo alterado durante o download: %d bytes listados, %d baixados", file.Size, size)
+	}
+
+	return nil
+}
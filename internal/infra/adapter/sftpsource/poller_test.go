@@ -0,0 +1,244 @@
+package sftpsource
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// startServer inicia um servidor SFTP local que serve root ao usuário banco, com a senha
+// segredo, e devolve seu endereço e sua chave.
+func startServer(t *testing.T, root string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "banco" && string(password) == "segredo" {
+				return nil, nil
+			}
+
+			return nil, errors.New("acesso negado")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveConn(conn, config, root)
+		}
+	}()
+
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig, root string) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "apenas sessões")
+
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for request := range requests {
+				ok := request.Type == "subsystem" && string(request.Payload[4:]) == "sftp"
+				request.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+
+				server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(root))
+				if err != nil {
+					channel.Close()
+
+					return
+				}
+				server.Serve()
+				channel.Close()
+			}
+		}()
+	}
+}
+
+// memoryLedger registra os arquivos baixados em memória.
+type memoryLedger map[string]domain.RemoteFile
+
+func (l memoryLedger) Ingested(file domain.RemoteFile) bool {
+	_, exists := l[file.Key()]
+
+	return exists
+}
+
+func (l memoryLedger) Save(file domain.RemoteFile) error {
+	l[file.Key()] = file
+
+	return nil
+}
+
+type jobsByID map[string]domain.IngestionJob
+
+func (j jobsByID) FindByID(id string) (domain.IngestionJob, bool) {
+	job, exists := j[id]
+
+	return job, exists
+}
+
+func TestPoller_Poll(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "saida"), 0o755))
+	address, hostKey := startServer(t, root)
+
+	old := time.Now().Add(-time.Hour)
+	write := func(name, content string, modTime time.Time) {
+		path := filepath.Join(root, "saida", name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	write("remessa-01.csv", "name\nJohn Doe\n", old)
+	write("sem-colunas.csv", "x\n", old)
+	write("gravando.csv", "name\n", time.Now())
+	write("leia-me.txt", "ignorado", old)
+
+	ledger := memoryLedger{}
+	jobs := jobsByID{"job_sem-colunas.csv": {Error: "colunas obrigatórias ausentes"}}
+	downloaded := map[string]string{}
+
+	server := Server{
+		Name:      "banco",
+		Address:   address,
+		User:      "banco",
+		Auth:      []ssh.AuthMethod{ssh.Password("segredo")},
+		HostKey:   hostKey,
+		Directory: "saida",
+		Pattern:   "*.csv",
+		MinAge:    time.Minute,
+	}
+	poller := NewPoller(server, ledger, jobs, func(path string) ([]string, error) {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		downloaded[filepath.Base(path)] = string(content)
+
+		return []string{"job_" + filepath.Base(path)}, nil
+	})
+
+	count, err := poller.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, map[string]string{"remessa-01.csv": "name\nJohn Doe\n", "sem-colunas.csv": "x\n"}, downloaded)
+
+	var failed domain.RemoteFile
+	for _, file := range ledger {
+		if file.Path == "saida/sem-colunas.csv" {
+			failed = file
+		}
+	}
+	assert.Contains(t, failed.Error, "colunas obrigatórias ausentes")
+
+	t.Run("Arquivos já baixados são ignorados", func(t *testing.T) {
+		count, err := poller.Poll()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("Arquivo republicado é baixado de novo", func(t *testing.T) {
+		write("remessa-01.csv", "name\nJohn Doe\nJane Doe\n", old.Add(time.Minute))
+
+		count, err := poller.Poll()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "name\nJohn Doe\nJane Doe\n", downloaded["remessa-01.csv"])
+	})
+
+	t.Run("Keepalives mantêm a conexão durante o processamento", func(t *testing.T) {
+		write("remessa-02.csv", "name\nJane Doe\n", old)
+		write("remessa-03.csv", "name\nJim Doe\n", old)
+
+		slow := NewPoller(server, ledger, jobs, func(path string) ([]string, error) {
+			time.Sleep(300 * time.Millisecond)
+
+			return []string{"job_" + filepath.Base(path)}, nil
+		})
+		slow.idle = 100 * time.Millisecond
+
+		count, err := slow.Poll()
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("Chave do servidor diferente", func(t *testing.T) {
+		public, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		otherKey, err := ssh.NewPublicKey(public)
+		require.NoError(t, err)
+
+		server.HostKey = otherKey
+		_, err = NewPoller(server, ledger, jobs, nil).Poll()
+		assert.Error(t, err)
+	})
+}
+
+func TestPoller_IdleServer(t *testing.T) {
+	// O servidor aceita a conexão e nunca inicia o handshake SSH.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	poller := NewPoller(Server{Name: "banco", Address: listener.Addr().String(), User: "banco"}, memoryLedger{}, jobsByID{}, nil)
+	poller.idle = 100 * time.Millisecond
+
+	started := time.Now()
+	_, err = poller.Poll()
+
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
@@ -0,0 +1,608 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/infra/adapter/archive"
+)
+
+// maxColumn é o índice da última coluna de uma planilha do Excel, XFD.
+const maxColumn = 16383
+
+var (
+	ErrInvalidWorkbook = errors.New("planilha XLSX inválida")
+	ErrSheetNotFound   = errors.New("aba não encontrada na planilha")
+)
+
+// Reader lê as linhas de uma aba de uma planilha XLSX em streaming: apenas a tabela de
+// textos compartilhados e os estilos ficam em memória, e as linhas são decodificadas à
+// medida que são lidas. Os valores são convertidos para o texto que apareceria em um CSV:
+// datas no formato YYYY-MM-DD e números sem notação científica ou resíduos de ponto
+// flutuante.
+type Reader struct {
+	sheet         io.ReadCloser
+	decoder       *xml.Decoder
+	sharedStrings []string
+	dateStyles    map[int]bool
+	date1904      bool
+	err           error
+}
+
+// Open abre a aba sheetName da planilha, ou a primeira aba quando sheetName é vazio.
+// Os limites de archive.Limits valem para o pacote da planilha como para um zip: o número
+// de arquivos do pacote e o total descompactado, somando os textos compartilhados, os
+// estilos e a aba lida, que falham com archive.ErrDecompressedLimit ao passar do limite.
+func Open(file io.ReaderAt, size int64, sheetName string, limits archive.Limits) (*Reader, error) {
+	reader, err := zip.NewReader(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+	}
+
+	if len(reader.File) > limits.MaxEntries {
+		return nil, fmt.Errorf("%w: %d arquivos no pacote, limite de %d", archive.ErrTooManyEntries, len(reader.File), limits.MaxEntries)
+	}
+
+	var declared uint64
+	for _, file := range reader.File {
+		declared += file.UncompressedSize64
+	}
+
+	if declared > uint64(limits.MaxDecompressedSize) {
+		return nil, fmt.Errorf("%w: %d bytes declarados, limite de %d", archive.ErrDecompressedLimit, declared, limits.MaxDecompressedSize)
+	}
+
+	parts := &packageParts{Reader: reader, budget: limits.MaxDecompressedSize}
+
+	book, err := readWorkbook(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetPath, err := book.sheetPath(parts, sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedStrings, err := readSharedStrings(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	dateStyles, err := readDateStyles(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet, err := openEntry(parts, sheetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		sheet:         sheet,
+		decoder:       xml.NewDecoder(sheet),
+		sharedStrings: sharedStrings,
+		dateStyles:    dateStyles,
+		date1904:      book.date1904(),
+	}, nil
+}
+
+// Read devolve a próxima linha com valores, ignorando linhas vazias, e io.EOF ao fim da
+// aba. Células vazias no meio da linha viram campos vazios; as do fim são descartadas.
+func (r *Reader) Read() ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for {
+		row, err := r.nextRow()
+		if err != nil {
+			r.err = err
+
+			return nil, err
+		}
+
+		if len(row) > 0 {
+			return row, nil
+		}
+	}
+}
+
+func (r *Reader) Close() error {
+	return r.sheet.Close()
+}
+
+func (r *Reader) nextRow() ([]string, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidWorkbook, err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "row" {
+			return r.readRow()
+		}
+	}
+}
+
+func (r *Reader) readRow() ([]string, error) {
+	var row []string
+
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: linha incompleta: %w", ErrInvalidWorkbook, err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if element.Name.Local != "c" {
+				continue
+			}
+
+			column, value, err := r.readCell(element, len(row))
+			if err != nil {
+				return nil, err
+			}
+
+			for len(row) < column {
+				row = append(row, "")
+			}
+
+			row = append(row, value)
+		case xml.EndElement:
+			if element.Name.Local == "row" {
+				for len(row) > 0 && row[len(row)-1] == "" {
+					row = row[:len(row)-1]
+				}
+
+				return row, nil
+			}
+		}
+	}
+}
+
+// readCell devolve a coluna da célula (a partir de zero) e seu valor convertido.
+func (r *Reader) readCell(start xml.StartElement, next int) (int, string, error) {
+	column, cellType, style := next, "", -1
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "r":
+			if index, ok := columnIndex(attr.Value); ok {
+				column = index
+			}
+		case "t":
+			cellType = attr.Value
+		case "s":
+			if index, err := strconv.Atoi(attr.Value); err == nil {
+				style = index
+			}
+		}
+	}
+
+	if column < next {
+		return 0, "", fmt.Errorf("%w: célula %s fora de ordem", ErrInvalidWorkbook, cellRef(start))
+	}
+
+	// Sem o limite, uma referência como ZZZZZ1 faria a linha ocupar milhões de colunas vazias.
+	if column > maxColumn {
+		return 0, "", fmt.Errorf("%w: célula %s além da coluna XFD", ErrInvalidWorkbook, cellRef(start))
+	}
+
+	var value, inline strings.Builder
+	var inValue, inInline bool
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return 0, "", fmt.Errorf("%w: célula incompleta: %w", ErrInvalidWorkbook, err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "v":
+				inValue = true
+			case "t":
+				inInline = cellType == "inlineStr"
+			case "rPh":
+				if err := r.decoder.Skip(); err != nil {
+					return 0, "", fmt.Errorf("%w: %v", ErrInvalidWorkbook, err)
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(element)
+			}
+
+			if inInline {
+				inline.Write(element)
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "v":
+				inValue = false
+			case "t":
+				inInline = false
+			case "c":
+				converted, err := r.convert(cellType, style, value.String(), inline.String())
+
+				return column, converted, err
+			}
+		}
+	}
+}
+
+func (r *Reader) convert(cellType string, style int, value, inline string) (string, error) {
+	switch cellType {
+	case "s":
+		index, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || index < 0 || index >= len(r.sharedStrings) {
+			return "", fmt.Errorf("%w: texto compartilhado %q inexistente", ErrInvalidWorkbook, value)
+		}
+
+		return r.sharedStrings[index], nil
+	case "inlineStr":
+		return inline, nil
+	case "b":
+		if strings.TrimSpace(value) == "1" {
+			return "TRUE", nil
+		}
+
+		return "FALSE", nil
+	case "d":
+		return isoDate(value), nil
+	case "str", "e":
+		return value, nil
+	default:
+		if value == "" {
+			return "", nil
+		}
+
+		if r.dateStyles[style] {
+			return serialDate(value, r.date1904), nil
+		}
+
+		return normalizeNumber(value), nil
+	}
+}
+
+// normalizeNumber descarta os resíduos de ponto flutuante que o Excel grava (como
+// 1000.5000000000001) arredondando para 15 dígitos significativos, a precisão exibida pelo
+// próprio Excel, e evita a notação científica.
+func normalizeNumber(value string) string {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+		return value
+	}
+
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(number, 'g', 15, 64), 64)
+
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// serialDate converte o número de série de data do Excel. No sistema de 1900 a base é
+// 30/12/1899, o que compensa o 29/02/1900 inexistente contado pelo Excel.
+func serialDate(value string, date1904 bool) string {
+	serial, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return value
+	}
+
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	at := base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	if seconds == 0 {
+		return at.Format("2006-01-02")
+	}
+
+	return at.Format("2006-01-02 15:04:05")
+}
+
+// isoDate converte as células do tipo data, gravadas em ISO 8601.
+func isoDate(value string) string {
+	date, clock, _ := strings.Cut(strings.TrimSpace(value), "T")
+	if clock == "" || strings.HasPrefix(clock, "00:00:00") {
+		return date
+	}
+
+	return date + " " + strings.TrimSuffix(clock[:min(len(clock), 8)], "Z")
+}
+
+// columnIndex converte a referência da célula (como C12) no índice da coluna a partir de
+// zero. Referências com mais de três letras valem como além da última coluna.
+func columnIndex(ref string) (int, bool) {
+	index := 0
+	letters := 0
+	for _, char := range strings.ToUpper(ref) {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+
+		if letters == 3 {
+			return maxColumn + 1, true
+		}
+
+		index = index*26 + int(char-'A'+1)
+		letters++
+	}
+
+	if letters == 0 {
+		return 0, false
+	}
+
+	return index - 1, true
+}
+
+func cellRef(start xml.StartElement) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "r" {
+			return attr.Value
+		}
+	}
+
+	return "?"
+}
+
+type workbook struct {
+	Properties struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name  string     `xml:"name,attr"`
+		Attrs []xml.Attr `xml:",any,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+func (w workbook) date1904() bool {
+	return w.Properties.Date1904 == "1" || strings.EqualFold(w.Properties.Date1904, "true")
+}
+
+// sheetPath localiza o arquivo da aba pelo relacionamento declarado no workbook.
+func (w workbook) sheetPath(parts *packageParts, name string) (string, error) {
+	if len(w.Sheets) == 0 {
+		return "", fmt.Errorf("%w: nenhuma aba", ErrInvalidWorkbook)
+	}
+
+	index := 0
+	if name != "" {
+		index = -1
+		for i, sheet := range w.Sheets {
+			if strings.EqualFold(strings.TrimSpace(sheet.Name), strings.TrimSpace(name)) {
+				index = i
+
+				break
+			}
+		}
+
+		if index < 0 {
+			return "", fmt.Errorf("%w: %q", ErrSheetNotFound, name)
+		}
+	}
+
+	var relationID string
+	for _, attr := range w.Sheets[index].Attrs {
+		if attr.Name.Local == "id" {
+			relationID = attr.Value
+		}
+	}
+
+	var rels relationships
+	if err := decodeEntry(parts, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != relationID {
+			continue
+		}
+
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+
+		return path.Join("xl", rel.Target), nil
+	}
+
+	return "", fmt.Errorf("%w: aba %q sem arquivo", ErrInvalidWorkbook, w.Sheets[index].Name)
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+func readWorkbook(parts *packageParts) (workbook, error) {
+	var book workbook
+	if err := decodeEntry(parts, "xl/workbook.xml", &book); err != nil {
+		return workbook{}, err
+	}
+
+	return book, nil
+}
+
+// readSharedStrings carrega a tabela de textos compartilhados, concatenando os trechos de
+// texto formatado e ignorando as anotações fonéticas.
+func readSharedStrings(parts *packageParts) ([]string, error) {
+	entry, err := openEntry(parts, "xl/sharedStrings.xml")
+	if errors.Is(err, errEntryNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+
+	var strs []string
+	var current strings.Builder
+	var inText bool
+	decoder := xml.NewDecoder(entry)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return strs, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: textos compartilhados: %w", ErrInvalidWorkbook, err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				if err := decoder.Skip(); err != nil {
+					return nil, fmt.Errorf("%w: textos compartilhados: %w", ErrInvalidWorkbook, err)
+				}
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(element)
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "t":
+				inText = false
+			case "si":
+				strs = append(strs, current.String())
+			}
+		}
+	}
+}
+
+type styleSheet struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+// readDateStyles identifica os estilos de célula com formato de data, em que o valor
+// numérico é um número de série de data.
+func readDateStyles(parts *packageParts) (map[int]bool, error) {
+	var styles styleSheet
+	err := decodeEntry(parts, "xl/styles.xml", &styles)
+	if errors.Is(err, errEntryNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	custom := make(map[int]string, len(styles.NumFmts))
+	for _, format := range styles.NumFmts {
+		custom[format.ID] = format.Code
+	}
+
+	dateStyles := make(map[int]bool)
+	for index, xf := range styles.CellXfs {
+		code, isCustom := custom[xf.NumFmtID]
+		if isCustom && isDateFormat(code) || !isCustom && isBuiltinDateFormat(xf.NumFmtID) {
+			dateStyles[index] = true
+		}
+	}
+
+	return dateStyles, nil
+}
+
+// isBuiltinDateFormat reconhece os formatos de data e hora predefinidos do Excel.
+func isBuiltinDateFormat(id int) bool {
+	return id >= 14 && id <= 22 || id >= 45 && id <= 47
+}
+
+// isDateFormat reconhece formatos personalizados de data, como dd/mm/yyyy, ignorando os
+// trechos literais entre aspas, escapados ou entre colchetes.
+func isDateFormat(code string) bool {
+	var cleaned strings.Builder
+	quoted, bracket, escaped := false, false, false
+	for _, char := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '"':
+			quoted = !quoted
+		case quoted:
+		case char == '[':
+			bracket = true
+		case char == ']':
+			bracket = false
+		case bracket:
+		default:
+			cleaned.WriteRune(char)
+		}
+	}
+
+	lower := strings.ToLower(cleaned.String())
+
+	return strings.ContainsAny(lower, "yd")
+}
+
+// packageParts é o pacote zip da planilha, com o que resta do limite descompactado
+// compartilhado pelos arquivos lidos.
+type packageParts struct {
+	*zip.Reader
+	budget int64
+}
+
+// errEntryNotFound indica um arquivo ausente no pacote; os textos compartilhados e os
+// estilos são opcionais.
+var errEntryNotFound = errors.New("arquivo não encontrado na planilha")
+
+func openEntry(parts *packageParts, name string) (io.ReadCloser, error) {
+	for _, file := range parts.File {
+		if strings.EqualFold(file.Name, name) {
+			entry, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidWorkbook, name, err)
+			}
+
+			return archive.LimitDecompressed(entry, &parts.budget), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %w: %s", ErrInvalidWorkbook, errEntryNotFound, name)
+}
+
+func decodeEntry(parts *packageParts, name string, target any) error {
+	entry, err := openEntry(parts, name)
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	if err := xml.NewDecoder(entry).Decode(target); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidWorkbook, name, err)
+	}
+
+	return nil
+}
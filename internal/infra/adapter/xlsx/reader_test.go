@@ -0,0 +1,248 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"kanastra-api/internal/infra/adapter/archive"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <workbookPr date1904="false"/>
+  <sheets>
+    <sheet name="Resumo" sheetId="1" r:id="rId1"/>
+    <sheet name="Débitos" sheetId="2" r:id="rId2"/>
+  </sheets>
+</workbook>`
+	testRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`
+	testSharedStrings = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="8" uniqueCount="8">
+  <si><t>name</t></si>
+  <si><t>governmentId</t></si>
+  <si><t>email</t></si>
+  <si><t>debtAmount</t></si>
+  <si><t>debtDueDate</t></si>
+  <si><t>debtId</t></si>
+  <si><r><t>João </t></r><r><rPr><b/></rPr><t>da Silva</t></r><rPh><t>ジョアン</t></rPh></si>
+  <si><t>joao@example.com</t></si>
+</sst>`
+	testStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <numFmts count="2">
+    <numFmt numFmtId="164" formatCode="dd/mm/yyyy"/>
+    <numFmt numFmtId="165" formatCode="&quot;R$&quot; #,##0.00"/>
+  </numFmts>
+  <cellStyleXfs count="1"><xf numFmtId="14"/></cellStyleXfs>
+  <cellXfs count="4">
+    <xf numFmtId="0"/>
+    <xf numFmtId="164"/>
+    <xf numFmtId="165"/>
+    <xf numFmtId="14"/>
+  </cellXfs>
+</styleSheet>`
+	testSummarySheet = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>Resumo do lote</t></is></c></row></sheetData>
+</worksheet>`
+	testDebtsSheet = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1">
+      <c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c>
+      <c r="D1" t="s"><v>3</v></c><c r="E1" t="s"><v>4</v></c><c r="F1" t="s"><v>5</v></c>
+    </row>
+    <row r="2">
+      <c r="A2" t="s"><v>6</v></c>
+      <c r="B2"><v>12345678901</v></c>
+      <c r="C2" t="s"><v>7</v></c>
+      <c r="D2" s="2"><v>1000.5000000000001</v></c>
+      <c r="E2" s="1"><v>45658</v></c>
+      <c r="F2" t="inlineStr"><is><t>abc123</t></is></c>
+      <c r="G2" s="1"/>
+    </row>
+    <row r="3"/>
+    <row r="5">
+      <c r="A5" t="str"><f>UPPER("maria")</f><v>MARIA</v></c>
+      <c r="C5" t="b"><v>1</v></c>
+      <c r="E5" t="d"><v>2025-03-10T00:00:00Z</v></c>
+      <c r="F5" s="3"><v>45658.5</v></c>
+    </row>
+  </sheetData>
+</worksheet>`
+)
+
+var testLimits = archive.Limits{MaxDecompressedSize: 1 << 20, MaxEntries: 20}
+
+func buildWorkbook(t *testing.T, parts map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range parts {
+		part, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(part, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func testParts() map[string]string {
+	return map[string]string{
+		"xl/workbook.xml":            testWorkbook,
+		"xl/_rels/workbook.xml.rels": testRels,
+		"xl/sharedStrings.xml":       testSharedStrings,
+		"xl/styles.xml":              testStyles,
+		"xl/worksheets/sheet1.xml":   testSummarySheet,
+		"xl/worksheets/sheet2.xml":   testDebtsSheet,
+	}
+}
+
+func readAll(t *testing.T, reader *Reader) [][]string {
+	t.Helper()
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows
+		}
+
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestReader_NamedSheet(t *testing.T) {
+	file := buildWorkbook(t, testParts())
+
+	reader, err := Open(file, file.Size(), "débitos", testLimits)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, [][]string{
+		{"name", "governmentId", "email", "debtAmount", "debtDueDate", "debtId"},
+		{"João da Silva", "12345678901", "joao@example.com", "1000.5", "2025-01-01", "abc123"},
+		{"MARIA", "", "TRUE", "", "2025-03-10", "2025-01-01 12:00:00"},
+	}, readAll(t, reader))
+}
+
+func TestReader_FirstSheetByDefault(t *testing.T) {
+	file := buildWorkbook(t, testParts())
+
+	reader, err := Open(file, file.Size(), "", testLimits)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, [][]string{{"Resumo do lote"}}, readAll(t, reader))
+}
+
+func TestReader_Errors(t *testing.T) {
+	t.Run("Aba inexistente", func(t *testing.T) {
+		file := buildWorkbook(t, testParts())
+
+		_, err := Open(file, file.Size(), "Pagamentos", testLimits)
+
+		assert.ErrorIs(t, err, ErrSheetNotFound)
+	})
+
+	t.Run("Arquivo que não é XLSX", func(t *testing.T) {
+		file := bytes.NewReader([]byte("name,governmentId\n"))
+
+		_, err := Open(file, file.Size(), "", testLimits)
+
+		assert.ErrorIs(t, err, ErrInvalidWorkbook)
+	})
+
+	t.Run("XML da aba corrompido", func(t *testing.T) {
+		parts := testParts()
+		parts["xl/worksheets/sheet1.xml"] = `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>ok`
+		file := buildWorkbook(t, parts)
+
+		reader, err := Open(file, file.Size(), "", testLimits)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		_, err = reader.Read()
+		assert.ErrorIs(t, err, ErrInvalidWorkbook)
+		_, err = reader.Read()
+		assert.ErrorIs(t, err, ErrInvalidWorkbook)
+	})
+}
+
+func TestReader_ColumnLimit(t *testing.T) {
+	for _, ref := range []string{"XFE1", "ZZZZZZZZZZZZZZ1"} {
+		t.Run(ref, func(t *testing.T) {
+			parts := testParts()
+			parts["xl/worksheets/sheet1.xml"] = `<worksheet><sheetData><row r="1"><c r="` + ref + `" t="inlineStr"><is><t>longe</t></is></c></row></sheetData></worksheet>`
+			file := buildWorkbook(t, parts)
+
+			reader, err := Open(file, file.Size(), "", testLimits)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			_, err = reader.Read()
+			assert.ErrorIs(t, err, ErrInvalidWorkbook)
+		})
+	}
+
+	t.Run("XFD é a última coluna", func(t *testing.T) {
+		parts := testParts()
+		parts["xl/worksheets/sheet1.xml"] = `<worksheet><sheetData><row r="1"><c r="XFD1" t="inlineStr"><is><t>fim</t></is></c></row></sheetData></worksheet>`
+		file := buildWorkbook(t, parts)
+
+		reader, err := Open(file, file.Size(), "", testLimits)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		row, err := reader.Read()
+		require.NoError(t, err)
+		assert.Len(t, row, maxColumn+1)
+		assert.Equal(t, "fim", row[maxColumn])
+	})
+}
+
+func TestReader_Limits(t *testing.T) {
+	t.Run("Textos compartilhados acima do limite", func(t *testing.T) {
+		parts := testParts()
+		parts["xl/sharedStrings.xml"] = `<sst><si><t>` + strings.Repeat("a", 4096) + `</t></si></sst>`
+		file := buildWorkbook(t, parts)
+
+		_, err := Open(file, file.Size(), "", archive.Limits{MaxDecompressedSize: 4096, MaxEntries: 20})
+
+		assert.ErrorIs(t, err, archive.ErrDecompressedLimit)
+	})
+
+	t.Run("Arquivos demais no pacote", func(t *testing.T) {
+		file := buildWorkbook(t, testParts())
+
+		_, err := Open(file, file.Size(), "", archive.Limits{MaxDecompressedSize: 1 << 20, MaxEntries: 5})
+
+		assert.ErrorIs(t, err, archive.ErrTooManyEntries)
+	})
+}
+
+func TestSerialDate_1904(t *testing.T) {
+	assert.Equal(t, "2025-01-01", serialDate("44196", true))
+}
+
+func TestIsDateFormat(t *testing.T) {
+	assert.True(t, isDateFormat("dd/mm/yyyy"))
+	assert.True(t, isDateFormat("[$-416]d-mmm-yy;@"))
+	assert.False(t, isDateFormat(`"R$" #,##0.00`))
+	assert.False(t, isDateFormat("0.00"))
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/infra/i18n"
+)
+
+var ErrUnsupportedLocale = errors.New("idioma não suportado")
+
+// ClientSettings agrupa as configurações que variam de cliente para cliente.
+type ClientSettings struct {
+	ChargePolicy domain.ChargePolicy `json:"chargePolicy"`
+	// DunningCadence é a régua de lembretes do cliente; uma lista vazia desativa os
+	// lembretes, e a ausência do campo usa a régua padrão.
+	DunningCadence domain.DunningCadence `json:"dunningCadence"`
+	// Locale é o idioma das notificações dos devedores do cliente, como pt-BR ou en-US.
+	Locale string `json:"locale"`
+	// Columns localiza os campos dos débitos nos arquivos enviados pelo cliente, por nome
+	// ou posição da coluna.
+	Columns domain.ColumnMapping `json:"columns"`
+}
+
+type Clients struct {
+	Default ClientSettings            `json:"default"`
+	Clients map[string]ClientSettings `json:"clients"`
+}
+
+// LoadClients lê o arquivo JSON de configuração dos clientes. Sem caminho informado,
+// todos os clientes usam as configurações padrão.
+func LoadClients(path string) (*Clients, error) {
+	clients := &Clients{Clients: make(map[string]ClientSettings)}
+	if path == "" {
+		return clients, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler configuração de clientes: %w", err)
+	}
+
+	if err := json.Unmarshal(content, clients); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar configuração de clientes: %w", err)
+	}
+
+	if clients.Clients == nil {
+		clients.Clients = make(map[string]ClientSettings)
+	}
+
+	if err := clients.Default.DunningCadence.Validate(); err != nil {
+		return nil, fmt.Errorf("configuração padrão: %w", err)
+	}
+
+	if err := validateLocale(clients.Default.Locale); err != nil {
+		return nil, fmt.Errorf("configuração padrão: %w", err)
+	}
+
+	if err := clients.Default.Columns.Validate(); err != nil {
+		return nil, fmt.Errorf("configuração padrão: %w", err)
+	}
+
+	for clientID, settings := range clients.Clients {
+		if err := settings.DunningCadence.Validate(); err != nil {
+			return nil, fmt.Errorf("cliente %s: %w", clientID, err)
+		}
+
+		if err := validateLocale(settings.Locale); err != nil {
+			return nil, fmt.Errorf("cliente %s: %w", clientID, err)
+		}
+
+		if err := settings.Columns.Validate(); err != nil {
+			return nil, fmt.Errorf("cliente %s: %w", clientID, err)
+		}
+	}
+
+	return clients, nil
+}
+
+func (c *Clients) Settings(clientID string) ClientSettings {
+	if settings, ok := c.Clients[clientID]; ok {
+		return settings
+	}
+
+	return c.Default
+}
+
+func (c *Clients) ChargePolicy(clientID string) domain.ChargePolicy {
+	return c.Settings(clientID).ChargePolicy
+}
+
+func (c *Clients) DunningCadence(clientID string) domain.DunningCadence {
+	if settings, ok := c.Clients[clientID]; ok && settings.DunningCadence != nil {
+		return settings.DunningCadence
+	}
+
+	if c.Default.DunningCadence != nil {
+		return c.Default.DunningCadence
+	}
+
+	return domain.DefaultDunningCadence
+}
+
+// Locale devolve o idioma configurado para o cliente, usando o padrão quando ele não
+// define um.
+func (c *Clients) Locale(clientID string) string {
+	if settings, ok := c.Clients[clientID]; ok && settings.Locale != "" {
+		return settings.Locale
+	}
+
+	if c.Default.Locale != "" {
+		return c.Default.Locale
+	}
+
+	return string(i18n.Default)
+}
+
+// ColumnMapping devolve o mapeamento de colunas dos arquivos do cliente. Os campos que o
+// cliente não mapeia usam o mapeamento padrão.
+func (c *Clients) ColumnMapping(clientID string) domain.ColumnMapping {
+	mapping := c.Default.Columns
+	if settings, ok := c.Clients[clientID]; ok {
+		mapping = mapping.Merge(settings.Columns)
+	}
+
+	return mapping
+}
+
+func validateLocale(tag string) error {
+	if tag == "" {
+		return nil
+	}
+
+	if _, ok := i18n.Parse(tag); !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedLocale, tag)
+	}
+
+	return nil
+}
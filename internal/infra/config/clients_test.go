@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestLoadClients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	content := `{
+		"default": {"chargePolicy": {"finePercent": 2, "monthlyInterestPercent": 1}},
+		"clients": {"acme": {"chargePolicy": {"finePercent": 1, "earlyPaymentDiscountPercent": 5, "earlyPaymentDiscountDays": 3}}}
+	}`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	clients, err := LoadClients(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1.0, clients.ChargePolicy("acme").FinePercent)
+	assert.Equal(t, 5.0, clients.ChargePolicy("acme").EarlyPaymentDiscountPercent)
+	assert.Equal(t, 2.0, clients.ChargePolicy("unknown").FinePercent)
+	assert.Equal(t, 1.0, clients.ChargePolicy("").MonthlyInterestPercent)
+}
+
+func TestLoadClients_WithoutFile(t *testing.T) {
+	clients, err := LoadClients("")
+
+	assert.NoError(t, err)
+	assert.Zero(t, clients.ChargePolicy("acme"))
+}
+
+func TestLoadClients_InvalidFile(t *testing.T) {
+	_, err := LoadClients(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "clients.json")
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err = LoadClients(path)
+	assert.Error(t, err)
+}
+
+func TestLoadClients_DunningCadence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	content := `{
+		"default": {"dunningCadence": [-3, 0, 7]},
+		"clients": {"acme": {"dunningCadence": [-1, 2]}, "quiet": {"dunningCadence": []}, "other": {}}
+	}`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	clients, err := LoadClients(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, domain.DunningCadence{-1, 2}, clients.DunningCadence("acme"))
+	assert.Empty(t, clients.DunningCadence("quiet"))
+	assert.Equal(t, domain.DunningCadence{-3, 0, 7}, clients.DunningCadence("other"))
+
+	withoutFile, err := LoadClients("")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultDunningCadence, withoutFile.DunningCadence("acme"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"clients": {"acme": {"dunningCadence": [3, -1]}}}`), 0o600))
+	_, err = LoadClients(path)
+	assert.ErrorIs(t, err, domain.ErrInvalidDunningCadence)
+}
+
+func TestLoadClients_Locale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	content := `{"clients": {"acme": {"locale": "en-US"}, "other": {}}}`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	clients, err := LoadClients(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "en-US", clients.Locale("acme"))
+	assert.Equal(t, "pt-BR", clients.Locale("other"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"clients": {"acme": {"locale": "fr-FR"}}}`), 0o600))
+	_, err = LoadClients(path)
+	assert.ErrorIs(t, err, ErrUnsupportedLocale)
+}
+
+func TestLoadClients_ColumnMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clients.json")
+	content := `{
+		"default": {"columns": {"debtId": "codigo"}},
+		"clients": {"acme": {"columns": {"governmentId": ["cpf", "documento"], "debtAmount": 4}}}
+	}`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	clients, err := LoadClients(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, domain.ColumnMapping{
+		domain.DebtFieldDebtID:       {Headers: []string{"codigo"}},
+		domain.DebtFieldGovernmentID: {Headers: []string{"cpf", "documento"}},
+		domain.DebtFieldDebtAmount:   {Position: 4},
+	}, clients.ColumnMapping("acme"))
+	assert.Equal(t, domain.ColumnMapping{domain.DebtFieldDebtID: {Headers: []string{"codigo"}}}, clients.ColumnMapping("other"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"clients": {"acme": {"columns": {"cpf": "documento"}}}}`), 0o600))
+
+	_, err = LoadClients(path)
+	assert.ErrorIs(t, err, domain.ErrInvalidColumnMapping)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
+)
+
+// LoadHolidays lê os feriados municipais de um arquivo texto com uma entrada por linha
+// no formato "YYYY-MM-DD;Nome" (data específica) ou "MM-DD;Nome" (todo ano). Linhas em
+// branco e iniciadas por "#" são ignoradas.
+func LoadHolidays(path string) ([]domain.Holiday, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de feriados: %w", err)
+	}
+
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	var holidays []domain.Holiday
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		date, name, _ := strings.Cut(line, ";")
+		date = strings.TrimSpace(date)
+		if !isHolidayDate(date) {
+			return nil, fmt.Errorf("data inválida na linha %d do arquivo de feriados: %s", lineNumber, date)
+		}
+
+		holidays = append(holidays, domain.Holiday{Date: date, Name: strings.TrimSpace(name)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de feriados: %w", err)
+	}
+
+	return holidays, nil
+}
+
+func isHolidayDate(date string) bool {
+	if _, err := time.Parse(time.DateOnly, date); err == nil {
+		return true
+	}
+
+	_, err := time.Parse("01-02", date)
+
+	return err == nil
+}
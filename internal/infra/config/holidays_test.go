@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kanastra-api/internal/core/domain"
+)
+
+func TestLoadHolidays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feriados.txt")
+	content := "# São Paulo\n01-25;Aniversário de São Paulo\n\n2025-07-09; Revolução Constitucionalista\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	holidays, err := LoadHolidays(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Holiday{
+		{Date: "01-25", Name: "Aniversário de São Paulo"},
+		{Date: "2025-07-09", Name: "Revolução Constitucionalista"},
+	}, holidays)
+}
+
+func TestLoadHolidays_InvalidDate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feriados.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("25/01;Aniversário\n"), 0o600))
+
+	_, err := LoadHolidays(path)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "linha 1")
+}
+
+func TestLoadHolidays_WithoutFile(t *testing.T) {
+	holidays, err := LoadHolidays("")
+
+	assert.NoError(t, err)
+	assert.Empty(t, holidays)
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+var ErrInvalidSFTPSource = errors.New("origem SFTP inválida")
+
+const (
+	defaultSFTPInterval = 5 * time.Minute
+	defaultSFTPMinAge   = time.Minute
+)
+
+// Duration lê durações no formato de time.ParseDuration, como "5m" ou "30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duração deve ser um texto como \"5m\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// SFTPSource é um servidor SFTP de onde são baixados os arquivos de débitos de um cliente.
+type SFTPSource struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	User    string `json:"user"`
+	// Password e PrivateKeyFile autenticam o acesso; ao menos um deles é obrigatório.
+	Password       string `json:"password"`
+	PrivateKeyFile string `json:"privateKeyFile"`
+	// HostKey é a chave pública do servidor no formato do authorized_keys, conferida a cada
+	// conexão.
+	HostKey string `json:"hostKey"`
+	// Directory é a pasta remota verificada, e Pattern, o padrão de path.Match dos nomes
+	// dos arquivos baixados.
+	Directory string   `json:"directory"`
+	Pattern   string   `json:"pattern"`
+	ClientID  string   `json:"clientId"`
+	Interval  Duration `json:"interval"`
+	// MinAge é o tempo desde a última alteração para que um arquivo seja baixado, para não
+	// baixar arquivos que o servidor ainda está recebendo.
+	MinAge Duration `json:"minAge"`
+}
+
+// LoadSFTPSources lê o arquivo JSON com a lista de servidores SFTP. Sem caminho informado,
+// nenhum servidor é verificado.
+func LoadSFTPSources(path string) ([]SFTPSource, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler configuração de origens SFTP: %w", err)
+	}
+
+	var sources []SFTPSource
+	if err := json.Unmarshal(content, &sources); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar configuração de origens SFTP: %w", err)
+	}
+
+	names := make(map[string]bool, len(sources))
+	for i := range sources {
+		source := &sources[i]
+		if err := source.validate(); err != nil {
+			return nil, fmt.Errorf("origem SFTP %d: %w", i+1, err)
+		}
+
+		if names[source.Name] {
+			return nil, fmt.Errorf("%w: nome %q repetido", ErrInvalidSFTPSource, source.Name)
+		}
+		names[source.Name] = true
+	}
+
+	return sources, nil
+}
+
+// validate confere os campos obrigatórios e preenche os padrões dos opcionais.
+func (s *SFTPSource) validate() error {
+	switch {
+	case s.Name == "", s.Address == "", s.User == "":
+		return fmt.Errorf("%w: name, address e user são obrigatórios", ErrInvalidSFTPSource)
+	case s.Password == "" && s.PrivateKeyFile == "":
+		return fmt.Errorf("%w: %s sem password ou privateKeyFile", ErrInvalidSFTPSource, s.Name)
+	case s.HostKey == "":
+		return fmt.Errorf("%w: %s sem hostKey", ErrInvalidSFTPSource, s.Name)
+	}
+
+	if s.Directory == "" {
+		s.Directory = "."
+	}
+
+	if s.Pattern == "" {
+		s.Pattern = "*"
+	}
+
+	if _, err := path.Match(s.Pattern, ""); err != nil {
+		return fmt.Errorf("%w: %s com pattern inválido: %w", ErrInvalidSFTPSource, s.Name, err)
+	}
+
+	if s.Interval <= 0 {
+		s.Interval = Duration(defaultSFTPInterval)
+	}
+
+	if s.MinAge <= 0 {
+		s.MinAge = Duration(defaultSFTPMinAge)
+	}
+
+	return nil
+}
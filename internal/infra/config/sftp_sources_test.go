@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSFTPSources(t *testing.T) {
+	load := func(content string) ([]SFTPSource, error) {
+		path := filepath.Join(t.TempDir(), "sftp.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return LoadSFTPSources(path)
+	}
+
+	t.Run("Padrões dos campos opcionais", func(t *testing.T) {
+		sources, err := load(`[
+			{"name": "banco", "address": "sftp.banco.com:22", "user": "kanastra", "password": "segredo",
+			 "hostKey": "ssh-ed25519 AAAA", "pattern": "REM*.csv", "interval": "15m"}
+		]`)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ".", sources[0].Directory)
+		assert.Equal(t, Duration(15*time.Minute), sources[0].Interval)
+		assert.Equal(t, Duration(time.Minute), sources[0].MinAge)
+	})
+
+	t.Run("Sem chave do servidor", func(t *testing.T) {
+		_, err := load(`[{"name": "banco", "address": "sftp.banco.com:22", "user": "kanastra", "password": "segredo"}]`)
+
+		assert.ErrorIs(t, err, ErrInvalidSFTPSource)
+	})
+
+	t.Run("Nome repetido", func(t *testing.T) {
+		source := `{"name": "banco", "address": "sftp.banco.com:22", "user": "kanastra", "password": "segredo", "hostKey": "ssh-ed25519 AAAA"}`
+		_, err := load("[" + source + "," + source + "]")
+
+		assert.ErrorIs(t, err, ErrInvalidSFTPSource)
+	})
+
+	t.Run("Sem arquivo", func(t *testing.T) {
+		sources, err := LoadSFTPSources("")
+
+		assert.NoError(t, err)
+		assert.Empty(t, sources)
+	})
+}
@@ -0,0 +1,141 @@
+package i18n
+
+import "fmt"
+
+// apiMessages traduz as mensagens das respostas da API. As mensagens são escritas em
+// inglês no código e a própria mensagem é a chave do catálogo.
+var apiMessages = map[Locale]map[string]string{
+	PtBR: {
+		"Invalid payload":                        "Payload inválido",
+		"Invalid signature":                      "Assinatura inválida",
+		"Failed to read body":                    "Falha ao ler o corpo da requisição",
+		"Failed to parse form":                   "Falha ao interpretar o formulário",
+		"No files provided":                      "Nenhum arquivo enviado",
+		"Files are being processed":              "Os arquivos estão sendo processados",
+		"Return files reconciled":                "Arquivos de retorno conciliados",
+		"txid or nosso_numero is required":       "txid ou nosso_numero é obrigatório",
+		"Event already processed":                "Evento já processado",
+		"Invoice not found":                      "Boleto não encontrado",
+		"Failed to process payment":              "Falha ao processar o pagamento",
+		"Payment received":                       "Pagamento recebido",
+		"Invoice already paid":                   "Boleto já foi liquidado",
+		"Invoice rejected by the bank":           "Boleto foi rejeitado pelo banco",
+		"Invoice cancelled":                      "Boleto foi cancelado",
+		"Invalid installment parameters":         "Parâmetros de parcelamento inválidos",
+		"Debt not found":                         "Débito não encontrado",
+		"Installment plan already exists":        "O débito já possui parcelamento",
+		"Debt cannot be split into installments": "O débito não pode ser parcelado",
+		"Failed to create installment plan":      "Falha ao criar o parcelamento",
+		"Installment plan created":               "Parcelamento criado",
+		"Installment plan not found":             "Parcelamento não encontrado",
+		"Installment plan found":                 "Parcelamento encontrado",
+		"Email delivery not found":               "Envio de e-mail não encontrado",
+		"Failed to process email event":          "Falha ao processar o evento de e-mail",
+		"Email event received":                   "Evento de e-mail recebido",
+		"Invalid contact preferences":            "Preferências de contato inválidas",
+		"Failed to save contact preferences":     "Falha ao salvar as preferências de contato",
+		"Contact preferences saved":              "Preferências de contato salvas",
+		"Contact preferences not found":          "Preferências de contato não encontradas",
+		"Contact preferences found":              "Preferências de contato encontradas",
+		"Invalid unsubscribe link":               "Link de descadastro inválido",
+		"Failed to unsubscribe":                  "Falha ao registrar o descadastro",
+		"Stop payment reminders":                 "Parar de receber lembretes de cobrança",
+		"Confirm":                                "Confirmar",
+		"Stop receiving payment reminders at %s? Notices of newly issued bills will still be sent.":             "Deseja parar de receber lembretes de cobrança em %s? Os avisos de emissão de novos boletos continuarão sendo enviados.",
+		"You will no longer receive payment reminders at %s. Notices of newly issued bills will still be sent.": "Você não receberá mais lembretes de cobrança em %s. Os avisos de emissão de novos boletos continuarão sendo enviados.",
+		"Opt-outs found":                 "Descadastros encontrados",
+		"Opt-out removed":                "Descadastro removido",
+		"email is required":              "email é obrigatório",
+		"Failed to remove opt-out":       "Falha ao remover o descadastro",
+		"Invalid webhook URL":            "URL do webhook inválida",
+		"Invalid webhook event type":     "Tipo de evento do webhook inválido",
+		"Webhook subscription not found": "Inscrição de webhook não encontrada",
+		"Webhook delivery not found":     "Entrega de webhook não encontrada",
+		"Failed to create webhook":       "Falha ao criar o webhook",
+		"Webhook created":                "Webhook criado",
+		"Webhooks found":                 "Webhooks encontrados",
+		"Failed to remove webhook":       "Falha ao remover o webhook",
+		"Webhook removed":                "Webhook removido",
+		"Webhook deliveries found":       "Entregas do webhook encontradas",
+		"Failed to schedule redelivery":  "Falha ao agendar o reenvio",
+		"Webhook delivery scheduled":     "Reenvio do webhook agendado",
+		"Archive exceeds limits":         "Arquivo compactado acima dos limites",
+		"Invalid archive":                "Arquivo compactado inválido",
+		"Upload exceeds maximum size":    "Upload acima do tamanho máximo",
+		"Payload exceeds maximum size":   "Conteúdo acima do tamanho máximo",
+		"Form fields must precede files": "Os campos do formulário devem vir antes dos arquivos",
+		"Upload not found":               "Upload não encontrado",
+		"Upload found":                   "Upload encontrado",
+		"Upload created":                 "Upload criado",
+		"Invalid upload":                 "Upload inválido",
+		"Invalid upload part":            "Número da parte do upload inválido",
+		"Upload part stored":             "Parte do upload recebida",
+		"Upload already completed":       "Upload já concluído",
+		"Upload is being completed":      "Upload em conclusão",
+		"Upload parts missing":           "Partes do upload ausentes",
+		"Upload checksum mismatch":       "O checksum do upload não confere",
+		"Failed to store upload":         "Falha ao gravar o upload",
+		"Object storage not configured":  "Armazenamento de objetos não configurado",
+		"Invalid object location":        "Localização do objeto inválida",
+		"Bucket not allowed":             "Bucket não permitido",
+		"Object not found":               "Objeto não encontrado",
+		"Failed to download object":      "Falha ao baixar o objeto",
+		"Invalid file format":            "Formato de arquivo inválido",
+		"Failed to process files":        "Falha ao processar os arquivos",
+		"Invalid column mapping":         "Mapeamento de colunas inválido",
+		"Debts job not found":            "Envio de débitos não encontrado",
+		"Debts job found":                "Envio de débitos encontrado",
+		"Failed to process debts":        "Falha ao processar os débitos",
+		"Debts are being processed":      "Os débitos estão sendo processados",
+		"Unexpected error":               "Erro inesperado",
+	},
+}
+
+// notificationMessages reúne os textos das notificações enviadas ao devedor.
+var notificationMessages = map[Locale]map[string]string{
+	PtBR: {
+		"greeting.fallback":    "Olá",
+		"instructions.pay":     "Pague o boleto em qualquer banco, lotérica ou pelo aplicativo do seu banco até a data de vencimento.",
+		"instructions.late":    "Após o vencimento, serão cobrados multa e juros conforme as condições do contrato.",
+		"sms.notification":     "%s, seu boleto de %s vence em %s.",
+		"sms.reminder.before":  "Lembrete: %s, seu boleto de %s vence em %s.",
+		"sms.reminder.due":     "%s, seu boleto de %s vence hoje.",
+		"sms.reminder.overdue": "%s, seu boleto de %s venceu em %s e ainda não identificamos o pagamento.",
+		"sms.digitable_line":   " Linha digitável: %s",
+	},
+	EnUS: {
+		"greeting.fallback":    "Hello",
+		"instructions.pay":     "Pay the bank slip (boleto) at any bank or through your banking app by the due date.",
+		"instructions.late":    "After the due date, late fees and interest will be charged according to the contract terms.",
+		"sms.notification":     "%s, your bill of %s is due on %s.",
+		"sms.reminder.before":  "Reminder: %s, your bill of %s is due on %s.",
+		"sms.reminder.due":     "%s, your bill of %s is due today.",
+		"sms.reminder.overdue": "%s, your bill of %s was due on %s and we have not received the payment yet.",
+		"sms.digitable_line":   " Payment code: %s",
+	},
+}
+
+// API traduz uma mensagem de resposta da API. Mensagens sem tradução são devolvidas em
+// inglês.
+func (l Locale) API(message string) string {
+	if translated, ok := apiMessages[l][message]; ok {
+		return translated
+	}
+
+	return message
+}
+
+// Sprintf formata o texto de notificação identificado por key no idioma, usando o idioma
+// padrão quando não há tradução.
+func (l Locale) Sprintf(key string, args ...any) string {
+	format, ok := notificationMessages[l][key]
+	if !ok {
+		format, ok = notificationMessages[Default][key]
+	}
+
+	if !ok {
+		return key
+	}
+
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale identifica um idioma suportado pelas notificações e pela API (tag BCP 47).
+type Locale string
+
+const (
+	PtBR Locale = "pt-BR"
+	EnUS Locale = "en-US"
+)
+
+// Default é o idioma usado quando o débito, o cliente ou a requisição não definem outro.
+const Default = PtBR
+
+var supported = []Locale{PtBR, EnUS}
+
+// Parse reconhece uma tag de idioma, aceitando variações regionais e de caixa: "en",
+// "en-GB" e "EN_us" resultam em en-US.
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" {
+		return "", false
+	}
+
+	for _, locale := range supported {
+		if strings.ToLower(string(locale)) == tag {
+			return locale, true
+		}
+	}
+
+	language, _, _ := strings.Cut(tag, "-")
+	for _, locale := range supported {
+		if strings.HasPrefix(strings.ToLower(string(locale)), language+"-") {
+			return locale, true
+		}
+	}
+
+	return "", false
+}
+
+// Resolve devolve o idioma da tag ou Default quando ela é vazia ou não suportada.
+func Resolve(tag string) Locale {
+	if locale, ok := Parse(tag); ok {
+		return locale
+	}
+
+	return Default
+}
+
+// FromAcceptLanguage escolhe, entre os idiomas do cabeçalho Accept-Language, o suportado
+// de maior preferência (q). Sem correspondência, devolve fallback.
+func FromAcceptLanguage(header string, fallback Locale) Locale {
+	type candidate struct {
+		tag     string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if tag != "" && quality > 0 {
+			candidates = append(candidates, candidate{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].quality > candidates[j].quality })
+
+	for _, candidate := range candidates {
+		if locale, ok := Parse(candidate.tag); ok {
+			return locale
+		}
+	}
+
+	return fallback
+}
+
+// FormatMoney formata um valor em reais com os separadores do idioma.
+func (l Locale) FormatMoney(amount float64) string {
+	thousands, decimal, symbol := ".", ",", "R$ "
+	if l == EnUS {
+		thousands, decimal, symbol = ",", ".", "R$"
+	}
+
+	cents := int64(amount*100 + 0.5)
+	integer := fmt.Sprintf("%d", cents/100)
+
+	var grouped []string
+	for len(integer) > 3 {
+		grouped = append([]string{integer[len(integer)-3:]}, grouped...)
+		integer = integer[:len(integer)-3]
+	}
+	grouped = append([]string{integer}, grouped...)
+
+	return fmt.Sprintf("%s%s%s%02d", symbol, strings.Join(grouped, thousands), decimal, cents%100)
+}
+
+// FormatDate converte uma data no formato YYYY-MM-DD para o formato do idioma. Datas
+// inválidas são devolvidas sem alteração.
+func (l Locale) FormatDate(date string) string {
+	parsed, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return date
+	}
+
+	if l == EnUS {
+		return parsed.Format("01/02/2006")
+	}
+
+	return parsed.Format("02/01/2006")
+}
+
+// WhatsAppLanguage devolve o código de idioma usado pelos templates do WhatsApp.
+func (l Locale) WhatsAppLanguage() string {
+	return strings.ReplaceAll(string(l), "-", "_")
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag    string
+		locale Locale
+		ok     bool
+	}{
+		{tag: "pt-BR", locale: PtBR, ok: true},
+		{tag: "pt", locale: PtBR, ok: true},
+		{tag: "EN_us", locale: EnUS, ok: true},
+		{tag: "en-GB", locale: EnUS, ok: true},
+		{tag: "fr-FR", ok: false},
+		{tag: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			locale, ok := Parse(tt.tag)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.locale, locale)
+		})
+	}
+
+	assert.Equal(t, Default, Resolve("fr"))
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	assert.Equal(t, PtBR, FromAcceptLanguage("pt-BR,pt;q=0.9,en;q=0.8", EnUS))
+	assert.Equal(t, EnUS, FromAcceptLanguage("fr-FR, en;q=0.5, pt;q=0.4", PtBR))
+	assert.Equal(t, PtBR, FromAcceptLanguage("en;q=0, pt;q=0.1", EnUS))
+	assert.Equal(t, EnUS, FromAcceptLanguage("", EnUS))
+	assert.Equal(t, EnUS, FromAcceptLanguage("de", EnUS))
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "R$ 0,99", PtBR.FormatMoney(0.99))
+	assert.Equal(t, "R$ 100,00", PtBR.FormatMoney(100))
+	assert.Equal(t, "R$ 1.234.567,89", PtBR.FormatMoney(1234567.89))
+	assert.Equal(t, "R$1,234,567.89", EnUS.FormatMoney(1234567.89))
+}
+
+func TestFormatDate(t *testing.T) {
+	assert.Equal(t, "10/03/2025", PtBR.FormatDate("2025-03-10"))
+	assert.Equal(t, "03/10/2025", EnUS.FormatDate("2025-03-10"))
+	assert.Equal(t, "31/02/2025", EnUS.FormatDate("31/02/2025"))
+}
+
+func TestCatalogs(t *testing.T) {
+	assert.Equal(t, "Payload inválido", PtBR.API("Invalid payload"))
+	assert.Equal(t, "Invalid payload", EnUS.API("Invalid payload"))
+	assert.Equal(t, "Unknown message", PtBR.API("Unknown message"))
+
+	assert.Equal(t, "John, your bill of R$10.00 is due today.", EnUS.Sprintf("sms.reminder.due", "John", EnUS.FormatMoney(10)))
+	assert.Equal(t, "missing.key", EnUS.Sprintf("missing.key"))
+}
+
+// TestCatalogs_Complete garante que todo texto de notificação tem tradução em todos os
+// idiomas suportados.
+func TestCatalogs_Complete(t *testing.T) {
+	for _, locale := range supported {
+		for key := range notificationMessages[Default] {
+			_, ok := notificationMessages[locale][key]
+			assert.True(t, ok, "%s sem tradução para %s", key, locale)
+		}
+	}
+}
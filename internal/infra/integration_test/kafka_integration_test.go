@@ -10,13 +10,17 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/external"
 	"kanastra-api/internal/infra/adapter/kafka"
+	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/config"
 )
 
 type mockDebtRepository struct{}
 
-func (m *mockDebtRepository) Save(debtID string) error {
-	log.Printf("Mock repository: dívida salva com ID %s", debtID)
+func (m *mockDebtRepository) SaveWithOutbox(debtID string, messages []domain.OutboxMessage) error {
+	log.Printf("Mock repository: dívida salva com ID %s e %d mensagens no outbox", debtID, len(messages))
 
 	return nil
 }
@@ -46,14 +50,20 @@ func TestKafkaSetupIntegration(t *testing.T) {
 
 	mockRepo := &mockDebtRepository{}
 
-	producer, consumer := setup.Kafka(mockRepo)
+	producer, consumer := setup.Kafka(mockRepo, usecase.NewIssueInvoiceUseCase(
+		external.NewInvoiceGenerator(&config.Clients{}, domain.NewBusinessCalendar(), persistence.NewNossoNumeroSequence(), setup.Beneficiary(), setup.PixReceiver("Kanastra")),
+		persistence.NewInvoiceRepository(),
+		persistence.NewInvalidEmailRepository(),
+		persistence.NewContactPreferenceRepository(),
+		usecase.NewWebhookPublisher(persistence.NewWebhookSubscriptionRepository(), persistence.NewWebhookDeliveryRepository()),
+	))
 	defer setup.CloseKafka(producer, consumer)
 
 	externalEmail := &mockEmailPublisher{}
 	externalInvoice := &mockInvoiceGenerator{}
 
 	go func() {
-		consumerErr := consumer.Consume(func(debt domain.Debt, fileName string) {
+		consumerErr := consumer.Consume(func(debt domain.Debt, fileName string) ([]domain.OutboxMessage, error) {
 			log.Printf("Mensagem recebida: %+v", debt)
 
 			err := externalInvoice.Generate(debt)
@@ -63,6 +73,8 @@ func TestKafkaSetupIntegration(t *testing.T) {
 			assert.NoError(t, err, "Erro ao enviar email no mock")
 
 			log.Printf("Mensagem processada com sucesso no teste: %+v", debt)
+
+			return nil, nil
 		})
 		assert.NoError(t, consumerErr, "Erro no consumidor Kafka durante o consumo")
 	}()
@@ -0,0 +1,41 @@
+package setup
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/dropfolder"
+	"kanastra-api/internal/infra/adapter/filesource"
+	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/config"
+)
+
+// DropFolder lê os arquivos de débitos depositados em DROP_FOLDER_DIR, quando configurado,
+// como arquivos enviados a /process-files pelo cliente DROP_FOLDER_CLIENT_ID, e devolve a
+// função que encerra a leitura.
+func DropFolder(files *usecase.FileIntakeUseCase, jobs *persistence.IngestionJobRepository) context.CancelFunc {
+	dir := config.GetEnv("DROP_FOLDER_DIR", "")
+	if dir == "" {
+		return func() {}
+	}
+
+	interval, err := time.ParseDuration(config.GetEnv("DROP_FOLDER_POLL_INTERVAL", "10s"))
+	if err != nil {
+		log.Fatalf("Intervalo de leitura do diretório de arquivos inválido: %v", err)
+	}
+
+	stableFor, err := time.ParseDuration(config.GetEnv("DROP_FOLDER_STABLE_FOR", "30s"))
+	if err != nil {
+		log.Fatalf("Tempo de estabilidade dos arquivos inválido: %v", err)
+	}
+
+	clientID := config.GetEnv("DROP_FOLDER_CLIENT_ID", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := dropfolder.NewWatcher(dir, stableFor, filesource.ForClient(files, clientID), jobs)
+	go watcher.Run(ctx, interval)
+
+	return cancel
+}
@@ -5,39 +5,57 @@ import (
 	"time"
 
 	"kanastra-api/internal/core/domain"
-	"kanastra-api/internal/infra/adapter/external"
+	"kanastra-api/internal/core/usecase"
 	"kanastra-api/internal/infra/adapter/kafka"
 	"kanastra-api/internal/infra/config"
 )
 
-func Kafka(repo kafka.DebtRepositoryInterface) (*kafka.DynamicProducer, *kafka.Consumer) {
+func Kafka(repo kafka.DebtRepositoryInterface, issueUseCase *usecase.IssueInvoiceUseCase) (*kafka.DynamicProducer, *kafka.Consumer) {
 	broker := config.GetEnv("BROKER_ADDRESS", "localhost:9092")
 	topic := config.GetEnv("TOPIC", "default_topic")
 	groupID := config.GetEnv("GROUP_ID", "default_group")
+	// Sem DEAD_LETTER_TOPIC, as mensagens com falha não são desviadas.
+	deadLetterTopic := config.GetEnv("DEAD_LETTER_TOPIC", "")
 
 	kafka.WaitForKafka(broker, 60*time.Second)
 
 	producer := kafka.NewDynamicKafkaProducer(broker, topic)
-	consumer := kafka.NewKafkaConsumer(broker, topic, groupID, repo)
+	consumer, err := kafka.NewKafkaConsumer(broker, topic, groupID, deadLetterTopic, repo)
+	if err != nil {
+		log.Fatalf("Erro ao criar o consumer Kafka: %v", err)
+	}
 
-	go startKafkaConsumer(consumer, external.NewEmailPublisher(), external.NewInvoiceGenerator())
+	go startKafkaConsumer(consumer, issueUseCase)
 
 	return producer, consumer
 }
 
+func PaymentProducer() *kafka.DynamicProducer {
+	broker := config.GetEnv("BROKER_ADDRESS", "localhost:9092")
+	topic := config.GetEnv("PAYMENT_TOPIC", "payment_events")
+
+	return kafka.NewDynamicKafkaProducer(broker, topic)
+}
+
 func CloseKafka(producer *kafka.DynamicProducer, consumer *kafka.Consumer) {
 	producer.Close()
 	consumer.Close()
 }
 
-func startKafkaConsumer(consumer *kafka.Consumer, email *external.EmailPublisher, invoice *external.InvoiceGenerator) {
-	err := consumer.Consume(func(debt domain.Debt, filename string) {
+// startKafkaConsumer emite o boleto de cada débito recebido. A notificação não é enviada
+// aqui: ela é registrada no outbox junto com o débito e entregue pelo dispatcher.
+func startKafkaConsumer(consumer *kafka.Consumer, issueUseCase *usecase.IssueInvoiceUseCase) {
+	err := consumer.Consume(func(debt domain.Debt, filename string) ([]domain.OutboxMessage, error) {
 		log.Printf("Mensagem recebida: %+v", debt)
 
-		invoice.Generate(debt)
-		email.Publish(debt.Email, debt)
+		messages, err := issueUseCase.Issue(debt)
+		if err != nil {
+			return nil, err
+		}
 
 		log.Printf("Mensagem processada com sucesso: %+v", debt)
+
+		return messages, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,58 @@
+package setup
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"kanastra-api/internal/handler"
+	"kanastra-api/internal/infra/adapter/objectstore"
+	"kanastra-api/internal/infra/config"
+)
+
+// objectStorage conecta ao serviço compatível com S3 de S3_ENDPOINT, quando configurado,
+// de onde /process-files/from-url baixa os arquivos dos buckets de S3_SOURCE_BUCKETS,
+// separados por vírgulas. Com ARCHIVE_BUCKET, os arquivos enviados à API são arquivados
+// nesse bucket.
+func objectStorage() handler.ObjectStorage {
+	endpoint := config.GetEnv("S3_ENDPOINT", "")
+	if endpoint == "" {
+		return handler.ObjectStorage{}
+	}
+
+	pathStyle, err := strconv.ParseBool(config.GetEnv("S3_PATH_STYLE", "false"))
+	if err != nil {
+		log.Fatalf("S3_PATH_STYLE inválido: %v", err)
+	}
+
+	store, err := objectstore.NewS3(objectstore.Config{
+		Endpoint:  endpoint,
+		Region:    config.GetEnv("S3_REGION", "us-east-1"),
+		AccessKey: config.GetEnv("S3_ACCESS_KEY", ""),
+		SecretKey: config.GetEnv("S3_SECRET_KEY", ""),
+		PathStyle: pathStyle,
+	})
+	if err != nil {
+		log.Fatalf("Erro ao configurar armazenamento de objetos: %v", err)
+	}
+
+	var sources []string
+	for _, bucket := range strings.Split(config.GetEnv("S3_SOURCE_BUCKETS", ""), ",") {
+		bucket = strings.TrimSpace(bucket)
+		if bucket == "" {
+			continue
+		}
+
+		if !objectstore.ValidBucketName(bucket) {
+			log.Fatalf("Bucket de S3_SOURCE_BUCKETS inválido: %q", bucket)
+		}
+		sources = append(sources, bucket)
+	}
+
+	return handler.ObjectStorage{
+		Store:         store,
+		SourceBuckets: sources,
+		ArchiveBucket: config.GetEnv("ARCHIVE_BUCKET", ""),
+		ArchivePrefix: config.GetEnv("ARCHIVE_PREFIX", "uploads"),
+	}
+}
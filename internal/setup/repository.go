@@ -1,9 +1,71 @@
 package setup
 
 import (
+	"log"
+	"os"
+	"path/filepath"
+
 	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/config"
 )
 
 func Repository() *persistence.DebtRepository {
 	return persistence.NewDebtRepository()
 }
+
+func InvoiceRepository() *persistence.InvoiceRepository {
+	return persistence.NewInvoiceRepository()
+}
+
+func PaymentEventRepository() *persistence.PaymentEventRepository {
+	return persistence.NewPaymentEventRepository()
+}
+
+func InstallmentPlanRepository() *persistence.InstallmentPlanRepository {
+	return persistence.NewInstallmentPlanRepository()
+}
+
+func EmailDeliveryRepository() *persistence.EmailDeliveryRepository {
+	return persistence.NewEmailDeliveryRepository()
+}
+
+func InvalidEmailRepository() *persistence.InvalidEmailRepository {
+	return persistence.NewInvalidEmailRepository()
+}
+
+func ContactPreferenceRepository() *persistence.ContactPreferenceRepository {
+	return persistence.NewContactPreferenceRepository()
+}
+
+func LockRepository() *persistence.LockRepository {
+	return persistence.NewLockRepository()
+}
+
+func OptOutRepository() *persistence.OptOutRepository {
+	return persistence.NewOptOutRepository()
+}
+
+func WebhookSubscriptionRepository() *persistence.WebhookSubscriptionRepository {
+	return persistence.NewWebhookSubscriptionRepository()
+}
+
+func WebhookDeliveryRepository() *persistence.WebhookDeliveryRepository {
+	return persistence.NewWebhookDeliveryRepository()
+}
+
+func IngestionJobRepository() *persistence.IngestionJobRepository {
+	return persistence.NewIngestionJobRepository()
+}
+
+// UploadStore guarda as partes dos uploads retomáveis em UPLOAD_STORAGE_DIR, que deve
+// caber os maiores arquivos esperados.
+func UploadStore() *persistence.UploadStore {
+	dir := config.GetEnv("UPLOAD_STORAGE_DIR", filepath.Join(os.TempDir(), "kanastra-uploads"))
+
+	store, err := persistence.NewUploadStore(dir)
+	if err != nil {
+		log.Fatalf("Erro ao preparar armazenamento de uploads: %v", err)
+	}
+
+	return store
+}
@@ -1,16 +1,86 @@
 package setup
 
 import (
+	"log"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 
 	"kanastra-api/internal/core/usecase"
 	"kanastra-api/internal/handler"
+	"kanastra-api/internal/infra/adapter/archive"
+	"kanastra-api/internal/infra/config"
 )
 
-func Routes(useCase *usecase.ProcessFileUseCase) *gin.Engine {
+func Routes(
+	fileIntakeUseCase *usecase.FileIntakeUseCase,
+	reconcileUseCase *usecase.ReconcileReturnFileUseCase,
+	paymentUseCase *usecase.ProcessPaymentUseCase,
+	installmentUseCase *usecase.InstallmentPlanUseCase,
+	emailFeedbackUseCase *usecase.EmailFeedbackUseCase,
+	contactPreferencesUseCase *usecase.ContactPreferencesUseCase,
+	optOutUseCase *usecase.OptOutUseCase,
+	webhookUseCase *usecase.WebhookSubscriptionUseCase,
+	ingestUseCase *usecase.IngestDebtsUseCase,
+	uploadUseCase *usecase.ResumableUploadUseCase,
+) *gin.Engine {
 	router := gin.Default()
-	processFileHandler := handler.NewProcessFileHandler(useCase)
+	processFileHandler := handler.NewProcessFileHandler(fileIntakeUseCase, uploadMaxSize(), objectStorage())
 	processFileHandler.RegisterRoutes(router)
 
+	uploadHandler := handler.NewUploadHandler(uploadUseCase, processFileHandler)
+	uploadHandler.RegisterRoutes(router)
+
+	debtIngestionHandler := handler.NewDebtIngestionHandler(ingestUseCase, uploadMaxSize())
+	debtIngestionHandler.RegisterRoutes(router)
+
+	returnFileHandler := handler.NewReturnFileHandler(reconcileUseCase)
+	returnFileHandler.RegisterRoutes(router)
+
+	paymentWebhookHandler := handler.NewPaymentWebhookHandler(paymentUseCase, config.GetEnv("PAYMENT_WEBHOOK_SECRET", ""))
+	paymentWebhookHandler.RegisterRoutes(router)
+
+	installmentPlanHandler := handler.NewInstallmentPlanHandler(installmentUseCase)
+	installmentPlanHandler.RegisterRoutes(router)
+
+	emailFeedbackHandler := handler.NewEmailFeedbackHandler(emailFeedbackUseCase, config.GetEnv("EMAIL_WEBHOOK_SECRET", ""))
+	emailFeedbackHandler.RegisterRoutes(router)
+
+	contactPreferencesHandler := handler.NewContactPreferencesHandler(contactPreferencesUseCase)
+	contactPreferencesHandler.RegisterRoutes(router)
+
+	unsubscribeHandler := handler.NewUnsubscribeHandler(optOutUseCase)
+	unsubscribeHandler.RegisterRoutes(router)
+
+	webhookSubscriptionHandler := handler.NewWebhookSubscriptionHandler(webhookUseCase)
+	webhookSubscriptionHandler.RegisterRoutes(router)
+
 	return router
 }
+
+// archiveLimits lê os limites dos arquivos compactados e das planilhas: o tamanho
+// descompactado de cada arquivo, em MB, e o número de entradas de um zip.
+func archiveLimits() archive.Limits {
+	maxSize, err := strconv.ParseInt(config.GetEnv("UPLOAD_MAX_DECOMPRESSED_MB", "20480"), 10, 64)
+	if err != nil || maxSize <= 0 {
+		log.Fatalf("UPLOAD_MAX_DECOMPRESSED_MB inválido: %v", err)
+	}
+
+	maxEntries, err := strconv.Atoi(config.GetEnv("UPLOAD_MAX_ZIP_ENTRIES", "100"))
+	if err != nil || maxEntries <= 0 {
+		log.Fatalf("UPLOAD_MAX_ZIP_ENTRIES inválido: %v", err)
+	}
+
+	return archive.Limits{MaxDecompressedSize: maxSize << 20, MaxEntries: maxEntries}
+}
+
+// uploadMaxSize lê o tamanho máximo de um upload, em MB, seja enviado em uma requisição ou
+// em partes.
+func uploadMaxSize() int64 {
+	maxSize, err := strconv.ParseInt(config.GetEnv("UPLOAD_MAX_SIZE_MB", "10240"), 10, 64)
+	if err != nil || maxSize <= 0 {
+		log.Fatalf("UPLOAD_MAX_SIZE_MB inválido: %v", err)
+	}
+
+	return maxSize << 20
+}
@@ -1,12 +1,123 @@
 package setup
 
 import (
+	"log"
+
+	"kanastra-api/internal/core/domain"
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/boleto"
 	"kanastra-api/internal/infra/adapter/external"
+	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/adapter/pix"
+	"kanastra-api/internal/infra/config"
 )
 
-func Services() (*external.EmailPublisher, *external.InvoiceGenerator) {
-	email := external.NewEmailPublisher()
-	invoice := external.NewInvoiceGenerator()
+func Clients() *config.Clients {
+	clients, err := config.LoadClients(config.GetEnv("CLIENTS_CONFIG_FILE", ""))
+	if err != nil {
+		log.Fatalf("Erro ao carregar configuração de clientes: %v", err)
+	}
+
+	return clients
+}
+
+func BusinessCalendar() *domain.BusinessCalendar {
+	holidays, err := config.LoadHolidays(config.GetEnv("MUNICIPAL_HOLIDAYS_FILE", ""))
+	if err != nil {
+		log.Fatalf("Erro ao carregar feriados municipais: %v", err)
+	}
+
+	return domain.NewBusinessCalendar(holidays...)
+}
+
+func Services(
+	clients *config.Clients,
+	calendar *domain.BusinessCalendar,
+	unsubscribe external.UnsubscribeLinks,
+) (usecase.EmailPublisher, *external.InvoiceGenerator) {
+	beneficiary := Beneficiary()
+	email := emailPublisher(external.NewPaymentDocuments(beneficiary), unsubscribe)
+	invoice := external.NewInvoiceGenerator(clients, calendar, persistence.NewNossoNumeroSequence(), beneficiary, PixReceiver(beneficiary.Name))
 
 	return email, invoice
 }
+
+func Beneficiary() boleto.Beneficiary {
+	beneficiary := boleto.Beneficiary{
+		Name:     config.GetEnv("BENEFICIARY_NAME", "Kanastra"),
+		Document: config.GetEnv("BENEFICIARY_DOCUMENT", ""),
+		BankCode: config.GetEnv("BOLETO_BANK_CODE", "237"),
+		Agency:   config.GetEnv("BOLETO_AGENCY", "0001"),
+		Wallet:   config.GetEnv("BOLETO_WALLET", "09"),
+		Account:  config.GetEnv("BOLETO_ACCOUNT", "0000001"),
+	}
+
+	if err := beneficiary.Validate(); err != nil {
+		log.Fatalf("Erro na configuração do beneficiário dos boletos: %v", err)
+	}
+
+	return beneficiary
+}
+
+func PixReceiver(name string) pix.Receiver {
+	return pix.Receiver{
+		Name:        config.GetEnv("PIX_RECEIVER_NAME", name),
+		City:        config.GetEnv("PIX_RECEIVER_CITY", "SAO PAULO"),
+		LocationURL: config.GetEnv("PIX_LOCATION_URL", "pix.kanastra.com.br/qr/v2"),
+	}
+}
+
+// emailPublisher usa o envio por SMTP quando SMTP_HOST está configurado e, caso
+// contrário, apenas registra os e-mails no log.
+func emailPublisher(documents external.InvoiceDocuments, unsubscribe external.UnsubscribeLinks) usecase.EmailPublisher {
+	host := config.GetEnv("SMTP_HOST", "")
+	if host == "" {
+		log.Println("SMTP_HOST não configurado, e-mails serão apenas registrados no log")
+
+		return external.NewEmailPublisher()
+	}
+
+	publisher, err := external.NewSMTPEmailPublisher(external.SMTPConfig{
+		Host:            host,
+		Port:            config.GetEnv("SMTP_PORT", "587"),
+		Username:        config.GetEnv("SMTP_USERNAME", ""),
+		Password:        config.GetEnv("SMTP_PASSWORD", ""),
+		From:            config.GetEnv("SMTP_FROM", "cobranca@kanastra.com.br"),
+		FromName:        config.GetEnv("SMTP_FROM_NAME", "Kanastra Cobrança"),
+		StartTLS:        config.GetEnv("SMTP_STARTTLS", "true") == "true",
+		TemplateVersion: config.GetEnv("EMAIL_TEMPLATE_VERSION", "v1"),
+	}, documents, unsubscribe)
+	if err != nil {
+		log.Fatalf("Erro ao configurar envio de e-mails por SMTP: %v", err)
+	}
+
+	return publisher
+}
+
+// Notifiers devolve os canais de notificação configurados. O e-mail está sempre
+// disponível; SMS e WhatsApp só são habilitados quando SMS_API_URL e WHATSAPP_API_URL
+// estão definidos.
+func Notifiers(email usecase.EmailPublisher) []usecase.Notifier {
+	notifiers := []usecase.Notifier{usecase.NewEmailNotifier(email, config.GetEnv("SMTP_HOST", "log"))}
+
+	if url := config.GetEnv("SMS_API_URL", ""); url != "" {
+		notifiers = append(notifiers, external.NewSMSNotifier(external.SMSConfig{
+			BaseURL: url,
+			Token:   config.GetEnv("SMS_API_TOKEN", ""),
+			Sender:  config.GetEnv("SMS_SENDER", "Kanastra"),
+		}))
+	}
+
+	if url := config.GetEnv("WHATSAPP_API_URL", ""); url != "" {
+		notifiers = append(notifiers, external.NewWhatsAppNotifier(external.WhatsAppConfig{
+			BaseURL:          url,
+			Token:            config.GetEnv("WHATSAPP_TOKEN", ""),
+			PhoneNumberID:    config.GetEnv("WHATSAPP_PHONE_NUMBER_ID", ""),
+			Template:         config.GetEnv("WHATSAPP_TEMPLATE", "debt_notification"),
+			ReminderTemplate: config.GetEnv("WHATSAPP_REMINDER_TEMPLATE", "debt_reminder"),
+			Language:         config.GetEnv("WHATSAPP_TEMPLATE_LANGUAGE", "pt_BR"),
+		}))
+	}
+
+	return notifiers
+}
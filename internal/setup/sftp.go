@@ -0,0 +1,79 @@
+package setup
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/filesource"
+	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/adapter/sftpsource"
+	"kanastra-api/internal/infra/config"
+)
+
+// SFTPSources verifica os servidores SFTP de SFTP_SOURCES_FILE, quando configurado, cada
+// um no seu intervalo, e devolve a função que encerra as verificações. Os arquivos baixados
+// ficam registrados em SFTP_STATE_FILE.
+func SFTPSources(files *usecase.FileIntakeUseCase, jobs *persistence.IngestionJobRepository) context.CancelFunc {
+	sources, err := config.LoadSFTPSources(config.GetEnv("SFTP_SOURCES_FILE", ""))
+	if err != nil {
+		log.Fatalf("Erro ao carregar origens SFTP: %v", err)
+	}
+
+	if len(sources) == 0 {
+		return func() {}
+	}
+
+	ledger, err := persistence.NewRemoteFileRepository(config.GetEnv("SFTP_STATE_FILE", ""))
+	if err != nil {
+		log.Fatalf("Erro ao carregar registro de arquivos SFTP: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, source := range sources {
+		poller := sftpsource.NewPoller(sftpServer(source), ledger, jobs, filesource.ForClient(files, source.ClientID))
+		go poller.Run(ctx, time.Duration(source.Interval))
+	}
+
+	return cancel
+}
+
+func sftpServer(source config.SFTPSource) sftpsource.Server {
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(source.HostKey))
+	if err != nil {
+		log.Fatalf("Chave do servidor SFTP %s inválida: %v", source.Name, err)
+	}
+
+	var auth []ssh.AuthMethod
+	if source.PrivateKeyFile != "" {
+		key, err := os.ReadFile(source.PrivateKeyFile)
+		if err != nil {
+			log.Fatalf("Erro ao ler chave privada do SFTP %s: %v", source.Name, err)
+		}
+
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			log.Fatalf("Chave privada do SFTP %s inválida: %v", source.Name, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if source.Password != "" {
+		auth = append(auth, ssh.Password(source.Password))
+	}
+
+	return sftpsource.Server{
+		Name:      source.Name,
+		Address:   source.Address,
+		User:      source.User,
+		Auth:      auth,
+		HostKey:   hostKey,
+		Directory: source.Directory,
+		Pattern:   source.Pattern,
+		MinAge:    time.Duration(source.MinAge),
+	}
+}
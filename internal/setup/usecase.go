@@ -1,17 +1,321 @@
 package setup
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanastra-api/internal/core/domain"
 	"kanastra-api/internal/core/usecase"
+	"kanastra-api/internal/infra/adapter/cnab"
+	"kanastra-api/internal/infra/adapter/dsn"
 	"kanastra-api/internal/infra/adapter/external"
+	"kanastra-api/internal/infra/adapter/fileformat"
 	"kanastra-api/internal/infra/adapter/kafka"
 	"kanastra-api/internal/infra/adapter/persistence"
+	"kanastra-api/internal/infra/config"
 )
 
 func UseCase(
 	repo *persistence.DebtRepository,
-	email *external.EmailPublisher,
+	email usecase.EmailPublisher,
 	invoice *external.InvoiceGenerator,
 	producer *kafka.DynamicProducer,
+	webhooks usecase.WebhookEventPublisher,
+	jobs *persistence.IngestionJobRepository,
 ) *usecase.ProcessFileUseCase {
-	return usecase.NewProcessFileUseCase(repo, email, invoice, producer)
+	return usecase.NewProcessFileUseCase(repo, email, invoice, producer, webhooks, jobs)
+}
+
+// FileIntakeUseCase entrega os arquivos de débitos recebidos pela API, pelo drop folder e
+// pelos servidores SFTP ao processamento, com os mapeamentos de colunas dos clientes.
+func FileIntakeUseCase(useCase *usecase.ProcessFileUseCase, clients *config.Clients) *usecase.FileIntakeUseCase {
+	return usecase.NewFileIntakeUseCase(useCase, clients, fileformat.NewDecoder(archiveLimits()))
+}
+
+func IngestDebtsUseCase(
+	useCase *usecase.ProcessFileUseCase,
+	jobs *persistence.IngestionJobRepository,
+) *usecase.IngestDebtsUseCase {
+	return usecase.NewIngestDebtsUseCase(useCase, jobs)
+}
+
+// ResumableUploadUseCase remove os uploads que ficam UPLOAD_EXPIRATION sem receber partes.
+func ResumableUploadUseCase(store *persistence.UploadStore) *usecase.ResumableUploadUseCase {
+	expiration, err := time.ParseDuration(config.GetEnv("UPLOAD_EXPIRATION", "24h"))
+	if err != nil || expiration <= 0 {
+		log.Fatalf("Expiração dos uploads inválida: %v", err)
+	}
+
+	return usecase.NewResumableUploadUseCase(store, uploadMaxSize(), expiration)
+}
+
+// UploadCleanup remove os uploads expirados a cada UPLOAD_CLEANUP_INTERVAL e devolve a
+// função que encerra a limpeza.
+func UploadCleanup(uploads *usecase.ResumableUploadUseCase) context.CancelFunc {
+	interval, err := time.ParseDuration(config.GetEnv("UPLOAD_CLEANUP_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("Intervalo de limpeza dos uploads inválido: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go uploads.Run(ctx, interval)
+
+	return cancel
+}
+
+func ReconcileUseCase(
+	invoices *persistence.InvoiceRepository,
+	clients *config.Clients,
+	calendar *domain.BusinessCalendar,
+	webhooks usecase.WebhookEventPublisher,
+) *usecase.ReconcileReturnFileUseCase {
+	return usecase.NewReconcileReturnFileUseCase(cnab.NewParser(), invoices, clients, calendar, webhooks)
+}
+
+func PaymentUseCase(
+	invoices *persistence.InvoiceRepository,
+	events *persistence.PaymentEventRepository,
+	producer *kafka.DynamicProducer,
+	clients *config.Clients,
+	calendar *domain.BusinessCalendar,
+	webhooks usecase.WebhookEventPublisher,
+) *usecase.ProcessPaymentUseCase {
+	return usecase.NewProcessPaymentUseCase(invoices, events, producer, clients, calendar, webhooks)
+}
+
+func InstallmentPlanUseCase(
+	invoices *persistence.InvoiceRepository,
+	plans *persistence.InstallmentPlanRepository,
+	invoice *external.InvoiceGenerator,
+) *usecase.InstallmentPlanUseCase {
+	return usecase.NewInstallmentPlanUseCase(invoices, plans, invoice)
+}
+
+// OutboxDispatcher inicia a entrega das mensagens do outbox em background e devolve a
+// função que a encerra.
+func OutboxDispatcher(
+	repo *persistence.DebtRepository,
+	deliveries *persistence.EmailDeliveryRepository,
+	invalidEmails *persistence.InvalidEmailRepository,
+	optOuts *persistence.OptOutRepository,
+	notifiers []usecase.Notifier,
+	webhooks usecase.WebhookEventPublisher,
+) context.CancelFunc {
+	interval, err := time.ParseDuration(config.GetEnv("OUTBOX_DISPATCH_INTERVAL", "5s"))
+	if err != nil {
+		log.Fatalf("Intervalo de despacho do outbox inválido: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	throttle := usecase.NewNotificationThrottle(throttlePolicy(), repo)
+	dispatcher := usecase.NewDispatchOutboxUseCase(repo, deliveries, invalidEmails, optOuts, notifiers, throttle, webhooks, usecase.DefaultOutboxRetryPolicy())
+	go dispatcher.Run(ctx, interval)
+
+	return cancel
+}
+
+// notificationLocation é o fuso de NOTIFICATION_TIMEZONE, usado no horário de silêncio e
+// nos dias da régua de cobrança.
+func notificationLocation() *time.Location {
+	location, err := time.LoadLocation(config.GetEnv("NOTIFICATION_TIMEZONE", "America/Sao_Paulo"))
+	if err != nil {
+		log.Fatalf("Fuso horário das notificações inválido: %v", err)
+	}
+
+	return location
+}
+
+// throttlePolicy lê os limites de envio das notificações. Limites não configurados não são
+// aplicados.
+func throttlePolicy() usecase.ThrottlePolicy {
+	quietHours, err := domain.ParseQuietHours(config.GetEnv("NOTIFICATION_QUIET_HOURS", ""), notificationLocation())
+	if err != nil {
+		log.Fatalf("Erro ao configurar o horário de silêncio: %v", err)
+	}
+
+	policy := usecase.ThrottlePolicy{
+		QuietHours:     quietHours,
+		DebtorCap:      rateLimit("NOTIFICATION_DEBTOR_CAP"),
+		ChannelLimits:  make(map[domain.Channel]domain.RateLimit),
+		ProviderLimits: make(map[string]domain.RateLimit),
+	}
+
+	for channel, key := range map[domain.Channel]string{
+		domain.ChannelEmail:    "EMAIL_RATE_LIMIT",
+		domain.ChannelSMS:      "SMS_RATE_LIMIT",
+		domain.ChannelWhatsApp: "WHATSAPP_RATE_LIMIT",
+	} {
+		policy.ChannelLimits[channel] = rateLimit(key)
+	}
+
+	// NOTIFICATION_PROVIDER_RATE_LIMITS tem o formato host=limite separado por vírgulas,
+	// como smtp.example.com=100/s,api.sms.example.com=20/s.
+	for _, entry := range strings.Split(config.GetEnv("NOTIFICATION_PROVIDER_RATE_LIMITS", ""), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		provider, value, _ := strings.Cut(entry, "=")
+		limit, err := domain.ParseRateLimit(value)
+		if err != nil {
+			log.Fatalf("Limite de envio do provedor %s inválido: %v", provider, err)
+		}
+
+		policy.ProviderLimits[strings.TrimSpace(provider)] = limit
+	}
+
+	return policy
+}
+
+func rateLimit(key string) domain.RateLimit {
+	value := config.GetEnv(key, "")
+	if value == "" {
+		return domain.RateLimit{}
+	}
+
+	limit, err := domain.ParseRateLimit(value)
+	if err != nil {
+		log.Fatalf("%s inválido: %v", key, err)
+	}
+
+	return limit
+}
+
+// DunningScheduler agenda os lembretes da régua de cobrança a cada DUNNING_INTERVAL e
+// devolve a função que encerra o agendamento. O agendador só roda com
+// DUNNING_SCHEDULER_ENABLED=true: como o lock da régua vale só dentro do processo, ele deve
+// ser ativado em uma única réplica.
+func DunningScheduler(
+	repo *persistence.DebtRepository,
+	invoices *persistence.InvoiceRepository,
+	contacts *persistence.ContactPreferenceRepository,
+	invalidEmails *persistence.InvalidEmailRepository,
+	clients *config.Clients,
+	lock *persistence.LockRepository,
+) context.CancelFunc {
+	enabled, err := strconv.ParseBool(config.GetEnv("DUNNING_SCHEDULER_ENABLED", "false"))
+	if err != nil {
+		log.Fatalf("DUNNING_SCHEDULER_ENABLED inválido: %v", err)
+	}
+
+	if !enabled {
+		log.Println("Régua de cobrança desativada nesta réplica")
+
+		return func() {}
+	}
+
+	interval, err := time.ParseDuration(config.GetEnv("DUNNING_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("Intervalo da régua de cobrança inválido: %v", err)
+	}
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler := usecase.NewDunningUseCase(invoices, repo, contacts, invalidEmails, clients, lock, owner, notificationLocation())
+	go scheduler.Run(ctx, interval)
+
+	return cancel
+}
+
+func EmailFeedbackUseCase(
+	repo *persistence.DebtRepository,
+	deliveries *persistence.EmailDeliveryRepository,
+	invalidEmails *persistence.InvalidEmailRepository,
+) *usecase.EmailFeedbackUseCase {
+	return usecase.NewEmailFeedbackUseCase(repo, deliveries, invalidEmails)
+}
+
+func IssueInvoiceUseCase(
+	invoice *external.InvoiceGenerator,
+	invoices *persistence.InvoiceRepository,
+	invalidEmails *persistence.InvalidEmailRepository,
+	contacts *persistence.ContactPreferenceRepository,
+	webhooks usecase.WebhookEventPublisher,
+) *usecase.IssueInvoiceUseCase {
+	return usecase.NewIssueInvoiceUseCase(invoice, invoices, invalidEmails, contacts, webhooks)
+}
+
+func ContactPreferencesUseCase(contacts *persistence.ContactPreferenceRepository) *usecase.ContactPreferencesUseCase {
+	return usecase.NewContactPreferencesUseCase(contacts)
+}
+
+// OptOutUseCase configura os links de descadastro de lembretes. Sem UNSUBSCRIBE_SECRET e
+// UNSUBSCRIBE_BASE_URL, os e-mails seguem sem link.
+func OptOutUseCase(optOuts *persistence.OptOutRepository) *usecase.OptOutUseCase {
+	secret := config.GetEnv("UNSUBSCRIBE_SECRET", "")
+	baseURL := config.GetEnv("UNSUBSCRIBE_BASE_URL", "")
+	if secret == "" || baseURL == "" {
+		log.Println("UNSUBSCRIBE_SECRET ou UNSUBSCRIBE_BASE_URL não configurados, e-mails serão enviados sem link de descadastro")
+	}
+
+	return usecase.NewOptOutUseCase(optOuts, secret, baseURL)
+}
+
+func WebhookPublisher(
+	subscriptions *persistence.WebhookSubscriptionRepository,
+	deliveries *persistence.WebhookDeliveryRepository,
+) *usecase.WebhookPublisher {
+	return usecase.NewWebhookPublisher(subscriptions, deliveries)
+}
+
+func WebhookSubscriptionUseCase(
+	subscriptions *persistence.WebhookSubscriptionRepository,
+	deliveries *persistence.WebhookDeliveryRepository,
+) *usecase.WebhookSubscriptionUseCase {
+	return usecase.NewWebhookSubscriptionUseCase(subscriptions, deliveries)
+}
+
+// WebhookDispatcher inicia o envio dos webhooks dos clientes em background e devolve a
+// função que o encerra.
+func WebhookDispatcher(
+	subscriptions *persistence.WebhookSubscriptionRepository,
+	deliveries *persistence.WebhookDeliveryRepository,
+) context.CancelFunc {
+	interval, err := time.ParseDuration(config.GetEnv("WEBHOOK_DISPATCH_INTERVAL", "5s"))
+	if err != nil {
+		log.Fatalf("Intervalo de despacho dos webhooks inválido: %v", err)
+	}
+
+	timeout, err := time.ParseDuration(config.GetEnv("WEBHOOK_TIMEOUT", "10s"))
+	if err != nil {
+		log.Fatalf("Timeout dos webhooks inválido: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sender := external.NewWebhookSender(timeout)
+	dispatcher := usecase.NewDispatchWebhooksUseCase(subscriptions, deliveries, sender, usecase.DefaultOutboxRetryPolicy())
+	go dispatcher.Run(ctx, interval)
+
+	return cancel
+}
+
+// DSNPoller lê as notificações de entrega depositadas em EMAIL_DSN_DIR, quando
+// configurado, e devolve a função que encerra a leitura.
+func DSNPoller(feedbackUseCase *usecase.EmailFeedbackUseCase) context.CancelFunc {
+	dir := config.GetEnv("EMAIL_DSN_DIR", "")
+	if dir == "" {
+		return func() {}
+	}
+
+	interval, err := time.ParseDuration(config.GetEnv("EMAIL_DSN_POLL_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("Intervalo de leitura de DSN inválido: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := dsn.NewDirectoryPoller(dir, func(feedback domain.EmailFeedback) error {
+		_, err := feedbackUseCase.Process(feedback)
+
+		return err
+	})
+	go poller.Run(ctx, interval)
+
+	return cancel
 }